@@ -14,11 +14,14 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"bandcash/internal/backplane"
 	internalbilling "bandcash/internal/billing"
 	"bandcash/internal/db"
 	"bandcash/internal/i18n"
 	"bandcash/internal/middleware"
 	"bandcash/internal/utils"
+	"bandcash/models/recurrence"
+	shared "bandcash/models/shared"
 )
 
 func main() {
@@ -59,6 +62,9 @@ func main() {
 		slog.Error("failed to load locales", "err", err)
 		os.Exit(1)
 	}
+	utils.SSEHub.SetUpdateNotice(shared.UpdateNotice(true))
+	utils.SSEHub.SetPresenceView(shared.Presence)
+	utils.SSEHub.SetNotificationsView(shared.Notifications())
 
 	// Initialize database
 	err = db.Init(cfg.DBPath)
@@ -78,10 +84,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	bp, err := backplane.New(backplane.Config{
+		Backend:  cfg.Backplane,
+		DB:       db.BunDB,
+		Interval: cfg.BackplanePoll,
+	})
+	if err != nil {
+		slog.Error("failed to set up backplane", "err", err)
+		os.Exit(1)
+	}
+	utils.SSEHub.SetBackplane(bp)
+
 	startErr := make(chan error, 1)
 	lifecycleCtx, lifecycleCancel := context.WithCancel(context.Background())
 	defer lifecycleCancel()
 
+	// Apply hub messages from other instances sharing the backplane.
+	go func() {
+		if err := utils.SSEHub.Run(lifecycleCtx); err != nil {
+			slog.Error("backplane stopped", "err", err)
+		}
+	}()
+
 	// Graceful shutdown
 	go func() {
 		addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
@@ -105,6 +129,9 @@ func main() {
 		}
 	}()
 
+	// Keep recurring events and expenses generated ahead of time.
+	go recurrence.RunScheduler(lifecycleCtx, time.Hour)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -9,15 +9,24 @@ import (
 	"bandcash/internal/utils"
 	"bandcash/models/account"
 	"bandcash/models/admin"
+	"bandcash/models/analytics"
+	"bandcash/models/attachment"
+	"bandcash/models/audit"
 	"bandcash/models/auth"
 	billingmodel "bandcash/models/billing"
+	"bandcash/models/calendar"
 	"bandcash/models/dev"
 	"bandcash/models/event"
 	"bandcash/models/expense"
 	"bandcash/models/group"
 	"bandcash/models/health"
 	"bandcash/models/home"
+	"bandcash/models/importer"
+	"bandcash/models/invoice"
 	"bandcash/models/member"
+	"bandcash/models/payment"
+	"bandcash/models/quote"
+	"bandcash/models/recurrence"
 	"bandcash/models/sse"
 )
 
@@ -42,6 +51,7 @@ func registerRoutes(e *echo.Echo) {
 	e.GET("/login/verify", auth.VerifyMagicLink)
 	e.DELETE("/session", auth.Logout)
 	e.POST("/lemon_webhook", billingmodel.LemonWebhook)
+	e.GET("/calendar/:token", calendar.Feed)
 
 	adminRoutes := e.Group("/admin", middleware.RequireAuth, middleware.RequireSuperadmin)
 	adminRoutes.GET("", admin.Dashboard)
@@ -100,6 +110,7 @@ func registerRoutes(e *echo.Echo) {
 
 	eventRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
 	eventRoutes.GET("/events", event.IndexPage)
+	eventRoutes.GET("/events.csv", event.Export)
 	eventRoutes.GET("/overview", func(c echo.Context) error {
 		groupID := c.Param("groupId")
 		return c.Redirect(http.StatusMovedPermanently, "/groups/"+groupID+"/events")
@@ -111,9 +122,12 @@ func registerRoutes(e *echo.Echo) {
 	eventAdminRoutes.GET("/events/new", event.NewEventPage)
 	eventAdminRoutes.GET("/events/:id/edit", event.EditEventPage)
 	eventAdminRoutes.GET("/events/:id/participant/edit", event.EditEventParticipantsPage)
+	eventAdminRoutes.GET("/events/:id/cancel", event.CancelEventPage)
 	eventAdminRoutes.POST("/events", event.Create)
 	eventAdminRoutes.POST("/events/:id", event.Update)
 	eventAdminRoutes.POST("/events/:id/details", event.UpdateDetails)
+	eventAdminRoutes.POST("/events/:id/cancel", event.CancelEvent)
+	eventAdminRoutes.POST("/events/:id/restore", event.RestoreEvent)
 	eventAdminRoutes.POST("/events/:id/paid", event.TogglePaid)
 	eventAdminRoutes.GET("/events/:id/paid_at", event.OpenPaidAtPrompt)
 	eventAdminRoutes.POST("/events/:id/paid_at", event.UpdatePaidAt)
@@ -129,26 +143,105 @@ func registerRoutes(e *echo.Echo) {
 
 	expenseRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
 	expenseRoutes.GET("/expenses", expense.IndexPage)
+	expenseRoutes.GET("/expenses.csv", expense.Export)
+	expenseRoutes.GET("/expenses/categories", expense.CategoriesPage)
 	expenseRoutes.GET("/expenses/:id", expense.ShowPage)
 
 	expenseAdminRoutes := expenseRoutes.Group("", middleware.RequireAdmin)
 	expenseAdminRoutes.GET("/expenses/new", expense.NewExpensePage)
 	expenseAdminRoutes.GET("/expenses/:id/edit", expense.EditExpensePage)
 	expenseAdminRoutes.POST("/expenses", expense.Create)
+	expenseAdminRoutes.POST("/expenses/categories", expense.CreateCategory)
+	expenseAdminRoutes.DELETE("/expenses/categories/:id", expense.DeleteCategory)
 	expenseAdminRoutes.PUT("/expenses/:id", expense.Update)
 	expenseAdminRoutes.DELETE("/expenses/:id", expense.Destroy)
 	expenseAdminRoutes.PUT("/expenses/:id/toggle-paid", expense.TogglePaid)
 	expenseAdminRoutes.GET("/expenses/:id/paid_at", expense.OpenPaidAtPrompt)
 	expenseAdminRoutes.POST("/expenses/:id/paid_at", expense.UpdatePaidAt)
 
+	analyticsRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
+	analyticsRoutes.GET("/analytics", analytics.IndexPage)
+
+	recurrenceRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
+	recurrenceRoutes.GET("/recurrences", recurrence.IndexPage)
+	recurrenceRoutes.GET("/recurrences/:id", recurrence.ShowPage)
+
+	recurrenceAdminRoutes := recurrenceRoutes.Group("", middleware.RequireAdmin)
+	recurrenceAdminRoutes.GET("/recurrences/new", recurrence.NewRecurrencePage)
+	recurrenceAdminRoutes.GET("/recurrences/:id/edit", recurrence.EditRecurrencePage)
+	recurrenceAdminRoutes.POST("/recurrences", recurrence.Create)
+	recurrenceAdminRoutes.PUT("/recurrences/:id", recurrence.Update)
+	recurrenceAdminRoutes.POST("/recurrences/:id/generate", recurrence.Generate)
+	recurrenceAdminRoutes.DELETE("/recurrences/:id", recurrence.Destroy)
+
+	quoteRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
+	quoteRoutes.GET("/quotes", quote.IndexPage)
+	quoteRoutes.GET("/quotes/:id", quote.ShowPage)
+
+	quoteAdminRoutes := quoteRoutes.Group("", middleware.RequireAdmin)
+	quoteAdminRoutes.GET("/quotes/new", quote.NewQuotePage)
+	quoteAdminRoutes.GET("/quotes/:id/edit", quote.EditQuotePage)
+	quoteAdminRoutes.POST("/quotes", quote.Create)
+	quoteAdminRoutes.PUT("/quotes/:id", quote.Update)
+	quoteAdminRoutes.POST("/quotes/:id/send", quote.Send)
+	quoteAdminRoutes.POST("/quotes/:id/accept", quote.Accept)
+	quoteAdminRoutes.POST("/quotes/:id/reject", quote.Reject)
+	quoteAdminRoutes.POST("/quotes/:id/invoiced", quote.MarkInvoiced)
+	quoteAdminRoutes.DELETE("/quotes/:id", quote.Destroy)
+
+	attachmentRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
+	attachmentRoutes.GET("/attachments/:id", attachment.Download)
+
+	attachmentAdminRoutes := attachmentRoutes.Group("", middleware.RequireAdmin)
+	attachmentAdminRoutes.POST("/events/:id/attachments", attachment.UploadForEvent, middleware.UploadBodyLimit)
+	attachmentAdminRoutes.POST("/expenses/:id/attachments", attachment.UploadForExpense, middleware.UploadBodyLimit)
+	attachmentAdminRoutes.DELETE("/attachments/:id", attachment.Destroy)
+
+	invoiceRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
+	invoiceRoutes.GET("/events/:id/invoice.pdf", invoice.Download)
+
+	invoiceAdminRoutes := invoiceRoutes.Group("", middleware.RequireAdmin)
+	invoiceAdminRoutes.POST("/events/:id/invoice", invoice.Issue)
+
+	paymentAdminRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup, middleware.RequireAdmin)
+	paymentAdminRoutes.POST("/events/:id/payments", payment.Create)
+	paymentAdminRoutes.DELETE("/events/:id/payments/:paymentId", payment.Destroy)
+	paymentAdminRoutes.POST("/events/:id/payouts", payment.CreatePayout)
+	paymentAdminRoutes.DELETE("/events/:id/payouts/:payoutId", payment.DestroyPayout)
+
+	calendarRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
+	calendarRoutes.GET("/calendar", calendar.IndexPage)
+	calendarRoutes.POST("/calendar/token", calendar.Rotate)
+	calendarRoutes.DELETE("/calendar/token", calendar.Revoke)
+
+	calendarAdminRoutes := calendarRoutes.Group("", middleware.RequireAdmin)
+	calendarAdminRoutes.PUT("/calendar/settings", calendar.UpdateSettings)
+
+	auditRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup, middleware.RequireAdmin)
+	auditRoutes.GET("/audit", audit.IndexPage)
+
+	importRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup, middleware.RequireAdmin)
+	importRoutes.GET("/import", importer.IndexPage)
+	importRoutes.POST("/import/upload", importer.Upload)
+	importRoutes.POST("/import/preview", importer.Preview)
+	importRoutes.POST("/import", importer.Commit)
+
 	memberRoutes := e.Group("/groups/:groupId", middleware.RequireAuth, middleware.RequireWithinSubscriptionLimit, middleware.RequireGroup)
 	memberRoutes.GET("/members", member.Index)
+	memberRoutes.GET("/members.csv", member.Export)
+	memberRoutes.GET("/members/settle", member.Settle)
+	memberRoutes.GET("/members/withholding", member.Withholding)
 	memberRoutes.GET("/members/:id", member.Show)
+	memberRoutes.GET("/members/:id/statement", member.StatementPage)
+	memberRoutes.GET("/members/:id/statement.pdf", member.StatementPDF)
+	memberRoutes.GET("/members/:id/statement.csv", member.StatementCSV)
 
 	memberAdminRoutes := memberRoutes.Group("", middleware.RequireAdmin)
 	memberAdminRoutes.GET("/members/new", member.NewMemberPage)
+	memberAdminRoutes.GET("/members/statements.zip", member.StatementsZip)
 	memberAdminRoutes.GET("/members/:id/edit", member.EditMemberPage)
 	memberAdminRoutes.POST("/members", member.Create)
+	memberAdminRoutes.POST("/members/settle", member.ConfirmSettlement)
 	memberAdminRoutes.PUT("/members/:id", member.Update)
 	memberAdminRoutes.GET("/members/:id/events/:eventId/paid_at", member.OpenParticipantPaidAtDialog)
 	memberAdminRoutes.POST("/members/:id/events/:eventId/paid_at", member.UpdateParticipantPaidAt)
@@ -0,0 +1,45 @@
+package backplane
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Message is a change to the SSE hub, sent to every app instance so the one
+// holding a tab's connection can act on it.
+type Message struct {
+	Instance string          `json:"instance"`
+	Kind     string          `json:"kind"`
+	Tab      string          `json:"tab"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// Backplane carries hub messages between the app instances behind one proxy.
+type Backplane interface {
+	// Publish sends msg to every listening instance, the sender included.
+	Publish(ctx context.Context, msg Message) error
+	// Listen calls handle with each message published after it starts,
+	// in order, until ctx is done.
+	Listen(ctx context.Context, handle func(Message)) error
+}
+
+type Config struct {
+	Backend  string
+	DB       *bun.DB
+	Interval time.Duration
+}
+
+func New(cfg Config) (Backplane, error) {
+	switch cfg.Backend {
+	case "memory":
+		return NewMemory(), nil
+	case "sqlite":
+		return newSQLite(cfg.DB, cfg.Interval)
+	default:
+		return nil, fmt.Errorf("backplane: unknown backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,160 @@
+package backplane
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"bandcash/internal/db"
+)
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "backplane_test.sqlite")
+	if err := db.Init(dbPath); err != nil {
+		t.Fatalf("db.Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
+	}
+}
+
+// recorder collects the messages a listener was handed.
+type recorder struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+func (r *recorder) handle(msg Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+}
+
+func (r *recorder) waitFor(t *testing.T, n int) []Message {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		if len(r.msgs) >= n {
+			msgs := append([]Message(nil), r.msgs...)
+			r.mu.Unlock()
+			return msgs
+		}
+		r.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("got fewer than %d messages", n)
+	return nil
+}
+
+func listen(t *testing.T, bp Backplane) *recorder {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := bp.Listen(ctx, rec.handle); err != nil {
+			t.Errorf("Listen: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return rec
+}
+
+func TestMemoryDeliversToEveryListener(t *testing.T) {
+	t.Parallel()
+
+	bp := NewMemory()
+	a := listen(t, bp)
+	b := listen(t, bp)
+	// Listeners register from their own goroutines.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bp.mu.RLock()
+		n := len(bp.handlers)
+		bp.mu.RUnlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	msg := Message{Instance: "hub_a", Kind: "send", Tab: "tab_1", Body: []byte(`{}`)}
+	if err := bp.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	for name, rec := range map[string]*recorder{"a": a, "b": b} {
+		got := rec.waitFor(t, 1)
+		if got[0].Instance != "hub_a" || got[0].Tab != "tab_1" {
+			t.Fatalf("listener %s got %+v", name, got[0])
+		}
+	}
+}
+
+func TestSQLiteDeliversInOrderToBothInstances(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+
+	// A message from before the listeners started is not replayed.
+	first, err := New(Config{Backend: "sqlite", DB: db.BunDB, Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := first.Publish(ctx, Message{Instance: "hub_a", Kind: "connect", Tab: "tab_old"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	second, err := New(Config{Backend: "sqlite", DB: db.BunDB, Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	a := listen(t, first)
+	b := listen(t, second)
+	time.Sleep(50 * time.Millisecond)
+
+	for i, tab := range []string{"tab_1", "tab_2", "tab_3"} {
+		from := first
+		if i%2 == 1 {
+			from = second
+		}
+		msg := Message{Instance: "hub_a", Kind: "send", Tab: tab, Body: []byte(`{"event":{"type":"elements"}}`)}
+		if err := from.Publish(ctx, msg); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	for name, rec := range map[string]*recorder{"a": a, "b": b} {
+		got := rec.waitFor(t, 3)
+		if len(got) != 3 {
+			t.Fatalf("listener %s got %d messages, want 3", name, len(got))
+		}
+		for i, tab := range []string{"tab_1", "tab_2", "tab_3"} {
+			if got[i].Tab != tab || got[i].Kind != "send" || string(got[i].Body) != `{"event":{"type":"elements"}}` {
+				t.Fatalf("listener %s message %d = %+v", name, i, got[i])
+			}
+		}
+	}
+}
+
+func TestNewRejectsUnknownBackend(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(Config{Backend: "redis"}); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+	if _, err := New(Config{Backend: "sqlite"}); err == nil {
+		t.Fatal("expected an error without a database")
+	}
+}
@@ -0,0 +1,48 @@
+package backplane
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory delivers messages to the hubs of one process. It is all a single
+// instance needs, and lets tests run several hubs side by side.
+type Memory struct {
+	mu       sync.RWMutex
+	next     int
+	handlers map[int]func(Message)
+}
+
+func NewMemory() *Memory {
+	return &Memory{handlers: make(map[int]func(Message))}
+}
+
+// Publish calls every listener before it returns.
+func (m *Memory) Publish(_ context.Context, msg Message) error {
+	m.mu.RLock()
+	handlers := make([]func(Message), 0, len(m.handlers))
+	for _, handle := range m.handlers {
+		handlers = append(handlers, handle)
+	}
+	m.mu.RUnlock()
+
+	for _, handle := range handlers {
+		handle(msg)
+	}
+	return nil
+}
+
+func (m *Memory) Listen(ctx context.Context, handle func(Message)) error {
+	m.mu.Lock()
+	id := m.next
+	m.next++
+	m.handlers[id] = handle
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	m.mu.Lock()
+	delete(m.handlers, id)
+	m.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,116 @@
+package backplane
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+)
+
+const (
+	// messageRetention is how long messages stay in hub_messages. Listeners
+	// only read what was written since they started, so this only has to
+	// outlast a slow poll.
+	messageRetention = time.Minute
+	pollBatch        = 500
+)
+
+// sqliteBackplane passes messages through the hub_messages table, for
+// instances that share one database file. Each instance polls for rows newer
+// than the last one it has seen.
+type sqliteBackplane struct {
+	db       *bun.DB
+	interval time.Duration
+}
+
+func newSQLite(bunDB *bun.DB, interval time.Duration) (*sqliteBackplane, error) {
+	if bunDB == nil {
+		return nil, errors.New("backplane: sqlite needs a database")
+	}
+	if interval <= 0 {
+		return nil, errors.New("backplane: sqlite poll interval must be positive")
+	}
+	return &sqliteBackplane{db: bunDB, interval: interval}, nil
+}
+
+func (s *sqliteBackplane) Publish(ctx context.Context, msg Message) error {
+	row := db.HubMessage{
+		Instance: msg.Instance,
+		Kind:     msg.Kind,
+		Tab:      msg.Tab,
+		Body:     string(msg.Body),
+	}
+	_, err := s.db.NewInsert().Model(&row).ExcludeColumn("id", "created_at").Exec(ctx)
+	return err
+}
+
+func (s *sqliteBackplane) Listen(ctx context.Context, handle func(Message)) error {
+	var last int64
+	err := s.db.NewSelect().
+		Model((*db.HubMessage)(nil)).
+		ColumnExpr("COALESCE(MAX(id), 0)").
+		Scan(ctx, &last)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	cleaned := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		last = s.poll(ctx, last, handle)
+
+		if time.Since(cleaned) > messageRetention {
+			cleaned = time.Now()
+			_, err := s.db.NewDelete().
+				Model((*db.HubMessage)(nil)).
+				Where("created_at < ?", time.Now().UTC().Add(-messageRetention).Format(time.DateTime)).
+				Exec(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("backplane: failed to delete old messages", "err", err)
+			}
+		}
+	}
+}
+
+// poll hands every message after last to handle and returns the id of the
+// newest one.
+func (s *sqliteBackplane) poll(ctx context.Context, last int64, handle func(Message)) int64 {
+	for {
+		var rows []db.HubMessage
+		err := s.db.NewSelect().
+			Model(&rows).
+			Where("id > ?", last).
+			Order("id").
+			Limit(pollBatch).
+			Scan(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("backplane: failed to read messages", "err", err)
+			}
+			return last
+		}
+		for _, row := range rows {
+			last = row.ID
+			handle(Message{
+				Instance: row.Instance,
+				Kind:     row.Kind,
+				Tab:      row.Tab,
+				Body:     []byte(row.Body),
+			})
+		}
+		if len(rows) < pollBatch {
+			return last
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package currency lists the currencies a group can keep entries in and
+// converts entry amounts into the group's base currency.
+//
+// Amounts are stored and shown as whole units of their currency.
+package currency
+
+import (
+	"math"
+	"strings"
+)
+
+// Default is the base currency of new groups.
+const Default = "HUF"
+
+type Currency struct {
+	Code   string
+	Symbol string
+	// SymbolFirst puts the symbol before the amount in English, as in "€10".
+	// Hungarian always writes the symbol after the amount.
+	SymbolFirst bool
+}
+
+var all = []Currency{
+	{Code: "HUF", Symbol: "Ft"},
+	{Code: "EUR", Symbol: "€", SymbolFirst: true},
+	{Code: "USD", Symbol: "$", SymbolFirst: true},
+	{Code: "GBP", Symbol: "£", SymbolFirst: true},
+	{Code: "CHF", Symbol: "CHF"},
+	{Code: "CZK", Symbol: "Kč"},
+	{Code: "PLN", Symbol: "zł"},
+	{Code: "RON", Symbol: "lei"},
+}
+
+// All returns the supported currencies in display order.
+func All() []Currency {
+	return append([]Currency(nil), all...)
+}
+
+// Lookup finds a currency by its ISO 4217 code, ignoring case.
+func Lookup(code string) (Currency, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, c := range all {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return Currency{}, false
+}
+
+// IsValid reports whether code is a supported currency.
+func IsValid(code string) bool {
+	_, ok := Lookup(code)
+	return ok
+}
+
+// Normalize returns the upper-case code, or "" when it isn't supported.
+func Normalize(code string) string {
+	c, ok := Lookup(code)
+	if !ok {
+		return ""
+	}
+	return c.Code
+}
+
+// Of returns the currency an entry is kept in: its own, or the base currency
+// when it has none.
+func Of(entry, base string) string {
+	if entry = Normalize(entry); entry != "" {
+		return entry
+	}
+	if base = Normalize(base); base != "" {
+		return base
+	}
+	return Default
+}
+
+// ForEntry normalises the currency and exchange rate stored on an event or
+// expense. Entries in the base currency are stored with no currency and a
+// rate of 1 so a later change of base currency doesn't leave stale rates.
+func ForEntry(code string, rate float64, base string) (string, float64) {
+	code = Normalize(code)
+	if code == "" || code == Of("", base) || !ValidRate(rate) {
+		return "", 1
+	}
+	return code, rate
+}
+
+// ValidRate reports whether rate can be used for conversion.
+func ValidRate(rate float64) bool {
+	return rate > 0 && !math.IsInf(rate, 0) && !math.IsNaN(rate)
+}
+
+// ToBase converts an amount at the given exchange rate into the base
+// currency, rounded to whole units. Invalid rates count as 1.
+func ToBase(amount int64, rate float64) int64 {
+	if !ValidRate(rate) || rate == 1 {
+		return amount
+	}
+	return int64(math.Round(float64(amount) * rate))
+}
+
+// FromBase converts a base currency amount back into an entry's currency at
+// the given exchange rate, rounded to whole units. Invalid rates count as 1.
+func FromBase(amount int64, rate float64) int64 {
+	if !ValidRate(rate) || rate == 1 {
+		return amount
+	}
+	return int64(math.Round(float64(amount) / rate))
+}
@@ -0,0 +1,85 @@
+package currency
+
+import "testing"
+
+func TestForEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code     string
+		rate     float64
+		base     string
+		wantCode string
+		wantRate float64
+	}{
+		{"", 3, "HUF", "", 1},
+		{"huf", 1, "HUF", "", 1},
+		{"eur", 395.5, "HUF", "EUR", 395.5},
+		{"EUR", 0, "HUF", "", 1},
+		{"EUR", -2, "HUF", "", 1},
+		{"XYZ", 2, "HUF", "", 1},
+		{"HUF", 0.0025, "EUR", "HUF", 0.0025},
+		{"HUF", 2, "", "", 1},
+	}
+	for _, tt := range tests {
+		code, rate := ForEntry(tt.code, tt.rate, tt.base)
+		if code != tt.wantCode || rate != tt.wantRate {
+			t.Errorf("ForEntry(%q, %v, %q) = %q, %v; want %q, %v", tt.code, tt.rate, tt.base, code, rate, tt.wantCode, tt.wantRate)
+		}
+	}
+}
+
+func TestToBase(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{1000, 1, 1000},
+		{100, 395.5, 39550},
+		{3, 0.5, 2},
+		{-3, 0.5, -2},
+		{1000, 0, 1000},
+	}
+	for _, tt := range tests {
+		if got := ToBase(tt.amount, tt.rate); got != tt.want {
+			t.Errorf("ToBase(%d, %v) = %d; want %d", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestFromBase(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{1000, 1, 1000},
+		{39550, 395.5, 100},
+		{3, 2, 2},
+		{1000, 0, 1000},
+	}
+	for _, tt := range tests {
+		if got := FromBase(tt.amount, tt.rate); got != tt.want {
+			t.Errorf("FromBase(%d, %v) = %d; want %d", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestOf(t *testing.T) {
+	t.Parallel()
+
+	if got := Of("", "eur"); got != "EUR" {
+		t.Errorf("Of(\"\", eur) = %q; want EUR", got)
+	}
+	if got := Of("usd", "EUR"); got != "USD" {
+		t.Errorf("Of(usd, EUR) = %q; want USD", got)
+	}
+	if got := Of("", ""); got != Default {
+		t.Errorf("Of(\"\", \"\") = %q; want %q", got, Default)
+	}
+}
@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS trg_recurrences_updated_at;
+DROP INDEX IF EXISTS idx_expenses_recurrence_occurrence;
+DROP INDEX IF EXISTS idx_expenses_recurrence_id;
+DROP INDEX IF EXISTS idx_events_recurrence_occurrence;
+DROP INDEX IF EXISTS idx_events_recurrence_id;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- recurrence_id/recurrence_date stay on events and expenses; clear the links instead.
+UPDATE events SET recurrence_id = NULL, recurrence_date = '';
+UPDATE expenses SET recurrence_id = NULL, recurrence_date = '';
+
+DROP TABLE IF EXISTS recurrence_participants;
+
+-- The recurrence_id columns still reference recurrences, so the table stays,
+-- emptied, or every write to events and expenses would fail.
+DELETE FROM recurrences;
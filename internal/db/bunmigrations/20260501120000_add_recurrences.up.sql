@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS recurrences (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('event', 'expense')),
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    place TEXT NOT NULL DEFAULT '',
+    event_time TEXT NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL,
+    freq TEXT NOT NULL CHECK (freq IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval INTEGER NOT NULL DEFAULT 1 CHECK (interval > 0),
+    start_date TEXT NOT NULL,
+    end_date TEXT NOT NULL DEFAULT '',
+    count INTEGER NOT NULL DEFAULT 0,
+    generated_until TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_recurrences_group_id ON recurrences(group_id);
+
+CREATE TABLE IF NOT EXISTS recurrence_participants (
+    recurrence_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    amount INTEGER NOT NULL DEFAULT 0,
+    expense INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (recurrence_id, member_id),
+    FOREIGN KEY (recurrence_id) REFERENCES recurrences(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_recurrence_participants_member_id ON recurrence_participants(member_id);
+
+ALTER TABLE events ADD COLUMN recurrence_id TEXT REFERENCES recurrences(id) ON DELETE SET NULL;
+ALTER TABLE events ADD COLUMN recurrence_date TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_events_recurrence_id ON events(recurrence_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_recurrence_occurrence ON events(recurrence_id, recurrence_date) WHERE recurrence_id IS NOT NULL;
+
+ALTER TABLE expenses ADD COLUMN recurrence_id TEXT REFERENCES recurrences(id) ON DELETE SET NULL;
+ALTER TABLE expenses ADD COLUMN recurrence_date TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_expenses_recurrence_id ON expenses(recurrence_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expenses_recurrence_occurrence ON expenses(recurrence_id, recurrence_date) WHERE recurrence_id IS NOT NULL;
+
+CREATE TRIGGER IF NOT EXISTS trg_recurrences_updated_at
+AFTER UPDATE ON recurrences
+FOR EACH ROW
+BEGIN
+    UPDATE recurrences SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
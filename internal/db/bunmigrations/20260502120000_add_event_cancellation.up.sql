@@ -0,0 +1,46 @@
+ALTER TABLE events ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled'));
+ALTER TABLE events ADD COLUMN cancel_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN cancellation_fee INTEGER NOT NULL DEFAULT 0 CHECK (cancellation_fee >= 0);
+ALTER TABLE events ADD COLUMN cancelled_at TEXT;
+ALTER TABLE participants ADD COLUMN compensation INTEGER NOT NULL DEFAULT 0 CHECK (compensation >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_events_group_status ON events(group_id, status);
+
+-- Cancelled events pay out the compensation instead of cut + expense, and
+-- participants without compensation drop out of the payment lists.
+DROP VIEW IF EXISTS group_outgoing_payments;
+CREATE VIEW IF NOT EXISTS group_outgoing_payments AS
+SELECT
+  p.group_id AS group_id,
+  'participant' AS payment_kind,
+  CAST(p.event_id || ':' || p.member_id AS TEXT) AS payment_id,
+  CAST(p.event_id AS TEXT) AS event_id,
+  CAST(p.member_id AS TEXT) AS member_id,
+  CAST(m.name AS TEXT) AS member_name,
+  CAST(e.title AS TEXT) AS event_title,
+  e.title AS title,
+  CAST(CASE WHEN e.status = 'cancelled' THEN p.compensation ELSE p.amount + p.expense END AS INTEGER) AS amount,
+  p.paid AS paid,
+  p.paid_at AS paid_at,
+  p.updated_at AS updated_at,
+  e.time AS sort_date
+FROM participants p
+JOIN members m ON m.id = p.member_id AND m.group_id = p.group_id
+JOIN events e ON e.id = p.event_id AND e.group_id = p.group_id
+WHERE e.status <> 'cancelled' OR p.compensation > 0
+UNION ALL
+SELECT
+  ex.group_id AS group_id,
+  'expense' AS payment_kind,
+  CAST(ex.id AS TEXT) AS payment_id,
+  '' AS event_id,
+  '' AS member_id,
+  '' AS member_name,
+  '' AS event_title,
+  ex.title AS title,
+  CAST(ex.amount AS INTEGER) AS amount,
+  ex.paid AS paid,
+  ex.paid_at AS paid_at,
+  ex.updated_at AS updated_at,
+  ex.date AS sort_date
+FROM expenses ex;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_attachments_hash;
+DROP INDEX IF EXISTS idx_attachments_expense_id;
+DROP INDEX IF EXISTS idx_attachments_event_id;
+DROP INDEX IF EXISTS idx_attachments_group_id;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    event_id TEXT,
+    expense_id TEXT,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL CHECK (size >= 0),
+    hash TEXT NOT NULL,
+    uploaded_by TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((event_id IS NULL) <> (expense_id IS NULL)),
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (expense_id) REFERENCES expenses(id) ON DELETE CASCADE,
+    FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_group_id ON attachments(group_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_event_id ON attachments(event_id) WHERE event_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_expense_id ON attachments(expense_id) WHERE expense_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_hash ON attachments(hash);
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_quotes_updated_at;
+DROP TABLE IF EXISTS quote_items;
+DROP INDEX IF EXISTS idx_quotes_event_id;
+DROP INDEX IF EXISTS idx_quotes_status;
+DROP INDEX IF EXISTS idx_quotes_group_id;
+DROP TABLE IF EXISTS quotes;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS quotes (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    client_name TEXT NOT NULL,
+    client_email TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL,
+    place TEXT NOT NULL DEFAULT '',
+    event_date TEXT NOT NULL,
+    event_time TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    valid_until TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sent', 'accepted', 'rejected', 'invoiced')),
+    total INTEGER NOT NULL DEFAULT 0,
+    event_id TEXT REFERENCES events(id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_quotes_group_id ON quotes(group_id);
+CREATE INDEX IF NOT EXISTS idx_quotes_status ON quotes(status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quotes_event_id ON quotes(event_id) WHERE event_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS quote_items (
+    quote_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL DEFAULT 0 CHECK (unit_price >= 0),
+    PRIMARY KEY (quote_id, position),
+    FOREIGN KEY (quote_id) REFERENCES quotes(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_quotes_updated_at
+AFTER UPDATE ON quotes
+FOR EACH ROW
+BEGIN
+    UPDATE quotes SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
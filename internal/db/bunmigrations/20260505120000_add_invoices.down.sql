@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS trg_invoice_lines_immutable;
+DROP TRIGGER IF EXISTS trg_invoices_immutable;
+DROP TABLE IF EXISTS invoice_lines;
+DROP INDEX IF EXISTS idx_invoices_event_id;
+DROP TABLE IF EXISTS invoices;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- billing_name/billing_address/tax_number stay on groups.
@@ -0,0 +1,53 @@
+ALTER TABLE groups ADD COLUMN billing_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN billing_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN tax_number TEXT NOT NULL DEFAULT '';
+
+-- Invoices keep a snapshot of both parties and the rendered PDF, so later
+-- edits to the group or event never change an issued document.
+CREATE TABLE IF NOT EXISTS invoices (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    event_id TEXT REFERENCES events(id) ON DELETE SET NULL,
+    number INTEGER NOT NULL CHECK (number > 0),
+    issue_date TEXT NOT NULL,
+    performance_date TEXT NOT NULL,
+    seller_name TEXT NOT NULL,
+    seller_address TEXT NOT NULL DEFAULT '',
+    seller_tax_number TEXT NOT NULL DEFAULT '',
+    buyer_name TEXT NOT NULL,
+    buyer_address TEXT NOT NULL DEFAULT '',
+    buyer_tax_number TEXT NOT NULL DEFAULT '',
+    total INTEGER NOT NULL CHECK (total >= 0),
+    pdf BLOB NOT NULL,
+    issued_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (group_id, number),
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_event_id ON invoices(event_id) WHERE event_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    invoice_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price INTEGER NOT NULL CHECK (unit_price >= 0),
+    PRIMARY KEY (invoice_id, position),
+    FOREIGN KEY (invoice_id) REFERENCES invoices(id) ON DELETE CASCADE
+);
+
+-- Only the foreign keys may change (set to NULL when the event or issuing
+-- user is deleted); everything printed on the invoice is frozen.
+CREATE TRIGGER IF NOT EXISTS trg_invoices_immutable
+BEFORE UPDATE OF id, group_id, number, issue_date, performance_date, seller_name, seller_address, seller_tax_number, buyer_name, buyer_address, buyer_tax_number, total, pdf ON invoices
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'issued invoices cannot be changed');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_invoice_lines_immutable
+BEFORE UPDATE ON invoice_lines
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'issued invoices cannot be changed');
+END;
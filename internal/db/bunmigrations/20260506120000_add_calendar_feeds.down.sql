@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_calendar_feeds_group_id;
+DROP TABLE IF EXISTS calendar_feeds;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- calendar_show_amounts stays on groups.
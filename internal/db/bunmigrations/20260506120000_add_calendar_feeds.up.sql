@@ -0,0 +1,16 @@
+ALTER TABLE groups ADD COLUMN calendar_show_amounts INTEGER NOT NULL DEFAULT 0;
+
+-- One secret feed token per user and group. The token is the only credential
+-- for the .ics URL, so rotating or deleting the row revokes the old link.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    group_id TEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    last_used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, group_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_feeds_group_id ON calendar_feeds(group_id);
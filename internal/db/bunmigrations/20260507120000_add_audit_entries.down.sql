@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS audit_entries_no_delete;
+DROP TRIGGER IF EXISTS audit_entries_no_update;
+DROP INDEX IF EXISTS idx_audit_entries_entity;
+DROP INDEX IF EXISTS idx_audit_entries_group_created;
+DROP TABLE IF EXISTS audit_entries;
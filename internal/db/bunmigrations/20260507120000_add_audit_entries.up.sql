@@ -0,0 +1,32 @@
+-- Append-only log of changes per group. Actor email and entity label are
+-- copied in so entries stay readable after the user or entity is deleted.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    actor_user_id TEXT NOT NULL DEFAULT '',
+    actor_email TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    entity_label TEXT NOT NULL DEFAULT '',
+    diff TEXT NOT NULL DEFAULT '{}',
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_group_created ON audit_entries(group_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_entity ON audit_entries(group_id, entity_type, entity_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_update
+BEFORE UPDATE ON audit_entries
+BEGIN
+    SELECT RAISE(ABORT, 'audit_entries is append-only');
+END;
+
+-- Rows only go away with their group.
+CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete
+BEFORE DELETE ON audit_entries
+WHEN EXISTS (SELECT 1 FROM groups WHERE groups.id = OLD.group_id)
+BEGIN
+    SELECT RAISE(ABORT, 'audit_entries is append-only');
+END;
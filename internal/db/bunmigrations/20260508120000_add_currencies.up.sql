@@ -0,0 +1,52 @@
+-- Every group keeps its books in one base currency. Events and expenses can
+-- be entered in another currency with a manually entered exchange rate:
+-- one unit of the entry currency is worth exchange_rate units of the base.
+-- An empty currency means the entry is in the group's base currency.
+ALTER TABLE groups ADD COLUMN base_currency TEXT NOT NULL DEFAULT 'HUF';
+ALTER TABLE events ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN exchange_rate REAL NOT NULL DEFAULT 1 CHECK (exchange_rate > 0);
+ALTER TABLE expenses ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE expenses ADD COLUMN exchange_rate REAL NOT NULL DEFAULT 1 CHECK (exchange_rate > 0);
+
+-- Outgoing payments carry the entry currency so the payment lists can show
+-- amounts as entered and convert them for totals.
+DROP VIEW IF EXISTS group_outgoing_payments;
+CREATE VIEW IF NOT EXISTS group_outgoing_payments AS
+SELECT
+  p.group_id AS group_id,
+  'participant' AS payment_kind,
+  CAST(p.event_id || ':' || p.member_id AS TEXT) AS payment_id,
+  CAST(p.event_id AS TEXT) AS event_id,
+  CAST(p.member_id AS TEXT) AS member_id,
+  CAST(m.name AS TEXT) AS member_name,
+  CAST(e.title AS TEXT) AS event_title,
+  e.title AS title,
+  CAST(CASE WHEN e.status = 'cancelled' THEN p.compensation ELSE p.amount + p.expense END AS INTEGER) AS amount,
+  e.currency AS currency,
+  e.exchange_rate AS exchange_rate,
+  p.paid AS paid,
+  p.paid_at AS paid_at,
+  p.updated_at AS updated_at,
+  e.time AS sort_date
+FROM participants p
+JOIN members m ON m.id = p.member_id AND m.group_id = p.group_id
+JOIN events e ON e.id = p.event_id AND e.group_id = p.group_id
+WHERE e.status <> 'cancelled' OR p.compensation > 0
+UNION ALL
+SELECT
+  ex.group_id AS group_id,
+  'expense' AS payment_kind,
+  CAST(ex.id AS TEXT) AS payment_id,
+  '' AS event_id,
+  '' AS member_id,
+  '' AS member_name,
+  '' AS event_title,
+  ex.title AS title,
+  CAST(ex.amount AS INTEGER) AS amount,
+  ex.currency AS currency,
+  ex.exchange_rate AS exchange_rate,
+  ex.paid AS paid,
+  ex.paid_at AS paid_at,
+  ex.updated_at AS updated_at,
+  ex.date AS sort_date
+FROM expenses ex;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_event_payments_group_id;
+DROP INDEX IF EXISTS idx_event_payments_event_id;
+DROP TABLE IF EXISTS event_payments;
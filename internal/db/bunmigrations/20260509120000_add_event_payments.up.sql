@@ -0,0 +1,29 @@
+-- Payments received for an event's income. events.paid / events.paid_at are
+-- derived from these rows: paid once they cover the income, dated by the
+-- latest payment.
+CREATE TABLE IF NOT EXISTS event_payments (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    paid_at TEXT NOT NULL,
+    method TEXT NOT NULL DEFAULT '',
+    reference TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_event_payments_event_id ON event_payments(event_id, paid_at);
+CREATE INDEX IF NOT EXISTS idx_event_payments_group_id ON event_payments(group_id);
+
+-- Events already marked paid get one payment for their full income.
+INSERT INTO event_payments (id, group_id, event_id, amount, paid_at)
+SELECT
+    'pay_' || lower(hex(randomblob(10))),
+    group_id,
+    id,
+    CASE WHEN status = 'cancelled' THEN cancellation_fee ELSE amount END,
+    substr(COALESCE(paid_at, updated_at, created_at), 1, 10)
+FROM events
+WHERE paid = 1
+  AND (CASE WHEN status = 'cancelled' THEN cancellation_fee ELSE amount END) > 0;
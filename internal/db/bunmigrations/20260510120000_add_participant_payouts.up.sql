@@ -0,0 +1,77 @@
+-- Payouts made to a participant of an event. participants.paid /
+-- participants.paid_at are derived from these rows: paid once they cover the
+-- participant's cut and expense (or compensation), dated by the latest payout.
+CREATE TABLE IF NOT EXISTS participant_payouts (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    paid_at TEXT NOT NULL,
+    method TEXT NOT NULL DEFAULT '',
+    reference TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id, member_id) REFERENCES participants(event_id, member_id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_participant_payouts_participant ON participant_payouts(event_id, member_id, paid_at);
+CREATE INDEX IF NOT EXISTS idx_participant_payouts_group_id ON participant_payouts(group_id);
+
+-- Participants already marked paid get one payout for what they were owed.
+INSERT INTO participant_payouts (id, group_id, event_id, member_id, amount, paid_at)
+SELECT
+    'out_' || lower(hex(randomblob(10))),
+    p.group_id,
+    p.event_id,
+    p.member_id,
+    CASE WHEN e.status = 'cancelled' THEN p.compensation ELSE p.amount + p.expense END,
+    substr(COALESCE(p.paid_at, p.updated_at, p.created_at), 1, 10)
+FROM participants p
+JOIN events e ON e.id = p.event_id
+WHERE p.paid = 1
+  AND (CASE WHEN e.status = 'cancelled' THEN p.compensation ELSE p.amount + p.expense END) > 0;
+
+-- paid_amount is the part already paid out, so the payment lists can show
+-- partial payouts. Unpaid participants are dated by their latest payout.
+DROP VIEW IF EXISTS group_outgoing_payments;
+CREATE VIEW IF NOT EXISTS group_outgoing_payments AS
+SELECT
+  p.group_id AS group_id,
+  'participant' AS payment_kind,
+  CAST(p.event_id || ':' || p.member_id AS TEXT) AS payment_id,
+  CAST(p.event_id AS TEXT) AS event_id,
+  CAST(p.member_id AS TEXT) AS member_id,
+  CAST(m.name AS TEXT) AS member_name,
+  CAST(e.title AS TEXT) AS event_title,
+  e.title AS title,
+  CAST(CASE WHEN e.status = 'cancelled' THEN p.compensation ELSE p.amount + p.expense END AS INTEGER) AS amount,
+  CAST(COALESCE((SELECT SUM(pp.amount) FROM participant_payouts pp WHERE pp.event_id = p.event_id AND pp.member_id = p.member_id), 0) AS INTEGER) AS paid_amount,
+  e.currency AS currency,
+  e.exchange_rate AS exchange_rate,
+  p.paid AS paid,
+  COALESCE(p.paid_at, (SELECT MAX(pp.paid_at) FROM participant_payouts pp WHERE pp.event_id = p.event_id AND pp.member_id = p.member_id)) AS paid_at,
+  p.updated_at AS updated_at,
+  e.time AS sort_date
+FROM participants p
+JOIN members m ON m.id = p.member_id AND m.group_id = p.group_id
+JOIN events e ON e.id = p.event_id AND e.group_id = p.group_id
+WHERE e.status <> 'cancelled' OR p.compensation > 0
+UNION ALL
+SELECT
+  ex.group_id AS group_id,
+  'expense' AS payment_kind,
+  CAST(ex.id AS TEXT) AS payment_id,
+  '' AS event_id,
+  '' AS member_id,
+  '' AS member_name,
+  '' AS event_title,
+  ex.title AS title,
+  CAST(ex.amount AS INTEGER) AS amount,
+  CAST(CASE WHEN ex.paid = 1 THEN ex.amount ELSE 0 END AS INTEGER) AS paid_amount,
+  ex.currency AS currency,
+  ex.exchange_rate AS exchange_rate,
+  ex.paid AS paid,
+  ex.paid_at AS paid_at,
+  ex.updated_at AS updated_at,
+  ex.date AS sort_date
+FROM expenses ex;
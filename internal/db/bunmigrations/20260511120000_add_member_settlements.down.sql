@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_member_handovers_group_id;
+DROP INDEX IF EXISTS idx_member_handovers_member_id;
+DROP TABLE IF EXISTS member_handovers;
+
+DROP INDEX IF EXISTS idx_participant_payouts_paid_by;
+DROP INDEX IF EXISTS idx_event_payments_collected_by;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- collected_by/paid_by stay on the ledgers; clear the links instead.
+UPDATE participant_payouts SET paid_by = NULL;
+UPDATE event_payments SET collected_by = NULL;
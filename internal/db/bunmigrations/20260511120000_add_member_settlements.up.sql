@@ -0,0 +1,22 @@
+-- Money a member collected on the band's behalf, e.g. cash taken at the door.
+ALTER TABLE event_payments ADD COLUMN collected_by TEXT REFERENCES members(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_event_payments_collected_by ON event_payments(collected_by);
+
+-- Payouts a member made out of money they collected, instead of the band.
+ALTER TABLE participant_payouts ADD COLUMN paid_by TEXT REFERENCES members(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_participant_payouts_paid_by ON participant_payouts(paid_by);
+
+-- Collected money a member handed back to the band. Amounts are in the
+-- group's base currency.
+CREATE TABLE IF NOT EXISTS member_handovers (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    member_id TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    handed_at TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_member_handovers_member_id ON member_handovers(member_id);
+CREATE INDEX IF NOT EXISTS idx_member_handovers_group_id ON member_handovers(group_id);
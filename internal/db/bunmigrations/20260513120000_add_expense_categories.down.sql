@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_expense_tags_group_tag;
+DROP TABLE IF EXISTS expense_tags;
+
+DROP INDEX IF EXISTS idx_expenses_category_id;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- category_id stays on expenses; clear the links instead.
+UPDATE expenses SET category_id = NULL;
+
+DROP TRIGGER IF EXISTS trg_groups_expense_categories_insert;
+
+-- category_id still references expense_categories, so the table stays,
+-- emptied, or every write to expenses would fail.
+DELETE FROM expense_categories;
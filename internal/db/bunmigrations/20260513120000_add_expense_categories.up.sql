@@ -0,0 +1,46 @@
+-- Per-group expense categories. Presets are the defaults every group starts
+-- with; their name is empty until renamed and the UI shows the translated
+-- preset instead.
+CREATE TABLE IF NOT EXISTS expense_categories (
+    id TEXT PRIMARY KEY,
+    group_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    preset TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_expense_categories_group_id ON expense_categories(group_id);
+
+INSERT INTO expense_categories (id, group_id, preset)
+SELECT 'cat_' || lower(hex(randomblob(10))), g.id, p.preset
+FROM groups g
+CROSS JOIN (
+    SELECT 'travel' AS preset UNION ALL
+    SELECT 'equipment' UNION ALL
+    SELECT 'rehearsal' UNION ALL
+    SELECT 'marketing'
+) p;
+
+CREATE TRIGGER IF NOT EXISTS trg_groups_expense_categories_insert
+AFTER INSERT ON groups
+BEGIN
+    INSERT INTO expense_categories (id, group_id, preset) VALUES
+        ('cat_' || lower(hex(randomblob(10))), NEW.id, 'travel'),
+        ('cat_' || lower(hex(randomblob(10))), NEW.id, 'equipment'),
+        ('cat_' || lower(hex(randomblob(10))), NEW.id, 'rehearsal'),
+        ('cat_' || lower(hex(randomblob(10))), NEW.id, 'marketing');
+END;
+
+ALTER TABLE expenses ADD COLUMN category_id TEXT REFERENCES expense_categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_category_id ON expenses(category_id);
+
+-- Free-form tags, stored lowercased.
+CREATE TABLE IF NOT EXISTS expense_tags (
+    expense_id TEXT NOT NULL,
+    group_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (expense_id, tag),
+    FOREIGN KEY (expense_id) REFERENCES expenses(id) ON DELETE CASCADE,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_expense_tags_group_tag ON expense_tags(group_id, tag);
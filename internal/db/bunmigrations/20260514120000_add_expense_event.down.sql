@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_expenses_event_id;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- event_id stays on expenses; clear the links instead.
+UPDATE expenses SET event_id = NULL;
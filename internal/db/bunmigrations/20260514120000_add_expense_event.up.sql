@@ -0,0 +1,4 @@
+-- The event an expense was spent on (van hire, PA rental). Linked expenses
+-- count against the event's margin; deleting the event unlinks them.
+ALTER TABLE expenses ADD COLUMN event_id TEXT REFERENCES events(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_event_id ON expenses(event_id);
@@ -0,0 +1,53 @@
+-- Tax profile of a member: paid gross, invoicing their own taxes, or with a
+-- percentage of each cut withheld for the band to pay to the tax office.
+ALTER TABLE members ADD COLUMN tax_mode TEXT NOT NULL DEFAULT 'gross';
+ALTER TABLE members ADD COLUMN withholding_rate REAL NOT NULL DEFAULT 0;
+
+-- Tax withheld from a participant's cut, worked out when the cut is saved so
+-- later profile changes leave past events alone.
+ALTER TABLE participants ADD COLUMN withheld INTEGER NOT NULL DEFAULT 0;
+
+-- Participants are paid out their cut less the tax withheld.
+DROP VIEW IF EXISTS group_outgoing_payments;
+CREATE VIEW IF NOT EXISTS group_outgoing_payments AS
+SELECT
+  p.group_id AS group_id,
+  'participant' AS payment_kind,
+  CAST(p.event_id || ':' || p.member_id AS TEXT) AS payment_id,
+  CAST(p.event_id AS TEXT) AS event_id,
+  CAST(p.member_id AS TEXT) AS member_id,
+  CAST(m.name AS TEXT) AS member_name,
+  CAST(e.title AS TEXT) AS event_title,
+  e.title AS title,
+  CAST(CASE WHEN e.status = 'cancelled' THEN p.compensation ELSE p.amount - p.withheld + p.expense END AS INTEGER) AS amount,
+  CAST(COALESCE((SELECT SUM(pp.amount) FROM participant_payouts pp WHERE pp.event_id = p.event_id AND pp.member_id = p.member_id), 0) AS INTEGER) AS paid_amount,
+  e.currency AS currency,
+  e.exchange_rate AS exchange_rate,
+  p.paid AS paid,
+  COALESCE(p.paid_at, (SELECT MAX(pp.paid_at) FROM participant_payouts pp WHERE pp.event_id = p.event_id AND pp.member_id = p.member_id)) AS paid_at,
+  p.updated_at AS updated_at,
+  e.time AS sort_date
+FROM participants p
+JOIN members m ON m.id = p.member_id AND m.group_id = p.group_id
+JOIN events e ON e.id = p.event_id AND e.group_id = p.group_id
+WHERE e.status <> 'cancelled' OR p.compensation > 0
+UNION ALL
+SELECT
+  ex.group_id AS group_id,
+  CASE WHEN ex.paid_by_member_id IS NULL THEN 'expense' ELSE 'reimbursement' END AS payment_kind,
+  CAST(ex.id AS TEXT) AS payment_id,
+  '' AS event_id,
+  CAST(COALESCE(ex.paid_by_member_id, '') AS TEXT) AS member_id,
+  CAST(COALESCE(m.name, '') AS TEXT) AS member_name,
+  '' AS event_title,
+  ex.title AS title,
+  CAST(ex.amount AS INTEGER) AS amount,
+  CAST(CASE WHEN ex.paid = 1 THEN ex.amount ELSE 0 END AS INTEGER) AS paid_amount,
+  ex.currency AS currency,
+  ex.exchange_rate AS exchange_rate,
+  ex.paid AS paid,
+  ex.paid_at AS paid_at,
+  ex.updated_at AS updated_at,
+  ex.date AS sort_date
+FROM expenses ex
+LEFT JOIN members m ON m.id = ex.paid_by_member_id;
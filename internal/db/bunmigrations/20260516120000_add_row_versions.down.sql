@@ -0,0 +1,29 @@
+DROP TRIGGER IF EXISTS trg_participants_version_delete;
+DROP TRIGGER IF EXISTS trg_participants_version_update;
+DROP TRIGGER IF EXISTS trg_participants_version_insert;
+
+-- SQLite does not support DROP COLUMN safely across versions.
+-- The version columns stay; the triggers go back to only touching updated_at.
+DROP TRIGGER IF EXISTS trg_events_updated_at;
+CREATE TRIGGER IF NOT EXISTS trg_events_updated_at
+AFTER UPDATE ON events
+FOR EACH ROW
+BEGIN
+    UPDATE events SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+DROP TRIGGER IF EXISTS trg_members_updated_at;
+CREATE TRIGGER IF NOT EXISTS trg_members_updated_at
+AFTER UPDATE ON members
+FOR EACH ROW
+BEGIN
+    UPDATE members SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+DROP TRIGGER IF EXISTS trg_expenses_updated_at;
+CREATE TRIGGER IF NOT EXISTS trg_expenses_updated_at
+AFTER UPDATE ON expenses
+FOR EACH ROW
+BEGIN
+    UPDATE expenses SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,57 @@
+-- Edit forms carry the version they were opened at; an update with an older
+-- version is rejected instead of overwriting someone else's save.
+ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE events ADD COLUMN participants_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE expenses ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE members ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Every write bumps the version, whichever code path makes it. A change to
+-- the participants only bumps participants_version, so editing an event's
+-- details does not clash with someone editing its participants.
+DROP TRIGGER IF EXISTS trg_events_updated_at;
+CREATE TRIGGER IF NOT EXISTS trg_events_updated_at
+AFTER UPDATE ON events
+FOR EACH ROW
+BEGIN
+    UPDATE events
+    SET updated_at = CURRENT_TIMESTAMP,
+        version = version + CASE WHEN NEW.participants_version = OLD.participants_version THEN 1 ELSE 0 END
+    WHERE id = NEW.id;
+END;
+
+DROP TRIGGER IF EXISTS trg_members_updated_at;
+CREATE TRIGGER IF NOT EXISTS trg_members_updated_at
+AFTER UPDATE ON members
+FOR EACH ROW
+BEGIN
+    UPDATE members SET updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = NEW.id;
+END;
+
+DROP TRIGGER IF EXISTS trg_expenses_updated_at;
+CREATE TRIGGER IF NOT EXISTS trg_expenses_updated_at
+AFTER UPDATE ON expenses
+FOR EACH ROW
+BEGIN
+    UPDATE expenses SET updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_participants_version_insert
+AFTER INSERT ON participants
+FOR EACH ROW
+BEGIN
+    UPDATE events SET participants_version = participants_version + 1 WHERE id = NEW.event_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_participants_version_update
+AFTER UPDATE ON participants
+FOR EACH ROW
+BEGIN
+    UPDATE events SET participants_version = participants_version + 1 WHERE id = NEW.event_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_participants_version_delete
+AFTER DELETE ON participants
+FOR EACH ROW
+BEGIN
+    UPDATE events SET participants_version = participants_version + 1 WHERE id = OLD.event_id;
+END;
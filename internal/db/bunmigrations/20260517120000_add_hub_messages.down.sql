@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_hub_messages_created_at;
+DROP TABLE IF EXISTS hub_messages;
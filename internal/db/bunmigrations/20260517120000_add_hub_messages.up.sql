@@ -0,0 +1,12 @@
+-- Messages between app instances sharing this database, so a patch for a tab
+-- reaches the instance holding its SSE connection. Rows are kept briefly.
+CREATE TABLE IF NOT EXISTS hub_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    tab TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_hub_messages_created_at ON hub_messages(created_at);
@@ -0,0 +1,9 @@
+-- SQLite does not support DROP COLUMN safely across versions.
+-- currency stays on invoices; the trigger goes back to its original columns.
+DROP TRIGGER IF EXISTS trg_invoices_immutable;
+CREATE TRIGGER IF NOT EXISTS trg_invoices_immutable
+BEFORE UPDATE OF id, group_id, number, issue_date, performance_date, seller_name, seller_address, seller_tax_number, buyer_name, buyer_address, buyer_tax_number, total, pdf ON invoices
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'issued invoices cannot be changed');
+END;
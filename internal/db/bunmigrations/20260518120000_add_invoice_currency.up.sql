@@ -0,0 +1,17 @@
+-- Invoices print their amounts in the currency of the event they bill,
+-- frozen at issue like everything else on the document. Existing invoices
+-- get the event's currency, or the group's base currency.
+ALTER TABLE invoices ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+UPDATE invoices SET currency = COALESCE(
+    (SELECT NULLIF(e.currency, '') FROM events e WHERE e.id = invoices.event_id),
+    (SELECT g.base_currency FROM groups g WHERE g.id = invoices.group_id),
+    'HUF'
+);
+
+DROP TRIGGER IF EXISTS trg_invoices_immutable;
+CREATE TRIGGER IF NOT EXISTS trg_invoices_immutable
+BEFORE UPDATE OF id, group_id, number, issue_date, performance_date, seller_name, seller_address, seller_tax_number, buyer_name, buyer_address, buyer_tax_number, total, currency, pdf ON invoices
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'issued invoices cannot be changed');
+END;
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ConflictError is returned by an update that carries a version the row no
+// longer has: someone else saved it after the form was opened.
+type ConflictError struct {
+	Table string
+	ID    string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("db: %s %s changed since it was read", e.Table, e.ID)
+}
+
+// CheckVersion fails with a ConflictError when want is set and is not the
+// row's current version. A zero want skips the check, for writes that do not
+// come from an edit form.
+func CheckVersion(table, id string, want, current int64) error {
+	if want == 0 || want == current {
+		return nil
+	}
+	return &ConflictError{Table: table, ID: id}
+}
+
+// IsConflict reports whether err is or wraps a ConflictError.
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
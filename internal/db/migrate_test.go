@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/uptrace/bun/migrate"
+
+	"bandcash/internal/db/bunmigrations"
+)
+
+// rollbackFrom is the first migration rolled back by the test. Earlier ones
+// are applied as a separate group so a rollback leaves them in place.
+const rollbackFrom = "20260501120000"
+
+func TestRollbackKeepsWritesWorking(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate_test.sqlite")
+	if err := Init(dbPath); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Close()
+	})
+	// foreign_keys is set per connection; keep the one Init set it on.
+	sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+
+	older := migrate.NewMigrations()
+	for _, m := range bunmigrations.Migrations.Sorted() {
+		if m.Name < rollbackFrom {
+			older.Add(m)
+		}
+	}
+	base := migrate.NewMigrator(BunDB, older)
+	if err := base.Init(ctx); err != nil {
+		t.Fatalf("migrator.Init failed: %v", err)
+	}
+	if _, err := base.Migrate(ctx); err != nil {
+		t.Fatalf("migrating to %s failed: %v", rollbackFrom, err)
+	}
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := BunDB.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	exec(`INSERT INTO users (id, email) VALUES ('usr_1', 'a@example.com')`)
+	exec(`INSERT INTO groups (id, name, admin_user_id) VALUES ('grp_1', 'Band', 'usr_1')`)
+	exec(`INSERT INTO recurrences (id, group_id, kind, title, amount, freq, start_date) VALUES ('rec_1', 'grp_1', 'event', 'Gig', 100, 'weekly', '2026-05-01')`)
+	exec(`INSERT INTO events (id, group_id, title, time, description, amount, recurrence_id, recurrence_date) VALUES ('evt_1', 'grp_1', 'Gig', '2026-05-01', '', 100, 'rec_1', '2026-05-01')`)
+	exec(`INSERT INTO expenses (id, group_id, title, description, amount, date, recurrence_id, category_id) VALUES ('exp_1', 'grp_1', 'Fuel', '', 10, '2026-05-01', NULL, (SELECT id FROM expense_categories WHERE group_id = 'grp_1' LIMIT 1))`)
+
+	migrator := migrate.NewMigrator(BunDB, bunmigrations.Migrations)
+	group, err := migrator.Rollback(ctx)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if group.IsZero() || group.Migrations[0].Name != rollbackFrom {
+		t.Fatalf("Rollback undid %v, want every migration from %s", group, rollbackFrom)
+	}
+
+	exec(`INSERT INTO groups (id, name, admin_user_id) VALUES ('grp_2', 'Duo', 'usr_1')`)
+	exec(`INSERT INTO events (id, group_id, title, time, description, amount) VALUES ('evt_2', 'grp_2', 'Gig', '2026-06-01', '', 100)`)
+	exec(`INSERT INTO expenses (id, group_id, title, description, amount, date) VALUES ('exp_2', 'grp_2', 'Fuel', '', 10, '2026-06-01')`)
+	exec(`UPDATE events SET title = 'Show' WHERE id = 'evt_1'`)
+	exec(`UPDATE expenses SET title = 'Diesel' WHERE id = 'exp_1'`)
+	exec(`DELETE FROM events WHERE id = 'evt_1'`)
+	exec(`DELETE FROM expenses WHERE id = 'exp_1'`)
+
+	var violations int
+	if err := BunDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_foreign_key_check`).Scan(&violations); err != nil {
+		t.Fatalf("foreign_key_check failed: %v", err)
+	}
+	if violations != 0 {
+		t.Fatalf("foreign_key_check found %d violations after rollback", violations)
+	}
+}
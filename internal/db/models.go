@@ -11,6 +11,33 @@ type AppFlag struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type Attachment struct {
+	ID          string         `json:"id"`
+	GroupID     string         `json:"group_id"`
+	EventID     sql.NullString `json:"event_id"`
+	ExpenseID   sql.NullString `json:"expense_id"`
+	FileName    string         `json:"file_name"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	Hash        string         `json:"hash"`
+	UploadedBy  sql.NullString `json:"uploaded_by"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
+type AuditEntry struct {
+	ID          string    `json:"id"`
+	GroupID     string    `json:"group_id"`
+	ActorUserID string    `json:"actor_user_id"`
+	ActorEmail  string    `json:"actor_email"`
+	Action      string    `json:"action"`
+	EntityType  string    `json:"entity_type"`
+	EntityID    string    `json:"entity_id"`
+	EntityLabel string    `json:"entity_label"`
+	Diff        string    `json:"diff"`
+	RequestID   string    `json:"request_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 type BannedUser struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
@@ -47,40 +74,98 @@ type BillingWebhookEvent struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type CalendarFeed struct {
+	ID         string       `json:"id"`
+	UserID     string       `json:"user_id"`
+	GroupID    string       `json:"group_id"`
+	Token      string       `json:"token"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
 type Event struct {
+	ID                  string         `json:"id"`
+	GroupID             string         `json:"group_id"`
+	Title               string         `json:"title"`
+	Time                string         `json:"time"`
+	Description         string         `json:"description"`
+	Amount              int64          `json:"amount"`
+	CreatedAt           sql.NullTime   `json:"created_at"`
+	UpdatedAt           sql.NullTime   `json:"updated_at"`
+	Paid                int64          `json:"paid"`
+	PaidAt              sql.NullString `json:"paid_at"`
+	Place               string         `json:"place"`
+	Date                string         `json:"date"`
+	EventTime           string         `json:"event_time"`
+	RecurrenceID        sql.NullString `json:"recurrence_id"`
+	RecurrenceDate      string         `json:"recurrence_date"`
+	Status              string         `json:"status"`
+	CancelReason        string         `json:"cancel_reason"`
+	CancellationFee     int64          `json:"cancellation_fee"`
+	CancelledAt         sql.NullString `json:"cancelled_at"`
+	Currency            string         `json:"currency"`
+	ExchangeRate        float64        `json:"exchange_rate"`
+	Version             int64          `json:"version"`
+	ParticipantsVersion int64          `json:"participants_version"`
+}
+
+type EventPayment struct {
 	ID          string         `json:"id"`
 	GroupID     string         `json:"group_id"`
-	Title       string         `json:"title"`
-	Time        string         `json:"time"`
-	Description string         `json:"description"`
+	EventID     string         `json:"event_id"`
 	Amount      int64          `json:"amount"`
+	PaidAt      string         `json:"paid_at"`
+	Method      string         `json:"method"`
+	Reference   string         `json:"reference"`
+	CollectedBy sql.NullString `json:"collected_by"`
 	CreatedAt   sql.NullTime   `json:"created_at"`
-	UpdatedAt   sql.NullTime   `json:"updated_at"`
-	Paid        int64          `json:"paid"`
-	PaidAt      sql.NullString `json:"paid_at"`
-	Place       string         `json:"place"`
-	Date        string         `json:"date"`
-	EventTime   string         `json:"event_time"`
 }
 
 type Expense struct {
-	ID          string         `json:"id"`
-	GroupID     string         `json:"group_id"`
-	Title       string         `json:"title"`
-	Description string         `json:"description"`
-	Amount      int64          `json:"amount"`
-	Date        string         `json:"date"`
-	CreatedAt   sql.NullTime   `json:"created_at"`
-	UpdatedAt   sql.NullTime   `json:"updated_at"`
-	Paid        int64          `json:"paid"`
-	PaidAt      sql.NullString `json:"paid_at"`
+	ID             string         `json:"id"`
+	GroupID        string         `json:"group_id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Amount         int64          `json:"amount"`
+	Date           string         `json:"date"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+	UpdatedAt      sql.NullTime   `json:"updated_at"`
+	Paid           int64          `json:"paid"`
+	PaidAt         sql.NullString `json:"paid_at"`
+	RecurrenceID   sql.NullString `json:"recurrence_id"`
+	RecurrenceDate string         `json:"recurrence_date"`
+	Currency       string         `json:"currency"`
+	ExchangeRate   float64        `json:"exchange_rate"`
+	PaidByMemberID sql.NullString `json:"paid_by_member_id"`
+	CategoryID     sql.NullString `json:"category_id"`
+	EventID        sql.NullString `json:"event_id"`
+	Version        int64          `json:"version"`
+}
+
+type ExpenseCategory struct {
+	ID        string       `json:"id"`
+	GroupID   string       `json:"group_id"`
+	Name      string       `json:"name"`
+	Preset    string       `json:"preset"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type ExpenseTag struct {
+	ExpenseID string `json:"expense_id"`
+	GroupID   string `json:"group_id"`
+	Tag       string `json:"tag"`
 }
 
 type Group struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	AdminUserID string       `json:"admin_user_id"`
-	CreatedAt   sql.NullTime `json:"created_at"`
+	ID                  string       `json:"id"`
+	Name                string       `json:"name"`
+	AdminUserID         string       `json:"admin_user_id"`
+	CreatedAt           sql.NullTime `json:"created_at"`
+	BillingName         string       `json:"billing_name"`
+	BillingAddress      string       `json:"billing_address"`
+	TaxNumber           string       `json:"tax_number"`
+	CalendarShowAmounts int64        `json:"calendar_show_amounts"`
+	BaseCurrency        string       `json:"base_currency"`
 }
 
 type GroupAccess struct {
@@ -92,19 +177,59 @@ type GroupAccess struct {
 }
 
 type GroupOutgoingPayment struct {
-	GroupID     string         `json:"group_id"`
-	PaymentKind string         `json:"payment_kind"`
-	PaymentID   string         `json:"payment_id"`
-	EventID     string         `json:"event_id"`
-	MemberID    string         `json:"member_id"`
-	MemberName  string         `json:"member_name"`
-	EventTitle  string         `json:"event_title"`
-	Title       string         `json:"title"`
-	Amount      int64          `json:"amount"`
-	Paid        int64          `json:"paid"`
-	PaidAt      sql.NullString `json:"paid_at"`
-	UpdatedAt   sql.NullTime   `json:"updated_at"`
-	SortDate    string         `json:"sort_date"`
+	GroupID      string         `json:"group_id"`
+	PaymentKind  string         `json:"payment_kind"`
+	PaymentID    string         `json:"payment_id"`
+	EventID      string         `json:"event_id"`
+	MemberID     string         `json:"member_id"`
+	MemberName   string         `json:"member_name"`
+	EventTitle   string         `json:"event_title"`
+	Title        string         `json:"title"`
+	Amount       int64          `json:"amount"`
+	PaidAmount   int64          `json:"paid_amount"`
+	Currency     string         `json:"currency"`
+	ExchangeRate float64        `json:"exchange_rate"`
+	Paid         int64          `json:"paid"`
+	PaidAt       sql.NullString `json:"paid_at"`
+	UpdatedAt    sql.NullTime   `json:"updated_at"`
+	SortDate     string         `json:"sort_date"`
+}
+
+type HubMessage struct {
+	ID        int64     `json:"id"`
+	Instance  string    `json:"instance"`
+	Kind      string    `json:"kind"`
+	Tab       string    `json:"tab"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Invoice struct {
+	ID              string         `json:"id"`
+	GroupID         string         `json:"group_id"`
+	EventID         sql.NullString `json:"event_id"`
+	Number          int64          `json:"number"`
+	IssueDate       string         `json:"issue_date"`
+	PerformanceDate string         `json:"performance_date"`
+	SellerName      string         `json:"seller_name"`
+	SellerAddress   string         `json:"seller_address"`
+	SellerTaxNumber string         `json:"seller_tax_number"`
+	BuyerName       string         `json:"buyer_name"`
+	BuyerAddress    string         `json:"buyer_address"`
+	BuyerTaxNumber  string         `json:"buyer_tax_number"`
+	Total           int64          `json:"total"`
+	Currency        string         `json:"currency"`
+	PDF             []byte         `json:"pdf"`
+	IssuedBy        sql.NullString `json:"issued_by"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+}
+
+type InvoiceLine struct {
+	InvoiceID   string `json:"invoice_id"`
+	Position    int64  `json:"position"`
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unit_price"`
 }
 
 type MagicLink struct {
@@ -120,25 +245,104 @@ type MagicLink struct {
 }
 
 type Member struct {
-	ID          string       `json:"id"`
-	GroupID     string       `json:"group_id"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	CreatedAt   sql.NullTime `json:"created_at"`
-	UpdatedAt   sql.NullTime `json:"updated_at"`
+	ID              string       `json:"id"`
+	GroupID         string       `json:"group_id"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+	TaxMode         string       `json:"tax_mode"`
+	WithholdingRate float64      `json:"withholding_rate"`
+	Version         int64        `json:"version"`
+}
+
+type MemberHandover struct {
+	ID        string       `json:"id"`
+	GroupID   string       `json:"group_id"`
+	MemberID  string       `json:"member_id"`
+	Amount    int64        `json:"amount"`
+	HandedAt  string       `json:"handed_at"`
+	CreatedAt sql.NullTime `json:"created_at"`
 }
 
 type Participant struct {
+	GroupID      string         `json:"group_id"`
+	EventID      string         `json:"event_id"`
+	MemberID     string         `json:"member_id"`
+	Amount       int64          `json:"amount"`
+	Expense      int64          `json:"expense"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	UpdatedAt    sql.NullTime   `json:"updated_at"`
+	Paid         int64          `json:"paid"`
+	PaidAt       sql.NullString `json:"paid_at"`
+	Note         string         `json:"note"`
+	Compensation int64          `json:"compensation"`
+	Withheld     int64          `json:"withheld"`
+}
+
+type ParticipantPayout struct {
+	ID        string         `json:"id"`
 	GroupID   string         `json:"group_id"`
 	EventID   string         `json:"event_id"`
 	MemberID  string         `json:"member_id"`
 	Amount    int64          `json:"amount"`
-	Expense   int64          `json:"expense"`
+	PaidAt    string         `json:"paid_at"`
+	Method    string         `json:"method"`
+	Reference string         `json:"reference"`
+	PaidBy    sql.NullString `json:"paid_by"`
 	CreatedAt sql.NullTime   `json:"created_at"`
-	UpdatedAt sql.NullTime   `json:"updated_at"`
-	Paid      int64          `json:"paid"`
-	PaidAt    sql.NullString `json:"paid_at"`
-	Note      string         `json:"note"`
+}
+
+type Quote struct {
+	ID          string         `json:"id"`
+	GroupID     string         `json:"group_id"`
+	ClientName  string         `json:"client_name"`
+	ClientEmail string         `json:"client_email"`
+	Title       string         `json:"title"`
+	Place       string         `json:"place"`
+	EventDate   string         `json:"event_date"`
+	EventTime   string         `json:"event_time"`
+	Notes       string         `json:"notes"`
+	ValidUntil  string         `json:"valid_until"`
+	Status      string         `json:"status"`
+	Total       int64          `json:"total"`
+	EventID     sql.NullString `json:"event_id"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+}
+
+type QuoteItem struct {
+	QuoteID     string `json:"quote_id"`
+	Position    int64  `json:"position"`
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unit_price"`
+}
+
+type Recurrence struct {
+	ID             string       `json:"id"`
+	GroupID        string       `json:"group_id"`
+	Kind           string       `json:"kind"`
+	Title          string       `json:"title"`
+	Description    string       `json:"description"`
+	Place          string       `json:"place"`
+	EventTime      string       `json:"event_time"`
+	Amount         int64        `json:"amount"`
+	Freq           string       `json:"freq"`
+	Interval       int64        `json:"interval"`
+	StartDate      string       `json:"start_date"`
+	EndDate        string       `json:"end_date"`
+	Count          int64        `json:"count"`
+	GeneratedUntil string       `json:"generated_until"`
+	CreatedAt      sql.NullTime `json:"created_at"`
+	UpdatedAt      sql.NullTime `json:"updated_at"`
+}
+
+type RecurrenceParticipant struct {
+	RecurrenceID string `json:"recurrence_id"`
+	MemberID     string `json:"member_id"`
+	Amount       int64  `json:"amount"`
+	Expense      int64  `json:"expense"`
 }
 
 type User struct {
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/uptrace/bun"
+)
+
+type txKey struct{}
+
+// Conn returns the transaction ctx was given by RunInTx, or the database.
+// Stores run their queries on it, so a handler can group several store calls
+// into one transaction.
+func Conn(ctx context.Context) bun.IDB {
+	if tx, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return tx
+	}
+	return BunDB
+}
+
+// RunInTx runs fn in a transaction that the ctx given to fn also carries.
+// Stores called with that ctx join the transaction, so their changes commit or
+// roll back together. Inside another RunInTx it runs in a savepoint.
+func RunInTx(ctx context.Context, fn func(ctx context.Context, tx bun.Tx) error) error {
+	return Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx), tx)
+	})
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/uptrace/bun"
+)
+
+func TestRunInTxRollsBackConnWrites(t *testing.T) {
+	if err := Init(filepath.Join(t.TempDir(), "tx_test.sqlite")); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Close()
+	})
+	ctx := context.Background()
+	if _, err := BunDB.ExecContext(ctx, `CREATE TABLE notes (id TEXT PRIMARY KEY)`); err != nil {
+		t.Fatalf("creating table failed: %v", err)
+	}
+	insert := func(ctx context.Context, id string) error {
+		_, err := Conn(ctx).ExecContext(ctx, `INSERT INTO notes (id) VALUES (?)`, id)
+		return err
+	}
+	count := func() int {
+		t.Helper()
+		var n int
+		if err := BunDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM notes`).Scan(&n); err != nil {
+			t.Fatalf("counting failed: %v", err)
+		}
+		return n
+	}
+
+	failed := errors.New("audit write failed")
+	err := RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		if err := insert(ctx, "a"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("RunInTx error = %v; want %v", err, failed)
+	}
+	if n := count(); n != 0 {
+		t.Fatalf("%d rows after rollback; want 0", n)
+	}
+
+	// A nested RunInTx rolls back only its own writes.
+	err = RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		if err := insert(ctx, "b"); err != nil {
+			return err
+		}
+		if err := RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+			if err := insert(ctx, "c"); err != nil {
+				return err
+			}
+			return failed
+		}); !errors.Is(err, failed) {
+			t.Errorf("nested RunInTx error = %v; want %v", err, failed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTx failed: %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("%d rows after nested rollback; want 1", n)
+	}
+}
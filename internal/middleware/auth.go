@@ -55,6 +55,7 @@ func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		c.Set(utils.CtxUserIDKey, user.ID)
+		c.Set(utils.CtxUserEmailKey, user.Email)
 		c.Set(utils.CtxIsSuperadminKey, isSuperadmin)
 		return next(c)
 	}
@@ -1,15 +1,41 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
 
 	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
 )
 
+// uploadEnvelopeBytes leaves room for multipart boundaries and form fields on
+// top of the file itself.
+const uploadEnvelopeBytes = 64 << 10
+
 func GlobalBodyLimit(next echo.HandlerFunc) echo.HandlerFunc {
-	return echoMiddleware.BodyLimit("1M")(next)
+	return echoMiddleware.BodyLimitWithConfig(echoMiddleware.BodyLimitConfig{
+		Skipper: isUploadRoute,
+		Limit:   "1M",
+	})(next)
 }
 
 func AuthBodyLimit(next echo.HandlerFunc) echo.HandlerFunc {
 	return echoMiddleware.BodyLimit("64K")(next)
 }
+
+// UploadBodyLimit caps attachment uploads at UPLOAD_MAX_BYTES. Routes using it
+// must end in /attachments so GlobalBodyLimit skips them.
+func UploadBodyLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	limit := utils.Env().UploadMaxBytes + uploadEnvelopeBytes
+	return echoMiddleware.BodyLimit(strconv.FormatInt(limit, 10))(next)
+}
+
+// isUploadRoute relies on global middleware running after routing, so c.Path
+// is the matched route pattern.
+func isUploadRoute(c echo.Context) bool {
+	return c.Request().Method == http.MethodPost && strings.HasSuffix(c.Path(), "/attachments")
+}
@@ -118,6 +118,12 @@ func csrfFromRequest(r *http.Request) (string, error) {
 		return headerToken, nil
 	}
 
+	// Uploads must send the token as a header; buffering a multipart body here
+	// would bypass the upload size limit.
+	if strings.Contains(strings.ToLower(r.Header.Get(echo.HeaderContentType)), echo.MIMEMultipartForm) {
+		return "", nil
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return "", err
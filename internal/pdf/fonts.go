@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// extraCodes must match the /Differences array written by fontObject.
+var extraCodes = map[rune]byte{
+	'Ő': 129,
+	'ő': 141,
+	'Ű': 143,
+	'ű': 144,
+}
+
+// Encode converts s to the single-byte font encoding. Characters the fonts
+// cannot show become '?'.
+func Encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if code, ok := extraCodes[r]; ok {
+			out = append(out, code)
+			continue
+		}
+		if code, ok := charmap.Windows1252.EncodeRune(r); ok && code >= 32 {
+			out = append(out, code)
+			continue
+		}
+		out = append(out, '?')
+	}
+	return out
+}
+
+// TextWidth measures s in points. Accented letters are measured as their base
+// letter, which matches Helvetica closely enough for aligning columns.
+func TextWidth(s string, size float64, font Font) float64 {
+	widths := &helveticaWidths
+	if font == Bold {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range s {
+		total += glyphWidth(widths, r)
+	}
+	return float64(total) * size / 1000
+}
+
+// Fit shortens s with an ellipsis until it is at most width wide.
+func Fit(s string, width, size float64, font Font) string {
+	if TextWidth(s, size, font) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "…"
+		if TextWidth(candidate, size, font) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func glyphWidth(widths *[95]int, r rune) int {
+	if r < 32 || r > 126 {
+		decomposed := []rune(norm.NFD.String(string(r)))
+		if len(decomposed) == 0 || decomposed[0] < 32 || decomposed[0] > 126 {
+			return widths['o'-32]
+		}
+		r = decomposed[0]
+	}
+	return widths[r-32]
+}
+
+// Advance widths for ASCII 32-126 from the Adobe core font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,78 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	got := Encode("Ár ő ű € 日")
+	want := []byte{0xC1, 'r', ' ', 141, ' ', 144, ' ', 0x80, ' ', '?'}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Encode = %v, want %v", got, want)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got := TextWidth("Helvetica", 10, Regular); got < 40 || got > 45 {
+		t.Fatalf("TextWidth regular = %v", got)
+	}
+	if TextWidth("Ő", 10, Bold) != TextWidth("O", 10, Bold) {
+		t.Fatal("accented letter should use base letter width")
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := Fit("Short", 200, 10, Regular); got != "Short" {
+		t.Fatalf("Fit short = %q", got)
+	}
+	got := Fit("A very long line item description that cannot fit", 80, 10, Regular)
+	if got == "" || []rune(got)[len([]rune(got))-1] != '…' {
+		t.Fatalf("Fit long = %q", got)
+	}
+}
+
+func TestBytesXref(t *testing.T) {
+	doc := New("Invoice (1)")
+	doc.Text(50, 50, 12, Bold, "Számla")
+	doc.Line(50, 60, 545, 60, 0.5)
+	doc.AddPage()
+	doc.TextRight(545, 50, 10, Regular, "10 000")
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing header or trailer")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Fatal("expected two pages")
+	}
+	if !bytes.Contains(out, []byte(`/Title (Invoice \(1\))`)) {
+		t.Fatal("title not escaped")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatal("startxref does not point at xref table")
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("xref entries = %d, want 9", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		prefix := []byte(strconv.Itoa(i+1) + " 0 obj\n")
+		if !bytes.HasPrefix(out[offset:], prefix) {
+			t.Fatalf("xref entry %d points at wrong offset", i+1)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package spreadsheet
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidNumber = errors.New("spreadsheet: invalid number")
+	ErrFractional    = errors.New("spreadsheet: not a whole number")
+	ErrInvalidDate   = errors.New("spreadsheet: invalid date")
+	ErrInvalidTime   = errors.New("spreadsheet: invalid time")
+	ErrInvalidBool   = errors.New("spreadsheet: invalid yes/no value")
+)
+
+var (
+	huNumber = regexp.MustCompile(`^-?(\d{1,3}(\.\d{3})+|\d+)(,\d+)?$`)
+	enNumber = regexp.MustCompile(`^-?(\d{1,3}(,\d{3})+|\d+)(\.\d+)?$`)
+)
+
+// currencyAffixes are stripped so pasted values like "1 500 Ft" still parse.
+var currencyAffixes = []string{"huf", "ft", "eur", "€", "usd", "$"}
+
+// ParseAmount reads a whole amount as written in format f. Thousands
+// separators are optional; a decimal part is only accepted when it is zero.
+func ParseAmount(s string, f Format) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, affix := range currencyAffixes {
+		s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s, affix), affix))
+	}
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	thousands, decimal, pattern := ".", ",", huNumber
+	if f == FormatEnglish {
+		thousands, decimal, pattern = ",", ".", enNumber
+	}
+	if !pattern.MatchString(s) {
+		return 0, ErrInvalidNumber
+	}
+
+	s = strings.ReplaceAll(s, thousands, "")
+	if whole, fraction, ok := strings.Cut(s, decimal); ok {
+		if strings.Trim(fraction, "0") != "" {
+			return 0, ErrFractional
+		}
+		s = whole
+	}
+
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return value, nil
+}
+
+// ParseRate reads an exchange rate as written in format f. Rates have no
+// thousands separators, so only the decimal separator is accepted. An empty
+// value is 0.
+func ParseRate(s string, f Format) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if f == FormatHungarian {
+		if strings.Contains(s, ".") {
+			return 0, ErrInvalidNumber
+		}
+		s = strings.ReplaceAll(s, ",", ".")
+	} else if strings.Contains(s, ",") {
+		return 0, ErrInvalidNumber
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, ErrInvalidNumber
+	}
+	return value, nil
+}
+
+// FormatRate writes an exchange rate in format f, matching ParseRate.
+func FormatRate(rate float64, f Format) string {
+	s := strconv.FormatFloat(rate, 'f', -1, 64)
+	if f == FormatHungarian {
+		s = strings.ReplaceAll(s, ".", ",")
+	}
+	return s
+}
+
+var (
+	huDateLayouts = []string{"2006.1.2"}
+	enDateLayouts = []string{"1/2/2006", "Jan 2, 2006", "January 2, 2006", "2 Jan 2006", "2 January 2006"}
+)
+
+// ParseDate reads a date as written in format f and returns it as
+// YYYY-MM-DD. ISO dates are accepted in every format.
+func ParseDate(s string, f Format) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+
+	layouts := enDateLayouts
+	if f == FormatHungarian {
+		// "2026. 05. 01." and "2026.5.1" are both common.
+		s = strings.TrimSuffix(strings.ReplaceAll(s, " ", ""), ".")
+		layouts = huDateLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", ErrInvalidDate
+}
+
+var (
+	huTimeLayouts = []string{"15:04", "15.04", "15:04:05"}
+	enTimeLayouts = []string{"15:04", "15:04:05", "3:04PM", "3:04 PM", "3PM", "3 PM"}
+)
+
+// ParseTime reads a time of day as written in format f and returns HH:MM.
+func ParseTime(s string, f Format) (string, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+
+	layouts := enTimeLayouts
+	if f == FormatHungarian {
+		layouts = huTimeLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("15:04"), nil
+		}
+	}
+	return "", ErrInvalidTime
+}
+
+// ParseBool accepts yes/no in both languages, 1/0 and true/false. An empty
+// value is false.
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "0", "false", "no", "n", "nem":
+		return false, nil
+	case "1", "true", "yes", "y", "igen", "i", "x":
+		return true, nil
+	default:
+		return false, ErrInvalidBool
+	}
+}
+
+// FormatBool writes yes/no in the language of format f, matching ParseBool.
+func FormatBool(v bool, f Format) string {
+	switch {
+	case f == FormatHungarian && v:
+		return "igen"
+	case f == FormatHungarian:
+		return "nem"
+	case v:
+		return "yes"
+	default:
+		return "no"
+	}
+}
@@ -0,0 +1,201 @@
+// Package spreadsheet reads and writes the CSV files bands exchange with
+// spreadsheet apps and accountants. A Format decides the delimiter and how
+// numbers, dates and yes/no values are written by hand in each language.
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type Format string
+
+const (
+	// FormatHungarian uses ";" between fields, as Hungarian Excel does,
+	// "1 500,5" for numbers and "2026. 05. 01." for dates.
+	FormatHungarian Format = "hu"
+	// FormatEnglish uses "," between fields, "1,500.5" for numbers and
+	// "5/1/2026" for dates.
+	FormatEnglish Format = "en"
+)
+
+// FormatForLocale picks the format matching an app locale.
+func FormatForLocale(locale string) Format {
+	if locale == "hu" {
+		return FormatHungarian
+	}
+	return FormatEnglish
+}
+
+// ParseFormat returns the format named by s, or false for unknown names.
+func ParseFormat(s string) (Format, bool) {
+	switch Format(s) {
+	case FormatHungarian, FormatEnglish:
+		return Format(s), true
+	default:
+		return "", false
+	}
+}
+
+func (f Format) Delimiter() rune {
+	if f == FormatHungarian {
+		return ';'
+	}
+	return ','
+}
+
+// utf8BOM makes Excel open the file as UTF-8 instead of the system code page.
+const utf8BOM = "\uFEFF"
+
+// formulaPrefixes start a formula in Excel and other spreadsheet apps.
+const formulaPrefixes = "=+-@\t\r"
+
+// plainNumber matches numbers as exported, which may start with a sign.
+var plainNumber = regexp.MustCompile(`^[+-]?\d+([.,]\d+)?$`)
+
+// escapeFormula prefixes a field that a spreadsheet app would run as a
+// formula with an apostrophe, so it is shown as text. Numbers are kept.
+func escapeFormula(field string) string {
+	if field == "" || !strings.ContainsRune(formulaPrefixes, rune(field[0])) || plainNumber.MatchString(field) {
+		return field
+	}
+	return "'" + field
+}
+
+// unescapeFormula drops the apostrophe escapeFormula added.
+func unescapeFormula(field string) string {
+	if len(field) > 1 && field[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(field[1])) {
+		return field[1:]
+	}
+	return field
+}
+
+// Write writes records, header first, as a CSV file in format f. Fields that
+// would run as formulas are written as text.
+func Write(w io.Writer, f Format, records [][]string) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	out := csv.NewWriter(w)
+	out.Comma = f.Delimiter()
+	out.UseCRLF = true
+	for _, record := range records {
+		escaped := make([]string, len(record))
+		for i, field := range record {
+			escaped[i] = escapeFormula(field)
+		}
+		if err := out.Write(escaped); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+var (
+	ErrEmpty       = errors.New("spreadsheet: no header row")
+	ErrTooManyRows = errors.New("spreadsheet: too many rows")
+)
+
+// Table is a parsed file. Rows keep their line number in the file so errors
+// can point at the spreadsheet row the user sees.
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+type Row struct {
+	Line   int
+	Fields []string
+}
+
+// Field returns column i of the row, or "" when the row is shorter.
+func (r Row) Field(i int) string {
+	if i < 0 || i >= len(r.Fields) {
+		return ""
+	}
+	return r.Fields[i]
+}
+
+// Read parses a CSV file, detecting the delimiter from the header line.
+// Blank rows are skipped and every field is trimmed.
+func Read(r io.Reader, maxRows int) (Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Table{}, err
+	}
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+
+	in := csv.NewReader(bytes.NewReader(data))
+	in.Comma = sniffDelimiter(data)
+	in.FieldsPerRecord = -1
+
+	var table Table
+	for {
+		record, err := in.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Table{}, err
+		}
+		if isBlank(record) {
+			continue
+		}
+		for i := range record {
+			record[i] = unescapeFormula(strings.TrimSpace(record[i]))
+		}
+		if table.Header == nil {
+			table.Header = record
+			continue
+		}
+		if len(table.Rows) == maxRows {
+			return Table{}, fmt.Errorf("%w: more than %d", ErrTooManyRows, maxRows)
+		}
+		line, _ := in.FieldPos(0)
+		table.Rows = append(table.Rows, Row{Line: line, Fields: record})
+	}
+
+	if table.Header == nil {
+		return Table{}, ErrEmpty
+	}
+	return table, nil
+}
+
+// sniffDelimiter counts candidate delimiters outside quotes on the first line.
+func sniffDelimiter(data []byte) rune {
+	line, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	counts := map[rune]int{}
+	quoted := false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ',' || c == ';' || c == '\t'):
+			counts[c]++
+		}
+	}
+
+	best := ','
+	for _, c := range []rune{';', '\t'} {
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
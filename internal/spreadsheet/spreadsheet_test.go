@@ -0,0 +1,221 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		f    Format
+		want int64
+		err  error
+	}{
+		{"1500", FormatHungarian, 1500, nil},
+		{"1 500", FormatHungarian, 1500, nil},
+		{"1\u00a0500 Ft", FormatHungarian, 1500, nil},
+		{"1.500", FormatHungarian, 1500, nil},
+		{"1500,00", FormatHungarian, 1500, nil},
+		{"1500,50", FormatHungarian, 0, ErrFractional},
+		{"1,500", FormatHungarian, 0, ErrFractional},
+		{"1,500", FormatEnglish, 1500, nil},
+		{"1,500.00", FormatEnglish, 1500, nil},
+		{"$1,500", FormatEnglish, 1500, nil},
+		{"1.500", FormatEnglish, 0, ErrFractional},
+		{"12,34", FormatEnglish, 0, ErrInvalidNumber},
+		{"abc", FormatEnglish, 0, ErrInvalidNumber},
+		{"", FormatEnglish, 0, nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in, tt.f)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseAmount(%q, %s) = %d, %v; want %d, %v", tt.in, tt.f, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		f    Format
+		want float64
+		err  error
+	}{
+		{"385,5", FormatHungarian, 385.5, nil},
+		{"385.5", FormatEnglish, 385.5, nil},
+		{"1", FormatHungarian, 1, nil},
+		{"385.5", FormatHungarian, 0, ErrInvalidNumber},
+		{"1,385.5", FormatEnglish, 0, ErrInvalidNumber},
+		{"-2", FormatEnglish, 0, ErrInvalidNumber},
+		{"", FormatEnglish, 0, nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in, tt.f)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseRate(%q, %s) = %v, %v; want %v, %v", tt.in, tt.f, got, err, tt.want, tt.err)
+		}
+		if tt.err == nil && tt.in != "" {
+			if back := FormatRate(got, tt.f); back != tt.in {
+				t.Errorf("FormatRate(%v, %s) = %q; want %q", got, tt.f, back, tt.in)
+			}
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		f    Format
+		want string
+		ok   bool
+	}{
+		{"2026-05-01", FormatHungarian, "2026-05-01", true},
+		{"2026-05-01", FormatEnglish, "2026-05-01", true},
+		{"2026. 05. 01.", FormatHungarian, "2026-05-01", true},
+		{"2026.5.1", FormatHungarian, "2026-05-01", true},
+		{"5/1/2026", FormatEnglish, "2026-05-01", true},
+		{"May 1, 2026", FormatEnglish, "2026-05-01", true},
+		{"5/1/2026", FormatHungarian, "", false},
+		{"2026.02.30", FormatHungarian, "", false},
+		{"", FormatEnglish, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, tt.f)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseDate(%q, %s) = %q, %v; want %q", tt.in, tt.f, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		f    Format
+		want string
+		ok   bool
+	}{
+		{"20:30", FormatHungarian, "20:30", true},
+		{"20.30", FormatHungarian, "20:30", true},
+		{"8:30", FormatHungarian, "08:30", true},
+		{"8:30 pm", FormatEnglish, "20:30", true},
+		{"8PM", FormatEnglish, "20:00", true},
+		{"25:00", FormatEnglish, "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, tt.f)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseTime(%q, %s) = %q, %v; want %q", tt.in, tt.f, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"igen", "Yes", "1", "x"} {
+		if v, err := ParseBool(in); err != nil || !v {
+			t.Errorf("ParseBool(%q) = %v, %v", in, v, err)
+		}
+	}
+	for _, in := range []string{"", "nem", "no", "0"} {
+		if v, err := ParseBool(in); err != nil || v {
+			t.Errorf("ParseBool(%q) = %v, %v", in, v, err)
+		}
+	}
+	if _, err := ParseBool("maybe"); !errors.Is(err, ErrInvalidBool) {
+		t.Errorf("ParseBool(maybe) err = %v", err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	in := "\uFEFFName;Description\r\n\"Smith; John\";Drums\r\n;\r\n  Anna ;\"Vocals,\nkeys\"\r\nBob;Bass\r\n"
+	table, err := Read(strings.NewReader(in), 10)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if strings.Join(table.Header, "|") != "Name|Description" {
+		t.Fatalf("header = %q", table.Header)
+	}
+	if len(table.Rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(table.Rows))
+	}
+	if table.Rows[0].Field(0) != "Smith; John" || table.Rows[1].Field(0) != "Anna" || table.Rows[1].Field(5) != "" {
+		t.Fatalf("rows = %v", table.Rows)
+	}
+	if table.Rows[0].Line != 2 || table.Rows[1].Line != 4 || table.Rows[2].Line != 6 {
+		t.Fatalf("lines = %d %d %d", table.Rows[0].Line, table.Rows[1].Line, table.Rows[2].Line)
+	}
+
+	if _, err := Read(strings.NewReader("a,b\n1,2\n3,4\n"), 1); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("row limit err = %v", err)
+	}
+	if _, err := Read(strings.NewReader("\n\n"), 1); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("empty err = %v", err)
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := Write(&buf, FormatHungarian, [][]string{
+		{"Név", "Leírás"},
+		{"Kovács; Anna", "ének"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := Read(&buf, 10)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if table.Header[0] != "Név" || table.Rows[0].Field(0) != "Kovács; Anna" {
+		t.Fatalf("round trip = %v", table)
+	}
+}
+
+func TestWriteEscapesFormulas(t *testing.T) {
+	t.Parallel()
+
+	fields := []string{"=HYPERLINK(\"http://x\")", "+36 30 123", "-2+3", "@SUM(A1)", "\tTab", "-500", "+12", "1,5", "Gig", ""}
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatEnglish, [][]string{fields}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	want := []string{"'=HYPERLINK(\"http://x\")", "'+36 30 123", "'-2+3", "'@SUM(A1)", "'\tTab", "-500", "+12", "1,5", "Gig", ""}
+	if !slices.Equal(records[0], want) {
+		t.Fatalf("written = %q, want %q", records[0], want)
+	}
+
+	table, err := Read(&buf, 10)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := table.Header; got[0] != fields[0] || got[2] != "-2+3" || got[3] != "@SUM(A1)" || got[5] != "-500" {
+		t.Fatalf("read back = %q", got)
+	}
+	if got := escapeFormula("\rCR"); got != "'\rCR" {
+		t.Fatalf("escapeFormula(CR) = %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// diskStore lays blobs out as <root>/ab/cd/<key> so no single folder grows
+// too large.
+type diskStore struct {
+	root string
+}
+
+func newDiskStore(root string) (*diskStore, error) {
+	if root == "" {
+		return nil, errors.New("storage: disk root is required")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &diskStore{root: root}, nil
+}
+
+func (s *diskStore) path(key string) (string, error) {
+	if !IsValidKey(key) {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(s.root, key[0:2], key[2:4], key), nil
+}
+
+func (s *diskStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); err == nil {
+		// Same key means same content; nothing to write.
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *diskStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *diskStore) Delete(_ context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
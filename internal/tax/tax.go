@@ -0,0 +1,36 @@
+// Package tax works out the tax a band withholds from member payouts.
+//
+// A member is either paid their whole cut and handles their own taxes, as
+// invoicing freelancers do, or has a percentage of it withheld that the band
+// pays to the tax office on their behalf.
+package tax
+
+import "math"
+
+const (
+	// ModeGross pays the whole cut; the member invoices and pays their taxes.
+	ModeGross = "gross"
+	// ModeWithholding pays the cut less the member's withholding rate.
+	ModeWithholding = "withholding"
+)
+
+// Modes lists the tax modes in the order forms offer them.
+var Modes = []string{ModeGross, ModeWithholding}
+
+// NormalizeMode returns mode when it is known and ModeGross otherwise.
+func NormalizeMode(mode string) string {
+	if mode == ModeWithholding {
+		return ModeWithholding
+	}
+	return ModeGross
+}
+
+// Withheld is the tax withheld from a gross cut for a member with the given
+// mode and rate, a percentage. It is rounded to whole units and never more
+// than the cut.
+func Withheld(mode string, rate float64, amount int64) int64 {
+	if mode != ModeWithholding || rate <= 0 || amount <= 0 {
+		return 0
+	}
+	return min(int64(math.Round(float64(amount)*rate/100)), amount)
+}
@@ -0,0 +1,29 @@
+package tax
+
+import "testing"
+
+func TestWithheld(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode   string
+		rate   float64
+		amount int64
+		want   int64
+	}{
+		{ModeGross, 15, 10000, 0},
+		{ModeWithholding, 15, 10000, 1500},
+		{ModeWithholding, 33.5, 10001, 3350},
+		{ModeWithholding, 15, 3, 0},
+		{ModeWithholding, 15, 10, 2},
+		{ModeWithholding, 150, 100, 100},
+		{ModeWithholding, 0, 100, 0},
+		{ModeWithholding, 15, -100, 0},
+		{"", 15, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := Withheld(tt.mode, tt.rate, tt.amount); got != tt.want {
+			t.Errorf("Withheld(%q, %v, %d) = %d; want %d", tt.mode, tt.rate, tt.amount, got, tt.want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 const (
 	CtxUserIDKey       = "user_id"
+	CtxUserEmailKey    = "user_email"
 	CtxGroupIDKey      = "group_id"
 	CtxGroupRoleKey    = "group_role"
 	CtxIsSuperadminKey = "is_superadmin"
@@ -22,6 +23,13 @@ func GetUserID(c echo.Context) string {
 	return ""
 }
 
+func GetUserEmail(c echo.Context) string {
+	if email, ok := c.Get(CtxUserEmailKey).(string); ok {
+		return email
+	}
+	return ""
+}
+
 func GetGroupID(c echo.Context) string {
 	if id, ok := c.Get(CtxGroupIDKey).(string); ok {
 		return id
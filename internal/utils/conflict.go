@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PatchConflict opens the conflict dialog of the current tab after a save
+// was rejected as stale. fields are the labels of what differs between the
+// saved row and the form; versions are the row's current versions, copied
+// into the form if the user chooses to overwrite.
+func PatchConflict(c echo.Context, fields []string, versions map[string]int64) error {
+	conflict := map[string]any{
+		"open":   true,
+		"fields": strings.Join(fields, ", "),
+	}
+	for key, version := range versions {
+		conflict[key] = version
+	}
+	return SSEHub.PatchSignals(c, map[string]any{"conflict": conflict})
+}
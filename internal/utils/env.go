@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -30,6 +31,16 @@ type EnvConfig struct {
 	LemonWebhookSecret string
 	LemonAPIKey        string
 	LemonCheckoutURL   string
+	StorageBackend     string
+	StorageDir         string
+	S3Endpoint         string
+	S3Region           string
+	S3Bucket           string
+	S3AccessKeyID      string
+	S3SecretAccessKey  string
+	UploadMaxBytes     int64
+	Backplane          string
+	BackplanePoll      time.Duration
 }
 
 // DefaultSuperadminEmail is a non-production placeholder; staging and production must set SUPERADMIN_EMAIL.
@@ -81,6 +92,26 @@ type envVars struct {
 	LemonAPIKey string `env:"LEMON_API_KEY" validate:"required_with=LemonWebhookSecret"`
 	// LemonCheckoutURL is the hosted checkout buy URL used for new subscriptions.
 	LemonCheckoutURL string `env:"LEMON_CHECKOUT_URL" validate:"required_if=AppEnv production"`
+	// StorageBackend selects where uploaded attachments are stored.
+	StorageBackend string `env:"STORAGE_BACKEND" envDefault:"disk" validate:"required,oneof=disk s3"`
+	// StorageDir is the root folder for the disk storage backend.
+	StorageDir string `env:"STORAGE_DIR" envDefault:"uploads" validate:"required_if=StorageBackend disk"`
+	// S3Endpoint is the base URL of the S3-compatible service.
+	S3Endpoint string `env:"S3_ENDPOINT" validate:"required_if=StorageBackend s3,omitempty,url"`
+	// S3Region is the signing region for the S3-compatible service.
+	S3Region string `env:"S3_REGION" envDefault:"us-east-1"`
+	// S3Bucket is the bucket attachments are written to.
+	S3Bucket string `env:"S3_BUCKET" validate:"required_if=StorageBackend s3"`
+	// S3AccessKeyID is the access key used to sign S3 requests.
+	S3AccessKeyID string `env:"S3_ACCESS_KEY_ID" validate:"required_if=StorageBackend s3"`
+	// S3SecretAccessKey is the secret key used to sign S3 requests.
+	S3SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY" validate:"required_if=StorageBackend s3"`
+	// UploadMaxBytes caps the size of a single uploaded attachment.
+	UploadMaxBytes int64 `env:"UPLOAD_MAX_BYTES" envDefault:"10485760" validate:"gte=1"`
+	// Backplane selects how SSE patches reach tabs connected to other app instances.
+	Backplane string `env:"BACKPLANE" envDefault:"memory" validate:"required,oneof=memory sqlite"`
+	// BackplanePoll is how often the sqlite backplane checks for new messages.
+	BackplanePoll time.Duration `env:"BACKPLANE_POLL" envDefault:"200ms" validate:"gt=0"`
 }
 
 func Env() *EnvConfig {
@@ -95,6 +126,8 @@ func Env() *EnvConfig {
 		parsed.AppEnv = strings.ToLower(strings.TrimSpace(parsed.AppEnv))
 		parsed.LogLevel = strings.ToLower(strings.TrimSpace(parsed.LogLevel))
 		parsed.EmailProvider = strings.ToLower(strings.TrimSpace(parsed.EmailProvider))
+		parsed.StorageBackend = strings.ToLower(strings.TrimSpace(parsed.StorageBackend))
+		parsed.Backplane = strings.ToLower(strings.TrimSpace(parsed.Backplane))
 
 		err = validate.Struct(parsed)
 		if err != nil {
@@ -132,6 +165,16 @@ func Env() *EnvConfig {
 			LemonWebhookSecret: strings.TrimSpace(parsed.LemonWebhookSecret),
 			LemonAPIKey:        strings.TrimSpace(parsed.LemonAPIKey),
 			LemonCheckoutURL:   strings.TrimSpace(parsed.LemonCheckoutURL),
+			StorageBackend:     parsed.StorageBackend,
+			StorageDir:         strings.TrimSpace(parsed.StorageDir),
+			S3Endpoint:         strings.TrimRight(strings.TrimSpace(parsed.S3Endpoint), "/"),
+			S3Region:           strings.TrimSpace(parsed.S3Region),
+			S3Bucket:           strings.TrimSpace(parsed.S3Bucket),
+			S3AccessKeyID:      strings.TrimSpace(parsed.S3AccessKeyID),
+			S3SecretAccessKey:  strings.TrimSpace(parsed.S3SecretAccessKey),
+			UploadMaxBytes:     parsed.UploadMaxBytes,
+			Backplane:          parsed.Backplane,
+			BackplanePoll:      parsed.BackplanePoll,
 		}
 	})
 	return envCfg
@@ -9,7 +9,11 @@ import (
 )
 
 // CalculateGroupTotals computes all financial totals for a group in-memory
-// respecting paid/unpaid status. Results are cached.
+// respecting paid/unpaid status. Cancelled events count with their
+// cancellation fee and compensation instead of the original amounts.
+// Event income counts as paid as far as its payments cover it.
+// Entries in other currencies are converted into the group's base currency
+// at their own exchange rate. Results are cached.
 func CalculateGroupTotals(ctx context.Context, groupID string) (GroupTotals, error) {
 	// Check cache first
 	cacheKey := GroupTotalsCacheKey(groupID)
@@ -27,13 +31,20 @@ func CalculateGroupTotals(ctx context.Context, groupID string) (GroupTotals, err
 		slog.Error("failed to list events for totals", "group_id", groupID, "err", err)
 		return totals, err
 	}
+	payments, err := eventstore.SumEventPaymentsByGroup(ctx, groupID)
+	if err != nil {
+		slog.Error("failed to sum event payments for totals", "group_id", groupID, "err", err)
+		return totals, err
+	}
 	for _, event := range events {
-		totals.Income.All += event.Amount
+		income := eventstore.BaseIncomeAmount(event)
+		received := eventstore.BaseReceivedAmount(event, payments[event.ID].Received)
 		if event.Paid == 1 {
-			totals.Income.Paid += event.Amount
-		} else {
-			totals.Income.Unpaid += event.Amount
+			received = income
 		}
+		totals.Income.All += income
+		totals.Income.Paid += received
+		totals.Income.Unpaid += income - received
 	}
 
 	// Calculate from expenses
@@ -43,11 +54,12 @@ func CalculateGroupTotals(ctx context.Context, groupID string) (GroupTotals, err
 		return totals, err
 	}
 	for _, expense := range expenses {
-		totals.Expenses.All += expense.Amount
+		amount := expensestore.BaseAmount(expense)
+		totals.Expenses.All += amount
 		if expense.Paid == 1 {
-			totals.Expenses.Paid += expense.Amount
+			totals.Expenses.Paid += amount
 		} else {
-			totals.Expenses.Unpaid += expense.Amount
+			totals.Expenses.Unpaid += amount
 		}
 	}
 
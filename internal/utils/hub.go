@@ -1,47 +1,112 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/a-h/templ"
+	"github.com/invopop/ctxi18n"
 	"github.com/labstack/echo/v4"
 	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/backplane"
 )
 
+// subscriptionTTL is how long the topics of a tab are kept while it has no
+// connection, so a reconnecting tab keeps them and a closed one is dropped.
+const subscriptionTTL = 10 * time.Minute
+
 type Client struct {
 	ID  string
 	SSE *datastar.ServerSentEventGenerator
 }
 
+// subscription is what a tab listens to, kept apart from its connection
+// because a page subscribes before its SSE stream opens.
+type subscription struct {
+	topics []string
+	locale string
+	seen   time.Time
+}
+
+// Hub keeps the SSE connections of this instance. What tabs listen to, who
+// is on which page and queued notifications are shared with the other
+// instances through the backplane, so any of them can reach any tab.
 type Hub struct {
-	mu      sync.RWMutex
-	clients map[string]*Client
+	mu        sync.RWMutex
+	instance  string
+	backplane backplane.Backplane
+	clients   map[string]*Client
+	// online maps tabs to their home instance, see tabHome.
+	online            map[string]tabHome
+	buffers           map[string]*replayBuffer
+	subscriptions     map[string]subscription
+	topics            map[string]map[string]struct{}
+	notice            templ.Component
+	presence          map[string]presence
+	presenceView      func(viewers []Viewer) templ.Component
+	tabUsers          map[string]tabUser
+	notifications     *notificationStore
+	notificationsView templ.Component
 }
 
 var SSEHub = NewHub()
 
 func NewHub() *Hub {
 	return &Hub{
-		clients: make(map[string]*Client),
+		instance:      GenerateID("hub"),
+		backplane:     backplane.NewMemory(),
+		clients:       make(map[string]*Client),
+		online:        make(map[string]tabHome),
+		buffers:       make(map[string]*replayBuffer),
+		subscriptions: make(map[string]subscription),
+		topics:        make(map[string]map[string]struct{}),
+		presence:      make(map[string]presence),
+		tabUsers:      make(map[string]tabUser),
+		notifications: newNotificationStore(),
 	}
 }
 
-func (h *Hub) AddClient(id string, sse *datastar.ServerSentEventGenerator) *Client {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
+// AddClient connects a tab's SSE stream. lastEventID is the Last-Event-ID
+// the browser sent when it reconnected, so the tab gets what it missed.
+func (h *Hub) AddClient(id string, sse *datastar.ServerSentEventGenerator, lastEventID string) *Client {
 	client := &Client{
 		ID:  id,
 		SSE: sse,
 	}
-	h.clients[id] = client
+	h.resume(client, lastEventID)
+	h.share(kindConnect, id, hubPayload{})
+
+	h.mu.RLock()
+	p, joined := h.presence[id]
+	h.mu.RUnlock()
+	if joined {
+		h.broadcastPresence(p.page)
+	}
 	return client
 }
 
-func (h *Hub) RemoveClient(id string) {
+// RemoveClient disconnects a stream. The tab's events are kept for
+// replayWindow in case it comes back.
+func (h *Hub) RemoveClient(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
-	delete(h.clients, id)
+	if h.clients[client.ID] != client {
+		// The tab has already reconnected on a new stream.
+		h.mu.Unlock()
+		return
+	}
+	delete(h.clients, client.ID)
+	p, joined := h.presence[client.ID]
+	h.mu.Unlock()
+	h.share(kindDisconnect, client.ID, hubPayload{})
+
+	if joined {
+		h.broadcastPresence(p.page)
+	}
 }
 
 func (h *Hub) GetClient(id string) (*Client, error) {
@@ -49,7 +114,7 @@ func (h *Hub) GetClient(id string) (*Client, error) {
 	defer h.mu.RUnlock()
 	client, ok := h.clients[id]
 	if !ok {
-		return nil, errors.New("client not found")
+		return nil, errClientNotFound
 	}
 	return client, nil
 }
@@ -59,13 +124,7 @@ func (h *Hub) PatchHTML(c echo.Context, html string) error {
 	if tabID == "" {
 		return errors.New("no tab_id in context")
 	}
-
-	client, err := h.GetClient(tabID)
-	if err != nil {
-		return err
-	}
-
-	return client.SSE.PatchElements(html)
+	return h.deliver(tabID, sseEvent{Type: eventElements, Data: html})
 }
 
 func (h *Hub) PatchSignals(c echo.Context, signals any) error {
@@ -74,11 +133,11 @@ func (h *Hub) PatchSignals(c echo.Context, signals any) error {
 		return errors.New("no tab_id in context")
 	}
 
-	client, err := h.GetClient(tabID)
+	b, err := json.Marshal(signals)
 	if err != nil {
 		return err
 	}
-	return client.SSE.MarshalAndPatchSignals(signals)
+	return h.deliver(tabID, sseEvent{Type: eventSignals, Data: string(b)})
 }
 
 func (h *Hub) Redirect(c echo.Context, url string) error {
@@ -86,12 +145,7 @@ func (h *Hub) Redirect(c echo.Context, url string) error {
 	if tabID == "" {
 		return errors.New("no tab_id in context")
 	}
-
-	client, err := h.GetClient(tabID)
-	if err != nil {
-		return err
-	}
-	return client.SSE.Redirect(url)
+	return h.deliver(tabID, sseEvent{Type: eventRedirect, Data: url})
 }
 
 func (h *Hub) ExecuteScript(c echo.Context, script string) error {
@@ -99,13 +153,137 @@ func (h *Hub) ExecuteScript(c echo.Context, script string) error {
 	if tabID == "" {
 		return errors.New("no tab_id in context")
 	}
+	return h.deliver(tabID, sseEvent{Type: eventScript, Data: script})
+}
 
-	client, err := h.GetClient(tabID)
-	if err != nil {
-		return err
+// SetUpdateNotice sets what Publish shows other tabs: a component patched by
+// id, rendered in each tab's locale.
+func (h *Hub) SetUpdateNotice(notice templ.Component) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notice = notice
+}
+
+// Subscribe adds topics to the current tab. Pages subscribe while they
+// render, after the route has checked access, so a tab only hears about
+// what it may see.
+func (h *Hub) Subscribe(c echo.Context, topics ...string) {
+	ctx := c.Request().Context()
+	tabID := TabIDFromContext(ctx)
+	if tabID == "" || len(topics) == 0 {
+		return
 	}
 
-	return client.SSE.ExecuteScript(script)
+	payload := hubPayload{Topics: topics}
+	if l := ctxi18n.Locale(ctx); l != nil {
+		payload.Locale = string(l.Code())
+	}
+	h.share(kindSubscribe, tabID, payload)
+}
+
+// subscribeLocked adds topics to a tab. h.mu must be held for writing.
+func (h *Hub) subscribeLocked(tabID string, topics []string, locale string) {
+	h.pruneLocked()
+
+	sub := h.subscriptions[tabID]
+	for _, topic := range topics {
+		if topic == "" {
+			continue
+		}
+		if _, ok := h.topics[topic]; !ok {
+			h.topics[topic] = make(map[string]struct{})
+		}
+		if _, ok := h.topics[topic][tabID]; !ok {
+			h.topics[topic][tabID] = struct{}{}
+			sub.topics = append(sub.topics, topic)
+		}
+	}
+	if locale != "" {
+		sub.locale = locale
+	}
+	sub.seen = time.Now()
+	h.subscriptions[tabID] = sub
+}
+
+// IsSubscribed reports whether the current tab listens to any topic.
+func (h *Hub) IsSubscribed(c echo.Context) bool {
+	tabID := TabIDFromContext(c.Request().Context())
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.subscriptions[tabID]
+	return ok
+}
+
+// Publish tells every other connected tab subscribed to any of topics that
+// the data it shows changed. The tab making the change is left out; it
+// re-renders itself.
+func (h *Hub) Publish(c echo.Context, topics ...string) {
+	senderID := TabIDFromContext(c.Request().Context())
+
+	h.mu.RLock()
+	notice := h.notice
+	byLocale := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, topic := range topics {
+		for tabID := range h.topics[topic] {
+			if tabID == senderID || seen[tabID] {
+				continue
+			}
+			seen[tabID] = true
+			if !h.reachableLocked(tabID) {
+				continue
+			}
+			locale := h.subscriptions[tabID].locale
+			byLocale[locale] = append(byLocale[locale], tabID)
+		}
+	}
+	h.mu.RUnlock()
+
+	if notice == nil {
+		return
+	}
+	for locale, tabIDs := range byLocale {
+		html, err := RenderHTML(localeContext(locale), notice)
+		if err != nil {
+			slog.Error("hub: failed to render update notice", "err", err)
+			return
+		}
+		for _, tabID := range tabIDs {
+			if err := h.deliver(tabID, sseEvent{Type: eventElements, Data: html}); err != nil {
+				slog.Debug("hub: failed to send update notice", "tab_id", tabID, "err", err)
+			}
+		}
+	}
+}
+
+// pruneLocked drops the topics of tabs that have had no connection for
+// subscriptionTTL. h.mu must be held for writing.
+func (h *Hub) pruneLocked() {
+	cutoff := time.Now().Add(-subscriptionTTL)
+	for tabID, sub := range h.subscriptions {
+		if h.connectedLocked(tabID) || sub.seen.After(cutoff) {
+			continue
+		}
+		for _, topic := range sub.topics {
+			delete(h.topics[topic], tabID)
+			if len(h.topics[topic]) == 0 {
+				delete(h.topics, topic)
+			}
+		}
+		delete(h.subscriptions, tabID)
+	}
+}
+
+// localeContext is a background context in locale, for rendering patches
+// outside of a request.
+func localeContext(locale string) context.Context {
+	ctx := context.Background()
+	if locale != "" {
+		if localized, err := ctxi18n.WithLocale(ctx, locale); err == nil {
+			ctx = localized
+		}
+	}
+	return ctx
 }
 
 func (h *Hub) Close() {
@@ -113,4 +291,31 @@ func (h *Hub) Close() {
 	defer h.mu.Unlock()
 
 	h.clients = make(map[string]*Client)
+	h.online = make(map[string]tabHome)
+	h.buffers = make(map[string]*replayBuffer)
+	h.subscriptions = make(map[string]subscription)
+	h.topics = make(map[string]map[string]struct{})
+	h.presence = make(map[string]presence)
+	h.tabUsers = make(map[string]tabUser)
+}
+
+// GroupTopic is published on every change to a group's data.
+func GroupTopic(groupID string) string {
+	return "group:" + groupID
+}
+
+// EventTopic is published on changes to an event, its participants and
+// payments.
+func EventTopic(eventID string) string {
+	return "event:" + eventID
+}
+
+// ExpenseTopic is the presence page of an expense.
+func ExpenseTopic(expenseID string) string {
+	return "expense:" + expenseID
+}
+
+// MemberTopic is published on changes to a member and their participations.
+func MemberTopic(memberID string) string {
+	return "member:" + memberID
 }
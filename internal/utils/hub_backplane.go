@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/backplane"
+)
+
+// Kinds of hub messages. All but kindSend change state every instance keeps;
+// kindSend is a patch for whichever instance holds the tab's connection.
+const (
+	kindConnect    = "connect"
+	kindDisconnect = "disconnect"
+	kindSubscribe  = "subscribe"
+	kindJoin       = "join"
+	kindTabUser    = "tab_user"
+	kindNotify     = "notify"
+	kindDrain      = "drain"
+	kindSend       = "send"
+)
+
+// Types of SSE events a tab can be sent.
+const (
+	eventElements = "elements"
+	eventSignals  = "signals"
+	eventRedirect = "redirect"
+	eventScript   = "script"
+)
+
+// shareRefresh is how often a tab's unchanged topics, presence and user
+// are sent again, so the other instances keep them past subscriptionTTL.
+const shareRefresh = subscriptionTTL / 2
+
+var errClientNotFound = errors.New("client not found")
+
+// sseEvent is one patch for a tab, kept whole until it is written so it can
+// travel to another instance.
+type sseEvent struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+func (e sseEvent) writeTo(sse *datastar.ServerSentEventGenerator, id string) error {
+	switch e.Type {
+	case eventElements:
+		return sse.PatchElements(e.Data, datastar.WithPatchElementsEventID(id))
+	case eventSignals:
+		return sse.PatchSignals([]byte(e.Data), datastar.WithPatchSignalsEventID(id))
+	case eventRedirect:
+		return sse.Redirect(e.Data, datastar.WithExecuteScriptEventID(id))
+	case eventScript:
+		return sse.ExecuteScript(e.Data, datastar.WithExecuteScriptEventID(id))
+	default:
+		return fmt.Errorf("hub: unknown event type %q", e.Type)
+	}
+}
+
+// hubPayload is the body of a hub message; each kind fills its own fields.
+type hubPayload struct {
+	Topics        []string      `json:"topics,omitempty"`
+	Locale        string        `json:"locale,omitempty"`
+	Page          string        `json:"page,omitempty"`
+	Viewer        *Viewer       `json:"viewer,omitempty"`
+	UserID        string        `json:"user_id,omitempty"`
+	GroupID       string        `json:"group_id,omitempty"`
+	Notification  *Notification `json:"notification,omitempty"`
+	IncludeActive bool          `json:"include_active,omitempty"`
+	Event         *sseEvent     `json:"event,omitempty"`
+}
+
+// SetBackplane replaces the in-memory backplane, before Run is called.
+func (h *Hub) SetBackplane(bp backplane.Backplane) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.backplane = bp
+}
+
+// Run applies messages from the other instances until ctx is done.
+func (h *Hub) Run(ctx context.Context) error {
+	h.mu.RLock()
+	bp := h.backplane
+	h.mu.RUnlock()
+	return bp.Listen(ctx, h.receive)
+}
+
+// share applies a change here and sends it to the other instances. Pages
+// subscribe and join on every render, so a message that changes nothing is
+// dropped; every instance then keeps the same state and timestamps.
+func (h *Hub) share(kind, tabID string, payload hubPayload) {
+	h.mu.RLock()
+	unchanged := h.unchangedLocked(kind, tabID, payload)
+	h.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	h.apply(h.instance, kind, tabID, payload)
+	h.send(kind, tabID, payload)
+}
+
+// unchangedLocked reports whether a subscribe, join or tab user message
+// repeats what the tab already has, shared less than shareRefresh ago.
+// h.mu must be held.
+func (h *Hub) unchangedLocked(kind, tabID string, payload hubPayload) bool {
+	fresh := time.Now().Add(-shareRefresh)
+	switch kind {
+	case kindSubscribe:
+		sub, ok := h.subscriptions[tabID]
+		if !ok || !sub.seen.After(fresh) || (payload.Locale != "" && payload.Locale != sub.locale) {
+			return false
+		}
+		for _, topic := range payload.Topics {
+			if _, ok := h.topics[topic][tabID]; topic != "" && !ok {
+				return false
+			}
+		}
+		return true
+	case kindJoin:
+		p, ok := h.presence[tabID]
+		return ok && payload.Viewer != nil && p.joined.After(fresh) &&
+			p.page == payload.Page && p.viewer == *payload.Viewer && p.locale == payload.Locale
+	case kindTabUser:
+		t, ok := h.tabUsers[tabID]
+		return ok && t.seen.After(fresh) &&
+			t.userID == payload.UserID && t.groupID == payload.GroupID && t.locale == payload.Locale
+	default:
+		return false
+	}
+}
+
+// send hands a message to the other instances.
+func (h *Hub) send(kind, tabID string, payload hubPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("hub: failed to encode message", "kind", kind, "err", err)
+		return
+	}
+
+	h.mu.RLock()
+	bp := h.backplane
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = bp.Publish(ctx, backplane.Message{Instance: h.instance, Kind: kind, Tab: tabID, Body: body})
+	if err != nil {
+		slog.Warn("hub: failed to publish message", "kind", kind, "tab_id", tabID, "err", err)
+	}
+}
+
+// deliver writes ev to a tab's connection, here or on the tab's home
+// instance. A tab whose stream dropped a moment ago gets it on reconnect.
+func (h *Hub) deliver(tabID string, ev sseEvent) error {
+	if home, err := h.write(tabID, ev); home {
+		return err
+	}
+
+	h.mu.RLock()
+	reachable := h.reachableLocked(tabID)
+	h.mu.RUnlock()
+	if !reachable {
+		return errClientNotFound
+	}
+	h.send(kindSend, tabID, hubPayload{Event: &ev})
+	return nil
+}
+
+// receive handles a message from the backplane. Messages from this instance
+// were applied when they were shared.
+func (h *Hub) receive(msg backplane.Message) {
+	if msg.Instance == h.instance {
+		return
+	}
+	var payload hubPayload
+	if err := json.Unmarshal(msg.Body, &payload); err != nil {
+		slog.Warn("hub: failed to decode message", "kind", msg.Kind, "err", err)
+		return
+	}
+	h.apply(msg.Instance, msg.Kind, msg.Tab, payload)
+}
+
+func (h *Hub) apply(instance, kind, tabID string, payload hubPayload) {
+	switch kind {
+	case kindSend:
+		if payload.Event == nil {
+			return
+		}
+		if _, err := h.write(tabID, *payload.Event); err != nil {
+			slog.Debug("hub: failed to send event", "tab_id", tabID, "err", err)
+		}
+	case kindNotify:
+		if payload.Notification != nil {
+			h.notifications.Add(tabID, *payload.Notification)
+		}
+	case kindDrain:
+		h.notifications.DrainForRender(tabID, payload.IncludeActive)
+	default:
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.applyLocked(instance, kind, tabID, payload)
+	}
+}
+
+// applyLocked changes the shared state. h.mu must be held for writing.
+func (h *Hub) applyLocked(instance, kind, tabID string, payload hubPayload) {
+	switch kind {
+	case kindConnect:
+		h.pruneHomesLocked()
+		h.online[tabID] = tabHome{instance: instance}
+		if instance != h.instance {
+			// The tab moved; its new home keeps its events from now on.
+			delete(h.buffers, tabID)
+		}
+	case kindDisconnect:
+		// The tab may have reconnected to another instance already.
+		home := h.online[tabID]
+		if home.instance != instance {
+			return
+		}
+		h.pruneHomesLocked()
+		home.dropped = time.Now()
+		h.online[tabID] = home
+		if sub, ok := h.subscriptions[tabID]; ok {
+			sub.seen = time.Now()
+			h.subscriptions[tabID] = sub
+		}
+		if t, ok := h.tabUsers[tabID]; ok {
+			t.seen = time.Now()
+			h.tabUsers[tabID] = t
+		}
+	case kindSubscribe:
+		h.subscribeLocked(tabID, payload.Topics, payload.Locale)
+	case kindJoin:
+		if payload.Viewer == nil {
+			return
+		}
+		h.prunePresenceLocked()
+		h.presence[tabID] = presence{
+			page:   payload.Page,
+			viewer: *payload.Viewer,
+			locale: payload.Locale,
+			joined: time.Now(),
+		}
+	case kindTabUser:
+		h.pruneTabUsersLocked()
+		h.tabUsers[tabID] = tabUser{
+			userID:  payload.UserID,
+			groupID: payload.GroupID,
+			locale:  payload.Locale,
+			seen:    time.Now(),
+		}
+	}
+}
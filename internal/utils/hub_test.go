@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/invopop/ctxi18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/backplane"
+	"bandcash/internal/i18n"
+)
+
+var loadLocales = sync.OnceValue(i18n.Load)
+
+// testTab is a browser tab of a test hub: the context of its requests and
+// the stream it is connected on.
+type testTab struct {
+	id     string
+	c      echo.Context
+	client *Client
+	stream *httptest.ResponseRecorder
+}
+
+// newTestTab returns a tab whose requests come from userID on a page of
+// groupID, in locale.
+func newTestTab(t *testing.T, userID, groupID, locale string) *testTab {
+	t.Helper()
+	if err := loadLocales(); err != nil {
+		t.Fatalf("i18n.Load: %v", err)
+	}
+
+	tab := &testTab{id: GenerateID("tab")}
+	ctx, err := ctxi18n.WithLocale(WithTabID(context.Background(), tab.id), locale)
+	if err != nil {
+		t.Fatalf("WithLocale(%s): %v", locale, err)
+	}
+	req := httptest.NewRequest("POST", "/", nil).WithContext(ctx)
+	tab.c = echo.New().NewContext(req, httptest.NewRecorder())
+	tab.c.Set(CtxUserIDKey, userID)
+	tab.c.Set(CtxUserEmailKey, userID+"@example.com")
+	tab.c.Set(CtxGroupIDKey, groupID)
+	return tab
+}
+
+// connect opens a new stream for the tab, resuming after lastEventID.
+func (tab *testTab) connect(h *Hub, lastEventID string) {
+	tab.stream = httptest.NewRecorder()
+	sse := datastar.NewSSE(tab.stream, httptest.NewRequest("GET", "/sse", nil))
+	tab.client = h.AddClient(tab.id, sse, lastEventID)
+}
+
+func (tab *testTab) disconnect(h *Hub) {
+	h.RemoveClient(tab.client)
+}
+
+// received is what was written to the tab's current stream.
+func (tab *testTab) received() string {
+	if tab.stream == nil {
+		return ""
+	}
+	return tab.stream.Body.String()
+}
+
+// localeNotice renders as the locale it was drawn in.
+var localeNotice = templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, `<div id="notice">notice-`+i18n.LocaleCode(ctx)+`</div>`)
+	return err
+})
+
+func TestPublishReachesOtherSubscribedTabs(t *testing.T) {
+	h := NewHub()
+	h.SetUpdateNotice(localeNotice)
+
+	sender := newTestTab(t, "usr_1", "grp_1", "en")
+	english := newTestTab(t, "usr_2", "grp_1", "en")
+	hungarian := newTestTab(t, "usr_3", "grp_1", "hu")
+	elsewhere := newTestTab(t, "usr_4", "grp_2", "en")
+	for _, tab := range []*testTab{sender, english, hungarian, elsewhere} {
+		tab.connect(h, "")
+	}
+	for _, tab := range []*testTab{sender, english, hungarian} {
+		h.Subscribe(tab.c, GroupTopic("grp_1"))
+	}
+	h.Subscribe(english.c, EventTopic("evt_1"))
+	h.Subscribe(elsewhere.c, GroupTopic("grp_2"))
+
+	h.Publish(sender.c, GroupTopic("grp_1"), EventTopic("evt_1"))
+
+	if got := strings.Count(english.received(), "notice-en"); got != 1 {
+		t.Fatalf("english tab got %d notices, want 1:\n%s", got, english.received())
+	}
+	if !strings.Contains(hungarian.received(), "notice-hu") {
+		t.Fatalf("hungarian tab got no notice in its locale:\n%s", hungarian.received())
+	}
+	for name, tab := range map[string]*testTab{"sender": sender, "elsewhere": elsewhere} {
+		if strings.Contains(tab.received(), "notice-") {
+			t.Fatalf("%s tab got a notice:\n%s", name, tab.received())
+		}
+	}
+}
+
+func TestSubscriptionsExpireWithoutConnection(t *testing.T) {
+	h := NewHub()
+
+	gone := newTestTab(t, "usr_1", "grp_1", "en")
+	open := newTestTab(t, "usr_2", "grp_1", "en")
+	gone.connect(h, "")
+	open.connect(h, "")
+	h.Subscribe(gone.c, GroupTopic("grp_1"))
+	h.Subscribe(open.c, GroupTopic("grp_1"))
+	gone.disconnect(h)
+
+	// A tab that only reconnects late keeps its topics for subscriptionTTL.
+	h.Subscribe(newTestTab(t, "usr_3", "grp_2", "en").c, GroupTopic("grp_2"))
+	if !h.IsSubscribed(gone.c) {
+		t.Fatal("a tab that just disconnected lost its topics")
+	}
+
+	h.mu.Lock()
+	for _, id := range []string{gone.id, open.id} {
+		sub := h.subscriptions[id]
+		sub.seen = time.Now().Add(-subscriptionTTL - time.Minute)
+		h.subscriptions[id] = sub
+	}
+	h.mu.Unlock()
+	h.Subscribe(newTestTab(t, "usr_3", "grp_2", "en").c, GroupTopic("grp_2"))
+
+	if h.IsSubscribed(gone.c) {
+		t.Fatal("a tab gone for longer than subscriptionTTL kept its topics")
+	}
+	if !h.IsSubscribed(open.c) {
+		t.Fatal("a connected tab lost its topics")
+	}
+	h.mu.RLock()
+	_, listed := h.topics[GroupTopic("grp_1")][gone.id]
+	h.mu.RUnlock()
+	if listed {
+		t.Fatal("an expired tab is still listed under its topic")
+	}
+}
+
+// countingBackplane records the kinds of the messages a hub publishes.
+type countingBackplane struct {
+	backplane.Backplane
+	mu    sync.Mutex
+	kinds []string
+}
+
+func (b *countingBackplane) Publish(ctx context.Context, msg backplane.Message) error {
+	b.mu.Lock()
+	b.kinds = append(b.kinds, msg.Kind)
+	b.mu.Unlock()
+	return b.Backplane.Publish(ctx, msg)
+}
+
+func TestShareSkipsUnchangedTabState(t *testing.T) {
+	h := NewHub()
+	bp := &countingBackplane{Backplane: backplane.NewMemory()}
+	h.SetBackplane(bp)
+
+	tab := newTestTab(t, "usr_1", "grp_1", "en")
+	tab.connect(h, "")
+	for range 2 {
+		h.Subscribe(tab.c, GroupTopic("grp_1"))
+		h.Join(tab.c, EventTopic("evt_1"), false)
+		h.SetTabUser(tab.c)
+	}
+	h.Subscribe(tab.c, GroupTopic("grp_1"), EventTopic("evt_1"))
+	h.Join(tab.c, EventTopic("evt_1"), true)
+
+	// Unchanged state is still sent now and then, so no instance prunes it.
+	h.mu.Lock()
+	sub := h.subscriptions[tab.id]
+	sub.seen = time.Now().Add(-shareRefresh - time.Minute)
+	h.subscriptions[tab.id] = sub
+	h.mu.Unlock()
+	h.Subscribe(tab.c, GroupTopic("grp_1"))
+
+	want := []string{kindConnect, kindSubscribe, kindJoin, kindTabUser, kindSubscribe, kindJoin, kindSubscribe}
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if strings.Join(bp.kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("published %v, want %v", bp.kinds, want)
+	}
+}
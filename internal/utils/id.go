@@ -45,8 +45,18 @@ func IsValidID(id string, prefix string) bool {
 
 // ID prefixes for different entity types
 const (
-	PrefixEvent       = "evt"
-	PrefixExpense     = "exp"
-	PrefixMember      = "mem"
-	PrefixParticipant = "par"
+	PrefixEvent        = "evt"
+	PrefixExpense      = "exp"
+	PrefixMember       = "mem"
+	PrefixParticipant  = "par"
+	PrefixRecurrence   = "rec"
+	PrefixAttachment   = "att"
+	PrefixQuote        = "quo"
+	PrefixInvoice      = "inv"
+	PrefixCalendarFeed = "cal"
+	PrefixAuditEntry   = "aud"
+	PrefixPayment      = "pay"
+	PrefixPayout       = "out"
+	PrefixHandover     = "hnd"
+	PrefixCategory     = "cat"
 )
@@ -26,7 +26,12 @@ type notificationStore struct {
 
 const notificationLifetime = 5 * time.Second
 
-var Notifications = &notificationStore{clients: map[string]clientNotifications{}}
+// Notifications are the queued notifications of the tabs of SSEHub.
+var Notifications = SSEHub.notifications
+
+func newNotificationStore() *notificationStore {
+	return &notificationStore{clients: map[string]clientNotifications{}}
+}
 
 type notificationsContextKey struct{}
 
@@ -91,11 +96,31 @@ func Notify(c echo.Context, message string) {
 		// Fallback: generate a new tab ID for notifications
 		tabID = EnsureTabID(c)
 	}
-	Notifications.Add(tabID, Notification{
+	SSEHub.queueNotification(tabID, message)
+}
+
+// queueNotification queues message for a tab on every instance, since the
+// page showing it may be rendered by any of them.
+func (h *Hub) queueNotification(tabID, message string) {
+	if message == "" {
+		return
+	}
+	n := Notification{
 		ID:      GenerateID("ntf"),
 		Message: message,
 		Created: time.Now(),
-	})
+	}
+	h.share(kindNotify, tabID, hubPayload{Notification: &n})
+}
+
+// drainNotifications takes the notifications of a tab for rendering, and
+// drops them on the other instances too.
+func (h *Hub) drainNotifications(tabID string, includeActive bool) []Notification {
+	items := h.notifications.DrainForRender(tabID, includeActive)
+	if len(items) > 0 {
+		h.send(kindDrain, tabID, hubPayload{IncludeActive: includeActive})
+	}
+	return items
 }
 
 func WithNotifications(ctx context.Context, items []Notification) context.Context {
@@ -3,9 +3,11 @@ package utils
 import (
 	"context"
 
+	"bandcash/internal/currency"
 	appi18n "bandcash/internal/i18n"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
+	"golang.org/x/text/number"
 )
 
 func FormatNumberLocalized(ctx context.Context, value int64) string {
@@ -21,6 +23,38 @@ func FormatNumberLocalizedWithSign(ctx context.Context, value int64, positive bo
 	return sign + " " + FormatNumberLocalized(ctx, value)
 }
 
+// FormatMoneyLocalized formats a whole amount in the given currency with its
+// symbol, e.g. "€1,500" or "1 500 €". Unknown codes are shown after the
+// plain number.
+func FormatMoneyLocalized(ctx context.Context, value int64, code string) string {
+	c, ok := currency.Lookup(code)
+	if !ok {
+		if code == "" {
+			return FormatNumberLocalized(ctx, value)
+		}
+		return FormatNumberLocalized(ctx, value) + " " + code
+	}
+
+	printer := numberPrinterByLocale(ctx)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	amount := printer.Sprint(number.Decimal(value))
+	if c.SymbolFirst && appi18n.LocaleCode(ctx) != "hu" {
+		return sign + c.Symbol + amount
+	}
+	return sign + amount + " " + c.Symbol
+}
+
+// FormatExchangeRateLocalized formats an exchange rate with up to six
+// decimals and no trailing zeros.
+func FormatExchangeRateLocalized(ctx context.Context, rate float64) string {
+	printer := numberPrinterByLocale(ctx)
+	return printer.Sprint(number.Decimal(rate, number.MaxFractionDigits(6)))
+}
+
 func numberPrinterByLocale(ctx context.Context) *message.Printer {
 	switch appi18n.LocaleCode(ctx) {
 	case "hu":
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/a-h/templ"
+	"github.com/invopop/ctxi18n"
+	"github.com/labstack/echo/v4"
+)
+
+// Viewer is a user with a page open in a connected tab.
+type Viewer struct {
+	UserID  string
+	Email   string
+	Editing bool
+}
+
+// Initials are the first letters of the first two parts of the email's
+// local part, e.g. "JD" for john.doe@example.com.
+func (v Viewer) Initials() string {
+	local, _, _ := strings.Cut(v.Email, "@")
+	parts := strings.FieldsFunc(local, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	initials := make([]rune, 0, 2)
+	for _, part := range parts {
+		initials = append(initials, []rune(part)[0])
+		if len(initials) == 2 {
+			break
+		}
+	}
+	if len(initials) == 1 {
+		if runes := []rune(parts[0]); len(runes) > 1 {
+			initials = append(initials, runes[1])
+		}
+	}
+	if len(initials) == 0 {
+		return "?"
+	}
+	return strings.ToUpper(string(initials))
+}
+
+// presence is the page a tab shows and who is looking at it.
+type presence struct {
+	page   string
+	viewer Viewer
+	locale string
+	joined time.Time
+}
+
+// SetPresenceView sets how the viewers of a page are drawn. It is patched by
+// id into every connected tab on the page whenever someone comes or goes.
+func (h *Hub) SetPresenceView(view func(viewers []Viewer) templ.Component) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.presenceView = view
+}
+
+// Join marks the current tab as showing page. editing is set while the tab
+// has a draft open, like the participants editor. The tab counts as a viewer
+// once its SSE stream connects and stops counting when it disconnects.
+func (h *Hub) Join(c echo.Context, page string, editing bool) {
+	ctx := c.Request().Context()
+	tabID := TabIDFromContext(ctx)
+	userID := GetUserID(c)
+	if tabID == "" || userID == "" || page == "" {
+		return
+	}
+
+	payload := hubPayload{
+		Page:   page,
+		Viewer: &Viewer{UserID: userID, Email: GetUserEmail(c), Editing: editing},
+	}
+	if l := ctxi18n.Locale(ctx); l != nil {
+		payload.Locale = string(l.Code())
+	}
+
+	h.mu.RLock()
+	previous, had := h.presence[tabID]
+	connected := h.connectedLocked(tabID)
+	h.mu.RUnlock()
+	h.share(kindJoin, tabID, payload)
+
+	if !connected {
+		return
+	}
+	if had && previous.page != page {
+		h.broadcastPresence(previous.page)
+	}
+	h.broadcastPresence(page)
+}
+
+// prunePresenceLocked drops tabs that joined a page but never connected.
+// h.mu must be held for writing.
+func (h *Hub) prunePresenceLocked() {
+	cutoff := time.Now().Add(-subscriptionTTL)
+	for tabID, p := range h.presence {
+		if !h.connectedLocked(tabID) && p.joined.Before(cutoff) {
+			delete(h.presence, tabID)
+		}
+	}
+}
+
+// HasJoined reports whether the current tab is on a page with presence.
+func (h *Hub) HasJoined(c echo.Context) bool {
+	tabID := TabIDFromContext(c.Request().Context())
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.presence[tabID]
+	return ok
+}
+
+// Viewers returns the users connected to page, one entry per user, sorted by
+// email. A user editing in any tab counts as editing.
+func (h *Hub) Viewers(page string) []Viewer {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.viewersLocked(page)
+}
+
+func (h *Hub) viewersLocked(page string) []Viewer {
+	byUser := make(map[string]Viewer)
+	for tabID, p := range h.presence {
+		if p.page != page {
+			continue
+		}
+		if !h.connectedLocked(tabID) {
+			continue
+		}
+		v, ok := byUser[p.viewer.UserID]
+		if !ok {
+			v = p.viewer
+		}
+		v.Editing = v.Editing || p.viewer.Editing
+		byUser[p.viewer.UserID] = v
+	}
+
+	viewers := make([]Viewer, 0, len(byUser))
+	for _, v := range byUser {
+		viewers = append(viewers, v)
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		return viewers[i].Email < viewers[j].Email
+	})
+	return viewers
+}
+
+// broadcastPresence sends every connected tab on page the other users there.
+func (h *Hub) broadcastPresence(page string) {
+	type target struct {
+		tabID  string
+		userID string
+		locale string
+	}
+
+	h.mu.RLock()
+	view := h.presenceView
+	viewers := h.viewersLocked(page)
+	var targets []target
+	for tabID, p := range h.presence {
+		if p.page != page {
+			continue
+		}
+		if h.reachableLocked(tabID) {
+			targets = append(targets, target{tabID: tabID, userID: p.viewer.UserID, locale: p.locale})
+		}
+	}
+	h.mu.RUnlock()
+
+	if view == nil {
+		return
+	}
+	for _, t := range targets {
+		others := make([]Viewer, 0, len(viewers))
+		for _, v := range viewers {
+			if v.UserID != t.userID {
+				others = append(others, v)
+			}
+		}
+		html, err := RenderHTML(localeContext(t.locale), view(others))
+		if err != nil {
+			slog.Error("hub: failed to render presence", "err", err)
+			return
+		}
+		if err := h.deliver(t.tabID, sseEvent{Type: eventElements, Data: html}); err != nil {
+			slog.Debug("hub: failed to send presence", "tab_id", t.tabID, "err", err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// viewersList renders the emails of viewers, editors marked with a star.
+func viewersList(viewers []Viewer) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		names := make([]string, 0, len(viewers))
+		for _, v := range viewers {
+			name := v.Email
+			if v.Editing {
+				name += "*"
+			}
+			names = append(names, name)
+		}
+		_, err := io.WriteString(w, `<div id="viewers">[`+strings.Join(names, ",")+`]</div>`)
+		return err
+	})
+}
+
+// viewerIDs lists the users on page, editors marked with a star.
+func viewerIDs(h *Hub, page string) string {
+	ids := make([]string, 0)
+	for _, v := range h.Viewers(page) {
+		id := v.UserID
+		if v.Editing {
+			id += "*"
+		}
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestViewersFollowConnections(t *testing.T) {
+	h := NewHub()
+	h.SetPresenceView(viewersList)
+	page := EventTopic("evt_1")
+
+	anna := newTestTab(t, "usr_1", "grp_1", "en")
+	bela := newTestTab(t, "usr_2", "grp_1", "en")
+
+	// A tab counts once its stream is open, not when its page renders.
+	h.Join(anna.c, page, false)
+	if got := viewerIDs(h, page); got != "" {
+		t.Fatalf("viewers before connecting = %q, want none", got)
+	}
+	anna.connect(h, "")
+	if got := viewerIDs(h, page); got != "usr_1" {
+		t.Fatalf("viewers after connecting = %q, want usr_1", got)
+	}
+
+	// Editing a draft marks the participant; the others are told.
+	bela.connect(h, "")
+	h.Join(bela.c, page, true)
+	if got := viewerIDs(h, page); got != "usr_1,usr_2*" {
+		t.Fatalf("viewers with a draft open = %q, want usr_1,usr_2*", got)
+	}
+	if !strings.Contains(anna.received(), "[usr_2@example.com*]") {
+		t.Fatalf("anna was not shown bela editing:\n%s", anna.received())
+	}
+
+	// Closing the tab takes bela off the page at once.
+	bela.disconnect(h)
+	if got := viewerIDs(h, page); got != "usr_1" {
+		t.Fatalf("viewers after disconnecting = %q, want usr_1", got)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(anna.received()), "[]</div>") {
+		t.Fatalf("anna was not told bela left:\n%s", anna.received())
+	}
+}
+
+func TestViewersMergeTabsOfOneUser(t *testing.T) {
+	h := NewHub()
+	page := EventTopic("evt_1")
+	other := EventTopic("evt_2")
+
+	first := newTestTab(t, "usr_1", "grp_1", "en")
+	second := newTestTab(t, "usr_1", "grp_1", "en")
+	first.connect(h, "")
+	second.connect(h, "")
+	h.Join(first.c, page, false)
+	h.Join(second.c, page, true)
+	if got := viewerIDs(h, page); got != "usr_1*" {
+		t.Fatalf("viewers of two tabs = %q, want one editing usr_1", got)
+	}
+
+	// Joining again replaces what the tab joined before.
+	h.Join(second.c, page, false)
+	if got := viewerIDs(h, page); got != "usr_1" {
+		t.Fatalf("viewers after the draft closed = %q, want usr_1", got)
+	}
+	h.Join(first.c, other, false)
+	h.Join(second.c, other, false)
+	if got := viewerIDs(h, page); got != "" {
+		t.Fatalf("viewers of the page left = %q, want none", got)
+	}
+	if got := viewerIDs(h, other); got != "usr_1" {
+		t.Fatalf("viewers of the new page = %q, want usr_1", got)
+	}
+}
+
+func TestPresenceOfUnconnectedTabsExpires(t *testing.T) {
+	h := NewHub()
+	page := EventTopic("evt_1")
+
+	stale := newTestTab(t, "usr_1", "grp_1", "en")
+	h.Join(stale.c, page, false)
+	h.mu.Lock()
+	p := h.presence[stale.id]
+	p.joined = time.Now().Add(-subscriptionTTL - time.Minute)
+	h.presence[stale.id] = p
+	h.mu.Unlock()
+
+	h.Join(newTestTab(t, "usr_2", "grp_1", "en").c, page, false)
+	if h.HasJoined(stale.c) {
+		t.Fatal("a tab that never connected kept its presence past subscriptionTTL")
+	}
+}
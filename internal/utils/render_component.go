@@ -12,12 +12,28 @@ func renderContext(c echo.Context) context.Context {
 	ctx := c.Request().Context()
 	tabID := EnsureTabID(c)
 	includeActive := c.Request().Method != echo.GET
-	items := Notifications.DrainForRender(tabID, includeActive)
+	items := SSEHub.drainNotifications(tabID, includeActive)
 	return WithNotifications(ctx, items)
 }
 
-// RenderPage writes a templ component to the response.
+// RenderPage writes a templ component to the response. Pages of a group
+// subscribe the tab to the group's updates and show who else is on the
+// group, unless the handler picked narrower topics or a page of its own.
+// The tab is recorded as the signed-in user's so access changes reach it.
 func RenderPage(c echo.Context, component templ.Component) error {
+	if GetUserID(c) != "" {
+		EnsureTabID(c)
+		SSEHub.SetTabUser(c)
+	}
+	if groupID := GetGroupID(c); groupID != "" {
+		EnsureTabID(c)
+		if !SSEHub.IsSubscribed(c) {
+			SSEHub.Subscribe(c, GroupTopic(groupID))
+		}
+		if !SSEHub.HasJoined(c) {
+			SSEHub.Join(c, GroupTopic(groupID), false)
+		}
+	}
 	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
 	return component.Render(renderContext(c), c.Response().Writer)
 }
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// replayBufferSize and replayBufferBytes bound the recent events a tab
+	// keeps for replay.
+	replayBufferSize  = 200
+	replayBufferBytes = 1 << 20
+	// replayWindow is how long events are kept for a tab whose stream
+	// dropped. Datastar retries for longer, but by then the page is stale.
+	replayWindow = 2 * time.Minute
+)
+
+// reloadScript is sent to a reconnecting tab that missed more than its
+// buffer holds.
+const reloadScript = "window.location.reload()"
+
+// tabHome is the instance holding a tab's connection. It stays the tab's
+// home for replayWindow after the stream drops, keeping its events.
+type tabHome struct {
+	instance string
+	dropped  time.Time
+}
+
+// replayEvent is a sent event and its id.
+type replayEvent struct {
+	id uint64
+	ev sseEvent
+}
+
+// replayBuffer is the recent events of a tab, numbered from 1. Its lock is
+// held while an event is written, so a tab sees events in id order.
+type replayBuffer struct {
+	mu     sync.Mutex
+	last   uint64
+	events []replayEvent
+	bytes  int
+}
+
+// add numbers ev and keeps it, dropping the oldest events when full.
+func (b *replayBuffer) add(ev sseEvent) uint64 {
+	b.last++
+	b.events = append(b.events, replayEvent{id: b.last, ev: ev})
+	b.bytes += len(ev.Data)
+
+	drop := 0
+	for len(b.events)-drop > replayBufferSize || (b.bytes > replayBufferBytes && drop < len(b.events)-1) {
+		b.bytes -= len(b.events[drop].ev.Data)
+		drop++
+	}
+	if drop > 0 {
+		b.events = append(b.events[:0], b.events[drop:]...)
+	}
+	return b.last
+}
+
+// since returns the events after id, or false when some of them are gone.
+func (b *replayBuffer) since(id uint64) ([]replayEvent, bool) {
+	if id > b.last {
+		return nil, false
+	}
+	if id == b.last {
+		return nil, true
+	}
+	if len(b.events) == 0 || b.events[0].id > id+1 {
+		return nil, false
+	}
+	return b.events[len(b.events)-int(b.last-id):], true
+}
+
+// eventID is the SSE id of an event, tied to this instance so an id from
+// another instance or an earlier run is never mistaken for one of ours.
+func (h *Hub) eventID(id uint64) string {
+	return h.instance + "-" + strconv.FormatUint(id, 10)
+}
+
+// parseEventID returns the number in an id from eventID, or false when it
+// was not made by this instance.
+func (h *Hub) parseEventID(value string) (uint64, bool) {
+	instance, n, ok := strings.Cut(value, "-")
+	if !ok || instance != h.instance {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(n, 10, 64)
+	return id, err == nil
+}
+
+// resume sets client as the connection of its tab and sends what the tab
+// missed since lastEventID, the Last-Event-ID of the request. Without a
+// buffer to cover the gap the tab reloads instead.
+func (h *Hub) resume(client *Client, lastEventID string) {
+	h.mu.Lock()
+	buf, ok := h.buffers[client.ID]
+	if !ok {
+		buf = &replayBuffer{}
+		h.buffers[client.ID] = buf
+	}
+	h.mu.Unlock()
+
+	// Hold the buffer until the replay is out, so new events queue behind it.
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	h.mu.Lock()
+	h.clients[client.ID] = client
+	h.mu.Unlock()
+
+	var after uint64
+	if lastEventID != "" {
+		id, ours := h.parseEventID(lastEventID)
+		if !ours {
+			h.reload(client)
+			return
+		}
+		after = id
+	}
+	missed, complete := buf.since(after)
+	if !complete {
+		h.reload(client)
+		return
+	}
+	for _, e := range missed {
+		if err := e.ev.writeTo(client.SSE, h.eventID(e.id)); err != nil {
+			slog.Debug("hub: failed to replay event", "tab_id", client.ID, "err", err)
+			return
+		}
+	}
+	if len(missed) > 0 {
+		slog.Debug("hub: replayed events", "tab_id", client.ID, "count", len(missed))
+	}
+}
+
+func (h *Hub) reload(client *Client) {
+	slog.Debug("hub: tab missed too much, reloading", "tab_id", client.ID)
+	if err := client.SSE.ExecuteScript(reloadScript); err != nil {
+		slog.Debug("hub: failed to reload tab", "tab_id", client.ID, "err", err)
+	}
+}
+
+// write numbers ev into the tab's buffer and sends it if the tab is
+// connected here. It reports false when this instance is not the tab's home.
+func (h *Hub) write(tabID string, ev sseEvent) (bool, error) {
+	h.mu.RLock()
+	buf, ok := h.buffers[tabID]
+	h.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	id := buf.add(ev)
+	h.mu.RLock()
+	client, connected := h.clients[tabID]
+	h.mu.RUnlock()
+	if !connected {
+		return true, nil
+	}
+	return true, ev.writeTo(client.SSE, h.eventID(id))
+}
+
+// connectedLocked reports whether a tab has an open stream on any instance.
+func (h *Hub) connectedLocked(tabID string) bool {
+	home, ok := h.online[tabID]
+	return ok && home.dropped.IsZero()
+}
+
+// reachableLocked reports whether events for a tab are sent or kept for
+// its reconnect.
+func (h *Hub) reachableLocked(tabID string) bool {
+	home, ok := h.online[tabID]
+	return ok && (home.dropped.IsZero() || time.Since(home.dropped) < replayWindow)
+}
+
+// pruneHomesLocked forgets tabs that dropped more than replayWindow ago,
+// with the events kept for them. h.mu must be held for writing.
+func (h *Hub) pruneHomesLocked() {
+	for tabID, home := range h.online {
+		if home.dropped.IsZero() || time.Since(home.dropped) < replayWindow {
+			continue
+		}
+		delete(h.online, tabID)
+		delete(h.buffers, tabID)
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReplayBufferKeepsRecentEvents(t *testing.T) {
+	t.Parallel()
+
+	b := &replayBuffer{}
+	for i := 1; i <= replayBufferSize+50; i++ {
+		if id := b.add(sseEvent{Type: eventElements, Data: fmt.Sprintf("<p>%d</p>", i)}); id != uint64(i) {
+			t.Fatalf("add returned id %d, want %d", id, i)
+		}
+	}
+	if len(b.events) != replayBufferSize || b.events[0].id != 51 {
+		t.Fatalf("buffer holds %d events from id %d, want %d from 51", len(b.events), b.events[0].id, replayBufferSize)
+	}
+
+	if missed, ok := b.since(49); ok {
+		t.Fatalf("since(49) = %d events, want a gap", len(missed))
+	}
+	if missed, ok := b.since(50); !ok || len(missed) != replayBufferSize {
+		t.Fatalf("since(50) = %d events, ok %v, want the whole buffer", len(missed), ok)
+	}
+	missed, ok := b.since(60)
+	if !ok || len(missed) != 190 || missed[0].id != 61 || missed[0].ev.Data != "<p>61</p>" {
+		t.Fatalf("since(60) = %d events, ok %v, want 190 from 61", len(missed), ok)
+	}
+	if missed, ok := b.since(b.last); !ok || len(missed) != 0 {
+		t.Fatalf("since(last) = %d events, ok %v, want none", len(missed), ok)
+	}
+	// An id ahead of the buffer is from an earlier run of the tab.
+	if _, ok := b.since(b.last + 1); ok {
+		t.Fatal("since(last+1) reported no gap")
+	}
+}
+
+func TestReplayBufferBoundsBytes(t *testing.T) {
+	t.Parallel()
+
+	b := &replayBuffer{}
+	chunk := strings.Repeat("x", replayBufferBytes/3+1)
+	for range 3 {
+		b.add(sseEvent{Type: eventElements, Data: chunk})
+	}
+	if len(b.events) != 2 || b.bytes != 2*len(chunk) {
+		t.Fatalf("buffer holds %d events in %d bytes, want 2 in %d", len(b.events), b.bytes, 2*len(chunk))
+	}
+	if _, ok := b.since(1); !ok {
+		t.Fatal("since(1) reported a gap for events still kept")
+	}
+	if _, ok := b.since(0); ok {
+		t.Fatal("since(0) reported no gap after an event was dropped")
+	}
+
+	// An event larger than the limit is still kept on its own.
+	huge := strings.Repeat("y", replayBufferBytes+1)
+	id := b.add(sseEvent{Type: eventElements, Data: huge})
+	if len(b.events) != 1 || b.events[0].id != id || b.bytes != len(huge) {
+		t.Fatalf("buffer holds %d events in %d bytes, want only the large one", len(b.events), b.bytes)
+	}
+}
+
+func TestParseEventIDRejectsOtherInstances(t *testing.T) {
+	t.Parallel()
+
+	h := NewHub()
+	other := NewHub()
+	if id, ok := h.parseEventID(h.eventID(42)); !ok || id != 42 {
+		t.Fatalf("parseEventID(own id) = %d, %v, want 42", id, ok)
+	}
+	for _, value := range []string{other.eventID(42), "42", h.instance + "-", h.instance + "-x", ""} {
+		if id, ok := h.parseEventID(value); ok {
+			t.Fatalf("parseEventID(%q) = %d, want it rejected", value, id)
+		}
+	}
+}
+
+func TestResumeReplaysMissedEventsOrReloads(t *testing.T) {
+	h := NewHub()
+	tab := newTestTab(t, "usr_1", "grp_1", "en")
+	tab.connect(h, "")
+
+	send := func(html string) {
+		t.Helper()
+		if err := h.deliver(tab.id, sseEvent{Type: eventElements, Data: html}); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+	send("<p>seen</p>")
+	tab.disconnect(h)
+	// Events for a tab whose stream just dropped wait for its return.
+	send("<p>missed</p>")
+
+	tab.connect(h, h.eventID(1))
+	got := tab.received()
+	if !strings.Contains(got, "<p>missed</p>") || strings.Contains(got, "<p>seen</p>") {
+		t.Fatalf("resuming after id 1 sent:\n%s", got)
+	}
+	if !strings.Contains(got, "id: "+h.eventID(2)) {
+		t.Fatalf("replayed event lost its id:\n%s", got)
+	}
+
+	// An id from another instance cannot be resumed from.
+	tab.disconnect(h)
+	tab.connect(h, NewHub().eventID(2))
+	if got := tab.received(); !strings.Contains(got, reloadScript) || strings.Contains(got, "<p>") {
+		t.Fatalf("resuming from another instance sent:\n%s", got)
+	}
+
+	// Neither can one whose events were dropped from the buffer.
+	tab.disconnect(h)
+	for range replayBufferSize {
+		send("<p>more</p>")
+	}
+	tab.connect(h, h.eventID(1))
+	if got := tab.received(); !strings.Contains(got, reloadScript) || strings.Contains(got, "<p>") {
+		t.Fatalf("resuming past the buffer sent:\n%s", got)
+	}
+}
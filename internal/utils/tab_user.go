@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/invopop/ctxi18n"
+	"github.com/labstack/echo/v4"
+)
+
+// tabUser is the signed-in user of a tab and the group its page belongs to.
+type tabUser struct {
+	userID  string
+	groupID string
+	locale  string
+	seen    time.Time
+}
+
+// userTab is a connected tab of a user, picked out for a message.
+type userTab struct {
+	tabID   string
+	groupID string
+	locale  string
+}
+
+// SetNotificationsView sets the component that shows a tab's notifications.
+// It is patched by id into tabs that get a message without leaving their page.
+func (h *Hub) SetNotificationsView(view templ.Component) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notificationsView = view
+}
+
+// SetTabUser records the signed-in user of the current tab and the group its
+// page belongs to, so changes to the user's access can reach their tabs.
+func (h *Hub) SetTabUser(c echo.Context) {
+	ctx := c.Request().Context()
+	tabID := TabIDFromContext(ctx)
+	userID := GetUserID(c)
+	if tabID == "" || userID == "" {
+		return
+	}
+
+	payload := hubPayload{UserID: userID, GroupID: GetGroupID(c)}
+	if l := ctxi18n.Locale(ctx); l != nil {
+		payload.Locale = string(l.Code())
+	}
+	h.share(kindTabUser, tabID, payload)
+}
+
+// RedirectUser sends the tabs of userID that show groupID to url, with
+// message waiting there. The user's other tabs show message where they are.
+// The current tab is left out; the handler answers it itself.
+func (h *Hub) RedirectUser(c echo.Context, userID, groupID, url string, message func(ctx context.Context) string) {
+	for _, t := range h.userTabs(c, userID) {
+		ctx := localeContext(t.locale)
+		if t.groupID != groupID {
+			h.showNotification(ctx, t.tabID, message(ctx))
+			continue
+		}
+		h.queueNotification(t.tabID, message(ctx))
+		if err := h.deliver(t.tabID, sseEvent{Type: eventRedirect, Data: url}); err != nil {
+			slog.Debug("hub: failed to redirect user tab", "tab_id", t.tabID, "err", err)
+		}
+	}
+}
+
+// ReloadUser reloads the tabs of userID that show groupID, so the page is
+// drawn again for their new role, with message waiting there. The user's
+// other tabs show message where they are. The current tab is left out.
+func (h *Hub) ReloadUser(c echo.Context, userID, groupID string, message func(ctx context.Context) string) {
+	for _, t := range h.userTabs(c, userID) {
+		ctx := localeContext(t.locale)
+		if t.groupID != groupID {
+			h.showNotification(ctx, t.tabID, message(ctx))
+			continue
+		}
+		h.queueNotification(t.tabID, message(ctx))
+		if err := h.deliver(t.tabID, sseEvent{Type: eventScript, Data: "window.location.reload()"}); err != nil {
+			slog.Debug("hub: failed to reload user tab", "tab_id", t.tabID, "err", err)
+		}
+	}
+}
+
+// userTabs returns the connected tabs of userID other than the current one.
+func (h *Hub) userTabs(c echo.Context, userID string) []userTab {
+	senderID := TabIDFromContext(c.Request().Context())
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var tabs []userTab
+	for tabID, t := range h.tabUsers {
+		if t.userID != userID || tabID == senderID {
+			continue
+		}
+		if h.reachableLocked(tabID) {
+			tabs = append(tabs, userTab{tabID: tabID, groupID: t.groupID, locale: t.locale})
+		}
+	}
+	return tabs
+}
+
+// showNotification adds message to the notifications of a tab in place.
+func (h *Hub) showNotification(ctx context.Context, tabID, message string) {
+	h.mu.RLock()
+	view := h.notificationsView
+	h.mu.RUnlock()
+	if view == nil {
+		return
+	}
+
+	h.queueNotification(tabID, message)
+	items := h.drainNotifications(tabID, true)
+	html, err := RenderHTML(WithNotifications(ctx, items), view)
+	if err != nil {
+		slog.Error("hub: failed to render notifications", "err", err)
+		return
+	}
+	if err := h.deliver(tabID, sseEvent{Type: eventElements, Data: html}); err != nil {
+		slog.Debug("hub: failed to send notification", "tab_id", tabID, "err", err)
+	}
+}
+
+// pruneTabUsersLocked drops tabs that have had no connection for
+// subscriptionTTL. h.mu must be held for writing.
+func (h *Hub) pruneTabUsersLocked() {
+	cutoff := time.Now().Add(-subscriptionTTL)
+	for tabID, t := range h.tabUsers {
+		if !h.connectedLocked(tabID) && t.seen.Before(cutoff) {
+			delete(h.tabUsers, tabID)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+
+	"bandcash/internal/i18n"
+)
+
+// notificationsList renders the messages of the notifications in ctx.
+var notificationsList = templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+	messages := make([]string, 0)
+	for _, n := range NotificationsFromContext(ctx) {
+		messages = append(messages, n.Message)
+	}
+	_, err := io.WriteString(w, `<div id="notifications">`+strings.Join(messages, ",")+`</div>`)
+	return err
+})
+
+// removedMessage is the message of an access change, in the tab's locale.
+func removedMessage(ctx context.Context) string {
+	return "removed-" + i18n.LocaleCode(ctx)
+}
+
+// setupUserTabs sets up the tabs of an access change: the admin making it, two
+// tabs of the user on the group, one of them in Hungarian, one on another
+// group, and a tab of someone else on the group.
+func setupUserTabs(t *testing.T, h *Hub) (admin, onGroup, onGroupHu, elsewhere, bystander *testTab) {
+	t.Helper()
+	h.SetNotificationsView(notificationsList)
+
+	admin = newTestTab(t, "usr_admin", "grp_1", "en")
+	onGroup = newTestTab(t, "usr_1", "grp_1", "en")
+	onGroupHu = newTestTab(t, "usr_1", "grp_1", "hu")
+	elsewhere = newTestTab(t, "usr_1", "grp_2", "en")
+	bystander = newTestTab(t, "usr_2", "grp_1", "en")
+	for _, tab := range []*testTab{admin, onGroup, onGroupHu, elsewhere, bystander} {
+		tab.connect(h, "")
+		h.SetTabUser(tab.c)
+	}
+	return admin, onGroup, onGroupHu, elsewhere, bystander
+}
+
+func TestRedirectUserSendsGroupTabsAway(t *testing.T) {
+	h := NewHub()
+	admin, onGroup, onGroupHu, elsewhere, bystander := setupUserTabs(t, h)
+
+	h.RedirectUser(admin.c, "usr_1", "grp_1", "/groups", removedMessage)
+
+	for tab, locale := range map[*testTab]string{onGroup: "en", onGroupHu: "hu"} {
+		if !strings.Contains(tab.received(), "/groups") {
+			t.Fatalf("tab on the group was not redirected:\n%s", tab.received())
+		}
+		// The message waits for the page the tab lands on.
+		queued := h.notifications.Drain(tab.id)
+		if len(queued) != 1 || queued[0].Message != "removed-"+locale {
+			t.Fatalf("queued notifications = %+v, want removed-%s", queued, locale)
+		}
+	}
+	if got := elsewhere.received(); strings.Contains(got, "/groups") || !strings.Contains(got, `<div id="notifications">removed-en</div>`) {
+		t.Fatalf("tab on another group was not only notified:\n%s", got)
+	}
+	for name, tab := range map[string]*testTab{"admin": admin, "bystander": bystander} {
+		if tab.received() != "" {
+			t.Fatalf("%s tab got events:\n%s", name, tab.received())
+		}
+	}
+}
+
+func TestReloadUserRedrawsGroupTabs(t *testing.T) {
+	h := NewHub()
+	admin, onGroup, onGroupHu, elsewhere, bystander := setupUserTabs(t, h)
+
+	h.ReloadUser(admin.c, "usr_1", "grp_1", removedMessage)
+
+	for tab, locale := range map[*testTab]string{onGroup: "en", onGroupHu: "hu"} {
+		if !strings.Contains(tab.received(), reloadScript) {
+			t.Fatalf("tab on the group was not reloaded:\n%s", tab.received())
+		}
+		queued := h.notifications.Drain(tab.id)
+		if len(queued) != 1 || queued[0].Message != "removed-"+locale {
+			t.Fatalf("queued notifications = %+v, want removed-%s", queued, locale)
+		}
+	}
+	if got := elsewhere.received(); strings.Contains(got, reloadScript) || !strings.Contains(got, "removed-en") {
+		t.Fatalf("tab on another group was not only notified:\n%s", got)
+	}
+	for name, tab := range map[string]*testTab{"admin": admin, "bystander": bystander} {
+		if tab.received() != "" {
+			t.Fatalf("%s tab got events:\n%s", name, tab.received())
+		}
+	}
+}
+
+func TestUserTabsSkipClosedTabs(t *testing.T) {
+	h := NewHub()
+	admin, onGroup, _, elsewhere, _ := setupUserTabs(t, h)
+	elsewhere.disconnect(h)
+
+	// A tab gone for longer than replayWindow is no longer reachable.
+	h.mu.Lock()
+	home := h.online[elsewhere.id]
+	home.dropped = home.dropped.Add(-replayWindow)
+	h.online[elsewhere.id] = home
+	h.mu.Unlock()
+
+	tabs := h.userTabs(admin.c, "usr_1")
+	if len(tabs) != 2 {
+		t.Fatalf("user tabs = %+v, want the two open ones", tabs)
+	}
+	for _, tab := range tabs {
+		if tab.tabID == elsewhere.id {
+			t.Fatalf("user tabs include the closed tab: %+v", tabs)
+		}
+	}
+	if tabs := h.userTabs(onGroup.c, "usr_1"); len(tabs) != 1 {
+		t.Fatalf("user tabs from the user's own tab = %+v, want the other open one", tabs)
+	}
+}
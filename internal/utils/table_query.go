@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+
+	expensestore "bandcash/models/expense/data"
 )
 
 type TableQuerySpec struct {
@@ -37,6 +39,10 @@ type TableQuery struct {
 	SortSet  bool   `json:"sortSet"`
 	Dir      string `json:"dir"`
 	Summary  string `json:"summary"`
+	Status   string `json:"status"`
+	Entity   string `json:"entity"`
+	Category string `json:"category"`
+	Tag      string `json:"tag"`
 	DateMode string `json:"dateMode"`
 	Year     string `json:"year"`
 	From     string `json:"from"`
@@ -49,6 +55,30 @@ const (
 	SummaryModeUnpaid = "unpaid"
 )
 
+// Status filters narrow tables with a lifecycle column (events, quotes). The
+// empty value shows every row; each table ignores statuses it does not have.
+const (
+	StatusFilterAll       = ""
+	StatusFilterActive    = "active"
+	StatusFilterCancelled = "cancelled"
+	StatusFilterDraft     = "draft"
+	StatusFilterSent      = "sent"
+	StatusFilterAccepted  = "accepted"
+	StatusFilterRejected  = "rejected"
+	StatusFilterInvoiced  = "invoiced"
+)
+
+// Entity filters narrow the audit log to one kind of record.
+const (
+	EntityFilterAll         = ""
+	EntityFilterEvent       = "event"
+	EntityFilterParticipant = "participant"
+	EntityFilterExpense     = "expense"
+	EntityFilterMember      = "member"
+	EntityFilterAccess      = "access"
+	EntityFilterRecurrence  = "recurrence"
+)
+
 type TableQueryParseResult struct {
 	Query    TableQuery
 	Rejected map[string]string
@@ -122,6 +152,46 @@ func ParseTableQueryWithResult(c echo.Context, queryable Queryable) TableQueryPa
 	}
 	query.Search = search
 
+	status := strings.TrimSpace(c.QueryParam("status"))
+	if status != "" {
+		normalizedStatus := NormalizeStatusFilter(status)
+		if normalizedStatus != status {
+			rejected["status"] = "must be a known status"
+		} else {
+			query.Status = normalizedStatus
+		}
+	}
+
+	entity := strings.TrimSpace(c.QueryParam("entity"))
+	if entity != "" {
+		normalizedEntity := NormalizeEntityFilter(entity)
+		if normalizedEntity != entity {
+			rejected["entity"] = "must be a known entity"
+		} else {
+			query.Entity = normalizedEntity
+		}
+	}
+
+	category := strings.TrimSpace(c.QueryParam("category"))
+	if category != "" {
+		normalizedCategory := NormalizeCategoryFilter(category)
+		if normalizedCategory != category {
+			rejected["category"] = "must be a category id"
+		} else {
+			query.Category = normalizedCategory
+		}
+	}
+
+	tag := strings.TrimSpace(c.QueryParam("tag"))
+	if tag != "" {
+		normalizedTag := NormalizeTagFilter(tag)
+		if normalizedTag != tag {
+			rejected["tag"] = "must be a lowercase tag"
+		} else {
+			query.Tag = normalizedTag
+		}
+	}
+
 	year := strings.TrimSpace(c.QueryParam("year"))
 	if year != "" {
 		if isValidYear(year) {
@@ -264,6 +334,10 @@ func NormalizeTableQuery(query TableQuery, spec TableQuerySpec) TableQuery {
 	}
 
 	normalized.Summary = NormalizeSummaryMode(query.Summary)
+	normalized.Status = NormalizeStatusFilter(query.Status)
+	normalized.Entity = NormalizeEntityFilter(query.Entity)
+	normalized.Category = NormalizeCategoryFilter(query.Category)
+	normalized.Tag = NormalizeTagFilter(query.Tag)
 
 	if normalized.DateMode != "custom" {
 		normalized.DateMode = ""
@@ -327,6 +401,10 @@ type TableQueryPatch struct {
 	Page     *int
 	PageSize *int
 	Summary  *string
+	Status   *string
+	Entity   *string
+	Category *string
+	Tag      *string
 	DateMode *string
 	Year     *string
 	From     *string
@@ -387,6 +465,30 @@ func BuildTableSummaryURL(basePath string, query TableQuery, summary string) str
 	return BuildTableQueryURLWith(basePath, query, TableQueryPatch{Summary: &normalizedSummary})
 }
 
+func BuildTableStatusURL(basePath string, query TableQuery, status string) string {
+	page := 1
+	normalizedStatus := NormalizeStatusFilter(status)
+	return BuildTableQueryURLWith(basePath, query, TableQueryPatch{Page: &page, Status: &normalizedStatus})
+}
+
+func BuildTableEntityURL(basePath string, query TableQuery, entity string) string {
+	page := 1
+	normalizedEntity := NormalizeEntityFilter(entity)
+	return BuildTableQueryURLWith(basePath, query, TableQueryPatch{Page: &page, Entity: &normalizedEntity})
+}
+
+func BuildTableCategoryURL(basePath string, query TableQuery, category string) string {
+	page := 1
+	normalizedCategory := NormalizeCategoryFilter(category)
+	return BuildTableQueryURLWith(basePath, query, TableQueryPatch{Page: &page, Category: &normalizedCategory})
+}
+
+func BuildTableTagURL(basePath string, query TableQuery, tag string) string {
+	page := 1
+	normalizedTag := NormalizeTagFilter(tag)
+	return BuildTableQueryURLWith(basePath, query, TableQueryPatch{Page: &page, Tag: &normalizedTag})
+}
+
 func TableQuerySignals(query TableQuery) map[string]any {
 	sort := ""
 	dir := ""
@@ -403,6 +505,10 @@ func TableQuerySignals(query TableQuery) map[string]any {
 		"page":     query.Page,
 		"pageSize": query.PageSize,
 		"summary":  query.Summary,
+		"status":   query.Status,
+		"entity":   query.Entity,
+		"category": query.Category,
+		"tag":      query.Tag,
 		"dateMode": query.DateMode,
 		"year":     query.Year,
 		"from":     query.From,
@@ -550,6 +656,22 @@ func BuildTableQueryURLWith(basePath string, query TableQuery, patch TableQueryP
 		resolved.Summary = strings.TrimSpace(*patch.Summary)
 	}
 
+	if patch.Status != nil {
+		resolved.Status = strings.TrimSpace(*patch.Status)
+	}
+
+	if patch.Entity != nil {
+		resolved.Entity = strings.TrimSpace(*patch.Entity)
+	}
+
+	if patch.Category != nil {
+		resolved.Category = strings.TrimSpace(*patch.Category)
+	}
+
+	if patch.Tag != nil {
+		resolved.Tag = strings.TrimSpace(*patch.Tag)
+	}
+
 	if patch.DateMode != nil {
 		resolved.DateMode = strings.TrimSpace(*patch.DateMode)
 	}
@@ -576,6 +698,10 @@ func BuildTableQueryURLWith(basePath string, query TableQuery, patch TableQueryP
 	}
 
 	resolved.Summary = NormalizeSummaryMode(resolved.Summary)
+	resolved.Status = NormalizeStatusFilter(resolved.Status)
+	resolved.Entity = NormalizeEntityFilter(resolved.Entity)
+	resolved.Category = NormalizeCategoryFilter(resolved.Category)
+	resolved.Tag = NormalizeTagFilter(resolved.Tag)
 
 	if !isValidYear(resolved.Year) {
 		resolved.Year = ""
@@ -621,6 +747,18 @@ func BuildTableQueryURLWith(basePath string, query TableQuery, patch TableQueryP
 		if resolved.Summary != SummaryModeAll {
 			values.Set("summary", resolved.Summary)
 		}
+		if resolved.Status != StatusFilterAll {
+			values.Set("status", resolved.Status)
+		}
+		if resolved.Entity != EntityFilterAll {
+			values.Set("entity", resolved.Entity)
+		}
+		if resolved.Category != "" {
+			values.Set("category", resolved.Category)
+		}
+		if resolved.Tag != "" {
+			values.Set("tag", resolved.Tag)
+		}
 		if resolved.Year != "" {
 			values.Set("year", resolved.Year)
 		}
@@ -674,6 +812,30 @@ func BuildTableQueryURLWith(basePath string, query TableQuery, patch TableQueryP
 		values.Del("summary")
 	}
 
+	if resolved.Status != StatusFilterAll {
+		values.Set("status", resolved.Status)
+	} else {
+		values.Del("status")
+	}
+
+	if resolved.Entity != EntityFilterAll {
+		values.Set("entity", resolved.Entity)
+	} else {
+		values.Del("entity")
+	}
+
+	if resolved.Category != "" {
+		values.Set("category", resolved.Category)
+	} else {
+		values.Del("category")
+	}
+
+	if resolved.Tag != "" {
+		values.Set("tag", resolved.Tag)
+	} else {
+		values.Del("tag")
+	}
+
 	if resolved.Year != "" {
 		values.Set("year", resolved.Year)
 	} else {
@@ -746,3 +908,36 @@ func NormalizeSummaryMode(value string) string {
 		return SummaryModeAll
 	}
 }
+
+func NormalizeStatusFilter(value string) string {
+	switch status := strings.TrimSpace(value); status {
+	case StatusFilterActive, StatusFilterCancelled,
+		StatusFilterDraft, StatusFilterSent, StatusFilterAccepted, StatusFilterRejected, StatusFilterInvoiced:
+		return status
+	default:
+		return StatusFilterAll
+	}
+}
+
+func NormalizeEntityFilter(value string) string {
+	switch entity := strings.TrimSpace(value); entity {
+	case EntityFilterEvent, EntityFilterParticipant, EntityFilterExpense, EntityFilterMember, EntityFilterAccess, EntityFilterRecurrence:
+		return entity
+	default:
+		return EntityFilterAll
+	}
+}
+
+// NormalizeCategoryFilter keeps an expense category id, or clears the filter.
+func NormalizeCategoryFilter(value string) string {
+	category := strings.TrimSpace(value)
+	if !IsValidID(category, PrefixCategory) {
+		return ""
+	}
+	return category
+}
+
+// NormalizeTagFilter normalizes an expense tag the way tags are stored.
+func NormalizeTagFilter(value string) string {
+	return expensestore.NormalizeTag(value)
+}
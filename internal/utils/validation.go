@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/currency"
 )
 
 var validate = func() *validator.Validate {
@@ -22,6 +24,9 @@ var validate = func() *validator.Validate {
 		}
 		return field.Name
 	})
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return currency.IsValid(fl.Field().String())
+	})
 	return v
 }()
 
@@ -75,6 +80,8 @@ func validationMessage(ctx context.Context, e validator.FieldError) string {
 		return ctxi18n.T(ctx, "validation.gte", e.Param())
 	case "email":
 		return ctxi18n.T(ctx, "validation.email")
+	case "currency":
+		return ctxi18n.T(ctx, "validation.currency")
 	default:
 		return ctxi18n.T(ctx, "validation.required")
 	}
@@ -25,7 +25,7 @@ type AdminSessionTableRow struct {
 }
 
 func CountUsersTable(ctx context.Context, search string) (int64, error) {
-	q := db.BunDB.NewSelect().TableExpr("users")
+	q := db.Conn(ctx).NewSelect().TableExpr("users")
 	search = strings.TrimSpace(search)
 	if search != "" {
 		q = q.Where("users.email LIKE '%' || ? || '%'", search)
@@ -43,7 +43,7 @@ func ListUsersTable(ctx context.Context, search, sort, dir string, limit, offset
 	}
 
 	rawRows := make([]rawRow, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("users").
 		ColumnExpr("users.id").
 		ColumnExpr("users.email").
@@ -96,7 +96,7 @@ func ListUsersTable(ctx context.Context, search, sort, dir string, limit, offset
 }
 
 func CountGroupsTable(ctx context.Context, search string) (int64, error) {
-	q := db.BunDB.NewSelect().TableExpr("groups")
+	q := db.Conn(ctx).NewSelect().TableExpr("groups")
 	search = strings.TrimSpace(search)
 	if search != "" {
 		q = q.Where("name LIKE '%' || ? || '%'", search)
@@ -107,7 +107,7 @@ func CountGroupsTable(ctx context.Context, search string) (int64, error) {
 
 func ListGroupsTable(ctx context.Context, search, sort, dir string, limit, offset int) ([]db.Group, error) {
 	rows := make([]db.Group, 0)
-	q := db.BunDB.NewSelect().Model(&rows)
+	q := db.Conn(ctx).NewSelect().Model(&rows)
 
 	search = strings.TrimSpace(search)
 	if search != "" {
@@ -137,7 +137,7 @@ func ListGroupsTable(ctx context.Context, search, sort, dir string, limit, offse
 }
 
 func CountSessionsTable(ctx context.Context, search string) (int64, error) {
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("user_sessions").
 		Join("JOIN users ON users.id = user_sessions.user_id")
 	search = strings.TrimSpace(search)
@@ -150,7 +150,7 @@ func CountSessionsTable(ctx context.Context, search string) (int64, error) {
 
 func ListSessionsTable(ctx context.Context, search, sort, dir string, limit, offset int) ([]AdminSessionTableRow, error) {
 	rows := make([]AdminSessionTableRow, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("user_sessions").
 		ColumnExpr("user_sessions.id").
 		ColumnExpr("user_sessions.user_id").
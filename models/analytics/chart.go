@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Chart geometry is in SVG user units; the svg scales to its container
+// through its viewBox.
+const (
+	chartWidth       = 720
+	chartHeight      = 240
+	chartPadLeft     = 88
+	chartPadRight    = 8
+	chartPadTop      = 12
+	chartPadBottom   = 24
+	chartTickCount   = 4
+	chartGroupMargin = 0.15
+
+	sparklineWidth  = 96
+	sparklineHeight = 24
+)
+
+// barChart is a grouped bar chart: one group per label, one bar per series.
+type barChart struct {
+	Groups []barGroup
+	Ticks  []chartTick
+	ZeroY  float64
+}
+
+type barGroup struct {
+	Label  string
+	LabelX float64
+	Bars   []chartBar
+}
+
+type chartBar struct {
+	Series int
+	Value  int64
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+type chartTick struct {
+	Value int64
+	Y     float64
+}
+
+// newBarChart lays out series[s][i], the value of series s for labels[i],
+// on an axis rounded out to steps of 1, 2 or 5 times a power of ten.
+func newBarChart(labels []string, series [][]int64) barChart {
+	low, high := int64(0), int64(0)
+	for _, values := range series {
+		for _, value := range values {
+			low = min(low, value)
+			high = max(high, value)
+		}
+	}
+	step := niceStep(high-low, chartTickCount)
+	bottom := floorTo(low, step)
+	top := ceilTo(high, step)
+	if top == bottom {
+		top = bottom + step*chartTickCount
+	}
+
+	plotWidth := float64(chartWidth - chartPadLeft - chartPadRight)
+	plotHeight := float64(chartHeight - chartPadTop - chartPadBottom)
+	y := func(value int64) float64 {
+		return chartPadTop + float64(top-value)/float64(top-bottom)*plotHeight
+	}
+
+	chart := barChart{ZeroY: y(0)}
+	for value := bottom; value <= top; value += step {
+		chart.Ticks = append(chart.Ticks, chartTick{Value: value, Y: y(value)})
+	}
+	if len(labels) == 0 {
+		return chart
+	}
+
+	groupWidth := plotWidth / float64(len(labels))
+	barWidth := groupWidth * (1 - 2*chartGroupMargin)
+	if len(series) > 0 {
+		barWidth /= float64(len(series))
+	}
+	for i, label := range labels {
+		left := chartPadLeft + float64(i)*groupWidth
+		group := barGroup{Label: label, LabelX: left + groupWidth/2}
+		for s, values := range series {
+			if i >= len(values) {
+				continue
+			}
+			top := min(y(values[i]), chart.ZeroY)
+			group.Bars = append(group.Bars, chartBar{
+				Series: s,
+				Value:  values[i],
+				X:      left + groupWidth*chartGroupMargin + float64(s)*barWidth,
+				Y:      top,
+				Width:  barWidth,
+				Height: max(y(values[i]), chart.ZeroY) - top,
+			})
+		}
+		chart.Groups = append(chart.Groups, group)
+	}
+	return chart
+}
+
+// niceStep returns the smallest of 1, 2 or 5 times a power of ten that splits
+// span into at most count steps.
+func niceStep(span int64, count int64) int64 {
+	raw := (span + count - 1) / count
+	if raw <= 1 {
+		return 1
+	}
+	magnitude := int64(1)
+	for magnitude*10 < raw {
+		magnitude *= 10
+	}
+	for _, multiple := range []int64{1, 2, 5, 10} {
+		if multiple*magnitude >= raw {
+			return multiple * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func ceilTo(value, step int64) int64 {
+	if value <= 0 {
+		return -floorTo(-value, step)
+	}
+	return (value + step - 1) / step * step
+}
+
+func floorTo(value, step int64) int64 {
+	if value >= 0 {
+		return value / step * step
+	}
+	return -ceilTo(-value, step)
+}
+
+// sparklinePoints returns the polyline points drawing values across a
+// sparkline, with zero or the smallest value at the bottom.
+func sparklinePoints(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := int64(0), int64(0)
+	for _, value := range values {
+		low = min(low, value)
+		high = max(high, value)
+	}
+	span := float64(high - low)
+	if span == 0 {
+		span = 1
+	}
+	stepX := float64(sparklineWidth)
+	if len(values) > 1 {
+		stepX /= float64(len(values) - 1)
+	}
+
+	points := make([]string, 0, len(values))
+	for i, value := range values {
+		x := float64(i) * stepX
+		y := sparklineHeight - float64(value-low)/span*sparklineHeight
+		points = append(points, svgNumber(x)+","+svgNumber(y))
+	}
+	return strings.Join(points, " ")
+}
+
+// svgNumber formats a coordinate for an SVG attribute.
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
@@ -0,0 +1,77 @@
+package analytics
+
+import "testing"
+
+func TestNiceStep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		span int64
+		want int64
+	}{
+		{0, 1},
+		{3, 1},
+		{7, 2},
+		{40, 10},
+		{41, 20},
+		{170000, 50000},
+		{400001, 200000},
+	}
+	for _, tt := range tests {
+		if got := niceStep(tt.span, 4); got != tt.want {
+			t.Errorf("niceStep(%d, 4) = %d; want %d", tt.span, got, tt.want)
+		}
+	}
+}
+
+func TestNewBarChart(t *testing.T) {
+	t.Parallel()
+
+	chart := newBarChart([]string{"Jan", "Feb"}, [][]int64{{300, 0}, {-100, 50}})
+	wantTicks := []int64{-100, 0, 100, 200, 300}
+	if len(chart.Ticks) != len(wantTicks) {
+		t.Fatalf("ticks = %+v; want values %v", chart.Ticks, wantTicks)
+	}
+	for i, want := range wantTicks {
+		if chart.Ticks[i].Value != want {
+			t.Errorf("tick %d = %d; want %d", i, chart.Ticks[i].Value, want)
+		}
+	}
+	if len(chart.Groups) != 2 || len(chart.Groups[0].Bars) != 2 {
+		t.Fatalf("groups = %+v; want 2 groups of 2 bars", chart.Groups)
+	}
+
+	income := chart.Groups[0].Bars[0]
+	if income.Y+income.Height != chart.ZeroY {
+		t.Errorf("positive bar ends at %v; want zero line %v", income.Y+income.Height, chart.ZeroY)
+	}
+	loss := chart.Groups[0].Bars[1]
+	if loss.Y != chart.ZeroY || loss.Height*3 != income.Height {
+		t.Errorf("negative bar = %+v; want a third of %v below %v", loss, income.Height, chart.ZeroY)
+	}
+	if empty := chart.Groups[1].Bars[0]; empty.Height != 0 {
+		t.Errorf("zero bar height = %v; want 0", empty.Height)
+	}
+}
+
+func TestNewBarChartEmpty(t *testing.T) {
+	t.Parallel()
+
+	chart := newBarChart([]string{"Jan"}, [][]int64{{0}})
+	if len(chart.Ticks) != chartTickCount+1 || chart.Ticks[0].Value != 0 {
+		t.Fatalf("ticks = %+v; want %d from zero", chart.Ticks, chartTickCount+1)
+	}
+	if chart.ZeroY != chartHeight-chartPadBottom {
+		t.Errorf("ZeroY = %v; want %v", chart.ZeroY, chartHeight-chartPadBottom)
+	}
+}
+
+func TestSparklinePoints(t *testing.T) {
+	t.Parallel()
+
+	got := sparklinePoints([]int64{0, 50, 100})
+	want := "0.0,24.0 48.0,12.0 96.0,0.0"
+	if got != want {
+		t.Errorf("sparklinePoints() = %q; want %q", got, want)
+	}
+}
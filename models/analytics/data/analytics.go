@@ -0,0 +1,247 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	"github.com/uptrace/bun"
+)
+
+// All totals are in the group's base currency, rounded per row like
+// currency.ToBase so they match the other pages.
+const (
+	// eventDateExpr is an event's date: the date column, or the day of its
+	// legacy time.
+	eventDateExpr = "COALESCE(NULLIF(events.date, ''), substr(events.time, 1, 10))"
+	// eventIncomeExpr is what an event earns: its fee, or the cancellation
+	// fee once cancelled.
+	eventIncomeExpr = "ROUND(CASE WHEN events.status = '" + eventstore.EventStatusCancelled + "' THEN events.cancellation_fee ELSE events.amount END * events.exchange_rate)"
+	// participantPayoutExpr is what a participant costs the band: cut plus
+	// expense, or the compensation once the event is cancelled. Tax withheld
+	// from the cut is still a cost, so it is not taken off.
+	participantPayoutExpr = "ROUND(CASE WHEN events.status = '" + eventstore.EventStatusCancelled + "' THEN participants.compensation ELSE participants.amount + participants.expense END * events.exchange_rate)"
+	// participantEarningExpr is a participant's cut, or the compensation once
+	// the event is cancelled; expenses they are paid back are not earnings.
+	participantEarningExpr = "ROUND(CASE WHEN events.status = '" + eventstore.EventStatusCancelled + "' THEN participants.compensation ELSE participants.amount END * events.exchange_rate)"
+	expenseAmountExpr      = "ROUND(expenses.amount * expenses.exchange_rate)"
+)
+
+// PeriodTotals are a group's totals over a month or a year.
+type PeriodTotals struct {
+	Period   int
+	Income   int64
+	Expenses int64
+	Payouts  int64
+}
+
+// Balance is what is left of the income once expenses and payouts are paid.
+func (t PeriodTotals) Balance() int64 {
+	return t.Income - t.Expenses - t.Payouts
+}
+
+// VenueTotal is what a group earned at one place.
+type VenueTotal struct {
+	Place  string `bun:"place"`
+	Events int    `bun:"events"`
+	Income int64  `bun:"income"`
+}
+
+// MemberEarnings is what a member earned per month of a year, January first.
+type MemberEarnings struct {
+	MemberID string
+	Name     string
+	Months   [12]int64
+}
+
+// Total is the member's earnings over the year.
+func (m MemberEarnings) Total() int64 {
+	total := int64(0)
+	for _, amount := range m.Months {
+		total += amount
+	}
+	return total
+}
+
+type periodSum struct {
+	Period int   `bun:"period"`
+	Total  int64 `bun:"total"`
+}
+
+// ListMonthlyTotals returns a group's income, expenses and payouts for each
+// month of year, January first. Events and their payouts count in the month
+// of the event, expenses in the month of their date.
+func ListMonthlyTotals(ctx context.Context, groupID string, year int) ([]PeriodTotals, error) {
+	months := make([]PeriodTotals, 12)
+	for i := range months {
+		months[i].Period = i + 1
+	}
+	monthOf := func(dateExpr string) string {
+		return "CAST(substr(" + dateExpr + ", 6, 2) AS INTEGER)"
+	}
+	sums, err := periodTotals(ctx, groupID, monthOf, func(q *bun.SelectQuery, dateExpr string) *bun.SelectQuery {
+		return q.Where("substr("+dateExpr+", 1, 4) = ?", strconv.Itoa(year))
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sum := range sums {
+		if sum.Period >= 1 && sum.Period <= 12 {
+			months[sum.Period-1] = sum
+		}
+	}
+	return months, nil
+}
+
+// ListYearlyTotals returns a group's income, expenses and payouts for every
+// year with any of them, oldest first.
+func ListYearlyTotals(ctx context.Context, groupID string) ([]PeriodTotals, error) {
+	yearOf := func(dateExpr string) string {
+		return "CAST(substr(" + dateExpr + ", 1, 4) AS INTEGER)"
+	}
+	return periodTotals(ctx, groupID, yearOf, func(q *bun.SelectQuery, _ string) *bun.SelectQuery {
+		return q
+	})
+}
+
+// periodTotals sums income, expenses and payouts by the period periodOf
+// derives from a date expression, keeping rows filter accepts.
+func periodTotals(ctx context.Context, groupID string, periodOf func(string) string, filter func(*bun.SelectQuery, string) *bun.SelectQuery) ([]PeriodTotals, error) {
+	income := make([]periodSum, 0)
+	q := db.Conn(ctx).NewSelect().
+		TableExpr("events").
+		ColumnExpr(periodOf(eventDateExpr)+" AS period").
+		ColumnExpr("CAST(SUM("+eventIncomeExpr+") AS INTEGER) AS total").
+		Where("events.group_id = ?", groupID).
+		GroupExpr("period")
+	if err := filter(q, eventDateExpr).Scan(ctx, &income); err != nil {
+		return nil, err
+	}
+
+	payouts := make([]periodSum, 0)
+	q = db.Conn(ctx).NewSelect().
+		TableExpr("participants").
+		ColumnExpr(periodOf(eventDateExpr)+" AS period").
+		ColumnExpr("CAST(SUM("+participantPayoutExpr+") AS INTEGER) AS total").
+		Join("JOIN events ON events.id = participants.event_id").
+		Where("participants.group_id = ?", groupID).
+		GroupExpr("period")
+	if err := filter(q, eventDateExpr).Scan(ctx, &payouts); err != nil {
+		return nil, err
+	}
+
+	expenses := make([]periodSum, 0)
+	q = db.Conn(ctx).NewSelect().
+		TableExpr("expenses").
+		ColumnExpr(periodOf("expenses.date")+" AS period").
+		ColumnExpr("CAST(SUM("+expenseAmountExpr+") AS INTEGER) AS total").
+		Where("expenses.group_id = ?", groupID).
+		GroupExpr("period")
+	if err := filter(q, "expenses.date").Scan(ctx, &expenses); err != nil {
+		return nil, err
+	}
+
+	return mergePeriodSums(income, expenses, payouts), nil
+}
+
+// mergePeriodSums combines income, expense and payout sums into totals per
+// period, in ascending period order.
+func mergePeriodSums(income, expenses, payouts []periodSum) []PeriodTotals {
+	byPeriod := make(map[int]PeriodTotals)
+	add := func(sums []periodSum, apply func(*PeriodTotals, int64)) {
+		for _, sum := range sums {
+			totals := byPeriod[sum.Period]
+			totals.Period = sum.Period
+			apply(&totals, sum.Total)
+			byPeriod[sum.Period] = totals
+		}
+	}
+	add(income, func(t *PeriodTotals, amount int64) { t.Income += amount })
+	add(expenses, func(t *PeriodTotals, amount int64) { t.Expenses += amount })
+	add(payouts, func(t *PeriodTotals, amount int64) { t.Payouts += amount })
+
+	totals := make([]PeriodTotals, 0, len(byPeriod))
+	for _, t := range byPeriod {
+		totals = append(totals, t)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Period < totals[j].Period
+	})
+	return totals
+}
+
+// ListTopVenues returns the places a group earned most at in year, at most
+// limit of them. Places are matched ignoring case and surrounding spaces.
+func ListTopVenues(ctx context.Context, groupID string, year, limit int) ([]VenueTotal, error) {
+	rows := make([]VenueTotal, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("events").
+		ColumnExpr("MIN(TRIM(events.place)) AS place").
+		ColumnExpr("COUNT(*) AS events").
+		ColumnExpr("CAST(SUM("+eventIncomeExpr+") AS INTEGER) AS income").
+		Where("events.group_id = ?", groupID).
+		Where("TRIM(events.place) <> ''").
+		Where("substr("+eventDateExpr+", 1, 4) = ?", strconv.Itoa(year)).
+		GroupExpr("lower(TRIM(events.place))").
+		OrderExpr("income DESC").
+		OrderExpr("place ASC").
+		Limit(limit).
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+// ListMemberEarnings returns what each member of a group earned per month of
+// year, highest total first. Members without earnings that year are left out.
+func ListMemberEarnings(ctx context.Context, groupID string, year int) ([]MemberEarnings, error) {
+	rows := make([]struct {
+		MemberID string `bun:"member_id"`
+		Name     string `bun:"name"`
+		Month    int    `bun:"month"`
+		Total    int64  `bun:"total"`
+	}, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("participants").
+		ColumnExpr("participants.member_id").
+		ColumnExpr("members.name").
+		ColumnExpr("CAST(substr("+eventDateExpr+", 6, 2) AS INTEGER) AS month").
+		ColumnExpr("CAST(SUM("+participantEarningExpr+") AS INTEGER) AS total").
+		Join("JOIN events ON events.id = participants.event_id").
+		Join("JOIN members ON members.id = participants.member_id").
+		Where("participants.group_id = ?", groupID).
+		Where("substr("+eventDateExpr+", 1, 4) = ?", strconv.Itoa(year)).
+		GroupExpr("participants.member_id").
+		GroupExpr("month").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	earnings := make([]MemberEarnings, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.MemberID]
+		if !ok {
+			i = len(earnings)
+			index[row.MemberID] = i
+			earnings = append(earnings, MemberEarnings{MemberID: row.MemberID, Name: row.Name})
+		}
+		if row.Month >= 1 && row.Month <= 12 {
+			earnings[i].Months[row.Month-1] += row.Total
+		}
+	}
+	sort.SliceStable(earnings, func(i, j int) bool {
+		if earnings[i].Total() != earnings[j].Total() {
+			return earnings[i].Total() > earnings[j].Total()
+		}
+		return earnings[i].Name < earnings[j].Name
+	})
+	filtered := earnings[:0]
+	for _, member := range earnings {
+		if member.Total() != 0 {
+			filtered = append(filtered, member)
+		}
+	}
+	return filtered, nil
+}
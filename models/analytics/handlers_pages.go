@@ -0,0 +1,27 @@
+package analytics
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
+)
+
+func IndexPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+	year := parseYear(c.QueryParam("year"))
+
+	data, err := GetIndexData(c.Request().Context(), groupID, year)
+	if err != nil {
+		slog.Error("analytics.index: failed to get data", "group_id", groupID, "year", year, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.Signals = map[string]any{}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, AnalyticsIndexPage(data))
+}
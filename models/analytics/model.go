@@ -0,0 +1,135 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/utils"
+	analyticsstore "bandcash/models/analytics/data"
+	groupstore "bandcash/models/group/data"
+)
+
+// topVenuesLimit is how many venues the dashboard ranks.
+const topVenuesLimit = 10
+
+// Chart series, in the order bars are drawn within a month.
+const (
+	seriesIncome = iota
+	seriesExpenses
+	seriesPayouts
+)
+
+// GetIndexData builds the analytics dashboard of a group for year.
+func GetIndexData(ctx context.Context, groupID string, year int) (AnalyticsData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return AnalyticsData{}, err
+	}
+
+	months, err := analyticsstore.ListMonthlyTotals(ctx, groupID, year)
+	if err != nil {
+		return AnalyticsData{}, err
+	}
+	previousMonths, err := analyticsstore.ListMonthlyTotals(ctx, groupID, year-1)
+	if err != nil {
+		return AnalyticsData{}, err
+	}
+	yearly, err := analyticsstore.ListYearlyTotals(ctx, groupID)
+	if err != nil {
+		return AnalyticsData{}, err
+	}
+	venues, err := analyticsstore.ListTopVenues(ctx, groupID, year, topVenuesLimit)
+	if err != nil {
+		return AnalyticsData{}, err
+	}
+	members, err := analyticsstore.ListMemberEarnings(ctx, groupID, year)
+	if err != nil {
+		return AnalyticsData{}, err
+	}
+
+	labels := make([]string, len(months))
+	income := make([]int64, len(months))
+	expenses := make([]int64, len(months))
+	payouts := make([]int64, len(months))
+	previousIncome := make([]int64, len(previousMonths))
+	totals := analyticsstore.PeriodTotals{Period: year}
+	for i, month := range months {
+		labels[i] = monthLabel(ctx, month.Period)
+		income[i] = month.Income
+		expenses[i] = month.Expenses
+		payouts[i] = month.Payouts
+		totals.Income += month.Income
+		totals.Expenses += month.Expenses
+		totals.Payouts += month.Payouts
+	}
+	for i, month := range previousMonths {
+		previousIncome[i] = month.Income
+	}
+
+	return AnalyticsData{
+		Title:           ctxi18n.T(ctx, "analytics.page_title"),
+		GroupID:         groupID,
+		BaseCurrency:    group.BaseCurrency,
+		Year:            year,
+		Years:           yearOptions(yearly, year),
+		Months:          months,
+		YearTotals:      totals,
+		MonthlyChart:    newBarChart(labels, [][]int64{seriesIncome: income, seriesExpenses: expenses, seriesPayouts: payouts}),
+		ComparisonChart: newBarChart(labels, [][]int64{previousIncome, income}),
+		YearlyTotals:    yearly,
+		Venues:          venues,
+		Members:         members,
+		MonthsTable:     MonthsTableLayout(),
+		YearsTable:      YearsTableLayout(),
+		VenuesTable:     VenuesTableLayout(),
+		MembersTable:    MembersTableLayout(),
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "analytics.title")},
+		},
+	}, nil
+}
+
+// parseYear reads the year query parameter, defaulting to the current year.
+func parseYear(value string) int {
+	year, err := strconv.Atoi(value)
+	if err != nil || year < 1900 || year > 9999 {
+		return time.Now().Year()
+	}
+	return year
+}
+
+// yearOptions lists the years with any totals and the current and selected
+// years, newest first.
+func yearOptions(yearly []analyticsstore.PeriodTotals, selected int) []int {
+	seen := map[int]bool{time.Now().Year(): true, selected: true}
+	for _, totals := range yearly {
+		if totals.Period >= 1900 {
+			seen[totals.Period] = true
+		}
+	}
+	years := make([]int, 0, len(seen))
+	for year := range seen {
+		years = append(years, year)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+	return years
+}
+
+// percentChange is how much current differs from previous, in whole
+// percents. It reports false when there is nothing to compare to.
+func percentChange(previous, current int64) (int64, bool) {
+	if previous <= 0 {
+		return 0, false
+	}
+	return (current - previous) * 100 / previous, true
+}
+
+func monthLabel(ctx context.Context, month int) string {
+	return ctxi18n.T(ctx, "analytics.months."+strconv.Itoa(month))
+}
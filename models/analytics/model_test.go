@@ -0,0 +1,24 @@
+package analytics
+
+import (
+	"testing"
+
+	analyticsstore "bandcash/models/analytics/data"
+)
+
+func TestIncomeChangeLabel(t *testing.T) {
+	t.Parallel()
+
+	yearly := []analyticsstore.PeriodTotals{
+		{Period: 2022, Income: 0},
+		{Period: 2023, Income: 1000},
+		{Period: 2024, Income: 1250},
+		{Period: 2026, Income: 500},
+	}
+	want := []string{"", "", "+25%", ""}
+	for i := range yearly {
+		if got := incomeChangeLabel(yearly, i); got != want[i] {
+			t.Errorf("incomeChangeLabel(%d) = %q; want %q", yearly[i].Period, got, want[i])
+		}
+	}
+}
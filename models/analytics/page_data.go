@@ -0,0 +1,29 @@
+package analytics
+
+import (
+	"bandcash/internal/utils"
+	analyticsstore "bandcash/models/analytics/data"
+)
+
+type AnalyticsData struct {
+	Title           string
+	GroupID         string
+	BaseCurrency    string
+	Year            int
+	Years           []int
+	Months          []analyticsstore.PeriodTotals
+	YearTotals      analyticsstore.PeriodTotals
+	MonthlyChart    barChart
+	ComparisonChart barChart
+	YearlyTotals    []analyticsstore.PeriodTotals
+	Venues          []analyticsstore.VenueTotal
+	Members         []analyticsstore.MemberEarnings
+	MonthsTable     utils.TableLayout
+	YearsTable      utils.TableLayout
+	VenuesTable     utils.TableLayout
+	MembersTable    utils.TableLayout
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
@@ -0,0 +1,40 @@
+package analytics
+
+import "bandcash/internal/utils"
+
+func MonthsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "month"},
+		{Key: "income", MaxWRem: 12, WRem: 12},
+		{Key: "expenses", MaxWRem: 12, WRem: 12},
+		{Key: "payouts", MaxWRem: 12, WRem: 12},
+		{Key: "balance", MaxWRem: 12, WRem: 12},
+	}, 0)
+}
+
+func YearsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "year"},
+		{Key: "income", MaxWRem: 12, WRem: 12},
+		{Key: "expenses", MaxWRem: 12, WRem: 12},
+		{Key: "payouts", MaxWRem: 12, WRem: 12},
+		{Key: "balance", MaxWRem: 12, WRem: 12},
+		{Key: "change", MaxWRem: 8, WRem: 8},
+	}, 0)
+}
+
+func VenuesTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "place"},
+		{Key: "events", MaxWRem: 7, WRem: 7},
+		{Key: "income", MaxWRem: 12, WRem: 12},
+	}, 0)
+}
+
+func MembersTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "name"},
+		{Key: "trend", MaxWRem: 8, WRem: 8},
+		{Key: "total", MaxWRem: 12, WRem: 12},
+	}, 0)
+}
@@ -0,0 +1,20 @@
+package analytics
+
+import (
+	"fmt"
+
+	analyticsstore "bandcash/models/analytics/data"
+)
+
+// incomeChangeLabel is the change in income of yearly[i] against the year
+// before it, such as "+12%", or empty when that year has no income.
+func incomeChangeLabel(yearly []analyticsstore.PeriodTotals, i int) string {
+	if i == 0 || yearly[i-1].Period != yearly[i].Period-1 {
+		return ""
+	}
+	change, ok := percentChange(yearly[i-1].Income, yearly[i].Income)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%+d%%", change)
+}
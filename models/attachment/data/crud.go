@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+const (
+	EntityEvent   = "event"
+	EntityExpense = "expense"
+)
+
+func entityColumn(entity string) (string, error) {
+	switch entity {
+	case EntityEvent:
+		return "event_id", nil
+	case EntityExpense:
+		return "expense_id", nil
+	default:
+		return "", fmt.Errorf("unknown attachment entity %q", entity)
+	}
+}
+
+func GetAttachment(ctx context.Context, arg GetAttachmentParams) (db.Attachment, error) {
+	var row db.Attachment
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
+
+func ListAttachments(ctx context.Context, arg ListAttachmentsParams) ([]db.Attachment, error) {
+	column, err := entityColumn(arg.Entity)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]db.Attachment, 0)
+	err = db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("group_id = ?", arg.GroupID).
+		Where("? = ?", bun.Ident(column), arg.EntityID).
+		OrderExpr("created_at ASC").
+		OrderExpr("file_name ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+// ListAttachmentHashes returns the blob keys referenced by an event or
+// expense, so they can be released after the row cascade-deletes.
+func ListAttachmentHashes(ctx context.Context, arg ListAttachmentsParams) ([]string, error) {
+	column, err := entityColumn(arg.Entity)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0)
+	err = db.Conn(ctx).NewSelect().
+		Model((*db.Attachment)(nil)).
+		Column("hash").
+		Where("group_id = ?", arg.GroupID).
+		Where("? = ?", bun.Ident(column), arg.EntityID).
+		Scan(ctx, &hashes)
+	return hashes, err
+}
+
+// ListGroupAttachmentHashes returns the blob keys referenced anywhere in a
+// group, so they can be released after the group cascade-deletes.
+func ListGroupAttachmentHashes(ctx context.Context, groupID string) ([]string, error) {
+	hashes := make([]string, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model((*db.Attachment)(nil)).
+		Column("hash").
+		Where("group_id = ?", groupID).
+		Scan(ctx, &hashes)
+	return hashes, err
+}
+
+// ClaimAttachmentHash counts the rows that reference a blob. It writes to
+// them first, which takes the database write lock inside a transaction, so
+// no row for the blob can be inserted until the transaction ends.
+func ClaimAttachmentHash(ctx context.Context, hash string) (int, error) {
+	_, err := db.Conn(ctx).NewUpdate().Model((*db.Attachment)(nil)).
+		Set("hash = hash").
+		Where("hash = ?", hash).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return db.Conn(ctx).NewSelect().Model((*db.Attachment)(nil)).Where("hash = ?", hash).Count(ctx)
+}
+
+func CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (db.Attachment, error) {
+	row := db.Attachment{
+		ID:          arg.ID,
+		GroupID:     arg.GroupID,
+		FileName:    arg.FileName,
+		ContentType: arg.ContentType,
+		Size:        arg.Size,
+		Hash:        arg.Hash,
+		UploadedBy:  sql.NullString{String: arg.UploadedBy, Valid: arg.UploadedBy != ""},
+	}
+	switch arg.Entity {
+	case EntityEvent:
+		row.EventID = sql.NullString{String: arg.EntityID, Valid: true}
+	case EntityExpense:
+		row.ExpenseID = sql.NullString{String: arg.EntityID, Valid: true}
+	default:
+		return db.Attachment{}, fmt.Errorf("unknown attachment entity %q", arg.Entity)
+	}
+
+	if _, err := db.Conn(ctx).NewInsert().Model(&row).ExcludeColumn("created_at").Exec(ctx); err != nil {
+		return db.Attachment{}, err
+	}
+	return GetAttachment(ctx, GetAttachmentParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+func DeleteAttachment(ctx context.Context, arg DeleteAttachmentParams) error {
+	_, err := db.Conn(ctx).NewDelete().
+		Model((*db.Attachment)(nil)).
+		Where("id = ?", arg.ID).
+		Where("group_id = ?", arg.GroupID).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,29 @@
+package data
+
+type GetAttachmentParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
+
+type ListAttachmentsParams struct {
+	GroupID  string `json:"group_id"`
+	Entity   string `json:"entity"`
+	EntityID string `json:"entity_id"`
+}
+
+type CreateAttachmentParams struct {
+	ID          string `json:"id"`
+	GroupID     string `json:"group_id"`
+	Entity      string `json:"entity"`
+	EntityID    string `json:"entity_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Hash        string `json:"hash"`
+	UploadedBy  string `json:"uploaded_by"`
+}
+
+type DeleteAttachmentParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
@@ -0,0 +1,143 @@
+package attachment
+
+import (
+	"log/slog"
+	"net/http"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/utils"
+	attachmentstore "bandcash/models/attachment/data"
+)
+
+func UploadForEvent(c echo.Context) error {
+	return upload(c, attachmentstore.EntityEvent)
+}
+
+func UploadForExpense(c echo.Context) error {
+	return upload(c, attachmentstore.EntityExpense)
+}
+
+// upload handles a multipart form post from the attachment list. The body
+// skips GlobalBodyLimit and is capped by UploadBodyLimit instead.
+func upload(c echo.Context, entity string) error {
+	groupID := utils.GetGroupID(c)
+
+	entityID := c.Param("id")
+	if !utils.IsValidID(entityID, entityPrefix(entity)) {
+		slog.Info("attachment.upload: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	if !utils.SetTabID(c, c.FormValue("tab_id")) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	ctx := c.Request().Context()
+
+	if err := ensureEntity(ctx, groupID, entity, entityID); err != nil {
+		slog.Info("attachment.upload: entity not found", "entity", entity, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Notify(c, ctxi18n.T(ctx, "attachments.errors.missing_file"))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	maxBytes := utils.Env().UploadMaxBytes
+	if fileHeader.Size > maxBytes {
+		utils.Notify(c, ctxi18n.T(ctx, "attachments.errors.too_large", formatSize(maxBytes)))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("attachment.upload: failed to open file", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	contentType, allowed, err := detectContentType(file)
+	if err != nil {
+		slog.Error("attachment.upload: failed to detect content type", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !allowed {
+		slog.Info("attachment.upload: rejected content type", "content_type", contentType)
+		utils.Notify(c, ctxi18n.T(ctx, "attachments.errors.unsupported_type"))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	err = storeAttachment(ctx, file, attachmentstore.CreateAttachmentParams{
+		ID:          utils.GenerateID(utils.PrefixAttachment),
+		GroupID:     groupID,
+		Entity:      entity,
+		EntityID:    entityID,
+		FileName:    sanitizeFileName(fileHeader.Filename),
+		ContentType: contentType,
+		UploadedBy:  utils.GetUserID(c),
+	})
+	if err != nil {
+		slog.Error("attachment.upload: failed to save file", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "attachments.notifications.upload_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "attachments.notifications.uploaded"))
+
+	utils.SSEHub.Publish(c, entityTopics(groupID, entity, entityID)...)
+	if err := utils.SSEHub.Redirect(c, entityURL(groupID, entity, entityID)); err != nil {
+		slog.Warn("attachment.upload: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Destroy(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixAttachment) {
+		slog.Info("attachment.destroy: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("attachment.destroy: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	row, err := attachmentstore.GetAttachment(ctx, attachmentstore.GetAttachmentParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Info("attachment.destroy: attachment not found", "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err := attachmentstore.DeleteAttachment(ctx, attachmentstore.DeleteAttachmentParams{ID: id, GroupID: groupID}); err != nil {
+		slog.Error("attachment.destroy: failed to delete attachment", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "attachments.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	ReleaseBlobs(ctx, row.Hash)
+
+	utils.Notify(c, ctxi18n.T(ctx, "attachments.notifications.deleted"))
+
+	entity, entityID := attachmentstore.EntityEvent, row.EventID.String
+	if row.ExpenseID.Valid {
+		entity, entityID = attachmentstore.EntityExpense, row.ExpenseID.String
+	}
+	utils.SSEHub.Publish(c, entityTopics(groupID, entity, entityID)...)
+	if err := utils.SSEHub.Redirect(c, entityURL(groupID, entity, entityID)); err != nil {
+		slog.Warn("attachment.destroy: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
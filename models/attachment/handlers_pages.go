@@ -0,0 +1,57 @@
+package attachment
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/storage"
+	"bandcash/internal/utils"
+	attachmentstore "bandcash/models/attachment/data"
+)
+
+// Download streams an attachment. Access is checked by RequireGroup on the
+// route, and the lookup is scoped to the group from the URL.
+func Download(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixAttachment) {
+		slog.Info("attachment.download: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	row, err := attachmentstore.GetAttachment(ctx, attachmentstore.GetAttachmentParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Info("attachment.download: attachment not found", "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	body, err := storage.Default().Open(ctx, row.Hash)
+	if errors.Is(err, storage.ErrNotFound) {
+		slog.Error("attachment.download: blob missing", "attachment_id", id, "hash", row.Hash)
+		return c.NoContent(http.StatusNotFound)
+	}
+	if err != nil {
+		slog.Error("attachment.download: failed to open blob", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	disposition := "attachment"
+	if isInline(row.ContentType) {
+		disposition = "inline"
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": row.FileName}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private, max-age=3600")
+	return c.Stream(http.StatusOK, row.ContentType, body)
+}
@@ -0,0 +1,11 @@
+package attachment
+
+import "bandcash/internal/db"
+
+type AttachmentListProps struct {
+	GroupID     string
+	Entity      string
+	EntityID    string
+	Attachments []db.Attachment
+	IsAdmin     bool
+}
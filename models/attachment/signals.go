@@ -0,0 +1,5 @@
+package attachment
+
+type tabParams struct {
+	TabID string `json:"tab_id"`
+}
@@ -0,0 +1,11 @@
+package attachment
+
+import "bandcash/internal/utils"
+
+func AttachmentsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "file_name"},
+		{Key: "size", MaxWRem: 8},
+		{Key: "created_at", MaxWRem: 12},
+	}, 4)
+}
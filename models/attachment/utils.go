@@ -0,0 +1,233 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/storage"
+	"bandcash/internal/utils"
+	attachmentstore "bandcash/models/attachment/data"
+	eventstore "bandcash/models/event/data"
+	expensestore "bandcash/models/expense/data"
+)
+
+// allowedContentTypes lists what can be uploaded, matched against the sniffed
+// content rather than the name or header the browser sent.
+var allowedContentTypes = []string{
+	"application/pdf",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"image/heic",
+	"text/plain",
+	"text/csv",
+	"application/msword",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.ms-excel",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.oasis.opendocument.text",
+	"application/vnd.oasis.opendocument.spreadsheet",
+}
+
+// detectContentType sniffs the start of r and rewinds it.
+func detectContentType(r io.ReadSeeker) (string, bool, error) {
+	detected, err := mimetype.DetectReader(r)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", false, err
+	}
+
+	contentType, _, err := mime.ParseMediaType(detected.String())
+	if err != nil {
+		contentType = detected.String()
+	}
+	for _, allowed := range allowedContentTypes {
+		if detected.Is(allowed) {
+			return contentType, true, nil
+		}
+	}
+	return contentType, false, nil
+}
+
+// isInline reports whether browsers can safely show the type in a tab.
+func isInline(contentType string) bool {
+	return contentType == "application/pdf" || strings.HasPrefix(contentType, "image/")
+}
+
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(ext)], "") + ext
+	}
+	return name
+}
+
+func entityPrefix(entity string) string {
+	if entity == attachmentstore.EntityExpense {
+		return utils.PrefixExpense
+	}
+	return utils.PrefixEvent
+}
+
+func entityURL(groupID, entity, entityID string) string {
+	if entity == attachmentstore.EntityExpense {
+		return "/groups/" + groupID + "/expenses/" + entityID
+	}
+	return "/groups/" + groupID + "/events/" + entityID
+}
+
+// entityTopics are the hub topics to publish when the attachments of an
+// event or expense change.
+func entityTopics(groupID, entity, entityID string) []string {
+	if entity == attachmentstore.EntityEvent {
+		return []string{utils.GroupTopic(groupID), utils.EventTopic(entityID)}
+	}
+	return []string{utils.GroupTopic(groupID)}
+}
+
+// ensureEntity checks that the event or expense belongs to the group.
+func ensureEntity(ctx context.Context, groupID, entity, entityID string) error {
+	switch entity {
+	case attachmentstore.EntityEvent:
+		_, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: entityID, GroupID: groupID})
+		return err
+	case attachmentstore.EntityExpense:
+		_, err := expensestore.GetExpense(ctx, expensestore.GetExpenseParams{ID: entityID, GroupID: groupID})
+		return err
+	default:
+		return fmt.Errorf("unknown attachment entity %q", entity)
+	}
+}
+
+// storeAttachment stores the content of r and creates the attachment row
+// pointing at it. Hash and Size of arg are filled from the stored blob.
+//
+// The blob is written before the row exists, so a release of the same content
+// may delete it in between. Once the row is inserted no release can drop the
+// blob any more, so it is checked and written again if it went missing.
+func storeAttachment(ctx context.Context, r io.ReadSeeker, arg attachmentstore.CreateAttachmentParams) error {
+	blob, err := storage.PutContent(ctx, storage.Default(), r, arg.ContentType)
+	if err != nil {
+		return fmt.Errorf("store file: %w", err)
+	}
+	arg.Hash = blob.Key
+	arg.Size = blob.Size
+
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := attachmentstore.CreateAttachment(ctx, arg); err != nil {
+			return fmt.Errorf("create attachment: %w", err)
+		}
+		stored, err := storage.Default().Open(ctx, blob.Key)
+		if err == nil {
+			return stored.Close()
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("check file: %w", err)
+		}
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("store file: %w", err)
+		}
+		if _, err := storage.PutContent(ctx, storage.Default(), r, arg.ContentType); err != nil {
+			return fmt.Errorf("store file: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		ReleaseBlobs(ctx, blob.Key)
+		return err
+	}
+	return nil
+}
+
+// ReleaseBlobs removes stored content that no attachment row points at any
+// more. Blobs are shared between identical uploads, so each key is only
+// dropped once its last reference is gone.
+func ReleaseBlobs(ctx context.Context, keys ...string) {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		// Counting and deleting share a write transaction, so an upload of
+		// the same content inserts its row either before the count or after
+		// the blob is gone, and then writes it again.
+		err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+			count, err := attachmentstore.ClaimAttachmentHash(ctx, key)
+			if err != nil || count > 0 {
+				return err
+			}
+			return storage.Default().Delete(ctx, key)
+		})
+		if err != nil {
+			slog.Warn("attachment: failed to release blob", "hash", key, "err", err)
+		}
+	}
+}
+
+// EntityHashes lists the blobs of an event or expense before it is deleted.
+func EntityHashes(ctx context.Context, groupID, entity, entityID string) []string {
+	hashes, err := attachmentstore.ListAttachmentHashes(ctx, attachmentstore.ListAttachmentsParams{
+		GroupID:  groupID,
+		Entity:   entity,
+		EntityID: entityID,
+	})
+	if err != nil {
+		slog.Warn("attachment: failed to list blobs", "entity", entity, "entity_id", entityID, "err", err)
+		return nil
+	}
+	return hashes
+}
+
+// GroupHashes lists the blobs of a whole group before it is deleted.
+func GroupHashes(ctx context.Context, groupID string) []string {
+	hashes, err := attachmentstore.ListGroupAttachmentHashes(ctx, groupID)
+	if err != nil {
+		slog.Warn("attachment: failed to list group blobs", "group_id", groupID, "err", err)
+		return nil
+	}
+	return hashes
+}
+
+// formatSize renders a byte count the way file managers do (12.3 MB).
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"bandcash/internal/db"
+)
+
+// CreateEntries appends entries to the log. The table rejects updates and
+// deletes, so this is the only write.
+func CreateEntries(ctx context.Context, entries []db.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := db.Conn(ctx).NewInsert().Model(&entries).ExcludeColumn("created_at").Exec(ctx)
+	return err
+}
+
+// ParticipantLabel names a participant as "<event title> – <member name>".
+func ParticipantLabel(ctx context.Context, arg ParticipantLabelParams) (string, error) {
+	var label string
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("participants AS p").
+		ColumnExpr("e.title || ' – ' || m.name").
+		Join("JOIN events AS e ON e.id = p.event_id").
+		Join("JOIN members AS m ON m.id = p.member_id").
+		Where("p.event_id = ?", arg.EventID).
+		Where("p.member_id = ?", arg.MemberID).
+		Where("p.group_id = ?", arg.GroupID).
+		Scan(ctx, &label)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return label, err
+}
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"strings"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+type AuditTableFilter struct {
+	GroupID string
+	Search  string
+	Entity  string
+	Year    string
+	From    string
+	To      string
+}
+
+type AuditTableListParams struct {
+	AuditTableFilter
+	Sort   string
+	Dir    string
+	Limit  int
+	Offset int
+}
+
+func CountEntriesTable(ctx context.Context, filter AuditTableFilter) (int64, error) {
+	q := db.Conn(ctx).NewSelect().TableExpr("audit_entries")
+	q = applyAuditTableFilters(q, filter)
+	n, err := q.Count(ctx)
+	return int64(n), err
+}
+
+func ListEntriesTable(ctx context.Context, params AuditTableListParams) ([]db.AuditEntry, error) {
+	rows := make([]db.AuditEntry, 0)
+	q := db.Conn(ctx).NewSelect().Model(&rows)
+	q = applyAuditTableFilters(q, params.AuditTableFilter)
+	q = orderEntries(q, params.Sort, params.Dir)
+	if params.Limit > 0 {
+		q = q.Limit(params.Limit)
+	}
+	if params.Offset > 0 {
+		q = q.Offset(params.Offset)
+	}
+	err := q.Scan(ctx)
+	return rows, err
+}
+
+func applyAuditTableFilters(q *bun.SelectQuery, filter AuditTableFilter) *bun.SelectQuery {
+	q = q.Where("group_id = ?", filter.GroupID)
+	if filter.Entity != "" {
+		q = q.Where("entity_type = ?", filter.Entity)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := "%" + search + "%"
+		q = q.WhereGroup(" AND ", func(qq *bun.SelectQuery) *bun.SelectQuery {
+			return qq.Where("actor_email LIKE ?", like).
+				WhereOr("entity_label LIKE ?", like).
+				WhereOr("entity_id LIKE ?", like).
+				WhereOr("request_id = ?", search)
+		})
+	}
+	return applyDateRangeOrYear(q, filter.From, filter.To, filter.Year, "substr(created_at, 1, 10)")
+}
+
+func orderEntries(q *bun.SelectQuery, sort, dir string) *bun.SelectQuery {
+	d := normalizeDir(dir)
+	switch sort {
+	case "actor":
+		q = q.OrderExpr("actor_email " + d)
+	case "action":
+		q = q.OrderExpr("action " + d)
+	case "entity":
+		q = q.OrderExpr("entity_type " + d).OrderExpr("entity_label " + d)
+	case "created_at":
+		q = q.OrderExpr("created_at " + d)
+	default:
+		q = q.OrderExpr("created_at DESC")
+	}
+	// Entries written in the same second keep their insertion order.
+	return q.OrderExpr("rowid " + d)
+}
+
+func applyDateRangeOrYear(q *bun.SelectQuery, from, to, year, columnExpr string) *bun.SelectQuery {
+	from = strings.TrimSpace(from)
+	to = strings.TrimSpace(to)
+	year = strings.TrimSpace(year)
+	if from != "" && to != "" {
+		return q.Where(columnExpr+" >= ?", from).Where(columnExpr+" <= ?", to)
+	}
+	if year != "" {
+		return q.Where("substr("+columnExpr+", 1, 4) = ?", year)
+	}
+	return q
+}
+
+func normalizeDir(dir string) string {
+	if strings.EqualFold(strings.TrimSpace(dir), "asc") {
+		return "ASC"
+	}
+	return "DESC"
+}
@@ -0,0 +1,7 @@
+package data
+
+type ParticipantLabelParams struct {
+	EventID  string `json:"event_id"`
+	MemberID string `json:"member_id"`
+	GroupID  string `json:"group_id"`
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldChange is one changed field. From is absent for created records and
+// To for deleted ones.
+type FieldChange struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// ignoredFields are bookkeeping columns that change on every write or never.
+var ignoredFields = map[string]bool{
+	"id":                   true,
+	"group_id":             true,
+	"created_at":           true,
+	"updated_at":           true,
+	"version":              true,
+	"participants_version": true,
+}
+
+// snapshot flattens a db row or map into JSON field names and plain values.
+// sql.Null* fields become their value or nil.
+func snapshot(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		out := make(map[string]any, len(m))
+		for key, value := range m {
+			out[key] = plainValue(value)
+		}
+		return out
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	out := make(map[string]any, rv.NumField())
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		out[name] = plainValue(rv.Field(i).Interface())
+	}
+	return out
+}
+
+func plainValue(v any) any {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		v = value
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format("2006-01-02 15:04:05")
+	}
+	return v
+}
+
+// diff compares two snapshots. A nil before lists the record's non-empty
+// fields as created; a nil after lists them as deleted.
+func diff(before, after map[string]any) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	for key, to := range after {
+		if ignoredFields[key] {
+			continue
+		}
+		if before == nil {
+			if !isEmpty(to) {
+				changes[key] = FieldChange{To: to}
+			}
+			continue
+		}
+		if from := before[key]; !reflect.DeepEqual(from, to) {
+			changes[key] = FieldChange{From: from, To: to}
+		}
+	}
+	for key, from := range before {
+		if ignoredFields[key] {
+			continue
+		}
+		if after == nil {
+			if !isEmpty(from) {
+				changes[key] = FieldChange{From: from}
+			}
+			continue
+		}
+		if _, ok := after[key]; !ok && !isEmpty(from) {
+			changes[key] = FieldChange{From: from}
+		}
+	}
+	return changes
+}
+
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	return reflect.ValueOf(v).IsZero()
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"bandcash/internal/db"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	event := db.Event{
+		ID:     "evt_1",
+		Title:  "Gig",
+		Amount: 1000,
+		PaidAt: sql.NullString{String: "2026-03-14", Valid: true},
+	}
+	got := snapshot(event)
+	if got["title"] != "Gig" || got["amount"] != int64(1000) {
+		t.Errorf("unexpected snapshot: %v", got)
+	}
+	if got["paid_at"] != "2026-03-14" {
+		t.Errorf("paid_at = %#v, want plain string", got["paid_at"])
+	}
+	if got := snapshot(db.Event{})["paid_at"]; got != nil {
+		t.Errorf("null paid_at = %#v, want nil", got)
+	}
+	if snapshot(nil) != nil || snapshot("text") != nil {
+		t.Errorf("non-struct values should not snapshot")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]any{"id": "evt_1", "title": "Gig", "paid": int64(0), "paid_at": nil}
+	after := map[string]any{"id": "evt_1", "title": "Gig", "paid": int64(1), "paid_at": "2026-03-14"}
+
+	got := diff(before, after)
+	if len(got) != 2 {
+		t.Fatalf("got %v, want paid and paid_at", got)
+	}
+	if got["paid"].From != int64(0) || got["paid"].To != int64(1) {
+		t.Errorf("paid = %+v", got["paid"])
+	}
+
+	created := diff(nil, after)
+	if _, ok := created["id"]; ok {
+		t.Errorf("created diff lists ignored id: %v", created)
+	}
+	if _, ok := created["paid_at"]; !ok || len(created) != 3 {
+		t.Errorf("unexpected created diff: %v", created)
+	}
+
+	deleted := diff(before, nil)
+	if len(deleted) != 1 || deleted["title"].From != "Gig" {
+		t.Errorf("deleted diff = %v, want only non-empty title", deleted)
+	}
+}
+
+func TestBuildEntry(t *testing.T) {
+	t.Parallel()
+
+	before := db.Expense{ID: "exp_1", Title: "Strings", Amount: 50}
+	if _, ok := buildEntry(Change{Action: ActionUpdate, Entity: "expense", EntityID: "exp_1", Before: before, After: before}); ok {
+		t.Errorf("unchanged update should be skipped")
+	}
+
+	after := before
+	after.Amount = 75
+	entry, ok := buildEntry(Change{Action: ActionUpdate, Entity: "expense", EntityID: "exp_1", Before: before, After: after})
+	if !ok {
+		t.Fatalf("changed update was skipped")
+	}
+	if entry.EntityLabel != "Strings" || entry.EntityType != "expense" || entry.Action != ActionUpdate {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	var changes map[string]FieldChange
+	if err := json.Unmarshal([]byte(entry.Diff), &changes); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	if len(changes) != 1 || changes["amount"].From != float64(50) || changes["amount"].To != float64(75) {
+		t.Errorf("unexpected diff: %s", entry.Diff)
+	}
+
+	entry, ok = buildEntry(Change{Action: ActionRemove, Entity: "access", EntityID: "usr_1", Label: "a@example.com", Before: map[string]any{"role": "viewer"}})
+	if !ok || entry.EntityLabel != "a@example.com" || entry.Diff != `{"role":{"from":"viewer"}}` {
+		t.Errorf("unexpected access entry: %+v", entry)
+	}
+}
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
+)
+
+func IndexPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+	query := utils.ParseTableQuery(c, staticTableQueryable{spec: TableQuerySpec()})
+
+	data, err := GetIndexData(c.Request().Context(), groupID, query)
+	if err != nil {
+		slog.Error("audit.index: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.Signals = auditIndexSignals(data.Query)
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, AuditIndexPage(data))
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/utils"
+	auditstore "bandcash/models/audit/data"
+	groupstore "bandcash/models/group/data"
+)
+
+func TableQuerySpec() utils.TableQuerySpec {
+	return utils.StandardTableQuerySpec(utils.StandardTableQuerySpecParams{
+		DefaultSort:  "created_at",
+		DefaultDir:   "desc",
+		AllowedSorts: []string{"created_at", "actor", "action", "entity"},
+	})
+}
+
+func GetIndexData(ctx context.Context, groupID string, query utils.TableQuery) (AuditData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return AuditData{}, err
+	}
+
+	filters := auditstore.AuditTableFilter{
+		GroupID: groupID,
+		Search:  query.Search,
+		Entity:  query.Entity,
+		Year:    query.Year,
+		From:    query.From,
+		To:      query.To,
+	}
+
+	totalItems, err := auditstore.CountEntriesTable(ctx, filters)
+	if err != nil {
+		return AuditData{}, err
+	}
+	query = utils.ClampPage(query, totalItems)
+
+	entries, err := auditstore.ListEntriesTable(ctx, auditstore.AuditTableListParams{
+		AuditTableFilter: filters,
+		Sort:             query.Sort,
+		Dir:              query.Dir,
+		Limit:            query.PageSize,
+		Offset:           int(query.Offset()),
+	})
+	if err != nil {
+		return AuditData{}, err
+	}
+
+	rows := make([]EntryRow, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, EntryRow{Entry: entry, Changes: changeLines(entry.Diff)})
+	}
+
+	return AuditData{
+		Title:       ctxi18n.T(ctx, "audit.page_title"),
+		Entries:     rows,
+		RecentYears: utils.RecentYears(3),
+		Query:       query,
+		Pager:       utils.BuildTablePagination(totalItems, query),
+		Table:       AuditIndexTableLayout(),
+		GroupID:     groupID,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "audit.title")},
+		},
+	}, nil
+}
+
+// changeLines decodes a stored diff into lines ordered by field name.
+func changeLines(encoded string) []ChangeLine {
+	var changes map[string]FieldChange
+	if err := json.Unmarshal([]byte(encoded), &changes); err != nil {
+		slog.Warn("audit: failed to decode diff", "err", err)
+		return nil
+	}
+	lines := make([]ChangeLine, 0, len(changes))
+	for field, change := range changes {
+		lines = append(lines, ChangeLine{Field: field, From: change.From, To: change.To})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Field < lines[j].Field })
+	return lines
+}
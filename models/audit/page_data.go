@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
+
+type AuditData struct {
+	Title           string
+	Entries         []EntryRow
+	RecentYears     []int
+	Query           utils.TableQuery
+	Pager           utils.TablePagination
+	Table           utils.TableLayout
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
+
+type EntryRow struct {
+	Entry   db.AuditEntry
+	Changes []ChangeLine
+}
+
+type ChangeLine struct {
+	Field string
+	From  any
+	To    any
+}
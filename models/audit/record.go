@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/db"
+	"bandcash/internal/middleware"
+	"bandcash/internal/utils"
+	auditstore "bandcash/models/audit/data"
+	authstore "bandcash/models/auth/data"
+)
+
+// Actions written to the log.
+const (
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionMarkPaid   = "mark_paid"
+	ActionMarkUnpaid = "mark_unpaid"
+	ActionPaidAt     = "paid_at"
+	ActionCancel     = "cancel"
+	ActionRestore    = "restore"
+	ActionInvite     = "invite"
+	ActionRole       = "role"
+	ActionRemove     = "remove"
+	ActionLeave      = "leave"
+	// Event payments are logged on their event, payouts on the participant.
+	ActionPayment       = "payment"
+	ActionPaymentDelete = "payment_delete"
+	ActionPayout        = "payout"
+	ActionPayoutDelete  = "payout_delete"
+	// Collected money a member hands back is logged on the member.
+	ActionHandover = "handover"
+)
+
+// Change is one mutation of one record. Before and After are db rows or
+// maps; Before is nil for creations and After is nil for deletions.
+type Change struct {
+	Action   string
+	Entity   string
+	EntityID string
+	// Label names the record in the log. When empty it is taken from the
+	// record's title or name.
+	Label  string
+	Before any
+	After  any
+}
+
+// PaidAction is the action for a payment toggle that left paid at value.
+func PaidAction(paid int64) string {
+	if paid == 1 {
+		return ActionMarkPaid
+	}
+	return ActionMarkUnpaid
+}
+
+// ParticipantID identifies a participant, which has no id of its own.
+func ParticipantID(eventID, memberID string) string {
+	return eventID + "/" + memberID
+}
+
+// Record appends changes to the current group's log, attributed to the
+// signed-in user and request. Updates that changed nothing are skipped. Call
+// it with the ctx of the db.RunInTx that made the changes, so a failed write
+// rolls them back.
+func Record(ctx context.Context, c echo.Context, changes ...Change) error {
+	groupID := utils.GetGroupID(c)
+	actorID := utils.GetUserID(c)
+
+	actorEmail := ""
+	if actorID != "" {
+		if user, err := authstore.GetUserByID(ctx, actorID); err == nil {
+			actorEmail = user.Email
+		}
+	}
+	requestID := middleware.GetRequestID(ctx)
+
+	entries := make([]db.AuditEntry, 0, len(changes))
+	for _, change := range changes {
+		entry, ok := buildEntry(change)
+		if !ok {
+			continue
+		}
+		entry.ID = utils.GenerateID(utils.PrefixAuditEntry)
+		entry.GroupID = groupID
+		entry.ActorUserID = actorID
+		entry.ActorEmail = actorEmail
+		entry.RequestID = requestID
+		if entry.EntityLabel == "" && change.Entity == utils.EntityFilterParticipant {
+			entry.EntityLabel = participantLabel(ctx, groupID, change.EntityID)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := auditstore.CreateEntries(ctx, entries); err != nil {
+		return fmt.Errorf("audit: write %d entries: %w", len(entries), err)
+	}
+	return nil
+}
+
+// buildEntry fills the parts of an entry that depend only on the change.
+func buildEntry(change Change) (db.AuditEntry, bool) {
+	before := snapshot(change.Before)
+	after := snapshot(change.After)
+	changes := diff(before, after)
+	if len(changes) == 0 && change.Action == ActionUpdate {
+		return db.AuditEntry{}, false
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		slog.Error("audit.record: failed to encode diff", "entity", change.Entity, "err", err)
+		encoded = []byte("{}")
+	}
+
+	label := change.Label
+	if label == "" {
+		label = labelOf(after)
+	}
+	if label == "" {
+		label = labelOf(before)
+	}
+
+	return db.AuditEntry{
+		Action:      change.Action,
+		EntityType:  change.Entity,
+		EntityID:    change.EntityID,
+		EntityLabel: label,
+		Diff:        string(encoded),
+	}, true
+}
+
+func labelOf(values map[string]any) string {
+	for _, key := range []string{"title", "name", "email"} {
+		if label, ok := values[key].(string); ok && label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+func participantLabel(ctx context.Context, groupID, id string) string {
+	eventID, memberID, ok := strings.Cut(id, "/")
+	if !ok {
+		return ""
+	}
+	label, err := auditstore.ParticipantLabel(ctx, auditstore.ParticipantLabelParams{
+		EventID:  eventID,
+		MemberID: memberID,
+		GroupID:  groupID,
+	})
+	if err != nil {
+		slog.Warn("audit.record: failed to label participant", "id", id, "err", err)
+	}
+	return label
+}
@@ -0,0 +1,11 @@
+package audit
+
+import "bandcash/internal/utils"
+
+func auditIndexSignals(query utils.TableQuery) map[string]any {
+	return map[string]any{
+		"tableQuery":      utils.TableQuerySignals(query),
+		"dateRange":       map[string]any{"from": query.From, "to": query.To},
+		"showCustomRange": query.DateMode == "custom" || query.From != "" || query.To != "",
+	}
+}
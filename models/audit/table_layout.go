@@ -0,0 +1,13 @@
+package audit
+
+import "bandcash/internal/utils"
+
+func AuditIndexTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "created_at", MaxWRem: 11, WRem: 11},
+		{Key: "actor", MaxWRem: 14},
+		{Key: "action", MaxWRem: 10},
+		{Key: "entity", MaxWRem: 16},
+		{Key: "changes"},
+	}, 0)
+}
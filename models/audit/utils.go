@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/utils"
+)
+
+type staticTableQueryable struct {
+	spec utils.TableQuerySpec
+}
+
+func (s staticTableQueryable) TableQuerySpec() utils.TableQuerySpec {
+	return s.spec
+}
+
+// entities lists the entity filters in the order they are offered.
+var entities = []string{
+	utils.EntityFilterAll,
+	utils.EntityFilterEvent,
+	utils.EntityFilterParticipant,
+	utils.EntityFilterExpense,
+	utils.EntityFilterMember,
+	utils.EntityFilterAccess,
+	utils.EntityFilterRecurrence,
+}
+
+func entityLabel(ctx context.Context, entity string) string {
+	if entity == utils.EntityFilterAll {
+		return ctxi18n.T(ctx, "table.all")
+	}
+	return ctxi18n.T(ctx, "audit.entities."+entity)
+}
+
+func actionLabel(ctx context.Context, action string) string {
+	if ctxi18n.Has(ctx, "audit.actions."+action) {
+		return ctxi18n.T(ctx, "audit.actions."+action)
+	}
+	return action
+}
+
+// FieldLabel names a column in the current locale, falling back to the
+// column name.
+func FieldLabel(ctx context.Context, field string) string {
+	if ctxi18n.Has(ctx, "audit.fields."+field) {
+		return ctxi18n.T(ctx, "audit.fields."+field)
+	}
+	return field
+}
+
+// entityURL links an entry to its record while the record page exists.
+// Access entries have no page of their own.
+func entityURL(groupID, entity, id string) string {
+	base := "/groups/" + groupID
+	switch entity {
+	case utils.EntityFilterEvent:
+		return base + "/events/" + id
+	case utils.EntityFilterParticipant:
+		eventID, _, _ := strings.Cut(id, "/")
+		return base + "/events/" + eventID
+	case utils.EntityFilterExpense:
+		return base + "/expenses/" + id
+	case utils.EntityFilterMember:
+		return base + "/members/" + id
+	case utils.EntityFilterRecurrence:
+		return base + "/recurrences/" + id
+	default:
+		return ""
+	}
+}
+
+// formatValue prints a diff value. Numbers come back from JSON as float64.
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "–"
+	case string:
+		if value == "" {
+			return "–"
+		}
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 func GetUserByID(ctx context.Context, id string) (db.User, error) {
 	var row db.User
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
 	return row, err
 }
 
@@ -19,7 +19,7 @@ func BanUser(ctx context.Context, arg BanUserParams) error {
 		UserID string `bun:"user_id"`
 	}{ID: arg.ID, UserID: arg.UserID}
 
-	_, err := db.BunDB.NewInsert().
+	_, err := db.Conn(ctx).NewInsert().
 		TableExpr("banned_users").
 		Model(&row).
 		On("CONFLICT(user_id) DO NOTHING").
@@ -28,12 +28,12 @@ func BanUser(ctx context.Context, arg BanUserParams) error {
 }
 
 func UnbanUser(ctx context.Context, userID string) error {
-	_, err := db.BunDB.NewDelete().TableExpr("banned_users").Where("user_id = ?", userID).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().TableExpr("banned_users").Where("user_id = ?", userID).Exec(ctx)
 	return err
 }
 
 func IsUserBanned(ctx context.Context, userID string) (int64, error) {
-	n, err := db.BunDB.NewSelect().TableExpr("banned_users").Where("user_id = ?", userID).Count(ctx)
+	n, err := db.Conn(ctx).NewSelect().TableExpr("banned_users").Where("user_id = ?", userID).Count(ctx)
 	return int64(n), err
 }
 
@@ -43,7 +43,7 @@ func CreateUser(ctx context.Context, arg CreateUserParams) (db.User, error) {
 		lang = strings.TrimSpace(s)
 	}
 	user := db.User{ID: arg.ID, Email: arg.Email, PreferredLang: lang}
-	if _, err := db.BunDB.NewInsert().Model(&user).Exec(ctx); err != nil {
+	if _, err := db.Conn(ctx).NewInsert().Model(&user).Exec(ctx); err != nil {
 		return db.User{}, err
 	}
 	return GetUserByID(ctx, arg.ID)
@@ -51,12 +51,12 @@ func CreateUser(ctx context.Context, arg CreateUserParams) (db.User, error) {
 
 func GetUserByEmail(ctx context.Context, email string) (db.User, error) {
 	var row db.User
-	err := db.BunDB.NewSelect().Model(&row).Where("email = ?", email).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("email = ?", email).Scan(ctx)
 	return row, err
 }
 
 func UpdateUserPreferredLang(ctx context.Context, arg UpdateUserPreferredLangParams) error {
-	_, err := db.BunDB.NewUpdate().Model((*db.User)(nil)).
+	_, err := db.Conn(ctx).NewUpdate().Model((*db.User)(nil)).
 		Set("preferred_lang = ?", arg.PreferredLang).
 		Where("id = ?", arg.ID).
 		Exec(ctx)
@@ -65,17 +65,17 @@ func UpdateUserPreferredLang(ctx context.Context, arg UpdateUserPreferredLangPar
 
 func CreateUserSession(ctx context.Context, arg CreateUserSessionParams) (db.UserSession, error) {
 	session := db.UserSession{ID: arg.ID, UserID: arg.UserID, Token: arg.Token, ExpiresAt: arg.ExpiresAt}
-	if _, err := db.BunDB.NewInsert().Model(&session).Exec(ctx); err != nil {
+	if _, err := db.Conn(ctx).NewInsert().Model(&session).Exec(ctx); err != nil {
 		return db.UserSession{}, err
 	}
 	var row db.UserSession
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", arg.ID).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Scan(ctx)
 	return row, err
 }
 
 func GetUserSessionByToken(ctx context.Context, token string) (db.UserSession, error) {
 	var row db.UserSession
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		Model(&row).
 		Where("token = ?", token).
 		Where("expires_at > CURRENT_TIMESTAMP").
@@ -84,7 +84,7 @@ func GetUserSessionByToken(ctx context.Context, token string) (db.UserSession, e
 }
 
 func DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) error {
-	_, err := db.BunDB.NewDelete().
+	_, err := db.Conn(ctx).NewDelete().
 		TableExpr("user_sessions").
 		Where("id = ?", arg.ID).
 		Where("user_id = ?", arg.UserID).
@@ -93,13 +93,13 @@ func DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) error {
 }
 
 func DeleteAllUserSessions(ctx context.Context, userID string) error {
-	_, err := db.BunDB.NewDelete().TableExpr("user_sessions").Where("user_id = ?", userID).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().TableExpr("user_sessions").Where("user_id = ?", userID).Exec(ctx)
 	return err
 }
 
 func ListUserSessions(ctx context.Context, userID string) ([]db.UserSession, error) {
 	rows := make([]db.UserSession, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		Model(&rows).
 		Where("user_id = ?", userID).
 		OrderExpr("created_at DESC").
@@ -109,12 +109,12 @@ func ListUserSessions(ctx context.Context, userID string) ([]db.UserSession, err
 
 func GetMagicLinkByToken(ctx context.Context, token string) (db.MagicLink, error) {
 	var row db.MagicLink
-	err := db.BunDB.NewSelect().Model(&row).Where("token = ?", token).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("token = ?", token).Scan(ctx)
 	return row, err
 }
 
 func UseMagicLink(ctx context.Context, id string) error {
-	_, err := db.BunDB.NewUpdate().
+	_, err := db.Conn(ctx).NewUpdate().
 		TableExpr("magic_links").
 		Set("used_at = CURRENT_TIMESTAMP").
 		Where("id = ?", id).
@@ -133,7 +133,7 @@ func CreateMagicLink(ctx context.Context, arg CreateMagicLinkParams) (db.MagicLi
 		// TODO: Make invite_role schema/action-aware so login links do not need a placeholder role.
 		InviteRole: "viewer",
 	}
-	_, err := db.BunDB.NewInsert().
+	_, err := db.Conn(ctx).NewInsert().
 		Model(&row).
 		Returning("id, token, email, action, group_id, expires_at, used_at, created_at, invite_role").
 		Exec(ctx)
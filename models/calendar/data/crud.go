@@ -0,0 +1,61 @@
+package data
+
+import (
+	"context"
+
+	"bandcash/internal/db"
+)
+
+func GetFeed(ctx context.Context, arg GetFeedParams) (db.CalendarFeed, error) {
+	var row db.CalendarFeed
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("user_id = ?", arg.UserID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
+
+func GetFeedByToken(ctx context.Context, token string) (db.CalendarFeed, error) {
+	var row db.CalendarFeed
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("token = ?", token).Scan(ctx)
+	return row, err
+}
+
+func RotateFeed(ctx context.Context, arg RotateFeedParams) (db.CalendarFeed, error) {
+	row := db.CalendarFeed{ID: arg.ID, UserID: arg.UserID, GroupID: arg.GroupID, Token: arg.Token}
+	_, err := db.Conn(ctx).NewInsert().
+		Model(&row).
+		ExcludeColumn("last_used_at", "created_at").
+		On("CONFLICT(user_id, group_id) DO UPDATE").
+		Set("token = EXCLUDED.token").
+		Set("last_used_at = NULL").
+		Exec(ctx)
+	if err != nil {
+		return db.CalendarFeed{}, err
+	}
+	return GetFeed(ctx, GetFeedParams{UserID: arg.UserID, GroupID: arg.GroupID})
+}
+
+func DeleteFeed(ctx context.Context, arg DeleteFeedParams) error {
+	_, err := db.Conn(ctx).NewDelete().Model((*db.CalendarFeed)(nil)).Where("user_id = ?", arg.UserID).Where("group_id = ?", arg.GroupID).Exec(ctx)
+	return err
+}
+
+func TouchFeed(ctx context.Context, id string) error {
+	_, err := db.Conn(ctx).NewUpdate().
+		Model((*db.CalendarFeed)(nil)).
+		Set("last_used_at = CURRENT_TIMESTAMP").
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// ListFeedEvents returns the group's events on or after From, oldest first.
+func ListFeedEvents(ctx context.Context, arg ListFeedEventsParams) ([]db.Event, error) {
+	rows := make([]db.Event, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("group_id = ?", arg.GroupID).
+		Where("date >= ?", arg.From).
+		OrderExpr("date ASC").
+		OrderExpr("event_time ASC").
+		Scan(ctx)
+	return rows, err
+}
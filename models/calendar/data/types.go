@@ -0,0 +1,25 @@
+package data
+
+type GetFeedParams struct {
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+}
+
+// RotateFeedParams creates the feed or replaces its token, which revokes the
+// previous URL.
+type RotateFeedParams struct {
+	ID      string `json:"id"`
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+	Token   string `json:"token"`
+}
+
+type DeleteFeedParams struct {
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+}
+
+type ListFeedEventsParams struct {
+	GroupID string `json:"group_id"`
+	From    string `json:"from"`
+}
@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"log/slog"
+	"net/http"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/utils"
+	calendarstore "bandcash/models/calendar/data"
+	groupstore "bandcash/models/group/data"
+)
+
+// Rotate creates the user's feed link, or replaces it so the old URL stops
+// working.
+func Rotate(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	userID := utils.GetUserID(c)
+	ctx := c.Request().Context()
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("calendar.rotate: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		slog.Error("calendar.rotate: failed to generate token", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	_, existingErr := calendarstore.GetFeed(ctx, calendarstore.GetFeedParams{UserID: userID, GroupID: groupID})
+	if _, err := calendarstore.RotateFeed(ctx, calendarstore.RotateFeedParams{
+		ID:      utils.GenerateID(utils.PrefixCalendarFeed),
+		UserID:  userID,
+		GroupID: groupID,
+		Token:   token,
+	}); err != nil {
+		slog.Error("calendar.rotate: failed to save feed", "group_id", groupID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if existingErr == nil {
+		utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.rotated"))
+	} else {
+		utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.created"))
+	}
+
+	if err := utils.SSEHub.Redirect(c, calendarPath(groupID)); err != nil {
+		slog.Warn("calendar.rotate: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// Revoke deletes the user's feed link.
+func Revoke(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("calendar.revoke: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	if err := calendarstore.DeleteFeed(ctx, calendarstore.DeleteFeedParams{UserID: utils.GetUserID(c), GroupID: groupID}); err != nil {
+		slog.Error("calendar.revoke: failed to delete feed", "group_id", groupID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.revoked"))
+
+	if err := utils.SSEHub.Redirect(c, calendarPath(groupID)); err != nil {
+		slog.Warn("calendar.revoke: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// UpdateSettings controls whether feeds of the group include event amounts.
+// It applies to every member's feed.
+func UpdateSettings(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	var signals settingsParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("calendar.settings: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	if err := groupstore.UpdateGroupCalendarAmounts(ctx, groupstore.UpdateGroupCalendarAmountsParams{
+		ShowAmounts: signals.ShowAmounts,
+		ID:          groupID,
+	}); err != nil {
+		slog.Error("calendar.settings: failed to update group", "group_id", groupID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "calendar.notifications.settings_saved"))
+
+	if err := utils.SSEHub.Redirect(c, calendarPath(groupID)); err != nil {
+		slog.Warn("calendar.settings: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,116 @@
+package calendar
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	ctxi18nlib "github.com/invopop/ctxi18n"
+	"github.com/labstack/echo/v4"
+
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/utils"
+	authstore "bandcash/models/auth/data"
+	calendarstore "bandcash/models/calendar/data"
+	groupstore "bandcash/models/group/data"
+)
+
+// feedHistory limits how far back the feed reaches, keeping it small for
+// long-running groups.
+const feedHistory = 365 * 24 * time.Hour
+
+func IndexPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	data, err := GetPageData(c.Request().Context(), groupID, utils.GetUserID(c))
+	if err != nil {
+		slog.Error("calendar.index: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = calendarSignals(data.Group.CalendarShowAmounts == 1)
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, CalendarPage(data))
+}
+
+// Feed serves the .ics document for a feed token. Calendar apps fetch it
+// without cookies, so the token is the only credential; it stops working
+// once the owner loses access to the group or is banned. Every failure is a
+// plain 404 so tokens cannot be probed.
+func Feed(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok || !tokenPattern.MatchString(token) {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	feed, err := calendarstore.GetFeedByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.NoContent(http.StatusNotFound)
+	}
+	if err != nil {
+		slog.Error("calendar.feed: failed to load feed", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	user, err := authstore.GetUserByID(ctx, feed.UserID)
+	if err != nil {
+		slog.Info("calendar.feed: owner not found", "feed_id", feed.ID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+	bannedCount, err := authstore.IsUserBanned(ctx, user.ID)
+	if err != nil || bannedCount > 0 {
+		slog.Info("calendar.feed: owner banned or unchecked", "feed_id", feed.ID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+	if _, err := groupstore.GetGroupAccessRole(ctx, groupstore.GetGroupAccessRoleParams{UserID: user.ID, GroupID: feed.GroupID}); err != nil {
+		slog.Info("calendar.feed: owner has no group access", "feed_id", feed.ID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	group, err := groupstore.GetGroupByID(ctx, feed.GroupID)
+	if err != nil {
+		slog.Error("calendar.feed: failed to load group", "group_id", feed.GroupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	now := time.Now()
+	events, err := calendarstore.ListFeedEvents(ctx, calendarstore.ListFeedEventsParams{
+		GroupID: group.ID,
+		From:    now.Add(-feedHistory).Format("2006-01-02"),
+	})
+	if err != nil {
+		slog.Error("calendar.feed: failed to list events", "group_id", group.ID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// The request carries no session, so render in the owner's language.
+	if localizedCtx, err := ctxi18nlib.WithLocale(ctx, appi18n.NormalizeLocale(user.PreferredLang)); err == nil {
+		ctx = localizedCtx
+	}
+
+	body := buildFeed(ctx, feedParams{
+		Group:       group,
+		Events:      events,
+		ShowAmounts: group.CalendarShowAmounts == 1,
+		BaseURL:     utils.Env().URL,
+		Now:         now,
+	})
+
+	if err := calendarstore.TouchFeed(ctx, feed.ID); err != nil {
+		slog.Warn("calendar.feed: failed to record use", "feed_id", feed.ID, "err", err)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, `inline; filename="calendar.ics"`)
+	header.Set("Cache-Control", "private, max-age=900")
+	header.Set("X-Robots-Tag", "noindex")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(body))
+}
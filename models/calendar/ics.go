@@ -0,0 +1,159 @@
+package calendar
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+)
+
+// defaultDuration is used for DTEND because events only store a start time.
+const defaultDuration = 2 * time.Hour
+
+// feedParams is everything that goes into one .ics document.
+type feedParams struct {
+	Group       db.Group
+	Events      []db.Event
+	ShowAmounts bool
+	BaseURL     string
+	Now         time.Time
+}
+
+// buildFeed renders an RFC 5545 calendar. Times are floating (no time zone),
+// so clients show gigs at the wall-clock time they were entered with. UIDs
+// are derived from event IDs, which lets clients update entries in place.
+func buildFeed(ctx context.Context, params feedParams) string {
+	w := &icsWriter{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//bandcash//Calendar Feed//EN")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.prop("X-WR-CALNAME", params.Group.Name)
+	w.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	w.line("X-PUBLISHED-TTL:PT1H")
+
+	stamp := params.Now.UTC().Format(icsUTCLayout)
+	for _, event := range params.Events {
+		start, allDay, ok := eventStart(event)
+		if !ok {
+			continue
+		}
+
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + event.ID + "@bandcash")
+		w.line("DTSTAMP:" + stamp)
+		if event.UpdatedAt.Valid {
+			w.line("LAST-MODIFIED:" + event.UpdatedAt.Time.UTC().Format(icsUTCLayout))
+		}
+		if allDay {
+			w.line("DTSTART;VALUE=DATE:" + start.Format(icsDateLayout))
+			w.line("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format(icsDateLayout))
+		} else {
+			w.line("DTSTART:" + start.Format(icsLocalLayout))
+			w.line("DTEND:" + start.Add(defaultDuration).Format(icsLocalLayout))
+		}
+		w.prop("SUMMARY", event.Title)
+		if event.Place != "" {
+			w.prop("LOCATION", event.Place)
+		}
+		if description := eventDescription(ctx, event, params.ShowAmounts); description != "" {
+			w.prop("DESCRIPTION", description)
+		}
+		if params.BaseURL != "" {
+			w.line("URL:" + strings.TrimRight(params.BaseURL, "/") + "/groups/" + event.GroupID + "/events/" + event.ID)
+		}
+		if event.Status == eventstore.EventStatusCancelled {
+			w.line("STATUS:CANCELLED")
+		} else {
+			w.line("STATUS:CONFIRMED")
+		}
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+	return w.buf.String()
+}
+
+const (
+	icsUTCLayout   = "20060102T150405Z"
+	icsLocalLayout = "20060102T150405"
+	icsDateLayout  = "20060102"
+)
+
+// eventStart parses the event date and optional time. Events without a time
+// become all-day entries.
+func eventStart(event db.Event) (time.Time, bool, bool) {
+	day, err := time.Parse("2006-01-02", event.Date)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	if event.EventTime == "" {
+		return day, true, true
+	}
+	start, err := time.Parse("2006-01-02 15:04", event.Date+" "+event.EventTime)
+	if err != nil {
+		return day, true, true
+	}
+	return start, false, true
+}
+
+func eventDescription(ctx context.Context, event db.Event, showAmounts bool) string {
+	parts := make([]string, 0, 3)
+	if event.Status == eventstore.EventStatusCancelled && event.CancelReason != "" {
+		parts = append(parts, ctxi18n.T(ctx, "calendar.cancel_reason_line", event.CancelReason))
+	}
+	if event.Description != "" {
+		parts = append(parts, event.Description)
+	}
+	if showAmounts {
+		parts = append(parts, ctxi18n.T(ctx, "calendar.amount_line", utils.FormatNumberLocalized(ctx, eventstore.IncomeAmount(event))))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// icsWriter emits CRLF-terminated content lines folded at 75 octets.
+type icsWriter struct {
+	buf strings.Builder
+}
+
+func (w *icsWriter) prop(name, value string) {
+	w.line(name + ":" + escapeText(value))
+}
+
+func (w *icsWriter) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		// Never split a UTF-8 sequence across folded lines.
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = 74
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+// escapeText applies the TEXT value escaping of RFC 5545 section 3.3.11.
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\n", `\n`,
+)
@@ -0,0 +1,90 @@
+package calendar
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+)
+
+func TestBuildFeed(t *testing.T) {
+	t.Parallel()
+
+	params := feedParams{
+		Group: db.Group{Name: "The Band"},
+		Events: []db.Event{
+			{ID: "evt_1", GroupID: "grp_1", Title: "Gig, with; specials", Date: "2026-05-01", EventTime: "20:30", Place: "Club", Amount: 1000, Status: "active"},
+			{ID: "evt_2", GroupID: "grp_1", Title: "Festival", Date: "2026-06-10", Amount: 500, Status: eventstore.EventStatusCancelled},
+		},
+		BaseURL: "https://example.com",
+		Now:     time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	out := buildFeed(context.Background(), params)
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("feed does not end with CRLF terminated END:VCALENDAR")
+	}
+	for _, want := range []string{
+		"UID:evt_1@bandcash\r\n",
+		"DTSTART:20260501T203000\r\n",
+		"DTEND:20260501T223000\r\n",
+		`SUMMARY:Gig\, with\; specials` + "\r\n",
+		"STATUS:CONFIRMED\r\n",
+		"DTSTART;VALUE=DATE:20260610\r\n",
+		"DTEND;VALUE=DATE:20260611\r\n",
+		"STATUS:CANCELLED\r\n",
+		"URL:https://example.com/groups/grp_1/events/evt_1\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("feed missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DESCRIPTION") {
+		t.Fatalf("amounts shown while disabled:\n%s", out)
+	}
+
+	params.ShowAmounts = true
+	if out := buildFeed(context.Background(), params); strings.Count(out, "DESCRIPTION:") != 2 {
+		t.Fatalf("expected a description per event when amounts are enabled:\n%s", out)
+	}
+}
+
+func TestICSWriterFolds(t *testing.T) {
+	t.Parallel()
+
+	w := &icsWriter{}
+	w.prop("DESCRIPTION", strings.Repeat("ő", 100))
+
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folded lines, got %d", len(lines))
+	}
+	var unfolded strings.Builder
+	for i, line := range lines {
+		if len(line) > 75 {
+			t.Fatalf("line %d is %d octets", i, len(line))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(line, " ") {
+				t.Fatalf("continuation line %d does not start with a space", i)
+			}
+			line = line[1:]
+		}
+		unfolded.WriteString(line)
+	}
+	if got, want := unfolded.String(), "DESCRIPTION:"+strings.Repeat("ő", 100); got != want {
+		t.Fatalf("unfolded = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	t.Parallel()
+
+	got := escapeText("a\\b;c,d\r\ne")
+	if want := `a\\b\;c\,d\ne`; got != want {
+		t.Fatalf("escapeText = %q, want %q", got, want)
+	}
+}
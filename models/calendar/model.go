@@ -0,0 +1,41 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/utils"
+	calendarstore "bandcash/models/calendar/data"
+	groupstore "bandcash/models/group/data"
+)
+
+func GetPageData(ctx context.Context, groupID, userID string) (CalendarData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return CalendarData{}, err
+	}
+
+	data := CalendarData{
+		Title:   ctxi18n.T(ctx, "calendar.page_title"),
+		GroupID: groupID,
+		Group:   group,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "events.title"), Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "calendar.title")},
+		},
+	}
+
+	feed, err := calendarstore.GetFeed(ctx, calendarstore.GetFeedParams{UserID: userID, GroupID: groupID})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return CalendarData{}, err
+	}
+	if err == nil {
+		data.Feed = &feed
+	}
+	return data, nil
+}
@@ -0,0 +1,18 @@
+package calendar
+
+import (
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
+
+type CalendarData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	Group           db.Group
+	Feed            *db.CalendarFeed
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
@@ -0,0 +1,17 @@
+package calendar
+
+type tabParams struct {
+	TabID string `json:"tab_id"`
+}
+
+type settingsParams struct {
+	TabID       string `json:"tab_id"`
+	ShowAmounts bool   `json:"showAmounts"`
+}
+
+func calendarSignals(showAmounts bool) map[string]any {
+	return map[string]any{
+		"showAmounts": showAmounts,
+		"_fetching":   false,
+	}
+}
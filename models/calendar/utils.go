@@ -0,0 +1,41 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"bandcash/internal/utils"
+)
+
+// tokenPattern matches newToken output: 32 random bytes, base64url encoded.
+var tokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func feedURL(token string) string {
+	return strings.TrimRight(utils.Env().URL, "/") + "/calendar/" + token + ".ics"
+}
+
+// subscribeURL opens the feed in the system calendar app.
+func subscribeURL(token string) string {
+	url := feedURL(token)
+	if rest, ok := strings.CutPrefix(url, "https://"); ok {
+		return "webcal://" + rest
+	}
+	if rest, ok := strings.CutPrefix(url, "http://"); ok {
+		return "webcal://" + rest
+	}
+	return url
+}
+
+func calendarPath(groupID string) string {
+	return "/groups/" + groupID + "/calendar"
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
+)
+
+// participantValues is the audited part of a participant row.
+func participantValues(row eventstore.ListParticipantsByEventRow) map[string]any {
+	return map[string]any{
+		"member_id":    row.ID,
+		"amount":       row.ParticipantAmount,
+		"expense":      row.ParticipantExpense,
+		"note":         row.ParticipantNote,
+		"paid":         row.ParticipantPaid,
+		"paid_at":      row.ParticipantPaidAt,
+		"compensation": row.ParticipantCompensation,
+	}
+}
+
+// participantChanges compares an event's participants before and after a
+// bulk save. Labels are set here because removed rows can't be looked up.
+func participantChanges(event db.Event, before, after []eventstore.ListParticipantsByEventRow) []audit.Change {
+	previous := make(map[string]eventstore.ListParticipantsByEventRow, len(before))
+	for _, row := range before {
+		previous[row.ID] = row
+	}
+
+	changes := make([]audit.Change, 0, len(before)+len(after))
+	for _, row := range after {
+		change := audit.Change{
+			Action:   audit.ActionCreate,
+			Entity:   utils.EntityFilterParticipant,
+			EntityID: audit.ParticipantID(event.ID, row.ID),
+			Label:    event.Title + " – " + row.Name,
+			After:    participantValues(row),
+		}
+		if old, ok := previous[row.ID]; ok {
+			change.Action = audit.ActionUpdate
+			change.Before = participantValues(old)
+			delete(previous, row.ID)
+		}
+		changes = append(changes, change)
+	}
+	for _, row := range before {
+		if _, removed := previous[row.ID]; !removed {
+			continue
+		}
+		changes = append(changes, audit.Change{
+			Action:   audit.ActionDelete,
+			Entity:   utils.EntityFilterParticipant,
+			EntityID: audit.ParticipantID(event.ID, row.ID),
+			Label:    event.Title + " – " + row.Name,
+			Before:   participantValues(row),
+		})
+	}
+	return changes
+}
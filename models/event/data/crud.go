@@ -3,42 +3,98 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strings"
 	"time"
 
+	"bandcash/internal/currency"
 	"bandcash/internal/db"
+	"github.com/uptrace/bun"
 )
 
+const (
+	EventStatusActive    = "active"
+	EventStatusCancelled = "cancelled"
+)
+
+// ErrEventInvoiced is returned when deleting an event that has an issued
+// invoice.
+var ErrEventInvoiced = errors.New("event: event has an issued invoice")
+
+// NotInvoicedExpr matches events without an issued invoice. Invoices are only
+// reachable through their event, so those events are never deleted.
+const NotInvoicedExpr = "NOT EXISTS (SELECT 1 FROM invoices WHERE invoices.event_id = events.id)"
+
+// IncomeAmount is what the group earns from an event: the agreed amount, or
+// the cancellation fee once the event is cancelled.
+func IncomeAmount(event db.Event) int64 {
+	if event.Status == EventStatusCancelled {
+		return event.CancellationFee
+	}
+	return event.Amount
+}
+
+// BaseIncomeAmount is IncomeAmount converted into the group's base currency.
+func BaseIncomeAmount(event db.Event) int64 {
+	return currency.ToBase(IncomeAmount(event), event.ExchangeRate)
+}
+
+// PayoutAmount is what a participant is owed: cut less the tax withheld from
+// it plus expense, or the compensation when the event is cancelled.
+func PayoutAmount(status string, amount, withheld, expense, compensation int64) int64 {
+	if status == EventStatusCancelled {
+		return compensation
+	}
+	return amount - withheld + expense
+}
+
 func GetEvent(ctx context.Context, arg GetEventParams) (db.Event, error) {
 	var row db.Event
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
 	return row, err
 }
 
 func GetEventByID(ctx context.Context, id string) (db.Event, error) {
 	var row db.Event
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
 	return row, err
 }
 
 func ListEvents(ctx context.Context, groupID string) ([]db.Event, error) {
 	rows := make([]db.Event, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("events").
-		Column("amount", "paid").
+		Column("id", "amount", "paid", "status", "cancellation_fee", "exchange_rate").
 		Where("group_id = ?", groupID).
 		OrderExpr("time ASC").
 		Scan(ctx, &rows)
 	return rows, err
 }
 
+// ListEventOptions returns the id, title and date of every event of a group,
+// newest first, for pickers.
+func ListEventOptions(ctx context.Context, groupID string) ([]db.Event, error) {
+	rows := make([]db.Event, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("events").
+		Column("id", "title", "date", "time").
+		Where("group_id = ?", groupID).
+		OrderExpr("COALESCE(NULLIF(date, ''), substr(time, 1, 10)) DESC").
+		OrderExpr("created_at DESC").
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+// ListPaidEventsByGroup returns events that are paid or have received at
+// least one payment, latest payment first.
 func ListPaidEventsByGroup(ctx context.Context, groupID string) ([]db.Event, error) {
 	rows := make([]db.Event, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		Model(&rows).
 		Where("group_id = ?", groupID).
-		Where("paid = 1").
-		OrderExpr("COALESCE(paid_at, updated_at) DESC").
+		Where("(paid = 1 OR EXISTS (SELECT 1 FROM event_payments WHERE event_payments.event_id = event.id))").
+		Where("(status <> ? OR cancellation_fee > 0)", EventStatusCancelled).
+		OrderExpr("COALESCE(paid_at, (SELECT MAX(event_payments.paid_at) FROM event_payments WHERE event_payments.event_id = event.id), updated_at) DESC").
 		OrderExpr("updated_at DESC").
 		Scan(ctx)
 	return rows, err
@@ -46,10 +102,11 @@ func ListPaidEventsByGroup(ctx context.Context, groupID string) ([]db.Event, err
 
 func ListUnpaidEventsByGroup(ctx context.Context, groupID string) ([]db.Event, error) {
 	rows := make([]db.Event, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		Model(&rows).
 		Where("group_id = ?", groupID).
 		Where("paid = 0").
+		Where("(status <> ? OR cancellation_fee > 0)", EventStatusCancelled).
 		OrderExpr("time ASC").
 		OrderExpr("created_at DESC").
 		Scan(ctx)
@@ -57,48 +114,73 @@ func ListUnpaidEventsByGroup(ctx context.Context, groupID string) ([]db.Event, e
 }
 
 func CreateEvent(ctx context.Context, arg CreateEventParams) (db.Event, error) {
-	paidAt := paidAtNullable(arg.PaidAt)
-	if arg.Paid == 1 && !paidAt.Valid {
-		paidAt = currentTimestampNullString()
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return insertEvent(ctx, tx, arg)
+	})
+	if err != nil {
+		return db.Event{}, err
 	}
+	return GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
+}
 
-	event := db.Event{
-		ID:          arg.ID,
-		GroupID:     arg.GroupID,
-		Title:       arg.Title,
-		Time:        eventTimeFromParts(arg.Date, arg.EventTime),
-		Date:        arg.Date,
-		EventTime:   arg.EventTime,
-		Place:       arg.Place,
-		Description: arg.Description,
-		Amount:      arg.Amount,
-		Paid:        arg.Paid,
-		PaidAt:      paidAt,
+// insertEvent creates the event unpaid; a paid flag is applied afterwards so
+// the payment ledger records it.
+func insertEvent(ctx context.Context, idb bun.IDB, arg CreateEventParams) error {
+	event := newEventRow(arg)
+	if _, err := idb.NewInsert().Model(&event).Exec(ctx); err != nil {
+		return err
 	}
+	if arg.Paid != 1 {
+		return nil
+	}
+	return settleEventPaid(ctx, idb, arg.GroupID, arg.ID, true, paidAtNullable(arg.PaidAt), arg.PaymentID)
+}
 
-	if _, err := db.BunDB.NewInsert().Model(&event).Exec(ctx); err != nil {
-		return db.Event{}, err
+func newEventRow(arg CreateEventParams) db.Event {
+	return db.Event{
+		ID:                  arg.ID,
+		GroupID:             arg.GroupID,
+		Title:               arg.Title,
+		Time:                eventTimeFromParts(arg.Date, arg.EventTime),
+		Date:                arg.Date,
+		EventTime:           arg.EventTime,
+		Place:               arg.Place,
+		Description:         arg.Description,
+		Amount:              arg.Amount,
+		Currency:            arg.Currency,
+		ExchangeRate:        exchangeRateValue(arg.ExchangeRate),
+		RecurrenceID:        arg.RecurrenceID,
+		RecurrenceDate:      arg.RecurrenceDate,
+		Status:              EventStatusActive,
+		Version:             1,
+		ParticipantsVersion: 1,
 	}
-	return GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
 }
 
 func UpdateEvent(ctx context.Context, arg UpdateEventParams) (db.Event, error) {
-	current, err := GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return updateEvent(ctx, tx, arg)
+	})
 	if err != nil {
 		return db.Event{}, err
 	}
+	return GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
+}
 
-	paidAtInput := paidAtNullable(arg.PaidAt)
-	finalPaidAt := current.PaidAt
-	if arg.Paid == 0 || isPaidAtClearRequest(arg.PaidAt) {
-		finalPaidAt = sql.NullString{}
-	} else if paidAtInput.Valid {
-		finalPaidAt = paidAtInput
-	} else if current.Paid == 0 {
-		finalPaidAt = currentTimestampNullString()
+func updateEvent(ctx context.Context, idb bun.IDB, arg UpdateEventParams) error {
+	var current db.Event
+	err := idb.NewSelect().Model(&current).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	if err != nil {
+		return err
+	}
+	if err := db.CheckVersion("events", arg.ID, arg.Version, current.Version); err != nil {
+		return err
+	}
+	if err := db.CheckVersion("participants", arg.ID, arg.ParticipantsVersion, current.ParticipantsVersion); err != nil {
+		return err
 	}
 
-	_, err = db.BunDB.NewUpdate().Model((*db.Event)(nil)).
+	_, err = idb.NewUpdate().Model((*db.Event)(nil)).
 		Set("title = ?", arg.Title).
 		Set("time = ?", eventTimeFromParts(arg.Date, arg.EventTime)).
 		Set("date = ?", arg.Date).
@@ -106,52 +188,152 @@ func UpdateEvent(ctx context.Context, arg UpdateEventParams) (db.Event, error) {
 		Set("place = ?", arg.Place).
 		Set("description = ?", arg.Description).
 		Set("amount = ?", arg.Amount).
-		Set("paid = ?", arg.Paid).
-		Set("paid_at = ?", paidAtValue(finalPaidAt)).
+		Set("currency = ?", arg.Currency).
+		Set("exchange_rate = ?", exchangeRateValue(arg.ExchangeRate)).
 		Where("id = ?", arg.ID).
 		Where("group_id = ?", arg.GroupID).
 		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return applyEventPaid(ctx, idb, current, arg.Paid, paidAtNullable(arg.PaidAt), arg.PaymentID)
+}
+
+// CancelEvent marks the event cancelled and stores the compensation owed to
+// each participant. Participants missing from arg.Compensation get zero.
+func CancelEvent(ctx context.Context, arg CancelEventParams) (db.Event, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model((*db.Event)(nil)).
+			Set("status = ?", EventStatusCancelled).
+			Set("cancel_reason = ?", arg.Reason).
+			Set("cancellation_fee = ?", arg.Fee).
+			Set("cancelled_at = COALESCE(cancelled_at, ?)", currentTimestampNullString().String).
+			Where("id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		participants := make([]db.Participant, 0)
+		err = tx.NewSelect().Model(&participants).
+			Column("member_id").
+			Where("event_id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, participant := range participants {
+			_, err = tx.NewUpdate().Model((*db.Participant)(nil)).
+				Set("compensation = ?", max(arg.Compensation[participant.MemberID], 0)).
+				Where("event_id = ?", arg.ID).
+				Where("member_id = ?", participant.MemberID).
+				Where("group_id = ?", arg.GroupID).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		if err := syncEventParticipantsPaid(ctx, tx, arg.GroupID, arg.ID); err != nil {
+			return err
+		}
+		return syncEventPaid(ctx, tx, arg.GroupID, arg.ID)
+	})
+	if err != nil {
+		return db.Event{}, err
+	}
+	return GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+// RestoreEvent reverts a cancellation. Compensation amounts are cleared so the
+// participants are owed their original cut again.
+func RestoreEvent(ctx context.Context, arg RestoreEventParams) (db.Event, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model((*db.Event)(nil)).
+			Set("status = ?", EventStatusActive).
+			Set("cancel_reason = ''").
+			Set("cancellation_fee = 0").
+			Set("cancelled_at = NULL").
+			Where("id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().Model((*db.Participant)(nil)).
+			Set("compensation = 0").
+			Where("event_id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if err := syncEventParticipantsPaid(ctx, tx, arg.GroupID, arg.ID); err != nil {
+			return err
+		}
+		return syncEventPaid(ctx, tx, arg.GroupID, arg.ID)
+	})
 	if err != nil {
 		return db.Event{}, err
 	}
 	return GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
 }
 
+// DeleteEvent removes an event unless it has an issued invoice, which must
+// stay reachable from the event; such events are cancelled instead.
 func DeleteEvent(ctx context.Context, arg DeleteEventParams) error {
-	_, err := db.BunDB.NewDelete().Model((*db.Event)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
-	return err
+	return deleteEvent(ctx, arg.ID, func(q *bun.DeleteQuery) *bun.DeleteQuery {
+		return q.Where("group_id = ?", arg.GroupID)
+	})
 }
 
 func DeleteEventByID(ctx context.Context, id string) error {
-	_, err := db.BunDB.NewDelete().Model((*db.Event)(nil)).Where("id = ?", id).Exec(ctx)
-	return err
+	return deleteEvent(ctx, id, func(q *bun.DeleteQuery) *bun.DeleteQuery { return q })
 }
 
-func ToggleEventPaid(ctx context.Context, arg ToggleEventPaidParams) (db.Event, error) {
-	current, err := GetEvent(ctx, GetEventParams(arg))
+func deleteEvent(ctx context.Context, id string, scope func(*bun.DeleteQuery) *bun.DeleteQuery) error {
+	res, err := scope(db.Conn(ctx).NewDelete().TableExpr("events").Where("id = ?", id)).
+		Where(NotInvoicedExpr).
+		Exec(ctx)
 	if err != nil {
-		return db.Event{}, err
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
 	}
+	invoiced, err := db.Conn(ctx).NewSelect().TableExpr("invoices").Where("event_id = ?", id).Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if invoiced {
+		return ErrEventInvoiced
+	}
+	return nil
+}
 
-	nextPaid := int64(1)
-	nextPaidAt := currentTimestampNullString()
-	if current.Paid == 1 {
-		nextPaid = 0
-		nextPaidAt = sql.NullString{}
+// ToggleEventPaid marks an unpaid event paid by recording the outstanding
+// amount, or a paid event without payments unpaid.
+func ToggleEventPaid(ctx context.Context, arg ToggleEventPaidParams) (db.Event, error) {
+	current, err := GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
+	if err != nil {
+		return db.Event{}, err
 	}
 
-	_, err = db.BunDB.NewUpdate().Model((*db.Event)(nil)).
-		Set("paid = ?", nextPaid).
-		Set("paid_at = ?", paidAtValue(nextPaidAt)).
-		Where("id = ?", arg.ID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
+	err = db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return settleEventPaid(ctx, tx, arg.GroupID, arg.ID, current.Paid == 0, sql.NullString{}, arg.PaymentID)
+	})
 	if err != nil {
 		return db.Event{}, err
 	}
-	return GetEvent(ctx, GetEventParams(arg))
+	return GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
 }
 
+// UpdateEventPaidAt dates the event's payment: an unpaid event gets the
+// outstanding amount recorded on that day, a paid one has its latest
+// payment moved.
 func UpdateEventPaidAt(ctx context.Context, arg UpdateEventPaidAtParams) (db.Event, error) {
 	current, err := GetEvent(ctx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
 	if err != nil {
@@ -159,17 +341,12 @@ func UpdateEventPaidAt(ctx context.Context, arg UpdateEventPaidAtParams) (db.Eve
 	}
 
 	paidAt := paidAtNullable(arg.PaidAt)
-	paid := current.Paid
-	if paidAt.Valid && paid == 0 {
-		paid = 1
-	}
-
-	_, err = db.BunDB.NewUpdate().Model((*db.Event)(nil)).
-		Set("paid = ?", paid).
-		Set("paid_at = ?", paidAtValue(paidAt)).
-		Where("id = ?", arg.ID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
+	err = db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if !paidAt.Valid {
+			return nil
+		}
+		return applyEventPaid(ctx, tx, current, 1, paidAt, arg.PaymentID)
+	})
 	if err != nil {
 		return db.Event{}, err
 	}
@@ -178,7 +355,7 @@ func UpdateEventPaidAt(ctx context.Context, arg UpdateEventPaidAtParams) (db.Eve
 
 func ListParticipantsByEvent(ctx context.Context, arg ListParticipantsByEventParams) ([]ListParticipantsByEventRow, error) {
 	rows := make([]ListParticipantsByEventRow, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("members").
 		ColumnExpr("members.id").
 		ColumnExpr("members.group_id").
@@ -187,10 +364,13 @@ func ListParticipantsByEvent(ctx context.Context, arg ListParticipantsByEventPar
 		ColumnExpr("members.created_at").
 		ColumnExpr("members.updated_at").
 		ColumnExpr("participants.amount AS participant_amount").
+		ColumnExpr("participants.withheld AS participant_withheld").
 		ColumnExpr("participants.expense AS participant_expense").
 		ColumnExpr("participants.note AS participant_note").
 		ColumnExpr("participants.paid AS participant_paid").
 		ColumnExpr("participants.paid_at AS participant_paid_at").
+		ColumnExpr("participants.compensation AS participant_compensation").
+		ColumnExpr(ParticipantPaidOutExpr+" AS participant_paid_out").
 		Join("JOIN participants ON participants.member_id = members.id").
 		Where("participants.event_id = ?", arg.EventID).
 		Where("participants.group_id = ?", arg.GroupID).
@@ -199,106 +379,107 @@ func ListParticipantsByEvent(ctx context.Context, arg ListParticipantsByEventPar
 	return rows, err
 }
 
+// ListParticipantsByGroup returns every participant of the group with the
+// member name, for exports that flatten participants into event rows.
+func ListParticipantsByGroup(ctx context.Context, groupID string) ([]GroupParticipantRow, error) {
+	rows := make([]GroupParticipantRow, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("participants").
+		ColumnExpr("participants.event_id").
+		ColumnExpr("members.name AS member_name").
+		ColumnExpr("participants.amount").
+		ColumnExpr("participants.expense").
+		ColumnExpr("participants.compensation").
+		ColumnExpr("participants.paid").
+		ColumnExpr("participants.paid_at").
+		Join("JOIN members ON members.id = participants.member_id").
+		Where("participants.group_id = ?", groupID).
+		OrderExpr("members.name ASC").
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+// SumParticipantPaidAmountsByGroup splits what participants are owed into the
+// part paid out and the rest, in the group's base currency.
 func SumParticipantPaidAmountsByGroup(ctx context.Context, groupID string) (SumParticipantPaidAmountsByGroupRow, error) {
-	rows := make([]db.Participant, 0)
-	err := db.BunDB.NewSelect().
-		Model(&rows).
-		Column("amount", "expense", "paid").
-		Where("group_id = ?", groupID).
-		Scan(ctx)
+	rows := make([]participantPayoutRow, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("participants").
+		ColumnExpr("participants.amount").
+		ColumnExpr("participants.withheld").
+		ColumnExpr("participants.expense").
+		ColumnExpr("participants.compensation").
+		ColumnExpr("participants.paid").
+		ColumnExpr(ParticipantPaidOutExpr+" AS paid_out").
+		ColumnExpr("events.status AS event_status").
+		ColumnExpr("events.exchange_rate").
+		Join("JOIN events ON events.id = participants.event_id").
+		Where("participants.group_id = ?", groupID).
+		Scan(ctx, &rows)
 	if err != nil {
 		return SumParticipantPaidAmountsByGroupRow{}, err
 	}
 
 	totals := SumParticipantPaidAmountsByGroupRow{}
 	for _, row := range rows {
-		value := row.Amount + row.Expense
-		if row.Paid == 1 {
-			totals.PaidAmount += value
-		} else {
-			totals.UnpaidAmount += value
-		}
+		paid, unpaid := row.baseSplit()
+		totals.PaidAmount += paid
+		totals.UnpaidAmount += unpaid
 	}
 	return totals, nil
 }
 
-func ToggleParticipantPaid(ctx context.Context, arg ToggleParticipantPaidParams) (db.Participant, error) {
-	var current db.Participant
-	err := db.BunDB.NewSelect().Model(&current).
+func GetParticipant(ctx context.Context, arg GetParticipantParams) (db.Participant, error) {
+	var row db.Participant
+	err := db.Conn(ctx).NewSelect().Model(&row).
 		Where("event_id = ?", arg.EventID).
 		Where("member_id = ?", arg.MemberID).
 		Where("group_id = ?", arg.GroupID).
 		Scan(ctx)
+	return row, err
+}
+
+// ToggleParticipantPaid marks an unpaid participant paid by recording the
+// outstanding amount as a payout, or a paid one without payouts unpaid.
+func ToggleParticipantPaid(ctx context.Context, arg ToggleParticipantPaidParams) (db.Participant, error) {
+	current, err := GetParticipant(ctx, GetParticipantParams{EventID: arg.EventID, MemberID: arg.MemberID, GroupID: arg.GroupID})
 	if err != nil {
 		return db.Participant{}, err
 	}
 
-	nextPaid := int64(1)
-	nextPaidAt := currentTimestampNullString()
-	if current.Paid == 1 {
-		nextPaid = 0
-		nextPaidAt = sql.NullString{}
-	}
-
-	_, err = db.BunDB.NewUpdate().Model((*db.Participant)(nil)).
-		Set("paid = ?", nextPaid).
-		Set("paid_at = ?", paidAtValue(nextPaidAt)).
-		Where("event_id = ?", arg.EventID).
-		Where("member_id = ?", arg.MemberID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
+	err = db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return settleParticipantPaid(ctx, tx, arg.GroupID, arg.EventID, arg.MemberID, current.Paid == 0, sql.NullString{}, arg.PayoutID)
+	})
 	if err != nil {
 		return db.Participant{}, err
 	}
-
-	var p db.Participant
-	err = db.BunDB.NewSelect().Model(&p).
-		Where("event_id = ?", arg.EventID).
-		Where("member_id = ?", arg.MemberID).
-		Where("group_id = ?", arg.GroupID).
-		Scan(ctx)
-	return p, err
+	return GetParticipant(ctx, GetParticipantParams{EventID: arg.EventID, MemberID: arg.MemberID, GroupID: arg.GroupID})
 }
 
+// UpdateParticipantPaidAt dates the participant's payout: an unpaid
+// participant gets the outstanding amount paid out on that day, a paid one
+// has its latest payout moved.
 func UpdateParticipantPaidAt(ctx context.Context, arg UpdateParticipantPaidAtParams) (db.Participant, error) {
-	var current db.Participant
-	err := db.BunDB.NewSelect().Model(&current).
-		Where("event_id = ?", arg.EventID).
-		Where("member_id = ?", arg.MemberID).
-		Where("group_id = ?", arg.GroupID).
-		Scan(ctx)
+	current, err := GetParticipant(ctx, GetParticipantParams{EventID: arg.EventID, MemberID: arg.MemberID, GroupID: arg.GroupID})
 	if err != nil {
 		return db.Participant{}, err
 	}
 
 	paidAt := paidAtNullable(arg.PaidAt)
-	paid := current.Paid
-	if paidAt.Valid && paid == 0 {
-		paid = 1
-	}
-
-	_, err = db.BunDB.NewUpdate().Model((*db.Participant)(nil)).
-		Set("paid = ?", paid).
-		Set("paid_at = ?", paidAtValue(paidAt)).
-		Where("event_id = ?", arg.EventID).
-		Where("member_id = ?", arg.MemberID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
+	err = db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if !paidAt.Valid {
+			return nil
+		}
+		return applyParticipantPaid(ctx, tx, current, 1, paidAt, arg.PayoutID)
+	})
 	if err != nil {
 		return db.Participant{}, err
 	}
-
-	var p db.Participant
-	err = db.BunDB.NewSelect().Model(&p).
-		Where("event_id = ?", arg.EventID).
-		Where("member_id = ?", arg.MemberID).
-		Where("group_id = ?", arg.GroupID).
-		Scan(ctx)
-	return p, err
+	return GetParticipant(ctx, GetParticipantParams{EventID: arg.EventID, MemberID: arg.MemberID, GroupID: arg.GroupID})
 }
 
 func UpdateParticipantNote(ctx context.Context, arg UpdateParticipantNoteParams) error {
-	_, err := db.BunDB.NewUpdate().Model((*db.Participant)(nil)).
+	_, err := db.Conn(ctx).NewUpdate().Model((*db.Participant)(nil)).
 		Set("note = ?", arg.Note).
 		Where("event_id = ?", arg.EventID).
 		Where("member_id = ?", arg.MemberID).
@@ -327,21 +508,24 @@ func paidAtNullable(v interface{}) sql.NullString {
 	}
 }
 
-func isPaidAtClearRequest(v interface{}) bool {
-	switch t := v.(type) {
-	case string:
-		return strings.TrimSpace(t) == ""
-	case sql.NullString:
-		return t.Valid && strings.TrimSpace(t.String) == ""
-	default:
-		return false
-	}
+// nullableString stores an empty optional reference as NULL.
+func nullableString(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	return sql.NullString{String: s, Valid: s != ""}
 }
 
 func currentTimestampNullString() sql.NullString {
 	return sql.NullString{String: time.Now().UTC().Format("2006-01-02 15:04:05"), Valid: true}
 }
 
+// exchangeRateValue keeps invalid rates out of the CHECK constraint.
+func exchangeRateValue(rate float64) float64 {
+	if !currency.ValidRate(rate) {
+		return 1
+	}
+	return rate
+}
+
 func paidAtValue(v sql.NullString) interface{} {
 	if v.Valid {
 		return v.String
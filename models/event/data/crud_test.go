@@ -0,0 +1,35 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeleteEventKeepsInvoicedEvents(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+
+	createTestEvent(t, "evt_1", "2026-05-01", 1000)
+	createTestEvent(t, "evt_2", "2026-05-02", 1000)
+	mustExec(t, `INSERT INTO invoices (id, group_id, event_id, number, issue_date, performance_date, seller_name, buyer_name, total, pdf)
+		VALUES ('inv_1', ?, 'evt_1', 1, '2026-05-01', '2026-05-01', 'Band', 'Club', 1000, x'00')`, testGroupID)
+
+	err := DeleteEvent(ctx, DeleteEventParams{ID: "evt_1", GroupID: testGroupID})
+	if !errors.Is(err, ErrEventInvoiced) {
+		t.Fatalf("DeleteEvent(invoiced) error = %v; want ErrEventInvoiced", err)
+	}
+	if _, err := GetEvent(ctx, GetEventParams{ID: "evt_1", GroupID: testGroupID}); err != nil {
+		t.Fatalf("invoiced event is gone: %v", err)
+	}
+
+	if err := DeleteEvent(ctx, DeleteEventParams{ID: "evt_2", GroupID: testGroupID}); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+	if _, err := GetEvent(ctx, GetEventParams{ID: "evt_2", GroupID: testGroupID}); err == nil {
+		t.Fatal("event without invoice was not deleted")
+	}
+	if err := DeleteEvent(ctx, DeleteEventParams{ID: "evt_missing", GroupID: testGroupID}); err != nil {
+		t.Fatalf("DeleteEvent(missing) error = %v; want nil", err)
+	}
+}
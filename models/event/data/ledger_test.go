@@ -0,0 +1,296 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"bandcash/internal/db"
+)
+
+const testGroupID = "grp_1"
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "event_test.sqlite")
+	if err := db.Init(dbPath); err != nil {
+		t.Fatalf("db.Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
+	}
+
+	mustExec(t, `INSERT INTO users (id, email) VALUES ('usr_1', 'a@example.com')`)
+	mustExec(t, `INSERT INTO groups (id, name, admin_user_id) VALUES (?, 'Band', 'usr_1')`, testGroupID)
+}
+
+func mustExec(t *testing.T, query string, args ...any) {
+	t.Helper()
+	if _, err := db.BunDB.ExecContext(context.Background(), query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+// createTestEvent adds an unpaid event dated on date.
+func createTestEvent(t *testing.T, id, date string, amount int64) db.Event {
+	t.Helper()
+	event, err := CreateEvent(context.Background(), CreateEventParams{
+		ID:      id,
+		GroupID: testGroupID,
+		Title:   "Gig",
+		Date:    date,
+		Amount:  amount,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	return event
+}
+
+// ledgerState is what a ledger holds and the paid state derived from it.
+type ledgerState struct {
+	Paid        int64
+	PaidAt      string
+	Entries     []string // id:amount@date, oldest first
+	Counted     int64    // the received part, overpayments capped
+	Outstanding int64
+}
+
+// ledger drives one paid state kept by a ledger of entries: an event and
+// its payments, or a participant and their payouts. Both owe 1000.
+type ledger struct {
+	name       string
+	setup      func(t *testing.T)
+	record     func(ctx context.Context, id, paidAt string, amount int64) error
+	remove     func(ctx context.Context, id string) error
+	toggle     func(ctx context.Context, id string) error
+	redate     func(ctx context.Context, paidAt string) error
+	state      func(ctx context.Context) (ledgerState, error)
+	version    func(ctx context.Context) (int64, error)
+	idRequired error
+	hasEntries error
+}
+
+var eventLedger = ledger{
+	name: "event payments",
+	setup: func(t *testing.T) {
+		createTestEvent(t, "evt_1", "2026-05-01", 1000)
+	},
+	record: func(ctx context.Context, id, paidAt string, amount int64) error {
+		_, err := CreateEventPayment(ctx, CreateEventPaymentParams{ID: id, GroupID: testGroupID, EventID: "evt_1", Amount: amount, PaidAt: paidAt})
+		return err
+	},
+	remove: func(ctx context.Context, id string) error {
+		return DeleteEventPayment(ctx, DeleteEventPaymentParams{ID: id, EventID: "evt_1", GroupID: testGroupID})
+	},
+	toggle: func(ctx context.Context, id string) error {
+		_, err := ToggleEventPaid(ctx, ToggleEventPaidParams{ID: "evt_1", GroupID: testGroupID, PaymentID: id})
+		return err
+	},
+	redate: func(ctx context.Context, paidAt string) error {
+		_, err := UpdateEventPaidAt(ctx, UpdateEventPaidAtParams{ID: "evt_1", GroupID: testGroupID, PaidAt: paidAt})
+		return err
+	},
+	state: func(ctx context.Context) (ledgerState, error) {
+		event, err := GetEvent(ctx, GetEventParams{ID: "evt_1", GroupID: testGroupID})
+		if err != nil {
+			return ledgerState{}, err
+		}
+		payments, err := ListEventPayments(ctx, ListEventPaymentsParams{EventID: "evt_1", GroupID: testGroupID})
+		if err != nil {
+			return ledgerState{}, err
+		}
+		state := ledgerState{Paid: event.Paid, PaidAt: event.PaidAt.String}
+		received := int64(0)
+		for _, payment := range payments {
+			state.Entries = append(state.Entries, fmt.Sprintf("%s:%d@%s", payment.ID, payment.Amount, payment.PaidAt))
+			received += payment.Amount
+		}
+		state.Counted = BaseReceivedAmount(event, received)
+		state.Outstanding = OutstandingAmount(event, received)
+		return state, nil
+	},
+	version: func(ctx context.Context) (int64, error) {
+		event, err := GetEvent(ctx, GetEventParams{ID: "evt_1", GroupID: testGroupID})
+		return event.Version, err
+	},
+	idRequired: ErrPaymentIDRequired,
+	hasEntries: ErrEventHasPayments,
+}
+
+var payoutLedger = ledger{
+	name: "participant payouts",
+	setup: func(t *testing.T) {
+		createTestEvent(t, "evt_1", "2026-05-01", 1200)
+		mustExec(t, `INSERT INTO members (id, group_id, name, description) VALUES ('mem_1', ?, 'Anna', '')`, testGroupID)
+		mustExec(t, `INSERT INTO participants (group_id, event_id, member_id, amount, expense) VALUES (?, 'evt_1', 'mem_1', 950, 50)`, testGroupID)
+	},
+	record: func(ctx context.Context, id, paidAt string, amount int64) error {
+		_, err := CreateParticipantPayout(ctx, CreateParticipantPayoutParams{ID: id, GroupID: testGroupID, EventID: "evt_1", MemberID: "mem_1", Amount: amount, PaidAt: paidAt})
+		return err
+	},
+	remove: func(ctx context.Context, id string) error {
+		return DeleteParticipantPayout(ctx, DeleteParticipantPayoutParams{ID: id, EventID: "evt_1", MemberID: "mem_1", GroupID: testGroupID})
+	},
+	toggle: func(ctx context.Context, id string) error {
+		_, err := ToggleParticipantPaid(ctx, ToggleParticipantPaidParams{EventID: "evt_1", MemberID: "mem_1", GroupID: testGroupID, PayoutID: id})
+		return err
+	},
+	redate: func(ctx context.Context, paidAt string) error {
+		_, err := UpdateParticipantPaidAt(ctx, UpdateParticipantPaidAtParams{EventID: "evt_1", MemberID: "mem_1", GroupID: testGroupID, PaidAt: paidAt})
+		return err
+	},
+	state: func(ctx context.Context) (ledgerState, error) {
+		event, err := GetEvent(ctx, GetEventParams{ID: "evt_1", GroupID: testGroupID})
+		if err != nil {
+			return ledgerState{}, err
+		}
+		participant, err := GetParticipant(ctx, GetParticipantParams{EventID: "evt_1", MemberID: "mem_1", GroupID: testGroupID})
+		if err != nil {
+			return ledgerState{}, err
+		}
+		payouts, err := ListParticipantPayouts(ctx, ListParticipantPayoutsParams{EventID: "evt_1", GroupID: testGroupID})
+		if err != nil {
+			return ledgerState{}, err
+		}
+		state := ledgerState{Paid: participant.Paid, PaidAt: participant.PaidAt.String}
+		paidOut := int64(0)
+		for _, payout := range payouts {
+			state.Entries = append(state.Entries, fmt.Sprintf("%s:%d@%s", payout.ID, payout.Amount, payout.PaidAt))
+			paidOut += payout.Amount
+		}
+		owed := PayoutAmount(event.Status, participant.Amount, participant.Withheld, participant.Expense, participant.Compensation)
+		state.Counted = PaidOutAmount(owed, paidOut, participant.Paid)
+		state.Outstanding = OutstandingPayout(owed, paidOut)
+		return state, nil
+	},
+	version: func(ctx context.Context) (int64, error) {
+		event, err := GetEvent(ctx, GetEventParams{ID: "evt_1", GroupID: testGroupID})
+		return event.ParticipantsVersion, err
+	},
+	idRequired: ErrPayoutIDRequired,
+	hasEntries: ErrParticipantHasPayouts,
+}
+
+func TestLedgerPaidState(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+	type step struct {
+		name string
+		do   func(ctx context.Context, l ledger) error
+		want ledgerState
+	}
+	steps := []step{
+		{
+			name: "partial entry",
+			do:   func(ctx context.Context, l ledger) error { return l.record(ctx, "e_1", "2026-05-02", 400) },
+			want: ledgerState{Entries: []string{"e_1:400@2026-05-02"}, Counted: 400, Outstanding: 600},
+		},
+		{
+			name: "tick without an entry id",
+			do: func(ctx context.Context, l ledger) error {
+				if err := l.toggle(ctx, ""); !errors.Is(err, l.idRequired) {
+					return fmt.Errorf("toggle error = %v, want %v", err, l.idRequired)
+				}
+				return nil
+			},
+			want: ledgerState{Entries: []string{"e_1:400@2026-05-02"}, Counted: 400, Outstanding: 600},
+		},
+		{
+			name: "tick records the outstanding amount",
+			do:   func(ctx context.Context, l ledger) error { return l.toggle(ctx, "e_2") },
+			want: ledgerState{Paid: 1, PaidAt: today, Entries: []string{"e_1:400@2026-05-02", "e_2:600@" + today}, Counted: 1000},
+		},
+		{
+			name: "redate moves the latest entry",
+			do:   func(ctx context.Context, l ledger) error { return l.redate(ctx, "2026-06-01") },
+			want: ledgerState{Paid: 1, PaidAt: "2026-06-01", Entries: []string{"e_1:400@2026-05-02", "e_2:600@2026-06-01"}, Counted: 1000},
+		},
+		{
+			name: "removing an entry reopens it",
+			do:   func(ctx context.Context, l ledger) error { return l.remove(ctx, "e_2") },
+			want: ledgerState{Entries: []string{"e_1:400@2026-05-02"}, Counted: 400, Outstanding: 600},
+		},
+		{
+			name: "overpayment",
+			do:   func(ctx context.Context, l ledger) error { return l.record(ctx, "e_3", "2026-06-10", 900) },
+			want: ledgerState{Paid: 1, PaidAt: "2026-06-10", Entries: []string{"e_1:400@2026-05-02", "e_3:900@2026-06-10"}, Counted: 1000},
+		},
+		{
+			name: "untick keeps the entries",
+			do: func(ctx context.Context, l ledger) error {
+				if err := l.toggle(ctx, ""); !errors.Is(err, l.hasEntries) {
+					return fmt.Errorf("toggle error = %v, want %v", err, l.hasEntries)
+				}
+				return nil
+			},
+			want: ledgerState{Paid: 1, PaidAt: "2026-06-10", Entries: []string{"e_1:400@2026-05-02", "e_3:900@2026-06-10"}, Counted: 1000},
+		},
+	}
+
+	for _, l := range []ledger{eventLedger, payoutLedger} {
+		t.Run(l.name, func(t *testing.T) {
+			setupTestDB(t)
+			ctx := context.Background()
+			l.setup(t)
+
+			for _, s := range steps {
+				if err := s.do(ctx, l); err != nil {
+					t.Fatalf("%s: %v", s.name, err)
+				}
+				got, err := l.state(ctx)
+				if err != nil {
+					t.Fatalf("%s: reading state: %v", s.name, err)
+				}
+				if !reflect.DeepEqual(got, s.want) {
+					t.Fatalf("%s: state = %+v\nwant %+v", s.name, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+// Entries that leave the paid state as it was must not move the version an
+// open edit form was loaded at.
+func TestLedgerKeepsVersionWithoutPaidChange(t *testing.T) {
+	for _, l := range []ledger{eventLedger, payoutLedger} {
+		t.Run(l.name, func(t *testing.T) {
+			setupTestDB(t)
+			ctx := context.Background()
+			l.setup(t)
+
+			before, err := l.version(ctx)
+			if err != nil {
+				t.Fatalf("reading version: %v", err)
+			}
+			if err := l.record(ctx, "e_1", "2026-05-02", 400); err != nil {
+				t.Fatalf("record: %v", err)
+			}
+			if err := l.remove(ctx, "e_1"); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+			after, err := l.version(ctx)
+			if err != nil {
+				t.Fatalf("reading version: %v", err)
+			}
+			if after != before {
+				t.Errorf("version = %d, want %d", after, before)
+			}
+
+			if err := l.record(ctx, "e_2", "2026-05-03", 1000); err != nil {
+				t.Fatalf("record: %v", err)
+			}
+			if after, err = l.version(ctx); err != nil || after == before {
+				t.Errorf("version = %d (%v), want a bump once paid", after, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+// ProfitLoss is an event's profit and loss. Income and the participant
+// amounts are in the event's currency; Expenses, the expenses linked to the
+// event, are already in the group's base currency.
+type ProfitLoss struct {
+	Income              int64
+	Cuts                int64
+	ParticipantExpenses int64
+	ExchangeRate        float64
+	Expenses            int64
+}
+
+// Margin is what the band keeps from the event, in the base currency: income
+// less what participants are owed, converted once, less linked expenses.
+// eventMarginExpr computes the same value in SQL.
+func (pl ProfitLoss) Margin() int64 {
+	return currency.ToBase(pl.Income-pl.Cuts-pl.ParticipantExpenses, pl.ExchangeRate) - pl.Expenses
+}
+
+// BaseIncome is Income in the base currency.
+func (pl ProfitLoss) BaseIncome() int64 {
+	return currency.ToBase(pl.Income, pl.ExchangeRate)
+}
+
+// BaseCuts is Cuts in the base currency.
+func (pl ProfitLoss) BaseCuts() int64 {
+	return currency.ToBase(pl.Cuts, pl.ExchangeRate)
+}
+
+// BaseParticipantExpenses is ParticipantExpenses in the base currency.
+func (pl ProfitLoss) BaseParticipantExpenses() int64 {
+	return currency.ToBase(pl.ParticipantExpenses, pl.ExchangeRate)
+}
+
+// eventMarginExpr is ProfitLoss.Margin for the events row named table.
+func eventMarginExpr(table string) string {
+	return "CAST(ROUND((" +
+		"CASE WHEN " + table + ".status = '" + EventStatusCancelled + "' THEN " + table + ".cancellation_fee ELSE " + table + ".amount END" +
+		" - COALESCE((SELECT SUM(CASE WHEN " + table + ".status = '" + EventStatusCancelled + "' THEN mp.compensation ELSE mp.amount + mp.expense END)" +
+		" FROM participants mp WHERE mp.event_id = " + table + ".id), 0)" +
+		") * " + table + ".exchange_rate)" +
+		" - COALESCE((SELECT SUM(ROUND(mx.amount * mx.exchange_rate)) FROM expenses mx WHERE mx.event_id = " + table + ".id), 0) AS INTEGER)"
+}
+
+// ListEventMargins returns the margin of each of the given events of a group,
+// by event id.
+func ListEventMargins(ctx context.Context, groupID string, eventIDs []string) (map[string]int64, error) {
+	margins := make(map[string]int64, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return margins, nil
+	}
+	rows := make([]struct {
+		ID     string `bun:"id"`
+		Margin int64  `bun:"margin"`
+	}, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("events").
+		ColumnExpr("events.id").
+		ColumnExpr(eventMarginExpr("events")+" AS margin").
+		Where("events.group_id = ?", groupID).
+		Where("events.id IN (?)", bun.In(eventIDs)).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		margins[row.ID] = row.Margin
+	}
+	return margins, nil
+}
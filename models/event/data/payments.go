@@ -0,0 +1,289 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+// Payment methods an event payment can be recorded with. An empty method
+// means it wasn't noted.
+const (
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodCash         = "cash"
+	PaymentMethodCard         = "card"
+	PaymentMethodOther        = "other"
+)
+
+// PaymentMethods lists the methods in display order.
+var PaymentMethods = []string{PaymentMethodBankTransfer, PaymentMethodCash, PaymentMethodCard, PaymentMethodOther}
+
+// ErrPaymentIDRequired is returned when an event is marked paid without an
+// id for the payment that records the outstanding amount.
+var ErrPaymentIDRequired = errors.New("event payment id required")
+
+// ErrEventHasPayments is returned when an event with recorded payments is
+// marked unpaid. The payments are removed one by one from the ledger instead.
+var ErrEventHasPayments = errors.New("event has recorded payments")
+
+// OutstandingAmount is the part of the event's income not received yet.
+func OutstandingAmount(event db.Event, received int64) int64 {
+	return max(IncomeAmount(event)-received, 0)
+}
+
+// BaseReceivedAmount is the received part of the event's income in the
+// group's base currency. Overpayments count up to the income.
+func BaseReceivedAmount(event db.Event, received int64) int64 {
+	return min(currency.ToBase(received, event.ExchangeRate), BaseIncomeAmount(event))
+}
+
+func ListEventPayments(ctx context.Context, arg ListEventPaymentsParams) ([]db.EventPayment, error) {
+	rows := make([]db.EventPayment, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("event_id = ?", arg.EventID).
+		Where("group_id = ?", arg.GroupID).
+		OrderExpr("paid_at ASC").
+		OrderExpr("created_at ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+func GetEventPayment(ctx context.Context, arg GetEventPaymentParams) (db.EventPayment, error) {
+	var row db.EventPayment
+	err := db.Conn(ctx).NewSelect().Model(&row).
+		Where("id = ?", arg.ID).
+		Where("event_id = ?", arg.EventID).
+		Where("group_id = ?", arg.GroupID).
+		Scan(ctx)
+	return row, err
+}
+
+// SumEventPayments totals the payments of one event.
+func SumEventPayments(ctx context.Context, arg ListEventPaymentsParams) (EventPaymentSummary, error) {
+	return sumEventPayments(ctx, db.Conn(ctx), arg.GroupID, arg.EventID)
+}
+
+// SumEventPaymentsByGroup totals payments per event. Events without
+// payments are missing from the map.
+func SumEventPaymentsByGroup(ctx context.Context, groupID string) (map[string]EventPaymentSummary, error) {
+	rows := make([]EventPaymentSummary, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("event_payments").
+		ColumnExpr("event_id").
+		ColumnExpr("SUM(amount) AS received").
+		ColumnExpr("MAX(paid_at) AS last_paid_at").
+		Where("group_id = ?", groupID).
+		GroupExpr("event_id").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byEvent := make(map[string]EventPaymentSummary, len(rows))
+	for _, row := range rows {
+		byEvent[row.EventID] = row
+	}
+	return byEvent, nil
+}
+
+// CreateEventPayment records a payment and updates the event's paid state.
+func CreateEventPayment(ctx context.Context, arg CreateEventPaymentParams) (db.EventPayment, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if err := insertEventPayment(ctx, tx, arg); err != nil {
+			return err
+		}
+		return syncEventPaid(ctx, tx, arg.GroupID, arg.EventID)
+	})
+	if err != nil {
+		return db.EventPayment{}, err
+	}
+	return GetEventPayment(ctx, GetEventPaymentParams{ID: arg.ID, EventID: arg.EventID, GroupID: arg.GroupID})
+}
+
+// DeleteEventPayment removes a payment and updates the event's paid state.
+func DeleteEventPayment(ctx context.Context, arg DeleteEventPaymentParams) error {
+	return db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().Model((*db.EventPayment)(nil)).
+			Where("id = ?", arg.ID).
+			Where("event_id = ?", arg.EventID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return sql.ErrNoRows
+		}
+		return syncEventPaid(ctx, tx, arg.GroupID, arg.EventID)
+	})
+}
+
+func insertEventPayment(ctx context.Context, idb bun.IDB, arg CreateEventPaymentParams) error {
+	row := db.EventPayment{
+		ID:          arg.ID,
+		GroupID:     arg.GroupID,
+		EventID:     arg.EventID,
+		Amount:      arg.Amount,
+		PaidAt:      arg.PaidAt,
+		Method:      arg.Method,
+		Reference:   arg.Reference,
+		CollectedBy: nullableString(arg.CollectedBy),
+	}
+	_, err := idb.NewInsert().Model(&row).Exec(ctx)
+	return err
+}
+
+func sumEventPayments(ctx context.Context, idb bun.IDB, groupID, eventID string) (EventPaymentSummary, error) {
+	var summary EventPaymentSummary
+	err := idb.NewSelect().
+		TableExpr("event_payments").
+		ColumnExpr("? AS event_id", eventID).
+		ColumnExpr("COALESCE(SUM(amount), 0) AS received").
+		ColumnExpr("COALESCE(MAX(paid_at), '') AS last_paid_at").
+		Where("event_id = ?", eventID).
+		Where("group_id = ?", groupID).
+		Scan(ctx, &summary)
+	return summary, err
+}
+
+// syncEventPaid derives events.paid and events.paid_at from the payments:
+// paid once they cover the income, dated by the latest one. Events with
+// nothing to receive and no payments keep their flag as set by hand.
+func syncEventPaid(ctx context.Context, idb bun.IDB, groupID, eventID string) error {
+	var event db.Event
+	err := idb.NewSelect().Model(&event).Where("id = ?", eventID).Where("group_id = ?", groupID).Scan(ctx)
+	if err != nil {
+		return err
+	}
+	summary, err := sumEventPayments(ctx, idb, groupID, eventID)
+	if err != nil {
+		return err
+	}
+
+	income := IncomeAmount(event)
+	if income <= 0 && summary.Received == 0 {
+		return nil
+	}
+
+	paid := int64(0)
+	paidAt := sql.NullString{}
+	if summary.Received >= income {
+		paid = 1
+		paidAt = sql.NullString{String: summary.LastPaidAt, Valid: true}
+	}
+	return setEventPaid(ctx, idb, groupID, eventID, paid, paidAt)
+}
+
+// settleEventPaid is the shortcut behind the paid toggle and checkbox.
+// Marking paid records the outstanding amount as one payment dated paidAt
+// (today when empty); marking unpaid only clears a flag set without
+// payments, recorded payments are never removed here.
+func settleEventPaid(ctx context.Context, idb bun.IDB, groupID, eventID string, paid bool, paidAt sql.NullString, paymentID string) error {
+	paidAt = paidAtNullable(paidAt)
+	var event db.Event
+	err := idb.NewSelect().Model(&event).Where("id = ?", eventID).Where("group_id = ?", groupID).Scan(ctx)
+	if err != nil {
+		return err
+	}
+	summary, err := sumEventPayments(ctx, idb, groupID, eventID)
+	if err != nil {
+		return err
+	}
+
+	if !paid {
+		if summary.Received > 0 {
+			return ErrEventHasPayments
+		}
+		return setEventPaid(ctx, idb, groupID, eventID, 0, sql.NullString{})
+	}
+
+	outstanding := OutstandingAmount(event, summary.Received)
+	if outstanding == 0 {
+		if summary.Received == 0 {
+			if !paidAt.Valid {
+				paidAt = currentTimestampNullString()
+			}
+			return setEventPaid(ctx, idb, groupID, eventID, 1, paidAt)
+		}
+		return syncEventPaid(ctx, idb, groupID, eventID)
+	}
+	if paymentID == "" {
+		return ErrPaymentIDRequired
+	}
+	err = insertEventPayment(ctx, idb, CreateEventPaymentParams{
+		ID:      paymentID,
+		GroupID: groupID,
+		EventID: eventID,
+		Amount:  outstanding,
+		PaidAt:  paymentDate(paidAt),
+	})
+	if err != nil {
+		return err
+	}
+	return syncEventPaid(ctx, idb, groupID, eventID)
+}
+
+// redateLastEventPayment moves the latest payment to paidAt, which is how a
+// paid event's date is edited.
+func redateLastEventPayment(ctx context.Context, idb bun.IDB, groupID, eventID string, paidAt sql.NullString) error {
+	if paidAt = paidAtNullable(paidAt); !paidAt.Valid {
+		return nil
+	}
+	last := idb.NewSelect().
+		TableExpr("event_payments").
+		Column("id").
+		Where("event_id = ?", eventID).
+		Where("group_id = ?", groupID).
+		OrderExpr("paid_at DESC").
+		OrderExpr("created_at DESC").
+		Limit(1)
+	_, err := idb.NewUpdate().Model((*db.EventPayment)(nil)).
+		Set("paid_at = ?", paymentDate(paidAt)).
+		Where("id = (?)", last).
+		Exec(ctx)
+	return err
+}
+
+// applyEventPaid applies the paid flag of an event form. Only a change of
+// the flag settles the event; otherwise the paid state is derived again,
+// since the amount may have changed.
+func applyEventPaid(ctx context.Context, idb bun.IDB, before db.Event, paid int64, paidAt sql.NullString, paymentID string) error {
+	switch {
+	case paid == 1 && before.Paid == 0:
+		return settleEventPaid(ctx, idb, before.GroupID, before.ID, true, paidAt, paymentID)
+	case paid == 0 && before.Paid == 1:
+		return settleEventPaid(ctx, idb, before.GroupID, before.ID, false, sql.NullString{}, "")
+	case paid == 1:
+		if err := redateLastEventPayment(ctx, idb, before.GroupID, before.ID, paidAt); err != nil {
+			return err
+		}
+	}
+	return syncEventPaid(ctx, idb, before.GroupID, before.ID)
+}
+
+// setEventPaid stores the paid state, skipping the write when it is already
+// stored so the event's version only moves on a real change.
+func setEventPaid(ctx context.Context, idb bun.IDB, groupID, eventID string, paid int64, paidAt sql.NullString) error {
+	_, err := idb.NewUpdate().Model((*db.Event)(nil)).
+		Set("paid = ?", paid).
+		Set("paid_at = ?", paidAtValue(paidAt)).
+		Where("id = ?", eventID).
+		Where("group_id = ?", groupID).
+		Where("(paid != ? OR paid_at IS NOT ?)", paid, paidAtValue(paidAt)).
+		Exec(ctx)
+	return err
+}
+
+// paymentDate turns a paid-at value into the date stored on a payment.
+func paymentDate(paidAt sql.NullString) string {
+	if paidAt.Valid && len(paidAt.String) >= len("2006-01-02") {
+		return paidAt.String[:len("2006-01-02")]
+	}
+	return time.Now().UTC().Format("2006-01-02")
+}
@@ -0,0 +1,288 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+// ParticipantOwedExpr is what a participant is owed, as PayoutAmount
+// computes it, for queries joining participants with events.
+const ParticipantOwedExpr = "CASE WHEN events.status = '" + EventStatusCancelled + "' THEN participants.compensation ELSE participants.amount - participants.withheld + participants.expense END"
+
+// ParticipantPaidOutExpr sums the payouts of the participants row a query is
+// on, in the event's currency.
+const ParticipantPaidOutExpr = "COALESCE((SELECT SUM(participant_payouts.amount) FROM participant_payouts WHERE participant_payouts.event_id = participants.event_id AND participant_payouts.member_id = participants.member_id), 0)"
+
+// ErrPayoutIDRequired is returned when a participant is marked paid without
+// an id for the payout that records the outstanding amount.
+var ErrPayoutIDRequired = errors.New("participant payout id required")
+
+// ErrParticipantHasPayouts is returned when a participant with recorded
+// payouts, settlements included, is marked unpaid. The payouts are removed
+// one by one from the ledger instead.
+var ErrParticipantHasPayouts = errors.New("participant has recorded payouts")
+
+// PaidOutAmount is the part of what a participant is owed that was already
+// paid out. Paid participants count in full, overpayments up to the amount.
+func PaidOutAmount(owed, paidOut, paid int64) int64 {
+	if paid == 1 {
+		return owed
+	}
+	return min(max(paidOut, 0), owed)
+}
+
+// OutstandingPayout is the part of what a participant is owed not paid out yet.
+func OutstandingPayout(owed, paidOut int64) int64 {
+	return max(owed-paidOut, 0)
+}
+
+// baseSplit splits what the participant is owed into the part paid out and
+// the rest, in the group's base currency.
+func (row participantPayoutRow) baseSplit() (int64, int64) {
+	owed := PayoutAmount(row.EventStatus, row.Amount, row.Withheld, row.Expense, row.Compensation)
+	paid := currency.ToBase(PaidOutAmount(owed, row.PaidOut, row.Paid), row.ExchangeRate)
+	return paid, currency.ToBase(owed, row.ExchangeRate) - paid
+}
+
+// ListParticipantPayouts returns the payouts of all participants of an event.
+func ListParticipantPayouts(ctx context.Context, arg ListParticipantPayoutsParams) ([]db.ParticipantPayout, error) {
+	rows := make([]db.ParticipantPayout, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("event_id = ?", arg.EventID).
+		Where("group_id = ?", arg.GroupID).
+		OrderExpr("paid_at ASC").
+		OrderExpr("created_at ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+func GetParticipantPayout(ctx context.Context, arg GetParticipantPayoutParams) (db.ParticipantPayout, error) {
+	var row db.ParticipantPayout
+	err := db.Conn(ctx).NewSelect().Model(&row).
+		Where("id = ?", arg.ID).
+		Where("event_id = ?", arg.EventID).
+		Where("group_id = ?", arg.GroupID).
+		Scan(ctx)
+	return row, err
+}
+
+// CreateParticipantPayout records a payout and updates the participant's
+// paid state.
+func CreateParticipantPayout(ctx context.Context, arg CreateParticipantPayoutParams) (db.ParticipantPayout, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if err := insertParticipantPayout(ctx, tx, arg); err != nil {
+			return err
+		}
+		return syncParticipantPaid(ctx, tx, arg.GroupID, arg.EventID, arg.MemberID)
+	})
+	if err != nil {
+		return db.ParticipantPayout{}, err
+	}
+	return GetParticipantPayout(ctx, GetParticipantPayoutParams{ID: arg.ID, EventID: arg.EventID, GroupID: arg.GroupID})
+}
+
+// DeleteParticipantPayout removes a payout and updates the participant's
+// paid state.
+func DeleteParticipantPayout(ctx context.Context, arg DeleteParticipantPayoutParams) error {
+	return db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().Model((*db.ParticipantPayout)(nil)).
+			Where("id = ?", arg.ID).
+			Where("event_id = ?", arg.EventID).
+			Where("member_id = ?", arg.MemberID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return sql.ErrNoRows
+		}
+		return syncParticipantPaid(ctx, tx, arg.GroupID, arg.EventID, arg.MemberID)
+	})
+}
+
+func insertParticipantPayout(ctx context.Context, idb bun.IDB, arg CreateParticipantPayoutParams) error {
+	row := db.ParticipantPayout{
+		ID:        arg.ID,
+		GroupID:   arg.GroupID,
+		EventID:   arg.EventID,
+		MemberID:  arg.MemberID,
+		Amount:    arg.Amount,
+		PaidAt:    arg.PaidAt,
+		Method:    arg.Method,
+		Reference: arg.Reference,
+		PaidBy:    nullableString(arg.PaidBy),
+	}
+	_, err := idb.NewInsert().Model(&row).Exec(ctx)
+	return err
+}
+
+// participantPayoutState is what a participant is owed and has been paid.
+type participantPayoutState struct {
+	Paid       int64  `bun:"paid"`
+	Owed       int64  `bun:"owed"`
+	PaidOut    int64  `bun:"paid_out"`
+	LastPaidAt string `bun:"last_paid_at"`
+}
+
+func getParticipantPayoutState(ctx context.Context, idb bun.IDB, groupID, eventID, memberID string) (participantPayoutState, error) {
+	var state participantPayoutState
+	err := idb.NewSelect().
+		TableExpr("participants").
+		ColumnExpr("participants.paid").
+		ColumnExpr(ParticipantOwedExpr+" AS owed").
+		ColumnExpr(ParticipantPaidOutExpr+" AS paid_out").
+		ColumnExpr("COALESCE((SELECT MAX(participant_payouts.paid_at) FROM participant_payouts WHERE participant_payouts.event_id = participants.event_id AND participant_payouts.member_id = participants.member_id), '') AS last_paid_at").
+		Join("JOIN events ON events.id = participants.event_id").
+		Where("participants.event_id = ?", eventID).
+		Where("participants.member_id = ?", memberID).
+		Where("participants.group_id = ?", groupID).
+		Scan(ctx, &state)
+	return state, err
+}
+
+// syncParticipantPaid derives participants.paid and participants.paid_at from
+// the payouts: paid once they cover what the participant is owed, dated by
+// the latest one. Participants owed nothing and without payouts keep their
+// flag as set by hand.
+func syncParticipantPaid(ctx context.Context, idb bun.IDB, groupID, eventID, memberID string) error {
+	state, err := getParticipantPayoutState(ctx, idb, groupID, eventID, memberID)
+	if err != nil {
+		return err
+	}
+	if state.Owed <= 0 && state.PaidOut == 0 {
+		return nil
+	}
+
+	paid := int64(0)
+	paidAt := sql.NullString{}
+	if state.PaidOut >= state.Owed {
+		paid = 1
+		paidAt = sql.NullString{String: state.LastPaidAt, Valid: true}
+	}
+	return setParticipantPaid(ctx, idb, groupID, eventID, memberID, paid, paidAt)
+}
+
+// syncEventParticipantsPaid re-derives the paid state of every participant
+// of an event, for changes that move what they are owed.
+func syncEventParticipantsPaid(ctx context.Context, idb bun.IDB, groupID, eventID string) error {
+	participants := make([]db.Participant, 0)
+	err := idb.NewSelect().Model(&participants).
+		Column("member_id").
+		Where("event_id = ?", eventID).
+		Where("group_id = ?", groupID).
+		Scan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, participant := range participants {
+		if err := syncParticipantPaid(ctx, idb, groupID, eventID, participant.MemberID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settleParticipantPaid is the shortcut behind the participant paid toggle
+// and checkbox. Marking paid records the outstanding amount as one payout
+// dated paidAt (today when empty); marking unpaid only clears a flag set
+// without payouts, recorded payouts are never removed here.
+func settleParticipantPaid(ctx context.Context, idb bun.IDB, groupID, eventID, memberID string, paid bool, paidAt sql.NullString, payoutID string) error {
+	paidAt = paidAtNullable(paidAt)
+	state, err := getParticipantPayoutState(ctx, idb, groupID, eventID, memberID)
+	if err != nil {
+		return err
+	}
+	if !paid {
+		if state.PaidOut > 0 {
+			return ErrParticipantHasPayouts
+		}
+		return setParticipantPaid(ctx, idb, groupID, eventID, memberID, 0, sql.NullString{})
+	}
+
+	outstanding := OutstandingPayout(state.Owed, state.PaidOut)
+	if outstanding == 0 {
+		if state.PaidOut == 0 {
+			if !paidAt.Valid {
+				paidAt = currentTimestampNullString()
+			}
+			return setParticipantPaid(ctx, idb, groupID, eventID, memberID, 1, paidAt)
+		}
+		return syncParticipantPaid(ctx, idb, groupID, eventID, memberID)
+	}
+	if payoutID == "" {
+		return ErrPayoutIDRequired
+	}
+	err = insertParticipantPayout(ctx, idb, CreateParticipantPayoutParams{
+		ID:       payoutID,
+		GroupID:  groupID,
+		EventID:  eventID,
+		MemberID: memberID,
+		Amount:   outstanding,
+		PaidAt:   paymentDate(paidAt),
+	})
+	if err != nil {
+		return err
+	}
+	return syncParticipantPaid(ctx, idb, groupID, eventID, memberID)
+}
+
+// redateLastParticipantPayout moves the latest payout to paidAt, which is how
+// a paid participant's date is edited.
+func redateLastParticipantPayout(ctx context.Context, idb bun.IDB, groupID, eventID, memberID string, paidAt sql.NullString) error {
+	if paidAt = paidAtNullable(paidAt); !paidAt.Valid {
+		return nil
+	}
+	last := idb.NewSelect().
+		TableExpr("participant_payouts").
+		Column("id").
+		Where("event_id = ?", eventID).
+		Where("member_id = ?", memberID).
+		Where("group_id = ?", groupID).
+		OrderExpr("paid_at DESC").
+		OrderExpr("created_at DESC").
+		Limit(1)
+	_, err := idb.NewUpdate().Model((*db.ParticipantPayout)(nil)).
+		Set("paid_at = ?", paymentDate(paidAt)).
+		Where("id = (?)", last).
+		Exec(ctx)
+	return err
+}
+
+// applyParticipantPaid applies the paid flag of a participant form. Only a
+// change of the flag settles the participant; otherwise the paid state is
+// derived again, since the amounts may have changed.
+func applyParticipantPaid(ctx context.Context, idb bun.IDB, before db.Participant, paid int64, paidAt sql.NullString, payoutID string) error {
+	switch {
+	case paid == 1 && before.Paid == 0:
+		return settleParticipantPaid(ctx, idb, before.GroupID, before.EventID, before.MemberID, true, paidAt, payoutID)
+	case paid == 0 && before.Paid == 1:
+		return settleParticipantPaid(ctx, idb, before.GroupID, before.EventID, before.MemberID, false, sql.NullString{}, "")
+	case paid == 1:
+		if err := redateLastParticipantPayout(ctx, idb, before.GroupID, before.EventID, before.MemberID, paidAt); err != nil {
+			return err
+		}
+	}
+	return syncParticipantPaid(ctx, idb, before.GroupID, before.EventID, before.MemberID)
+}
+
+// setParticipantPaid stores the paid state, skipping the write when it is
+// already stored so the event's participants version only moves on a real
+// change.
+func setParticipantPaid(ctx context.Context, idb bun.IDB, groupID, eventID, memberID string, paid int64, paidAt sql.NullString) error {
+	_, err := idb.NewUpdate().Model((*db.Participant)(nil)).
+		Set("paid = ?", paid).
+		Set("paid_at = ?", paidAtValue(paidAt)).
+		Where("event_id = ?", eventID).
+		Where("member_id = ?", memberID).
+		Where("group_id = ?", groupID).
+		Where("(paid != ? OR paid_at IS NOT ?)", paid, paidAtValue(paidAt)).
+		Exec(ctx)
+	return err
+}
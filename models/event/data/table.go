@@ -14,6 +14,7 @@ type EventTableFilter struct {
 	Year    string
 	From    string
 	To      string
+	Status  string
 }
 
 type EventTableListParams struct {
@@ -35,7 +36,7 @@ type ParticipantGroupTotals struct {
 }
 
 func CountEventsTable(ctx context.Context, filter EventTableFilter) (int64, error) {
-	q := db.BunDB.NewSelect().TableExpr("events")
+	q := db.Conn(ctx).NewSelect().TableExpr("events")
 	q = applyEventTableFilters(q, filter)
 	n, err := q.Count(ctx)
 	return int64(n), err
@@ -43,7 +44,7 @@ func CountEventsTable(ctx context.Context, filter EventTableFilter) (int64, erro
 
 func ListEventsTable(ctx context.Context, params EventTableListParams) ([]db.Event, error) {
 	rows := make([]db.Event, 0)
-	q := db.BunDB.NewSelect().Model(&rows)
+	q := db.Conn(ctx).NewSelect().Model(&rows)
 	q = applyEventTableFilters(q, params.EventTableFilter)
 	q = orderEvents(q, params.Sort, params.Dir)
 	if params.Limit > 0 {
@@ -57,34 +58,46 @@ func ListEventsTable(ctx context.Context, params EventTableListParams) ([]db.Eve
 }
 
 func SumEventIncomeTotalsTable(ctx context.Context, filter EventTableFilter) (EventIncomeTotals, error) {
-	rows := make([]db.Event, 0)
-	q := db.BunDB.NewSelect().
-		Model(&rows).
-		Column("amount", "paid")
+	rows := make([]struct {
+		db.Event
+		Received int64 `bun:"received"`
+	}, 0)
+	q := db.Conn(ctx).NewSelect().
+		TableExpr("events").
+		Column("amount", "paid", "status", "cancellation_fee", "exchange_rate").
+		ColumnExpr("COALESCE((SELECT SUM(event_payments.amount) FROM event_payments WHERE event_payments.event_id = events.id), 0) AS received")
 	q = applyEventTableFilters(q, filter)
-	if err := q.Scan(ctx); err != nil {
+	if err := q.Scan(ctx, &rows); err != nil {
 		return EventIncomeTotals{}, err
 	}
 
 	totals := EventIncomeTotals{}
 	for _, row := range rows {
-		totals.Total += row.Amount
+		income := BaseIncomeAmount(row.Event)
+		totals.Total += income
 		if row.Paid == 1 {
-			totals.Paid += row.Amount
+			totals.Paid += income
+		} else {
+			totals.Paid += BaseReceivedAmount(row.Event, row.Received)
 		}
 	}
 	return totals, nil
 }
 
 func SumParticipantTotalsByGroupTable(ctx context.Context, filter EventTableFilter) (ParticipantGroupTotals, error) {
-	rows := make([]db.Participant, 0)
-	q := db.BunDB.NewSelect().
+	rows := make([]participantPayoutRow, 0)
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("participants").
 		ColumnExpr("participants.amount").
 		ColumnExpr("participants.expense").
+		ColumnExpr("participants.compensation").
 		ColumnExpr("participants.paid").
+		ColumnExpr(ParticipantPaidOutExpr+" AS paid_out").
+		ColumnExpr("events.status AS event_status").
+		ColumnExpr("events.exchange_rate").
 		Join("JOIN events ON events.id = participants.event_id").
 		Where("participants.group_id = ?", filter.GroupID)
+	q = applyStatus(q, filter.Status, "events.status")
 
 	q = applySearch(q, filter.Search, func(sq *bun.SelectQuery, search string) *bun.SelectQuery {
 		return sq.Where("(events.title LIKE '%' || ? || '%' OR events.description LIKE '%' || ? || '%')", search, search)
@@ -97,18 +110,16 @@ func SumParticipantTotalsByGroupTable(ctx context.Context, filter EventTableFilt
 
 	totals := ParticipantGroupTotals{}
 	for _, row := range rows {
-		value := row.Amount + row.Expense
-		if row.Paid == 1 {
-			totals.TotalPaid += value
-		} else {
-			totals.TotalUnpaid += value
-		}
+		paid, unpaid := row.baseSplit()
+		totals.TotalPaid += paid
+		totals.TotalUnpaid += unpaid
 	}
 	return totals, nil
 }
 
 func applyEventTableFilters(q *bun.SelectQuery, filter EventTableFilter) *bun.SelectQuery {
 	q = q.Where("group_id = ?", filter.GroupID)
+	q = applyStatus(q, filter.Status, "status")
 	q = applySearch(q, filter.Search, func(sq *bun.SelectQuery, search string) *bun.SelectQuery {
 		like := "%" + search + "%"
 		return sq.WhereGroup(" AND ", func(qq *bun.SelectQuery) *bun.SelectQuery {
@@ -137,6 +148,8 @@ func orderEvents(q *bun.SelectQuery, sort, dir string) *bun.SelectQuery {
 		q = q.OrderExpr("paid " + d)
 	case "paid_at":
 		q = q.OrderExpr("(paid_at IS NULL OR paid_at = '') " + d).OrderExpr("paid_at " + d)
+	case "margin":
+		q = q.OrderExpr(eventMarginExpr("?TableAlias") + " " + d)
 	default:
 		q = q.OrderExpr("COALESCE(NULLIF(date, ''), substr(time, 1, 10)) DESC")
 	}
@@ -151,6 +164,15 @@ func applySearch(q *bun.SelectQuery, search string, fn func(*bun.SelectQuery, st
 	return fn(q, search)
 }
 
+func applyStatus(q *bun.SelectQuery, status, column string) *bun.SelectQuery {
+	switch strings.TrimSpace(status) {
+	case EventStatusActive, EventStatusCancelled:
+		return q.Where(column+" = ?", status)
+	default:
+		return q
+	}
+}
+
 func applyDateRangeOrYear(q *bun.SelectQuery, from, to, year, columnExpr string) *bun.SelectQuery {
 	from = strings.TrimSpace(from)
 	to = strings.TrimSpace(to)
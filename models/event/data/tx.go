@@ -2,9 +2,9 @@ package data
 
 import (
 	"context"
-	"database/sql"
 
 	"bandcash/internal/db"
+	"bandcash/internal/tax"
 	"github.com/uptrace/bun"
 )
 
@@ -14,36 +14,15 @@ func getEventTx(ctx context.Context, tx bun.Tx, arg GetEventParams) (db.Event, e
 	return row, err
 }
 
-func UpdateEventTx(ctx context.Context, tx bun.Tx, arg UpdateEventParams) (db.Event, error) {
-	current, err := getEventTx(ctx, tx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
-	if err != nil {
+func CreateEventTx(ctx context.Context, tx bun.Tx, arg CreateEventParams) (db.Event, error) {
+	if err := insertEvent(ctx, tx, arg); err != nil {
 		return db.Event{}, err
 	}
+	return getEventTx(ctx, tx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
+}
 
-	paidAtInput := paidAtNullable(arg.PaidAt)
-	finalPaidAt := current.PaidAt
-	if arg.Paid == 0 || isPaidAtClearRequest(arg.PaidAt) {
-		finalPaidAt = sql.NullString{}
-	} else if paidAtInput.Valid {
-		finalPaidAt = paidAtInput
-	} else if current.Paid == 0 {
-		finalPaidAt = currentTimestampNullString()
-	}
-
-	_, err = tx.NewUpdate().Model((*db.Event)(nil)).
-		Set("title = ?", arg.Title).
-		Set("time = ?", eventTimeFromParts(arg.Date, arg.EventTime)).
-		Set("date = ?", arg.Date).
-		Set("event_time = ?", arg.EventTime).
-		Set("place = ?", arg.Place).
-		Set("description = ?", arg.Description).
-		Set("amount = ?", arg.Amount).
-		Set("paid = ?", arg.Paid).
-		Set("paid_at = ?", paidAtValue(finalPaidAt)).
-		Where("id = ?", arg.ID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
-	if err != nil {
+func UpdateEventTx(ctx context.Context, tx bun.Tx, arg UpdateEventParams) (db.Event, error) {
+	if err := updateEvent(ctx, tx, arg); err != nil {
 		return db.Event{}, err
 	}
 	return getEventTx(ctx, tx, GetEventParams{ID: arg.ID, GroupID: arg.GroupID})
@@ -60,10 +39,13 @@ func ListParticipantsByEventTx(ctx context.Context, tx bun.Tx, arg ListParticipa
 		ColumnExpr("members.created_at").
 		ColumnExpr("members.updated_at").
 		ColumnExpr("participants.amount AS participant_amount").
+		ColumnExpr("participants.withheld AS participant_withheld").
 		ColumnExpr("participants.expense AS participant_expense").
 		ColumnExpr("participants.note AS participant_note").
 		ColumnExpr("participants.paid AS participant_paid").
 		ColumnExpr("participants.paid_at AS participant_paid_at").
+		ColumnExpr("participants.compensation AS participant_compensation").
+		ColumnExpr(ParticipantPaidOutExpr+" AS participant_paid_out").
 		Join("JOIN participants ON participants.member_id = members.id").
 		Where("participants.event_id = ?", arg.EventID).
 		Where("participants.group_id = ?", arg.GroupID).
@@ -72,70 +54,88 @@ func ListParticipantsByEventTx(ctx context.Context, tx bun.Tx, arg ListParticipa
 	return rows, err
 }
 
-func UpdateParticipantTx(ctx context.Context, tx bun.Tx, arg UpdateParticipantParams) error {
-	var current db.Participant
-	err := tx.NewSelect().Model(&current).
-		Where("event_id = ?", arg.EventID).
-		Where("member_id = ?", arg.MemberID).
-		Where("group_id = ?", arg.GroupID).
+// memberWithheldTx is the tax withheld from a cut of amount under the
+// member's current tax profile.
+func memberWithheldTx(ctx context.Context, idb bun.IDB, groupID, memberID string, amount int64) (int64, error) {
+	var member db.Member
+	err := idb.NewSelect().Model(&member).
+		Column("tax_mode", "withholding_rate").
+		Where("id = ?", memberID).
+		Where("group_id = ?", groupID).
 		Scan(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	return tax.Withheld(member.TaxMode, member.WithholdingRate, amount), nil
+}
 
-	paidAtInput := paidAtNullable(arg.PaidAt)
-	finalPaidAt := current.PaidAt
-	if arg.Paid == 0 || isPaidAtClearRequest(arg.PaidAt) {
-		finalPaidAt = sql.NullString{}
-	} else if paidAtInput.Valid {
-		finalPaidAt = paidAtInput
-	} else if current.Paid == 0 {
-		finalPaidAt = currentTimestampNullString()
+// UpdateParticipantTx saves a participant's amounts and note, withholding tax
+// from the cut by the member's tax profile. Ticking paid pays out the
+// outstanding amount, unticking it removes the payouts.
+func UpdateParticipantTx(ctx context.Context, tx bun.Tx, arg UpdateParticipantParams) error {
+	current, err := getParticipantTx(ctx, tx, GetParticipantParams{EventID: arg.EventID, MemberID: arg.MemberID, GroupID: arg.GroupID})
+	if err != nil {
+		return err
+	}
+	withheld, err := memberWithheldTx(ctx, tx, arg.GroupID, arg.MemberID, arg.Amount)
+	if err != nil {
+		return err
 	}
 
 	_, err = tx.NewUpdate().
 		Model((*db.Participant)(nil)).
 		Set("amount = ?", arg.Amount).
+		Set("withheld = ?", withheld).
 		Set("expense = ?", arg.Expense).
 		Set("note = ?", arg.Note).
-		Set("paid = ?", arg.Paid).
-		Set("paid_at = ?", paidAtValue(finalPaidAt)).
 		Where("event_id = ?", arg.EventID).
 		Where("member_id = ?", arg.MemberID).
 		Where("group_id = ?", arg.GroupID).
 		Exec(ctx)
-	return err
+	if err != nil {
+		return err
+	}
+	return applyParticipantPaid(ctx, tx, current, arg.Paid, paidAtNullable(arg.PaidAt), arg.PayoutID)
 }
 
+// AddParticipantTx adds a participant, withholding tax from the cut by the
+// member's tax profile; a paid one gets a payout for what it is owed.
 func AddParticipantTx(ctx context.Context, tx bun.Tx, arg AddParticipantParams) (db.Participant, error) {
-	paidAt := paidAtNullable(arg.PaidAt)
-	if arg.Paid == 0 {
-		paidAt = sql.NullString{}
+	withheld, err := memberWithheldTx(ctx, tx, arg.GroupID, arg.MemberID, arg.Amount)
+	if err != nil {
+		return db.Participant{}, err
 	}
-
 	row := db.Participant{
 		GroupID:  arg.GroupID,
 		EventID:  arg.EventID,
 		MemberID: arg.MemberID,
 		Amount:   arg.Amount,
+		Withheld: withheld,
 		Expense:  arg.Expense,
 		Note:     arg.Note,
-		Paid:     arg.Paid,
-		PaidAt:   paidAt,
 	}
 
-	_, err := tx.NewInsert().Model(&row).Exec(ctx)
+	_, err = tx.NewInsert().Model(&row).Exec(ctx)
 	if err != nil {
 		return db.Participant{}, err
 	}
+	if arg.Paid == 1 {
+		err = settleParticipantPaid(ctx, tx, arg.GroupID, arg.EventID, arg.MemberID, true, paidAtNullable(arg.PaidAt), arg.PayoutID)
+		if err != nil {
+			return db.Participant{}, err
+		}
+	}
+	return getParticipantTx(ctx, tx, GetParticipantParams{EventID: arg.EventID, MemberID: arg.MemberID, GroupID: arg.GroupID})
+}
 
-	var p db.Participant
-	err = tx.NewSelect().Model(&p).
-		Where("group_id = ?", arg.GroupID).
+func getParticipantTx(ctx context.Context, tx bun.Tx, arg GetParticipantParams) (db.Participant, error) {
+	var row db.Participant
+	err := tx.NewSelect().Model(&row).
 		Where("event_id = ?", arg.EventID).
 		Where("member_id = ?", arg.MemberID).
+		Where("group_id = ?", arg.GroupID).
 		Scan(ctx)
-	return p, err
+	return row, err
 }
 
 func RemoveParticipantTx(ctx context.Context, tx bun.Tx, arg RemoveParticipantParams) error {
@@ -147,3 +147,12 @@ func RemoveParticipantTx(ctx context.Context, tx bun.Tx, arg RemoveParticipantPa
 		Exec(ctx)
 	return err
 }
+
+// CreateParticipantPayoutTx records a payout inside tx and updates the
+// participant's paid state.
+func CreateParticipantPayoutTx(ctx context.Context, tx bun.Tx, arg CreateParticipantPayoutParams) error {
+	if err := insertParticipantPayout(ctx, tx, arg); err != nil {
+		return err
+	}
+	return syncParticipantPaid(ctx, tx, arg.GroupID, arg.EventID, arg.MemberID)
+}
@@ -8,29 +8,54 @@ type GetEventParams struct {
 }
 
 type CreateEventParams struct {
-	ID          string      `json:"id"`
-	GroupID     string      `json:"group_id"`
-	Title       string      `json:"title"`
-	Date        string      `json:"date"`
-	EventTime   string      `json:"event_time"`
-	Place       string      `json:"place"`
-	Description string      `json:"description"`
-	Amount      int64       `json:"amount"`
-	Paid        int64       `json:"paid"`
-	PaidAt      interface{} `json:"paid_at"`
+	ID             string         `json:"id"`
+	GroupID        string         `json:"group_id"`
+	Title          string         `json:"title"`
+	Date           string         `json:"date"`
+	EventTime      string         `json:"event_time"`
+	Place          string         `json:"place"`
+	Description    string         `json:"description"`
+	Amount         int64          `json:"amount"`
+	Currency       string         `json:"currency"`
+	ExchangeRate   float64        `json:"exchange_rate"`
+	Paid           int64          `json:"paid"`
+	PaidAt         interface{}    `json:"paid_at"`
+	PaymentID      string         `json:"payment_id"`
+	RecurrenceID   sql.NullString `json:"recurrence_id"`
+	RecurrenceDate string         `json:"recurrence_date"`
 }
 
 type UpdateEventParams struct {
-	Title       string      `json:"title"`
-	Date        string      `json:"date"`
-	EventTime   string      `json:"event_time"`
-	Place       string      `json:"place"`
-	Description string      `json:"description"`
-	Amount      int64       `json:"amount"`
-	Paid        int64       `json:"paid"`
-	PaidAt      interface{} `json:"paid_at"`
-	ID          string      `json:"id"`
-	GroupID     string      `json:"group_id"`
+	Title        string      `json:"title"`
+	Date         string      `json:"date"`
+	EventTime    string      `json:"event_time"`
+	Place        string      `json:"place"`
+	Description  string      `json:"description"`
+	Amount       int64       `json:"amount"`
+	Currency     string      `json:"currency"`
+	ExchangeRate float64     `json:"exchange_rate"`
+	Paid         int64       `json:"paid"`
+	PaidAt       interface{} `json:"paid_at"`
+	PaymentID    string      `json:"payment_id"`
+	ID           string      `json:"id"`
+	GroupID      string      `json:"group_id"`
+	// Version and ParticipantsVersion are the ones the edit form was opened
+	// at. Zero skips the check.
+	Version             int64 `json:"version"`
+	ParticipantsVersion int64 `json:"participants_version"`
+}
+
+type CancelEventParams struct {
+	ID           string           `json:"id"`
+	GroupID      string           `json:"group_id"`
+	Reason       string           `json:"reason"`
+	Fee          int64            `json:"fee"`
+	Compensation map[string]int64 `json:"compensation"`
+}
+
+type RestoreEventParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
 }
 
 type DeleteEventParams struct {
@@ -38,21 +63,99 @@ type DeleteEventParams struct {
 	GroupID string `json:"group_id"`
 }
 
+// PaymentID names the payment recorded for the outstanding amount when an
+// event is marked paid.
 type ToggleEventPaidParams struct {
+	ID        string `json:"id"`
+	GroupID   string `json:"group_id"`
+	PaymentID string `json:"payment_id"`
+}
+
+type UpdateEventPaidAtParams struct {
+	PaidAt    interface{} `json:"paid_at"`
+	PaymentID string      `json:"payment_id"`
+	ID        string      `json:"id"`
+	GroupID   string      `json:"group_id"`
+}
+
+type ListEventPaymentsParams struct {
+	EventID string `json:"event_id"`
+	GroupID string `json:"group_id"`
+}
+
+type GetEventPaymentParams struct {
 	ID      string `json:"id"`
+	EventID string `json:"event_id"`
 	GroupID string `json:"group_id"`
 }
 
-type UpdateEventPaidAtParams struct {
-	PaidAt  interface{} `json:"paid_at"`
-	ID      string      `json:"id"`
-	GroupID string      `json:"group_id"`
+type CreateEventPaymentParams struct {
+	ID          string `json:"id"`
+	GroupID     string `json:"group_id"`
+	EventID     string `json:"event_id"`
+	Amount      int64  `json:"amount"`
+	PaidAt      string `json:"paid_at"`
+	Method      string `json:"method"`
+	Reference   string `json:"reference"`
+	CollectedBy string `json:"collected_by"`
+}
+
+type DeleteEventPaymentParams struct {
+	ID      string `json:"id"`
+	EventID string `json:"event_id"`
+	GroupID string `json:"group_id"`
 }
 
+// EventPaymentSummary totals the payments received for one event.
+type EventPaymentSummary struct {
+	EventID    string `bun:"event_id"`
+	Received   int64  `bun:"received"`
+	LastPaidAt string `bun:"last_paid_at"`
+}
+
+type ListParticipantPayoutsParams struct {
+	EventID string `json:"event_id"`
+	GroupID string `json:"group_id"`
+}
+
+type GetParticipantPayoutParams struct {
+	ID      string `json:"id"`
+	EventID string `json:"event_id"`
+	GroupID string `json:"group_id"`
+}
+
+type CreateParticipantPayoutParams struct {
+	ID        string `json:"id"`
+	GroupID   string `json:"group_id"`
+	EventID   string `json:"event_id"`
+	MemberID  string `json:"member_id"`
+	Amount    int64  `json:"amount"`
+	PaidAt    string `json:"paid_at"`
+	Method    string `json:"method"`
+	Reference string `json:"reference"`
+	PaidBy    string `json:"paid_by"`
+}
+
+type DeleteParticipantPayoutParams struct {
+	ID       string `json:"id"`
+	EventID  string `json:"event_id"`
+	MemberID string `json:"member_id"`
+	GroupID  string `json:"group_id"`
+}
+
+type GetParticipantParams struct {
+	EventID  string `json:"event_id"`
+	MemberID string `json:"member_id"`
+	GroupID  string `json:"group_id"`
+}
+
+// ToggleParticipantPaidParams carries the id of the payout recorded when
+// the participant is marked paid.
 type ToggleParticipantPaidParams struct {
 	EventID  string `json:"event_id"`
 	MemberID string `json:"member_id"`
 	GroupID  string `json:"group_id"`
+	PayoutID string `json:"payout_id"`
 }
 
 type UpdateParticipantPaidAtParams struct {
@@ -60,6 +163,7 @@ type UpdateParticipantPaidAtParams struct {
 	EventID  string      `json:"event_id"`
 	MemberID string      `json:"member_id"`
 	GroupID  string      `json:"group_id"`
+	PayoutID string      `json:"payout_id"`
 }
 
 type UpdateParticipantNoteParams struct {
@@ -75,6 +179,7 @@ type UpdateParticipantParams struct {
 	Note     string      `json:"note"`
 	Paid     int64       `json:"paid"`
 	PaidAt   interface{} `json:"paid_at"`
+	PayoutID string      `json:"payout_id"`
 	EventID  string      `json:"event_id"`
 	MemberID string      `json:"member_id"`
 	GroupID  string      `json:"group_id"`
@@ -89,6 +194,7 @@ type AddParticipantParams struct {
 	Note     string      `json:"note"`
 	Paid     int64       `json:"paid"`
 	PaidAt   interface{} `json:"paid_at"`
+	PayoutID string      `json:"payout_id"`
 }
 
 type RemoveParticipantParams struct {
@@ -103,17 +209,41 @@ type ListParticipantsByEventParams struct {
 }
 
 type ListParticipantsByEventRow struct {
-	ID                 string         `json:"id"`
-	GroupID            string         `json:"group_id"`
-	Name               string         `json:"name"`
-	Description        string         `json:"description"`
-	CreatedAt          sql.NullTime   `json:"created_at"`
-	UpdatedAt          sql.NullTime   `json:"updated_at"`
-	ParticipantAmount  int64          `json:"participant_amount"`
-	ParticipantExpense int64          `json:"participant_expense"`
-	ParticipantNote    string         `json:"participant_note"`
-	ParticipantPaid    int64          `json:"participant_paid"`
-	ParticipantPaidAt  sql.NullString `json:"participant_paid_at"`
+	ID                      string         `json:"id"`
+	GroupID                 string         `json:"group_id"`
+	Name                    string         `json:"name"`
+	Description             string         `json:"description"`
+	CreatedAt               sql.NullTime   `json:"created_at"`
+	UpdatedAt               sql.NullTime   `json:"updated_at"`
+	ParticipantAmount       int64          `json:"participant_amount"`
+	ParticipantWithheld     int64          `json:"participant_withheld"`
+	ParticipantExpense      int64          `json:"participant_expense"`
+	ParticipantNote         string         `json:"participant_note"`
+	ParticipantPaid         int64          `json:"participant_paid"`
+	ParticipantPaidAt       sql.NullString `json:"participant_paid_at"`
+	ParticipantCompensation int64          `json:"participant_compensation"`
+	ParticipantPaidOut      int64          `json:"participant_paid_out"`
+}
+
+type GroupParticipantRow struct {
+	EventID      string         `bun:"event_id"`
+	MemberName   string         `bun:"member_name"`
+	Amount       int64          `bun:"amount"`
+	Expense      int64          `bun:"expense"`
+	Compensation int64          `bun:"compensation"`
+	Paid         int64          `bun:"paid"`
+	PaidAt       sql.NullString `bun:"paid_at"`
+}
+
+type participantPayoutRow struct {
+	Amount       int64
+	Withheld     int64
+	Expense      int64
+	Compensation int64
+	Paid         int64
+	PaidOut      int64
+	EventStatus  string
+	ExchangeRate float64
 }
 
 type SumParticipantPaidAmountsByGroupRow struct {
@@ -0,0 +1,102 @@
+package event
+
+import (
+	"context"
+	"strconv"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/currency"
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	groupstore "bandcash/models/group/data"
+)
+
+var exportColumns = []string{
+	"date", "time", "title", "place", "description", "status", "amount", "currency", "exchange_rate", "cancellation_fee", "paid", "paid_at",
+	"member", "member_amount", "member_expense", "member_compensation", "member_paid", "member_paid_at",
+}
+
+// exportRecords lists the events matching query, one row per participant.
+// Events without participants still get a row with the member columns empty.
+func exportRecords(ctx context.Context, groupID string, query utils.TableQuery) ([][]string, spreadsheet.Format, error) {
+	format := spreadsheet.FormatForLocale(appi18n.LocaleCode(ctx))
+
+	events, err := eventstore.ListEventsTable(ctx, eventstore.EventTableListParams{
+		EventTableFilter: eventstore.EventTableFilter{
+			GroupID: groupID,
+			Search:  query.Search,
+			Year:    query.Year,
+			From:    query.From,
+			To:      query.To,
+			Status:  query.Status,
+		},
+		Sort: query.Sort,
+		Dir:  query.Dir,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	participants, err := eventstore.ListParticipantsByGroup(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+	byEvent := make(map[string][]eventstore.GroupParticipantRow, len(events))
+	for _, participant := range participants {
+		byEvent[participant.EventID] = append(byEvent[participant.EventID], participant)
+	}
+
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	header := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = ctxi18n.T(ctx, "csv.columns."+column)
+	}
+	records := [][]string{header}
+
+	for _, event := range events {
+		status := ctxi18n.T(ctx, "events.status.active")
+		if event.Status == eventstore.EventStatusCancelled {
+			status = ctxi18n.T(ctx, "events.status.cancelled")
+		}
+		eventFields := []string{
+			event.Date,
+			event.EventTime,
+			event.Title,
+			event.Place,
+			event.Description,
+			status,
+			strconv.FormatInt(event.Amount, 10),
+			currency.Of(event.Currency, group.BaseCurrency),
+			spreadsheet.FormatRate(event.ExchangeRate, format),
+			strconv.FormatInt(event.CancellationFee, 10),
+			spreadsheet.FormatBool(event.Paid == 1, format),
+			utils.FormatDateInput(event.PaidAt.String),
+		}
+
+		rows := byEvent[event.ID]
+		if len(rows) == 0 {
+			records = append(records, append(eventFields, "", "", "", "", "", ""))
+			continue
+		}
+		for _, participant := range rows {
+			record := append([]string{}, eventFields...)
+			record = append(record,
+				participant.MemberName,
+				strconv.FormatInt(participant.Amount, 10),
+				strconv.FormatInt(participant.Expense, 10),
+				strconv.FormatInt(participant.Compensation, 10),
+				spreadsheet.FormatBool(participant.Paid == 1, format),
+				utils.FormatDateInput(participant.PaidAt.String),
+			)
+			records = append(records, record)
+		}
+	}
+	return records, format, nil
+}
@@ -2,7 +2,8 @@ package event
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -14,8 +15,14 @@ import (
 
 	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	"bandcash/models/attachment"
+	attachmentstore "bandcash/models/attachment/data"
+	"bandcash/models/audit"
 	eventstore "bandcash/models/event/data"
+	groupstore "bandcash/models/group/data"
 	memberstore "bandcash/models/member/data"
+	"bandcash/models/recurrence"
+	recurrencestore "bandcash/models/recurrence/data"
 )
 
 // Default signal states for resetting forms on success
@@ -24,11 +31,12 @@ var (
 		"mode":      "table",
 		"formState": "",
 		"editingId": "",
-		"formData":  map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": 0, "paid": false, "paidAt": ""},
-		"errors":    map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": ""},
+		"formData":  map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": 0, "currency": "", "exchangeRate": 1, "paid": false, "paidAt": ""},
+		"errors":    map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": "", "currency": "", "exchangeRate": ""},
 	}
 	// Error field lists for validation
-	eventErrorFields = []string{"title", "date", "time", "place", "description", "amount"}
+	eventErrorFields  = []string{"title", "date", "time", "place", "description", "amount", "currency", "exchangeRate"}
+	cancelErrorFields = []string{"reason", "fee", "compensation"}
 )
 
 func Create(c echo.Context) error {
@@ -60,22 +68,39 @@ func Create(c echo.Context) error {
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
 
-	event, err := eventstore.CreateEvent(c.Request().Context(), eventstore.CreateEventParams{
-		ID:          utils.GenerateID(utils.PrefixEvent),
-		GroupID:     groupID,
-		Title:       signals.FormData.Title,
-		Date:        signals.FormData.Date,
-		EventTime:   signals.FormData.Time,
-		Place:       signals.FormData.Place,
-		Description: signals.FormData.Description,
-		Amount:      signals.FormData.Amount,
-		Paid: func() int64 {
-			if signals.FormData.Paid {
-				return 1
-			}
-			return 0
-		}(),
-		PaidAt: paidAtArg(signals.FormData.Paid, signals.FormData.PaidAt),
+	entryCurrency, exchangeRate, err := groupstore.EntryCurrency(c.Request().Context(), groupID, signals.FormData.Currency, signals.FormData.ExchangeRate)
+	if err != nil {
+		slog.Error("event.create.table: failed to resolve currency", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var event db.Event
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		event, err = eventstore.CreateEvent(ctx, eventstore.CreateEventParams{
+			ID:           utils.GenerateID(utils.PrefixEvent),
+			GroupID:      groupID,
+			Title:        signals.FormData.Title,
+			Date:         signals.FormData.Date,
+			EventTime:    signals.FormData.Time,
+			Place:        signals.FormData.Place,
+			Description:  signals.FormData.Description,
+			Amount:       signals.FormData.Amount,
+			Currency:     entryCurrency,
+			ExchangeRate: exchangeRate,
+			Paid: func() int64 {
+				if signals.FormData.Paid {
+					return 1
+				}
+				return 0
+			}(),
+			PaidAt:    paidAtArg(signals.FormData.Paid, signals.FormData.PaidAt),
+			PaymentID: utils.GenerateID(utils.PrefixPayment),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterEvent, EntityID: event.ID, After: event})
 	})
 	if err != nil {
 		slog.Error("event.create.table: failed to create event", "err", err)
@@ -83,6 +108,8 @@ func Create(c echo.Context) error {
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	publishEvent(c, groupID, event.ID)
+
 	slog.Debug("event.create: created", "id", event.ID, "title", event.Title)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.created"))
 
@@ -139,29 +166,67 @@ func Update(c echo.Context) error {
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
 
-	_, err = eventstore.UpdateEvent(c.Request().Context(), eventstore.UpdateEventParams{
-		Title:       eventForm.Title,
-		Date:        eventForm.Date,
-		EventTime:   eventForm.Time,
-		Place:       eventForm.Place,
-		Description: eventForm.Description,
-		Amount:      eventForm.Amount,
-		Paid: func() int64 {
-			if eventForm.Paid {
-				return 1
-			}
-			return 0
-		}(),
-		PaidAt:  paidAtArg(eventForm.Paid, eventForm.PaidAt),
-		ID:      id,
-		GroupID: groupID,
+	before, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.update: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	entryCurrency, exchangeRate, err := groupstore.EntryCurrency(c.Request().Context(), groupID, eventForm.Currency, eventForm.ExchangeRate)
+	if err != nil {
+		slog.Error("event.update: failed to resolve currency", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var updated db.Event
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = eventstore.UpdateEvent(ctx, eventstore.UpdateEventParams{
+			Title:        eventForm.Title,
+			Date:         eventForm.Date,
+			EventTime:    eventForm.Time,
+			Place:        eventForm.Place,
+			Description:  eventForm.Description,
+			Amount:       eventForm.Amount,
+			Currency:     entryCurrency,
+			ExchangeRate: exchangeRate,
+			Paid: func() int64 {
+				if eventForm.Paid {
+					return 1
+				}
+				return 0
+			}(),
+			PaidAt:    paidAtArg(eventForm.Paid, eventForm.PaidAt),
+			PaymentID: utils.GenerateID(utils.PrefixPayment),
+			ID:        id,
+			GroupID:   groupID,
+			Version:   eventForm.Version,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionUpdate, Entity: utils.EntityFilterEvent, EntityID: id, Before: before, After: updated})
 	})
+	if db.IsConflict(err) {
+		if err := patchEventConflict(c, groupID, id, eventForm); err != nil {
+			slog.Error("event.update: failed to open conflict dialog", "err", err)
+		}
+		return c.NoContent(http.StatusConflict)
+	}
+	if errors.Is(err, eventstore.ErrEventHasPayments) {
+		slog.Info("event.update: event has payments", "id", id)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.unpaid_has_payments"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("event.update: failed to update event", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	publishEvent(c, groupID, id, eventMemberIDs(c.Request().Context(), groupID, id)...)
+
 	slog.Debug("event.update", "id", id)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.updated"))
 
@@ -195,15 +260,39 @@ func Destroy(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	err = eventstore.DeleteEvent(c.Request().Context(), eventstore.DeleteEventParams{
-		ID:      id,
-		GroupID: groupID,
+	before, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.destroy: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// Attachment rows cascade with the event; their blobs are released below.
+	attachmentHashes := attachment.EntityHashes(c.Request().Context(), groupID, attachmentstore.EntityEvent, id)
+	memberIDs := eventMemberIDs(c.Request().Context(), groupID, id)
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		err := eventstore.DeleteEvent(ctx, eventstore.DeleteEventParams{
+			ID:      id,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionDelete, Entity: utils.EntityFilterEvent, EntityID: id, Before: before})
 	})
+	if errors.Is(err, eventstore.ErrEventInvoiced) {
+		slog.Info("event.destroy: event has an invoice", "id", id)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.delete_invoiced"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("event.destroy: failed to delete event", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.delete_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	attachment.ReleaseBlobs(c.Request().Context(), attachmentHashes...)
+	publishEvent(c, groupID, id, memberIDs...)
 
 	slog.Debug("event.destroy", "id", id)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.deleted"))
@@ -267,16 +356,40 @@ func ToggleParticipantPaid(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	_, err = eventstore.ToggleParticipantPaid(c.Request().Context(), eventstore.ToggleParticipantPaidParams{
+	before, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  eventID,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		slog.Error("participant.togglePaid: failed to get participant", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.toggle_paid_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err := eventstore.ToggleParticipantPaid(ctx, eventstore.ToggleParticipantPaidParams{
+			EventID:  eventID,
+			MemberID: memberID,
+			GroupID:  groupID,
+			PayoutID: utils.GenerateID(utils.PrefixPayout),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updated.Paid), Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(eventID, memberID), Before: before, After: updated})
+	})
+	if errors.Is(err, eventstore.ErrParticipantHasPayouts) {
+		slog.Info("participant.togglePaid: participant has payouts", "event_id", eventID, "member_id", memberID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.unpaid_has_payouts"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("participant.togglePaid: failed to toggle paid status", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, eventID, memberID)
 
 	slog.Debug("participant.togglePaid", "event_id", eventID, "member_id", memberID)
 
@@ -356,28 +469,85 @@ func UpdateDetails(c echo.Context) error {
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
 
-	_, err = eventstore.UpdateEvent(c.Request().Context(), eventstore.UpdateEventParams{
-		Title:       eventForm.Title,
-		Date:        eventForm.Date,
-		EventTime:   eventForm.Time,
-		Place:       eventForm.Place,
-		Description: eventForm.Description,
-		Amount:      eventForm.Amount,
-		Paid: func() int64 {
-			if eventForm.Paid {
-				return 1
+	before, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.update_details: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	entryCurrency, exchangeRate, err := groupstore.EntryCurrency(c.Request().Context(), groupID, eventForm.Currency, eventForm.ExchangeRate)
+	if err != nil {
+		slog.Error("event.update_details: failed to resolve currency", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var updated db.Event
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = eventstore.UpdateEvent(ctx, eventstore.UpdateEventParams{
+			Title:        eventForm.Title,
+			Date:         eventForm.Date,
+			EventTime:    eventForm.Time,
+			Place:        eventForm.Place,
+			Description:  eventForm.Description,
+			Amount:       eventForm.Amount,
+			Currency:     entryCurrency,
+			ExchangeRate: exchangeRate,
+			Paid: func() int64 {
+				if eventForm.Paid {
+					return 1
+				}
+				return 0
+			}(),
+			PaidAt:    paidAtArg(eventForm.Paid, eventForm.PaidAt),
+			PaymentID: utils.GenerateID(utils.PrefixPayment),
+			ID:        id,
+			GroupID:   groupID,
+			Version:   eventForm.Version,
+		})
+		if err != nil {
+			return err
+		}
+		changes := []audit.Change{{Action: audit.ActionUpdate, Entity: utils.EntityFilterEvent, EntityID: id, Before: before, After: updated}}
+
+		if eventForm.Scope == seriesScopeFuture && updated.RecurrenceID.Valid {
+			series, err := recurrence.SeriesChanges(ctx, recurrencestore.KindEvent, updated.RecurrenceID.String, groupID, func() error {
+				return recurrencestore.ApplyEventSeries(ctx, recurrencestore.ApplySeriesParams{
+					RecurrenceID: updated.RecurrenceID.String,
+					GroupID:      groupID,
+					FromDate:     updated.RecurrenceDate,
+					Title:        eventForm.Title,
+					Description:  eventForm.Description,
+					Place:        eventForm.Place,
+					EventTime:    eventForm.Time,
+					Amount:       eventForm.Amount,
+				})
+			})
+			if err != nil {
+				return fmt.Errorf("update series %s: %w", updated.RecurrenceID.String, err)
 			}
-			return 0
-		}(),
-		PaidAt:  paidAtArg(eventForm.Paid, eventForm.PaidAt),
-		ID:      id,
-		GroupID: groupID,
+			changes = append(changes, series...)
+		}
+		return audit.Record(ctx, c, changes...)
 	})
+	if db.IsConflict(err) {
+		if err := patchEventConflict(c, groupID, id, eventForm); err != nil {
+			slog.Error("event.update_details: failed to open conflict dialog", "err", err)
+		}
+		return c.NoContent(http.StatusConflict)
+	}
+	if errors.Is(err, eventstore.ErrEventHasPayments) {
+		slog.Info("event.update_details: event has payments", "id", id)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.unpaid_has_payments"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("event.update_details: failed to update event", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, id, eventMemberIDs(c.Request().Context(), groupID, id)...)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
@@ -389,6 +559,126 @@ func UpdateDetails(c echo.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+func CancelEvent(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixEvent) {
+		slog.Info("event.cancel: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals cancelEventParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("event.cancel: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := signals.CancelFormData
+	form.Reason = strings.TrimSpace(form.Reason)
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(cancelErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+	for _, amount := range form.Compensation {
+		if amount < 0 {
+			errs := map[string]string{"compensation": ctxi18n.T(ctx, "validation.gte", "0")}
+			utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(cancelErrorFields, errs)})
+			return c.NoContent(http.StatusUnprocessableEntity)
+		}
+	}
+
+	before, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.cancel: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "events.notifications.cancel_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var cancelled db.Event
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		cancelled, err = eventstore.CancelEvent(ctx, eventstore.CancelEventParams{
+			ID:           id,
+			GroupID:      groupID,
+			Reason:       form.Reason,
+			Fee:          form.Fee,
+			Compensation: form.Compensation,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionCancel, Entity: utils.EntityFilterEvent, EntityID: id, Before: before, After: cancelled})
+	})
+	if err != nil {
+		slog.Error("event.cancel: failed to cancel event", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "events.notifications.cancel_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	publishEvent(c, groupID, id, eventMemberIDs(c.Request().Context(), groupID, id)...)
+
+	utils.Notify(c, ctxi18n.T(ctx, "events.notifications.cancelled"))
+	utils.InvalidateGroupCaches(groupID)
+
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/events/"+id); err != nil {
+		slog.Warn("event.cancel: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func RestoreEvent(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixEvent) {
+		slog.Info("event.restore: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals modeParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("event.restore: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	before, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.restore: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "events.notifications.restore_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var restored db.Event
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		restored, err = eventstore.RestoreEvent(ctx, eventstore.RestoreEventParams{ID: id, GroupID: groupID})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionRestore, Entity: utils.EntityFilterEvent, EntityID: id, Before: before, After: restored})
+	})
+	if err != nil {
+		slog.Error("event.restore: failed to restore event", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "events.notifications.restore_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	publishEvent(c, groupID, id, eventMemberIDs(c.Request().Context(), groupID, id)...)
+
+	utils.Notify(c, ctxi18n.T(ctx, "events.notifications.restored"))
+	utils.InvalidateGroupCaches(groupID)
+
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/events/"+id); err != nil {
+		slog.Warn("event.restore: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
 func CancelParticipantsDraft(c echo.Context) error {
 	groupID := utils.GetGroupID(c)
 
@@ -463,6 +753,18 @@ func UpdateParticipantsDraftRows(c echo.Context) error {
 	if signals.Wizard.PaidAts == nil {
 		signals.Wizard.PaidAts = map[string]string{}
 	}
+	if signals.Wizard.Shares == nil {
+		signals.Wizard.Shares = map[string]int64{}
+	}
+	if signals.Wizard.Fixed == nil {
+		signals.Wizard.Fixed = map[string]bool{}
+	}
+	split := ParticipantSplit{
+		Mode:           normalizeSplitMode(signals.Wizard.SplitMode),
+		Shares:         signals.Wizard.Shares,
+		Fixed:          signals.Wizard.Fixed,
+		DeductExpenses: signals.Wizard.DeductExpenses,
+	}
 
 	switch strings.TrimSpace(signals.DraftRowsAction) {
 	case "add":
@@ -474,6 +776,8 @@ func UpdateParticipantsDraftRows(c echo.Context) error {
 		signals.Wizard.Notes[rowID] = ""
 		signals.Wizard.Paids[rowID] = false
 		signals.Wizard.PaidAts[rowID] = ""
+		signals.Wizard.Shares[rowID] = 1
+		signals.Wizard.Fixed[rowID] = false
 	case "copy":
 		targetRowID := strings.TrimSpace(signals.DraftRowsRowID)
 		if targetRowID == "" {
@@ -527,6 +831,11 @@ func UpdateParticipantsDraftRows(c echo.Context) error {
 		signals.Wizard.Notes[newRowID] = sourceNote
 		signals.Wizard.Paids[newRowID] = sourcePaid
 		signals.Wizard.PaidAts[newRowID] = sourcePaidAt
+		signals.Wizard.Shares[newRowID] = 1
+		if value, ok := signals.Wizard.Shares[source.RowID]; ok {
+			signals.Wizard.Shares[newRowID] = value
+		}
+		signals.Wizard.Fixed[newRowID] = signals.Wizard.Fixed[source.RowID]
 	case "remove":
 		targetRowID := strings.TrimSpace(signals.DraftRowsRowID)
 		if targetRowID == "" {
@@ -546,12 +855,24 @@ func UpdateParticipantsDraftRows(c echo.Context) error {
 		delete(signals.Wizard.Notes, targetRowID)
 		delete(signals.Wizard.Paids, targetRowID)
 		delete(signals.Wizard.PaidAts, targetRowID)
+		delete(signals.Wizard.Shares, targetRowID)
+		delete(signals.Wizard.Fixed, targetRowID)
+	case "split":
+		eventAmount := signals.EventFormData.Amount
+		if eventAmount <= 0 {
+			eventAmount = signals.Wizard.EventAmount
+		}
+		resolved := mergeWizardRows(nil, nil, rows, signals.Wizard.MemberIDs, signals.Wizard.Amounts, signals.Wizard.Expenses, signals.Wizard.Notes, signals.Wizard.Paids, signals.Wizard.PaidAts)
+		for _, row := range applyParticipantSplit(eventAmount, resolved, split) {
+			signals.Wizard.Amounts[row.RowID] = row.Amount
+		}
+		slog.Debug("participant.draft.split", "event_id", eventID, "mode", split.Mode, "event_amount", eventAmount)
 	default:
 		return c.NoContent(http.StatusBadRequest)
 	}
 
 	query := utils.NormalizeTableQuery(signals.TableQuery, ParticipantTableQuerySpec())
-	if err := patchEventShow(c, groupID, eventID, query, "edit", signals.EventFormData, signals.Wizard.EventAmount, rows, signals.Wizard.MemberIDs, signals.Wizard.Amounts, signals.Wizard.Expenses, signals.Wizard.Notes, signals.Wizard.Paids, signals.Wizard.PaidAts, ""); err != nil {
+	if err := patchEventShow(c, groupID, eventID, query, "edit", signals.EventFormData, signals.Wizard.EventAmount, rows, signals.Wizard.MemberIDs, signals.Wizard.Amounts, signals.Wizard.Expenses, signals.Wizard.Notes, signals.Wizard.Paids, signals.Wizard.PaidAts, split, ""); err != nil {
 		slog.Error("participant.draft.rows: failed to render", "err", err)
 		return c.NoContent(http.StatusInternalServerError)
 	}
@@ -689,23 +1010,57 @@ func SaveParticipantsBulk(c echo.Context) error {
 	}
 	signals.Wizard.Rows = normalizedRows
 
-	err = db.BunDB.RunInTx(c.Request().Context(), &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
-		_, err = eventstore.UpdateEventTx(ctx, tx, eventstore.UpdateEventParams{
-			Title:       signals.EventFormData.Title,
-			Date:        signals.EventFormData.Date,
-			EventTime:   signals.EventFormData.Time,
-			Place:       signals.EventFormData.Place,
-			Description: signals.EventFormData.Description,
-			Amount:      signals.EventFormData.Amount,
+	// Computed splits must not hand out more than the event brings in.
+	if normalizeSplitMode(signals.Wizard.SplitMode) != splitModeManual {
+		rows := make([]ParticipantWizardRow, 0, len(signals.Wizard.Rows))
+		for _, row := range signals.Wizard.Rows {
+			rows = append(rows, ParticipantWizardRow(row))
+		}
+		payoutTotal := splitPayoutTotal(rows, signals.Wizard.DeductExpenses)
+		if over := payoutTotal - signals.EventFormData.Amount; over > 0 {
+			ctx := c.Request().Context()
+			patchWizardError(c, signals.Wizard, ctxi18n.T(ctx, "participants.validation.over_allocated", utils.FormatNumberLocalized(ctx, over)), "")
+			return c.NoContent(http.StatusUnprocessableEntity)
+		}
+	}
+
+	beforeEvent, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Error("participant.bulk: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	beforeParticipants, err := eventstore.ListParticipantsByEvent(c.Request().Context(), eventstore.ListParticipantsByEventParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Error("participant.bulk: failed to list participants", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var afterEvent db.Event
+	var afterParticipants []eventstore.ListParticipantsByEventRow
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		afterEvent, err = eventstore.UpdateEventTx(ctx, tx, eventstore.UpdateEventParams{
+			Title:        signals.EventFormData.Title,
+			Date:         signals.EventFormData.Date,
+			EventTime:    signals.EventFormData.Time,
+			Place:        signals.EventFormData.Place,
+			Description:  signals.EventFormData.Description,
+			Amount:       signals.EventFormData.Amount,
+			Currency:     beforeEvent.Currency,
+			ExchangeRate: beforeEvent.ExchangeRate,
 			Paid: func() int64 {
 				if signals.EventFormData.Paid {
 					return 1
 				}
 				return 0
 			}(),
-			PaidAt:  paidAtArg(signals.EventFormData.Paid, signals.EventFormData.PaidAt),
-			ID:      eventID,
-			GroupID: groupID,
+			PaidAt:              paidAtArg(signals.EventFormData.Paid, signals.EventFormData.PaidAt),
+			PaymentID:           utils.GenerateID(utils.PrefixPayment),
+			ID:                  eventID,
+			GroupID:             groupID,
+			Version:             signals.EventFormData.Version,
+			ParticipantsVersion: signals.EventFormData.ParticipantsVersion,
 		})
 		if err != nil {
 			return err
@@ -744,6 +1099,7 @@ func SaveParticipantsBulk(c echo.Context) error {
 						return 0
 					}(),
 					PaidAt:   paidAtArg(paid, paidAt),
+					PayoutID: utils.GenerateID(utils.PrefixPayout),
 					EventID:  eventID,
 					MemberID: row.MemberID,
 					GroupID:  groupID,
@@ -762,7 +1118,8 @@ func SaveParticipantsBulk(c echo.Context) error {
 						}
 						return 0
 					}(),
-					PaidAt: paidAtArg(paid, paidAt),
+					PaidAt:   paidAtArg(paid, paidAt),
+					PayoutID: utils.GenerateID(utils.PrefixPayout),
 				})
 			}
 			if err != nil {
@@ -783,13 +1140,36 @@ func SaveParticipantsBulk(c echo.Context) error {
 				return err
 			}
 		}
-		return nil
+
+		afterParticipants, err = eventstore.ListParticipantsByEventTx(ctx, tx, eventstore.ListParticipantsByEventParams{EventID: eventID, GroupID: groupID})
+		if err != nil {
+			return err
+		}
+		changes := []audit.Change{{Action: audit.ActionUpdate, Entity: utils.EntityFilterEvent, EntityID: eventID, Before: beforeEvent, After: afterEvent}}
+		return audit.Record(ctx, c, append(changes, participantChanges(afterEvent, beforeParticipants, afterParticipants)...)...)
 	})
+	if db.IsConflict(err) {
+		if err := patchEventConflict(c, groupID, eventID, signals.EventFormData); err != nil {
+			slog.Error("participant.bulk: failed to open conflict dialog", "err", err)
+		}
+		return c.NoContent(http.StatusConflict)
+	}
+	if errors.Is(err, eventstore.ErrEventHasPayments) {
+		slog.Info("participant.bulk: event has payments", "id", eventID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.unpaid_has_payments"))
+		return c.NoContent(http.StatusConflict)
+	}
+	if errors.Is(err, eventstore.ErrParticipantHasPayouts) {
+		slog.Info("participant.bulk: participant has payouts", "event_id", eventID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.unpaid_has_payouts"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("participant.bulk: tx failed", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, eventID, participantMemberIDs(beforeParticipants, afterParticipants)...)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.updated"))
 
@@ -1026,16 +1406,32 @@ func openParticipantPaidAtDialog(c echo.Context, groupID, id, memberID string, t
 func patchUpdatePaidAt(c echo.Context, groupID, id, mode string, tableQuery utils.TableQuery, value string) error {
 	paidAt := normalizePaidAtInput(value)
 
-	_, err := eventstore.UpdateEventPaidAt(c.Request().Context(), eventstore.UpdateEventPaidAtParams{
-		PaidAt:  paidAt,
-		ID:      id,
-		GroupID: groupID,
+	before, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.updatePaidAt: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var updated db.Event
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = eventstore.UpdateEventPaidAt(ctx, eventstore.UpdateEventPaidAtParams{
+			PaidAt:    paidAt,
+			PaymentID: utils.GenerateID(utils.PrefixPayment),
+			ID:        id,
+			GroupID:   groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterEvent, EntityID: id, Before: before, After: updated})
 	})
 	if err != nil {
 		slog.Error("event.updatePaidAt: failed to update paid_at", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, id)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.updated"))
 
@@ -1103,17 +1499,37 @@ func patchUpdateParticipantNote(c echo.Context, groupID, id string, tableQuery u
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	err := eventstore.UpdateParticipantNote(c.Request().Context(), eventstore.UpdateParticipantNoteParams{
-		Note:     strings.TrimSpace(value),
+	before, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  id,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		slog.Error("event.updateParticipantNote: failed to get participant", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	after := before
+	after.Note = strings.TrimSpace(value)
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		err := eventstore.UpdateParticipantNote(ctx, eventstore.UpdateParticipantNoteParams{
+			Note:     strings.TrimSpace(value),
+			EventID:  id,
+			MemberID: memberID,
+			GroupID:  groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionUpdate, Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(id, memberID), Before: before, After: after})
+	})
 	if err != nil {
 		slog.Error("event.updateParticipantNote: failed to update note", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, id, memberID)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
@@ -1151,17 +1567,37 @@ func patchUpdateParticipantPaidAt(c echo.Context, groupID, id string, tableQuery
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	_, err := eventstore.UpdateParticipantPaidAt(c.Request().Context(), eventstore.UpdateParticipantPaidAtParams{
-		PaidAt:   normalizePaidAtInput(value),
+	before, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  id,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		slog.Error("event.updateParticipantPaidAt: failed to get participant", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var updated db.Participant
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = eventstore.UpdateParticipantPaidAt(ctx, eventstore.UpdateParticipantPaidAtParams{
+			PaidAt:   normalizePaidAtInput(value),
+			EventID:  id,
+			MemberID: memberID,
+			GroupID:  groupID,
+			PayoutID: utils.GenerateID(utils.PrefixPayout),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(id, memberID), Before: before, After: updated})
+	})
 	if err != nil {
 		slog.Error("event.updateParticipantPaidAt: failed to update paid_at", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, id, memberID)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
@@ -1194,15 +1630,35 @@ func patchUpdateParticipantPaidAt(c echo.Context, groupID, id string, tableQuery
 }
 
 func patchTogglePaid(c echo.Context, groupID, id, mode string, tableQuery utils.TableQuery) error {
-	_, err := eventstore.ToggleEventPaid(c.Request().Context(), eventstore.ToggleEventPaidParams{
-		ID:      id,
-		GroupID: groupID,
+	before, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("event.togglePaid: failed to get event", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.toggle_paid_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err := eventstore.ToggleEventPaid(ctx, eventstore.ToggleEventPaidParams{
+			ID:        id,
+			GroupID:   groupID,
+			PaymentID: utils.GenerateID(utils.PrefixPayment),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updated.Paid), Entity: utils.EntityFilterEvent, EntityID: id, Before: before, After: updated})
 	})
+	if errors.Is(err, eventstore.ErrEventHasPayments) {
+		slog.Info("event.togglePaid: event has payments", "id", id)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.unpaid_has_payments"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("event.togglePaid: failed to toggle paid status", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishEvent(c, groupID, id)
 
 	slog.Debug("event.togglePaid", "id", id)
 
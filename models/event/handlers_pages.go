@@ -1,12 +1,16 @@
 package event
 
 import (
+	"bytes"
 	"log/slog"
+	"mime"
 	"net/http"
+	"time"
 
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 	"github.com/labstack/echo/v4"
 
+	"bandcash/internal/spreadsheet"
 	"bandcash/internal/utils"
 	groupstore "bandcash/models/group/data"
 )
@@ -30,6 +34,29 @@ func IndexPage(c echo.Context) error {
 	return utils.RenderPage(c, EventIndexPage(data))
 }
 
+// Export downloads the events matching the current table filters as CSV.
+// Paging is ignored so the file holds every matching event.
+func Export(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	query := utils.ParseTableQuery(c, staticTableQueryable{spec: TableQuerySpec()})
+
+	records, format, err := exportRecords(c.Request().Context(), groupID, query)
+	if err != nil {
+		slog.Error("event.export: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, format, records); err != nil {
+		slog.Error("event.export: failed to write csv", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	fileName := "events-" + time.Now().Format("2006-01-02") + ".csv"
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
 func ShowPage(c echo.Context) error {
 	utils.EnsureTabID(c)
 	groupID := utils.GetGroupID(c)
@@ -51,6 +78,8 @@ func ShowPage(c echo.Context) error {
 	data.IsAuthenticated = true
 	data.IsSuperAdmin = utils.IsSuperadmin(c)
 
+	utils.SSEHub.Subscribe(c, utils.EventTopic(id))
+	utils.SSEHub.Join(c, utils.EventTopic(id), false)
 	return utils.RenderPage(c, EventShowPage(data))
 }
 
@@ -72,10 +101,11 @@ func NewEventPage(c echo.Context) error {
 			{Label: ctxi18n.T(c.Request().Context(), "events.title"), Href: "/groups/" + groupID + "/events"},
 			{Label: ctxi18n.T(c.Request().Context(), "events.add")},
 		},
-		GroupID: groupID,
+		GroupID:      groupID,
+		BaseCurrency: group.BaseCurrency,
 		Signals: map[string]any{
-			"formData": map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": 0, "paid": false, "paidAt": ""},
-			"errors":   map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": ""},
+			"formData": map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": 0, "currency": "", "exchangeRate": 1, "paid": false, "paidAt": ""},
+			"errors":   map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": "", "currency": "", "exchangeRate": ""},
 		},
 		IsAuthenticated: true,
 		IsSuperAdmin:    utils.IsSuperadmin(c),
@@ -110,6 +140,40 @@ func EditEventPage(c echo.Context) error {
 	data.IsAuthenticated = true
 	data.IsSuperAdmin = utils.IsSuperadmin(c)
 
+	utils.SSEHub.Subscribe(c, utils.EventTopic(id))
+	utils.SSEHub.Join(c, utils.EventTopic(id), false)
+	return utils.RenderPage(c, EventEditPage(data))
+}
+
+func CancelEventPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+	query := parseParticipantTableQuery(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixEvent) {
+		slog.Info("event.cancel_page: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	data, err := GetShowData(c.Request().Context(), groupID, id, query)
+	if err != nil {
+		slog.Error("event.cancel_page: failed to get data", "group_id", groupID, "event_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	applyEventShowTableByRole(&data, utils.IsAdmin(c))
+	if len(data.Breadcrumbs) > 0 {
+		data.Breadcrumbs[len(data.Breadcrumbs)-1].Href = "/groups/" + groupID + "/events/" + id
+	}
+	data.Breadcrumbs = append(data.Breadcrumbs, utils.Crumb{Label: ctxi18n.T(c.Request().Context(), "events.cancel.title")})
+	data.EditorMode = "cancel"
+	data.Signals = eventShowSignals(data)
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	utils.SSEHub.Subscribe(c, utils.EventTopic(id))
+	utils.SSEHub.Join(c, utils.EventTopic(id), false)
 	return utils.RenderPage(c, EventEditPage(data))
 }
 
@@ -140,5 +204,7 @@ func EditEventParticipantsPage(c echo.Context) error {
 	data.IsAuthenticated = true
 	data.IsSuperAdmin = utils.IsSuperadmin(c)
 
+	utils.SSEHub.Subscribe(c, utils.EventTopic(id))
+	utils.SSEHub.Join(c, utils.EventTopic(id), true)
 	return utils.RenderPage(c, EventEditPage(data))
 }
@@ -2,26 +2,32 @@ package event
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"log/slog"
 	"sort"
 	"strings"
 
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 
+	"bandcash/internal/currency"
 	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	attachmentstore "bandcash/models/attachment/data"
 	authstore "bandcash/models/auth/data"
 	eventstore "bandcash/models/event/data"
 	expensestore "bandcash/models/expense/data"
 	groupstore "bandcash/models/group/data"
+	invoicestore "bandcash/models/invoice/data"
 	memberstore "bandcash/models/member/data"
+	quotestore "bandcash/models/quote/data"
 )
 
 func TableQuerySpec() utils.TableQuerySpec {
 	return utils.StandardTableQuerySpec(utils.StandardTableQuerySpecParams{
 		DefaultSort:  "date",
 		DefaultDir:   "desc",
-		AllowedSorts: []string{"date", "time", "title", "place", "amount", "description", "paid", "paid_at"},
+		AllowedSorts: []string{"date", "time", "title", "place", "amount", "margin", "description", "paid", "paid_at"},
 	})
 }
 
@@ -60,6 +66,52 @@ func GetShowData(ctx context.Context, groupID, eventID string, query utils.Table
 		return EventData{}, err
 	}
 
+	attachments, err := attachmentstore.ListAttachments(ctx, attachmentstore.ListAttachmentsParams{
+		GroupID:  groupID,
+		Entity:   attachmentstore.EntityEvent,
+		EntityID: eventID,
+	})
+	if err != nil {
+		return EventData{}, err
+	}
+
+	payments, err := eventstore.ListEventPayments(ctx, eventstore.ListEventPaymentsParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		return EventData{}, err
+	}
+	var received int64
+	for _, payment := range payments {
+		received += payment.Amount
+	}
+
+	payouts, err := eventstore.ListParticipantPayouts(ctx, eventstore.ListParticipantPayoutsParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		return EventData{}, err
+	}
+
+	expenses, err := expensestore.ListEventExpenses(ctx, groupID, eventID)
+	if err != nil {
+		return EventData{}, err
+	}
+
+	var source *db.Quote
+	quote, err := quotestore.GetQuoteByEventID(ctx, groupID, eventID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return EventData{}, err
+	}
+	if err == nil {
+		source = &quote
+	}
+
+	var issued *db.Invoice
+	invoice, err := invoicestore.GetInvoiceByEvent(ctx, invoicestore.GetInvoiceByEventParams{EventID: eventID, GroupID: groupID})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return EventData{}, err
+	}
+	if err == nil {
+		issued = &invoice
+	}
+
 	participantMemberIDs := make(map[string]bool, len(allParticipants))
 	participantByMemberID := make(map[string]eventstore.ListParticipantsByEventRow, len(allParticipants))
 	for _, participant := range allParticipants {
@@ -107,8 +159,8 @@ func GetShowData(ctx context.Context, groupID, eventID string, query utils.Table
 
 		switch query.Sort {
 		case "total":
-			leftTotal := left.ParticipantAmount + left.ParticipantExpense
-			rightTotal := right.ParticipantAmount + right.ParticipantExpense
+			leftTotal := eventstore.PayoutAmount(event.Status, left.ParticipantAmount, left.ParticipantWithheld, left.ParticipantExpense, left.ParticipantCompensation)
+			rightTotal := eventstore.PayoutAmount(event.Status, right.ParticipantAmount, right.ParticipantWithheld, right.ParticipantExpense, right.ParticipantCompensation)
 			if leftTotal == rightTotal {
 				leftName := strings.ToLower(left.Name)
 				rightName := strings.ToLower(right.Name)
@@ -184,26 +236,22 @@ func GetShowData(ctx context.Context, groupID, eventID string, query utils.Table
 	// If event is paid: balance = event.Amount - totalPaid (received minus paid out)
 	var totalPaid, totalUnpaid int64
 	for _, p := range allParticipants {
-		amount := p.ParticipantAmount + p.ParticipantExpense
-		if p.ParticipantPaid == 1 {
-			totalPaid += amount
-		} else {
-			totalUnpaid += amount
-		}
+		amount := eventstore.PayoutAmount(event.Status, p.ParticipantAmount, p.ParticipantWithheld, p.ParticipantExpense, p.ParticipantCompensation)
+		paid := eventstore.PaidOutAmount(amount, p.ParticipantPaidOut, p.ParticipantPaid)
+		totalPaid += paid
+		totalUnpaid += amount - paid
 	}
 
 	var filteredPaid, filteredUnpaid int64
 	for _, p := range participants {
-		amount := p.ParticipantAmount + p.ParticipantExpense
-		if p.ParticipantPaid == 1 {
-			filteredPaid += amount
-		} else {
-			filteredUnpaid += amount
-		}
+		amount := eventstore.PayoutAmount(event.Status, p.ParticipantAmount, p.ParticipantWithheld, p.ParticipantExpense, p.ParticipantCompensation)
+		paid := eventstore.PaidOutAmount(amount, p.ParticipantPaidOut, p.ParticipantPaid)
+		filteredPaid += paid
+		filteredUnpaid += amount - paid
 	}
 
-	balance := event.Amount - totalPaid
-	filteredBalance := event.Amount - filteredPaid
+	balance := eventstore.IncomeAmount(event) - totalPaid
+	filteredBalance := eventstore.IncomeAmount(event) - filteredPaid
 
 	slog.Info("event.show.data", "event_id", eventID, "participants", len(participants), "members_total", len(members), "members_filtered", len(filteredMembers), "balance", balance)
 
@@ -223,8 +271,11 @@ func GetShowData(ctx context.Context, groupID, eventID string, query utils.Table
 		FilteredBalance:   filteredBalance,
 		WizardEventAmount: event.Amount,
 		WizardError:       "",
+		WizardSplit:       defaultParticipantSplit(wizardRows),
 		EditorMode:        "read",
 		GroupID:           groupID,
+		BaseCurrency:      group.BaseCurrency,
+		EventCurrency:     currency.Of(event.Currency, group.BaseCurrency),
 		Breadcrumbs: []utils.Crumb{
 			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
 			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
@@ -232,6 +283,16 @@ func GetShowData(ctx context.Context, groupID, eventID string, query utils.Table
 			{Label: event.Title},
 		},
 		ParticipantsTable: EventParticipantsTableLayout(),
+		Attachments:       attachments,
+		Quote:             source,
+		Invoice:           issued,
+		Payments:          payments,
+		PaymentsReceived:  received,
+		AllParticipants:   allParticipants,
+		Payouts:           payouts,
+		Expenses:          expenses,
+		ProfitLoss:        eventProfitLoss(event, allParticipants, expenses),
+		BillingReady:      group.BillingName != "",
 	}, nil
 }
 
@@ -247,6 +308,7 @@ func GetIndexData(ctx context.Context, groupID string, query utils.TableQuery) (
 		Year:    query.Year,
 		From:    query.From,
 		To:      query.To,
+		Status:  query.Status,
 	}
 
 	totalItems, err := eventstore.CountEventsTable(ctx, filters)
@@ -326,6 +388,15 @@ func buildEventsData(ctx context.Context, groupID string, group db.Group, query
 		return EventsData{}, err
 	}
 
+	eventIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+	margins, err := eventstore.ListEventMargins(ctx, groupID, eventIDs)
+	if err != nil {
+		return EventsData{}, err
+	}
+
 	groupCreatedAt := "-"
 	if group.CreatedAt.Valid {
 		groupCreatedAt = utils.FormatTimeLocalized(ctx, group.CreatedAt.Time)
@@ -336,7 +407,9 @@ func buildEventsData(ctx context.Context, groupID string, group db.Group, query
 		GroupName:              group.Name,
 		GroupAdminEmail:        admin.Email,
 		GroupCreatedAt:         groupCreatedAt,
+		BaseCurrency:           group.BaseCurrency,
 		Events:                 events,
+		Margins:                margins,
 		RecentYears:            utils.RecentYears(3),
 		Query:                  query,
 		Pager:                  utils.BuildTablePagination(totals.TotalItems, query),
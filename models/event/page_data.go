@@ -25,8 +25,11 @@ type EventData struct {
 	FilteredBalance         int64
 	WizardEventAmount       int64
 	WizardError             string
+	WizardSplit             ParticipantSplit
 	EditorMode              string
 	GroupID                 string
+	BaseCurrency            string
+	EventCurrency           string
 	IsAdmin                 bool
 	PaidAtDialog            PaidAtDialogState
 	ParticipantPaidAtDialog ParticipantPaidAtDialogState
@@ -34,6 +37,16 @@ type EventData struct {
 	IsAuthenticated         bool
 	IsSuperAdmin            bool
 	ParticipantsTable       utils.TableLayout
+	Attachments             []db.Attachment
+	Payments                []db.EventPayment
+	PaymentsReceived        int64
+	AllParticipants         []eventstore.ListParticipantsByEventRow
+	Payouts                 []db.ParticipantPayout
+	Expenses                []db.Expense
+	ProfitLoss              eventstore.ProfitLoss
+	Quote                   *db.Quote
+	Invoice                 *db.Invoice
+	BillingReady            bool
 }
 
 type PaidAtDialogState struct {
@@ -89,10 +102,18 @@ type ParticipantWizardRow struct {
 	PaidAt     string
 }
 
+type ParticipantSplit struct {
+	Mode           string
+	Shares         map[string]int64
+	Fixed          map[string]bool
+	DeductExpenses bool
+}
+
 type NewEventPageData struct {
 	Title           string
 	Breadcrumbs     []utils.Crumb
 	GroupID         string
+	BaseCurrency    string
 	Signals         map[string]any
 	IsAuthenticated bool
 	IsSuperAdmin    bool
@@ -103,7 +124,9 @@ type EventsData struct {
 	GroupName              string
 	GroupAdminEmail        string
 	GroupCreatedAt         string
+	BaseCurrency           string
 	Events                 []db.Event
+	Margins                map[string]int64
 	RecentYears            []int
 	Query                  utils.TableQuery
 	Pager                  utils.TablePagination
@@ -0,0 +1,29 @@
+package event
+
+import (
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	expensestore "bandcash/models/expense/data"
+)
+
+// eventProfitLoss totals an event's income, what its participants are owed
+// and the expenses linked to it. A cancelled event owes compensations, which
+// count as cuts.
+func eventProfitLoss(event db.Event, participants []eventstore.ListParticipantsByEventRow, expenses []db.Expense) eventstore.ProfitLoss {
+	pl := eventstore.ProfitLoss{
+		Income:       eventstore.IncomeAmount(event),
+		ExchangeRate: event.ExchangeRate,
+	}
+	for _, p := range participants {
+		if event.Status == eventstore.EventStatusCancelled {
+			pl.Cuts += p.ParticipantCompensation
+			continue
+		}
+		pl.Cuts += p.ParticipantAmount
+		pl.ParticipantExpenses += p.ParticipantExpense
+	}
+	for _, expense := range expenses {
+		pl.Expenses += expensestore.BaseAmount(expense)
+	}
+	return pl
+}
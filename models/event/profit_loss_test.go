@@ -0,0 +1,42 @@
+package event
+
+import (
+	"testing"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+)
+
+func TestEventProfitLoss(t *testing.T) {
+	t.Parallel()
+
+	event := db.Event{Amount: 1000, Status: eventstore.EventStatusActive, ExchangeRate: 2}
+	participants := []eventstore.ListParticipantsByEventRow{
+		{ParticipantAmount: 300, ParticipantExpense: 50, ParticipantCompensation: 100},
+		{ParticipantAmount: 200},
+	}
+	expenses := []db.Expense{
+		{Amount: 100, ExchangeRate: 1},
+		// Kept in a currency worth half a base unit.
+		{Amount: 51, ExchangeRate: 0.5},
+	}
+
+	got := eventProfitLoss(event, participants, expenses)
+	if got.Income != 1000 || got.Cuts != 500 || got.ParticipantExpenses != 50 || got.Expenses != 126 {
+		t.Fatalf("eventProfitLoss() = %+v", got)
+	}
+	// (1000 - 500 - 50) * 2 - 126
+	if margin := got.Margin(); margin != 774 {
+		t.Errorf("Margin() = %d; want 774", margin)
+	}
+
+	event.Status = eventstore.EventStatusCancelled
+	event.CancellationFee = 150
+	got = eventProfitLoss(event, participants, nil)
+	if got.Income != 150 || got.Cuts != 100 || got.ParticipantExpenses != 0 {
+		t.Fatalf("cancelled eventProfitLoss() = %+v", got)
+	}
+	if margin := got.Margin(); margin != 100 {
+		t.Errorf("cancelled Margin() = %d; want 100", margin)
+	}
+}
@@ -1,6 +1,9 @@
 package event
 
-import "bandcash/internal/utils"
+import (
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
 
 type eventInlineParams struct {
 	TabID                 string           `json:"tab_id"`
@@ -51,14 +54,34 @@ type participantPaidAtDialogParams struct {
 }
 
 type eventData struct {
-	Title       string `json:"title" validate:"required,min=1,max=255"`
-	Date        string `json:"date" validate:"required"`
-	Time        string `json:"time" validate:"required"`
-	Place       string `json:"place" validate:"max=255"`
-	Description string `json:"description" validate:"max=1000"`
-	Amount      int64  `json:"amount" validate:"required,gt=0"`
-	Paid        bool   `json:"paid"`
-	PaidAt      string `json:"paidAt"`
+	Title        string  `json:"title" validate:"required,min=1,max=255"`
+	Date         string  `json:"date" validate:"required"`
+	Time         string  `json:"time" validate:"required"`
+	Place        string  `json:"place" validate:"max=255"`
+	Description  string  `json:"description" validate:"max=1000"`
+	Amount       int64   `json:"amount" validate:"required,gt=0"`
+	Currency     string  `json:"currency" validate:"omitempty,currency"`
+	ExchangeRate float64 `json:"exchangeRate" validate:"required_with=Currency,omitempty,gt=0"`
+	Paid         bool    `json:"paid"`
+	PaidAt       string  `json:"paidAt"`
+	Scope        string  `json:"scope"`
+	// Version and ParticipantsVersion are the event's when the form opened.
+	Version             int64 `json:"version"`
+	ParticipantsVersion int64 `json:"participantsVersion"`
+	// Opened holds the event's values when the form opened, so a conflict
+	// lists what others changed since rather than what this form changes.
+	Opened *eventData `json:"opened,omitempty" validate:"-"`
+}
+
+type cancelEventData struct {
+	Reason       string           `json:"reason" validate:"max=1000"`
+	Fee          int64            `json:"fee" validate:"gte=0"`
+	Compensation map[string]int64 `json:"compensation"`
+}
+
+type cancelEventParams struct {
+	TabID          string          `json:"tab_id"`
+	CancelFormData cancelEventData `json:"cancelFormData"`
 }
 
 type participantBulkRowData struct {
@@ -74,18 +97,22 @@ type participantBulkRowData struct {
 }
 
 type participantWizardSignals struct {
-	EventAmount int64                    `json:"eventAmount"`
-	Rows        []participantBulkRowData `json:"rows"`
-	RowErrors   map[string]string        `json:"rowErrors"`
-	MemberIDs   map[string]string        `json:"memberIds"`
-	Amounts     map[string]int64         `json:"amounts"`
-	Expenses    map[string]int64         `json:"expenses"`
-	Notes       map[string]string        `json:"notes"`
-	Paids       map[string]bool          `json:"paids"`
-	PaidAts     map[string]string        `json:"paidAts"`
-	Total       int64                    `json:"total"`
-	Balance     int64                    `json:"balance"`
-	Error       string                   `json:"error"`
+	EventAmount    int64                    `json:"eventAmount"`
+	Rows           []participantBulkRowData `json:"rows"`
+	RowErrors      map[string]string        `json:"rowErrors"`
+	MemberIDs      map[string]string        `json:"memberIds"`
+	Amounts        map[string]int64         `json:"amounts"`
+	Expenses       map[string]int64         `json:"expenses"`
+	Notes          map[string]string        `json:"notes"`
+	Paids          map[string]bool          `json:"paids"`
+	PaidAts        map[string]string        `json:"paidAts"`
+	Total          int64                    `json:"total"`
+	Balance        int64                    `json:"balance"`
+	Error          string                   `json:"error"`
+	SplitMode      string                   `json:"splitMode"`
+	Shares         map[string]int64         `json:"shares"`
+	Fixed          map[string]bool          `json:"fixed"`
+	DeductExpenses bool                     `json:"deductExpenses"`
 }
 
 type participantBulkParams struct {
@@ -119,7 +146,7 @@ func eventIndexSignals(query utils.TableQuery) map[string]any {
 		"mode":            "table",
 		"formState":       "",
 		"editingId":       0,
-		"formData":        map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": 0, "paid": false, "paidAt": ""},
+		"formData":        map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": 0, "currency": "", "exchangeRate": 1, "paid": false, "paidAt": ""},
 		"eventFormState":  "",
 		"summaryMode":     query.Summary,
 		"_fetching":       false,
@@ -136,8 +163,38 @@ func eventIndexSignals(query utils.TableQuery) map[string]any {
 			"url":         "",
 			"triggerID":   "",
 		},
-		"errors": map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": "", "memberId": "", "expense": ""},
+		"errors": map[string]any{"title": "", "date": "", "time": "", "place": "", "description": "", "amount": "", "currency": "", "exchangeRate": "", "memberId": "", "expense": ""},
+	}
+}
+
+// eventFormValues returns the editable fields of event as the edit form
+// binds them.
+func eventFormValues(event db.Event) map[string]any {
+	paidAt := ""
+	if event.PaidAt.Valid {
+		paidAt = utils.FormatDateInput(event.PaidAt.String)
 	}
+	return map[string]any{
+		"title":        event.Title,
+		"date":         eventDateValue(event),
+		"time":         eventTimeValue(event),
+		"place":        event.Place,
+		"description":  event.Description,
+		"amount":       event.Amount,
+		"currency":     event.Currency,
+		"exchangeRate": event.ExchangeRate,
+		"paid":         event.Paid == 1,
+		"paidAt":       paidAt,
+	}
+}
+
+func eventEditFormData(event db.Event) map[string]any {
+	form := eventFormValues(event)
+	form["scope"] = seriesScopeSingle
+	form["version"] = event.Version
+	form["participantsVersion"] = event.ParticipantsVersion
+	form["opened"] = eventFormValues(event)
+	return form
 }
 
 func eventShowSignals(data EventData) map[string]any {
@@ -149,7 +206,13 @@ func eventShowSignals(data EventData) map[string]any {
 	wizardNotes := make(map[string]string, len(data.WizardRows))
 	wizardPaids := make(map[string]bool, len(data.WizardRows))
 	wizardPaidAts := make(map[string]string, len(data.WizardRows))
+	wizardShares := make(map[string]int64, len(data.WizardRows))
+	wizardFixed := make(map[string]bool, len(data.WizardRows))
 	wizardTotal := int64(0)
+	compensation := make(map[string]int64, len(data.Participants))
+	for _, participant := range data.Participants {
+		compensation[participant.ID] = participant.ParticipantCompensation
+	}
 	for _, row := range data.WizardRows {
 		rowID := row.RowID
 		if rowID == "" {
@@ -172,6 +235,11 @@ func eventShowSignals(data EventData) map[string]any {
 		wizardNotes[rowID] = row.Note
 		wizardPaids[rowID] = row.Paid
 		wizardPaidAts[rowID] = row.PaidAt
+		wizardShares[rowID] = 1
+		if share, ok := data.WizardSplit.Shares[rowID]; ok {
+			wizardShares[rowID] = share
+		}
+		wizardFixed[rowID] = data.WizardSplit.Fixed[rowID]
 		wizardTotal += row.Amount + row.Expense
 	}
 
@@ -183,33 +251,29 @@ func eventShowSignals(data EventData) map[string]any {
 		"eventFormState":        "",
 		"participantEditorMode": data.EditorMode,
 		"wizard": map[string]any{
-			"error":       data.WizardError,
-			"eventAmount": data.WizardEventAmount,
-			"rows":        wizardRows,
-			"rowErrors":   wizardRowErrors,
-			"memberIds":   wizardMemberIDs,
-			"amounts":     wizardAmounts,
-			"expenses":    wizardExpenses,
-			"notes":       wizardNotes,
-			"paids":       wizardPaids,
-			"paidAts":     wizardPaidAts,
-			"total":       wizardTotal,
-			"balance":     data.WizardEventAmount - wizardTotal,
+			"error":          data.WizardError,
+			"eventAmount":    data.WizardEventAmount,
+			"rows":           wizardRows,
+			"rowErrors":      wizardRowErrors,
+			"memberIds":      wizardMemberIDs,
+			"amounts":        wizardAmounts,
+			"expenses":       wizardExpenses,
+			"notes":          wizardNotes,
+			"paids":          wizardPaids,
+			"paidAts":        wizardPaidAts,
+			"total":          wizardTotal,
+			"balance":        data.WizardEventAmount - splitPayoutTotal(data.WizardRows, data.WizardSplit.DeductExpenses),
+			"splitMode":      normalizeSplitMode(data.WizardSplit.Mode),
+			"shares":         wizardShares,
+			"fixed":          wizardFixed,
+			"deductExpenses": data.WizardSplit.DeductExpenses,
+			"taxRates":       memberTaxRates(data.AllMembers),
 		},
-		"eventFormData": map[string]any{
-			"title":       data.Event.Title,
-			"date":        eventDateValue(*data.Event),
-			"time":        eventTimeValue(*data.Event),
-			"place":       data.Event.Place,
-			"description": data.Event.Description,
-			"amount":      data.Event.Amount,
-			"paid":        data.Event.Paid == 1,
-			"paidAt": func() string {
-				if !data.Event.PaidAt.Valid {
-					return ""
-				}
-				return utils.FormatDateInput(data.Event.PaidAt.String)
-			}(),
+		"eventFormData": eventEditFormData(*data.Event),
+		"cancelFormData": map[string]any{
+			"reason":       data.Event.CancelReason,
+			"fee":          data.Event.CancellationFee,
+			"compensation": compensation,
 		},
 		"formState":   "",
 		"editingId":   0,
@@ -261,14 +325,17 @@ func eventShowSignals(data EventData) map[string]any {
 			"expense":    0,
 		},
 		"errors": map[string]any{
-			"title":       "",
-			"date":        "",
-			"time":        "",
-			"place":       "",
-			"description": "",
-			"amount":      "",
-			"memberId":    "",
-			"expense":     "",
+			"title":        "",
+			"date":         "",
+			"time":         "",
+			"place":        "",
+			"description":  "",
+			"amount":       "",
+			"memberId":     "",
+			"expense":      "",
+			"reason":       "",
+			"fee":          "",
+			"compensation": "",
 		},
 	}
 }
@@ -0,0 +1,175 @@
+package event
+
+import "strings"
+
+const (
+	splitModeManual = "manual"
+	splitModeEqual  = "equal"
+	splitModeShares = "shares"
+	splitModeFixed  = "fixed"
+)
+
+func normalizeSplitMode(mode string) string {
+	switch strings.TrimSpace(mode) {
+	case splitModeEqual, splitModeShares, splitModeFixed:
+		return strings.TrimSpace(mode)
+	default:
+		return splitModeManual
+	}
+}
+
+func defaultParticipantSplit(rows []ParticipantWizardRow) ParticipantSplit {
+	shares := make(map[string]int64, len(rows))
+	fixed := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		shares[row.RowID] = 1
+		fixed[row.RowID] = false
+	}
+	return ParticipantSplit{
+		Mode:   splitModeManual,
+		Shares: shares,
+		Fixed:  fixed,
+	}
+}
+
+// applyParticipantSplit recomputes payout amounts for included rows that have a
+// member selected. Rows without a member and manual mode are left untouched.
+func applyParticipantSplit(eventAmount int64, rows []ParticipantWizardRow, split ParticipantSplit) []ParticipantWizardRow {
+	mode := normalizeSplitMode(split.Mode)
+	if mode == splitModeManual {
+		return rows
+	}
+
+	targets := make([]int, 0, len(rows))
+	pool := eventAmount
+	for i, row := range rows {
+		if !row.Included || strings.TrimSpace(row.MemberID) == "" {
+			continue
+		}
+		targets = append(targets, i)
+		if split.DeductExpenses {
+			pool -= row.Expense
+		}
+	}
+	if len(targets) == 0 {
+		return rows
+	}
+	if pool < 0 {
+		pool = 0
+	}
+
+	switch mode {
+	case splitModeEqual:
+		amounts := distributeEqual(pool, len(targets))
+		for i, index := range targets {
+			rows[index].Amount = amounts[i]
+		}
+	case splitModeShares:
+		weights := make([]int64, len(targets))
+		for i, index := range targets {
+			weight, ok := split.Shares[rows[index].RowID]
+			if !ok {
+				weight = 1
+			}
+			if weight < 0 {
+				weight = 0
+			}
+			weights[i] = weight
+		}
+		amounts := distributeWeighted(pool, weights)
+		for i, index := range targets {
+			rows[index].Amount = amounts[i]
+		}
+	case splitModeFixed:
+		flexible := make([]int, 0, len(targets))
+		for _, index := range targets {
+			if split.Fixed[rows[index].RowID] {
+				pool -= rows[index].Amount
+				continue
+			}
+			flexible = append(flexible, index)
+		}
+		if pool < 0 {
+			pool = 0
+		}
+		amounts := distributeEqual(pool, len(flexible))
+		for i, index := range flexible {
+			rows[index].Amount = amounts[i]
+		}
+	}
+
+	return rows
+}
+
+// distributeEqual splits pool into n integer parts. The rounding remainder is
+// handed out one unit at a time starting with the first part.
+func distributeEqual(pool int64, n int) []int64 {
+	if n <= 0 {
+		return nil
+	}
+	amounts := make([]int64, n)
+	base := pool / int64(n)
+	remainder := pool % int64(n)
+	for i := range amounts {
+		amounts[i] = base
+		if int64(i) < remainder {
+			amounts[i]++
+		}
+	}
+	return amounts
+}
+
+// distributeWeighted splits pool proportionally to weights using the largest
+// remainder method, so the parts always add up to pool. Ties keep row order.
+func distributeWeighted(pool int64, weights []int64) []int64 {
+	amounts := make([]int64, len(weights))
+	var totalWeight int64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	if totalWeight <= 0 {
+		return amounts
+	}
+
+	remainders := make([]int64, len(weights))
+	var allocated int64
+	for i, weight := range weights {
+		amounts[i] = pool * weight / totalWeight
+		remainders[i] = pool * weight % totalWeight
+		allocated += amounts[i]
+	}
+
+	for left := pool - allocated; left > 0; left-- {
+		best := -1
+		for i, remainder := range remainders {
+			if weights[i] == 0 {
+				continue
+			}
+			if best < 0 || remainder > remainders[best] {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		amounts[best]++
+		remainders[best] = -1
+	}
+	return amounts
+}
+
+// splitPayoutTotal sums what the included rows take out of the event amount:
+// their cuts, plus their expenses when the split deducted them from the pool.
+func splitPayoutTotal(rows []ParticipantWizardRow, deductExpenses bool) int64 {
+	var total int64
+	for _, row := range rows {
+		if !row.Included || strings.TrimSpace(row.MemberID) == "" {
+			continue
+		}
+		total += row.Amount
+		if deductExpenses {
+			total += row.Expense
+		}
+	}
+	return total
+}
@@ -0,0 +1,112 @@
+package event
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApplyParticipantSplit(t *testing.T) {
+	t.Parallel()
+
+	rows := func() []ParticipantWizardRow {
+		return []ParticipantWizardRow{
+			{RowID: "a", MemberID: "mem_a", Included: true, Amount: 500, Expense: 10},
+			{RowID: "b", MemberID: "mem_b", Included: true, Expense: 0},
+			{RowID: "c", MemberID: "mem_c", Included: true, Expense: 20},
+			{RowID: "d", MemberID: "", Included: true},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		amount int64
+		split  ParticipantSplit
+		want   []int64
+	}{
+		{
+			name:   "manual keeps amounts",
+			amount: 1000,
+			split:  ParticipantSplit{Mode: splitModeManual},
+			want:   []int64{500, 0, 0, 0},
+		},
+		{
+			name:   "equal hands out remainder in row order",
+			amount: 1000,
+			split:  ParticipantSplit{Mode: splitModeEqual},
+			want:   []int64{334, 333, 333, 0},
+		},
+		{
+			name:   "equal after deducting expenses",
+			amount: 1000,
+			split:  ParticipantSplit{Mode: splitModeEqual, DeductExpenses: true},
+			want:   []int64{324, 323, 323, 0},
+		},
+		{
+			name:   "weighted shares add up to pool",
+			amount: 1000,
+			split:  ParticipantSplit{Mode: splitModeShares, Shares: map[string]int64{"a": 2, "b": 1, "c": 0}},
+			want:   []int64{667, 333, 0, 0},
+		},
+		{
+			name:   "fixed rows keep amount and rest is split",
+			amount: 1000,
+			split:  ParticipantSplit{Mode: splitModeFixed, Fixed: map[string]bool{"a": true}},
+			want:   []int64{500, 250, 250, 0},
+		},
+		{
+			name:   "fixed over pool leaves nothing for others",
+			amount: 400,
+			split:  ParticipantSplit{Mode: splitModeFixed, Fixed: map[string]bool{"a": true}},
+			want:   []int64{500, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := applyParticipantSplit(tt.amount, rows(), tt.split)
+			amounts := make([]int64, 0, len(got))
+			for _, row := range got {
+				amounts = append(amounts, row.Amount)
+			}
+			if !slices.Equal(amounts, tt.want) {
+				t.Fatalf("applyParticipantSplit() amounts = %v, want %v", amounts, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistributeWeighted(t *testing.T) {
+	t.Parallel()
+
+	got := distributeWeighted(100, []int64{1, 1, 1})
+	if !slices.Equal(got, []int64{34, 33, 33}) {
+		t.Fatalf("distributeWeighted() = %v, want [34 33 33]", got)
+	}
+
+	got = distributeWeighted(100, []int64{0, 0})
+	if !slices.Equal(got, []int64{0, 0}) {
+		t.Fatalf("distributeWeighted() with zero weights = %v, want [0 0]", got)
+	}
+}
+
+func TestSplitPayoutTotalFitsEventAmount(t *testing.T) {
+	t.Parallel()
+
+	rows := []ParticipantWizardRow{
+		{RowID: "a", MemberID: "mem_a", Included: true, Expense: 10},
+		{RowID: "b", MemberID: "mem_b", Included: true},
+		{RowID: "c", MemberID: "mem_c", Included: true, Expense: 20},
+		{RowID: "d", MemberID: "mem_d", Included: false, Amount: 900},
+	}
+
+	for _, deduct := range []bool{false, true} {
+		split := ParticipantSplit{Mode: splitModeEqual, DeductExpenses: deduct}
+		got := splitPayoutTotal(applyParticipantSplit(1000, slices.Clone(rows), split), deduct)
+		if got != 1000 {
+			t.Fatalf("splitPayoutTotal(deduct=%v) = %d, want 1000", deduct, got)
+		}
+	}
+}
@@ -9,16 +9,28 @@ func EventsIndexTableLayout() utils.TableLayout {
 		{Key: "time"},
 		{Key: "place"},
 		{Key: "amount"},
+		{Key: "margin"},
 		{Key: "paid", MaxWRem: 7, WRem: 7},
 		{Key: "paid_at", MaxWRem: 12, WRem: 12},
 	}, 0)
 }
 
+func EventExpensesTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "title"},
+		{Key: "date", MaxWRem: 10},
+		{Key: "amount", MaxWRem: 12},
+	}, 0)
+}
+
 func EventParticipantsTableLayout() utils.TableLayout {
 	return utils.NewTableLayout([]utils.TableColumn{
 		{Key: "name", MaxWRem: 12},
 		{Key: "amount", MaxWRem: 8},
+		{Key: "withheld", MaxWRem: 8},
+		{Key: "net", MaxWRem: 8},
 		{Key: "expense", MaxWRem: 8},
+		{Key: "share", MaxWRem: 6},
 		{Key: "total"},
 		{Key: "note", MaxWRem: 16},
 		{Key: "paid", MaxWRem: 7, WRem: 7},
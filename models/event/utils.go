@@ -1,6 +1,7 @@
 package event
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
@@ -11,9 +12,29 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"bandcash/internal/db"
+	"bandcash/internal/tax"
 	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
 )
 
+// Edit scopes for rows generated from a recurrence template.
+const (
+	seriesScopeSingle = "single"
+	seriesScopeFuture = "future"
+)
+
+func eventStatusFilterLabel(ctx context.Context, status string) string {
+	switch status {
+	case utils.StatusFilterActive:
+		return ctxi18n.T(ctx, "events.status.active")
+	case utils.StatusFilterCancelled:
+		return ctxi18n.T(ctx, "events.status.cancelled")
+	default:
+		return ctxi18n.T(ctx, "table.all")
+	}
+}
+
 func normalizeCacheKeyPart(value string) string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -74,6 +95,15 @@ type staticTableQueryable struct {
 	spec utils.TableQuerySpec
 }
 
+// quoteClientName is the default invoice buyer for an event created from a
+// quote.
+func quoteClientName(quote *db.Quote) string {
+	if quote == nil {
+		return ""
+	}
+	return quote.ClientName
+}
+
 func (s staticTableQueryable) TableQuerySpec() utils.TableQuerySpec {
 	return s.spec
 }
@@ -278,7 +308,7 @@ func patchWizardError(c echo.Context, wizard participantWizardSignals, message s
 	}
 }
 
-func patchEventShow(c echo.Context, groupID, eventID string, query utils.TableQuery, editorMode string, eventForm eventData, wizardEventAmount int64, wizardRows []participantBulkRowData, wizardMemberIDs map[string]string, wizardAmounts map[string]int64, wizardExpenses map[string]int64, wizardNotes map[string]string, wizardPaids map[string]bool, wizardPaidAts map[string]string, wizardError string) error {
+func patchEventShow(c echo.Context, groupID, eventID string, query utils.TableQuery, editorMode string, eventForm eventData, wizardEventAmount int64, wizardRows []participantBulkRowData, wizardMemberIDs map[string]string, wizardAmounts map[string]int64, wizardExpenses map[string]int64, wizardNotes map[string]string, wizardPaids map[string]bool, wizardPaidAts map[string]string, wizardSplit ParticipantSplit, wizardError string) error {
 	data, err := GetShowData(c.Request().Context(), groupID, eventID, query)
 	if err != nil {
 		return err
@@ -318,6 +348,12 @@ func patchEventShow(c echo.Context, groupID, eventID string, query utils.TableQu
 		} else {
 			data.Event.PaidAt = sql.NullString{}
 		}
+		// A draft keeps the versions it was opened at, so saving it still
+		// notices changes made in the meantime.
+		if eventForm.Version != 0 {
+			data.Event.Version = eventForm.Version
+			data.Event.ParticipantsVersion = eventForm.ParticipantsVersion
+		}
 	}
 
 	if wizardEventAmount > 0 {
@@ -334,6 +370,7 @@ func patchEventShow(c echo.Context, groupID, eventID string, query utils.TableQu
 		data.WizardRows = mergeWizardRows(data.WizardRows, data.AllMembers, nil, wizardMemberIDs, wizardAmounts, wizardExpenses, wizardNotes, wizardPaids, wizardPaidAts)
 	}
 
+	data.WizardSplit = wizardSplit
 	data.WizardError = wizardError
 	data.Signals = eventShowSignals(data)
 
@@ -355,3 +392,101 @@ func patchEventShow(c echo.Context, groupID, eventID string, query utils.TableQu
 	}
 	return nil
 }
+
+// memberTaxRates maps the members with tax withheld from their cuts to their
+// withholding rate.
+func memberTaxRates(members []db.Member) map[string]float64 {
+	rates := make(map[string]float64)
+	for _, member := range members {
+		if member.TaxMode == tax.ModeWithholding && member.WithholdingRate > 0 {
+			rates[member.ID] = member.WithholdingRate
+		}
+	}
+	return rates
+}
+
+// wizardWithheldExpr computes the tax withheld from a wizard row's cut in the
+// browser, rounding like tax.Withheld.
+func wizardWithheldExpr(rowID string) string {
+	amount := fmt.Sprintf("($wizard.amounts['%s'] || 0)", rowID)
+	rate := fmt.Sprintf("($wizard.taxRates[$wizard.memberIds['%s']] || 0)", rowID)
+	return fmt.Sprintf("Math.max(Math.min(Math.round(%s * %s / 100), %s), 0)", amount, rate, amount)
+}
+
+// publishEvent tells other tabs that an event changed: the group's pages, the
+// event's and those of memberIDs.
+func publishEvent(c echo.Context, groupID, eventID string, memberIDs ...string) {
+	topics := []string{utils.GroupTopic(groupID), utils.EventTopic(eventID)}
+	for _, memberID := range memberIDs {
+		topics = append(topics, utils.MemberTopic(memberID))
+	}
+	utils.SSEHub.Publish(c, topics...)
+}
+
+// eventMemberIDs lists the members taking part in an event, whose pages show
+// it. A failed lookup only narrows who hears of a change.
+func eventMemberIDs(ctx context.Context, groupID, eventID string) []string {
+	rows, err := eventstore.ListParticipantsByEvent(ctx, eventstore.ListParticipantsByEventParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Warn("event: failed to list participants to publish", "event_id", eventID, "err", err)
+		return nil
+	}
+	return participantMemberIDs(rows)
+}
+
+func participantMemberIDs(rows ...[]eventstore.ListParticipantsByEventRow) []string {
+	ids := make([]string, 0)
+	for _, list := range rows {
+		for _, row := range list {
+			ids = append(ids, row.ID)
+		}
+	}
+	return ids
+}
+
+// eventConflictFields lists, as audit field names, where the saved event
+// differs from the one the form was opened with.
+func eventConflictFields(current db.Event, opened eventData) []string {
+	fields := make([]string, 0)
+	add := func(changed bool, field string) {
+		if changed {
+			fields = append(fields, field)
+		}
+	}
+	add(current.Title != opened.Title, "title")
+	add(eventDateValue(current) != opened.Date, "date")
+	add(eventTimeValue(current) != opened.Time, "event_time")
+	add(current.Place != opened.Place, "place")
+	add(current.Description != opened.Description, "description")
+	add(current.Amount != opened.Amount, "amount")
+	add(current.Currency != opened.Currency, "currency")
+	add(current.ExchangeRate != opened.ExchangeRate, "exchange_rate")
+	add((current.Paid == 1) != opened.Paid, "paid")
+	return fields
+}
+
+// patchEventConflict opens the conflict dialog after a save of form was
+// rejected as stale, with the event's current versions to overwrite with.
+func patchEventConflict(c echo.Context, groupID, eventID string, form eventData) error {
+	ctx := c.Request().Context()
+	current, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		return err
+	}
+
+	opened := form
+	if form.Opened != nil {
+		opened = *form.Opened
+	}
+	labels := make([]string, 0)
+	for _, field := range eventConflictFields(current, opened) {
+		labels = append(labels, audit.FieldLabel(ctx, field))
+	}
+	if form.ParticipantsVersion != 0 && form.ParticipantsVersion != current.ParticipantsVersion {
+		labels = append(labels, ctxi18n.T(ctx, "conflict.participants"))
+	}
+	return utils.PatchConflict(c, labels, map[string]int64{
+		"version":             current.Version,
+		"participantsVersion": current.ParticipantsVersion,
+	})
+}
@@ -0,0 +1,28 @@
+package event
+
+import (
+	"slices"
+	"testing"
+
+	"bandcash/internal/db"
+)
+
+func TestEventConflictFields(t *testing.T) {
+	t.Parallel()
+
+	current := db.Event{Title: "Club night", Date: "2026-05-01", EventTime: "20:00", Place: "Club", Amount: 3000, ExchangeRate: 1, Paid: 1}
+	opened := eventData{Title: "Club night", Date: "2026-05-01", Time: "20:00", Place: "Club", Amount: 3000, ExchangeRate: 1, Paid: true}
+	if got := eventConflictFields(current, opened); len(got) != 0 {
+		t.Errorf("eventConflictFields() = %v; want none", got)
+	}
+
+	current.Title = "Late club night"
+	current.EventTime = "22:00"
+	current.Currency = "EUR"
+	current.ExchangeRate = 395
+	current.Paid = 0
+	want := []string{"title", "event_time", "currency", "exchange_rate", "paid"}
+	if got := eventConflictFields(current, opened); !slices.Equal(got, want) {
+		t.Errorf("eventConflictFields() = %v; want %v", got, want)
+	}
+}
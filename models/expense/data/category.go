@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+
+	"bandcash/internal/db"
+)
+
+// CategoryPresets are the categories every group starts with, in display
+// order. Preset rows keep an empty name until renamed.
+var CategoryPresets = []string{"travel", "equipment", "rehearsal", "marketing"}
+
+// CategoryTotal is what a group spent in one category, in the base currency.
+// CategoryID is empty for uncategorized expenses.
+type CategoryTotal struct {
+	CategoryID string
+	Total      int64
+	Paid       int64
+	Count      int
+}
+
+func ListCategories(ctx context.Context, groupID string) ([]db.ExpenseCategory, error) {
+	rows := make([]db.ExpenseCategory, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("group_id = ?", groupID).
+		OrderExpr("created_at ASC").
+		OrderExpr("rowid ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+func GetCategory(ctx context.Context, arg GetCategoryParams) (db.ExpenseCategory, error) {
+	var row db.ExpenseCategory
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
+
+func CreateCategory(ctx context.Context, arg CreateCategoryParams) (db.ExpenseCategory, error) {
+	row := db.ExpenseCategory{ID: arg.ID, GroupID: arg.GroupID, Name: arg.Name}
+	if _, err := db.Conn(ctx).NewInsert().Model(&row).Exec(ctx); err != nil {
+		return db.ExpenseCategory{}, err
+	}
+	return GetCategory(ctx, GetCategoryParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+// DeleteCategory removes a category; its expenses become uncategorized.
+func DeleteCategory(ctx context.Context, arg DeleteCategoryParams) error {
+	_, err := db.Conn(ctx).NewDelete().Model((*db.ExpenseCategory)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
+	return err
+}
+
+// SumExpensesByCategory totals the expenses of a group per category, for a
+// year or date range. Categories without expenses are left out.
+func SumExpensesByCategory(ctx context.Context, filter CategoryReportFilter) ([]CategoryTotal, error) {
+	rows := make([]db.Expense, 0)
+	q := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Column("category_id", "amount", "paid", "exchange_rate").
+		Where("group_id = ?", filter.GroupID)
+	q = applyDateRangeOrYear(q, filter.From, filter.To, filter.Year, "date")
+	if err := q.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	totals := make([]CategoryTotal, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		categoryID := row.CategoryID.String
+		i, ok := index[categoryID]
+		if !ok {
+			i = len(totals)
+			index[categoryID] = i
+			totals = append(totals, CategoryTotal{CategoryID: categoryID})
+		}
+		amount := BaseAmount(row)
+		totals[i].Total += amount
+		totals[i].Count++
+		if row.Paid == 1 {
+			totals[i].Paid += amount
+		}
+	}
+	return totals, nil
+}
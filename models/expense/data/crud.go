@@ -6,24 +6,25 @@ import (
 	"strings"
 	"time"
 
+	"bandcash/internal/currency"
 	"bandcash/internal/db"
 )
 
 func GetExpense(ctx context.Context, arg GetExpenseParams) (db.Expense, error) {
 	var row db.Expense
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
 	return row, err
 }
 
 func GetExpenseByID(ctx context.Context, id string) (db.Expense, error) {
 	var row db.Expense
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
 	return row, err
 }
 
 func ListExpenses(ctx context.Context, groupID string) ([]db.Expense, error) {
 	rows := make([]db.Expense, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		Model(&rows).
 		Where("group_id = ?", groupID).
 		OrderExpr("date DESC").
@@ -32,27 +33,65 @@ func ListExpenses(ctx context.Context, groupID string) ([]db.Expense, error) {
 	return rows, err
 }
 
+// ListMemberExpenses returns the expenses a member paid out of pocket, newest
+// first.
+func ListMemberExpenses(ctx context.Context, groupID, memberID string) ([]db.Expense, error) {
+	rows := make([]db.Expense, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("group_id = ?", groupID).
+		Where("paid_by_member_id = ?", memberID).
+		OrderExpr("date DESC").
+		OrderExpr("created_at DESC").
+		Scan(ctx)
+	return rows, err
+}
+
+// ListEventExpenses returns the expenses linked to an event, oldest first.
+func ListEventExpenses(ctx context.Context, groupID, eventID string) ([]db.Expense, error) {
+	rows := make([]db.Expense, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("group_id = ?", groupID).
+		Where("event_id = ?", eventID).
+		OrderExpr("date ASC").
+		OrderExpr("created_at ASC").
+		Scan(ctx)
+	return rows, err
+}
+
 func CreateExpense(ctx context.Context, arg CreateExpenseParams) (db.Expense, error) {
+	expense := newExpenseRow(arg)
+	if _, err := db.Conn(ctx).NewInsert().Model(&expense).Exec(ctx); err != nil {
+		return db.Expense{}, err
+	}
+	return GetExpense(ctx, GetExpenseParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+func newExpenseRow(arg CreateExpenseParams) db.Expense {
 	paidAt := paidAtNullable(arg.PaidAt)
 	if arg.Paid == 1 && !paidAt.Valid {
 		paidAt = currentTimestampNullString()
 	}
 
-	expense := db.Expense{
-		ID:          arg.ID,
-		GroupID:     arg.GroupID,
-		Title:       arg.Title,
-		Description: arg.Description,
-		Amount:      arg.Amount,
-		Date:        arg.Date,
-		Paid:        arg.Paid,
-		PaidAt:      paidAt,
-	}
-
-	if _, err := db.BunDB.NewInsert().Model(&expense).Exec(ctx); err != nil {
-		return db.Expense{}, err
+	return db.Expense{
+		ID:             arg.ID,
+		GroupID:        arg.GroupID,
+		Title:          arg.Title,
+		Description:    arg.Description,
+		Amount:         arg.Amount,
+		Currency:       arg.Currency,
+		ExchangeRate:   exchangeRateValue(arg.ExchangeRate),
+		Date:           arg.Date,
+		Paid:           arg.Paid,
+		PaidAt:         paidAt,
+		RecurrenceID:   arg.RecurrenceID,
+		RecurrenceDate: arg.RecurrenceDate,
+		PaidByMemberID: nullableString(arg.PaidByMemberID),
+		CategoryID:     nullableString(arg.CategoryID),
+		EventID:        nullableString(arg.EventID),
+		Version:        1,
 	}
-	return GetExpense(ctx, GetExpenseParams{ID: arg.ID, GroupID: arg.GroupID})
 }
 
 func UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (db.Expense, error) {
@@ -60,6 +99,9 @@ func UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (db.Expense, er
 	if err != nil {
 		return db.Expense{}, err
 	}
+	if err := db.CheckVersion("expenses", arg.ID, arg.Version, current.Version); err != nil {
+		return db.Expense{}, err
+	}
 
 	paidAtInput := paidAtNullable(arg.PaidAt)
 	finalPaidAt := current.PaidAt
@@ -71,29 +113,41 @@ func UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (db.Expense, er
 		finalPaidAt = currentTimestampNullString()
 	}
 
-	_, err = db.BunDB.NewUpdate().Model((*db.Expense)(nil)).
+	q := db.Conn(ctx).NewUpdate().Model((*db.Expense)(nil)).
 		Set("title = ?", arg.Title).
 		Set("description = ?", arg.Description).
 		Set("amount = ?", arg.Amount).
+		Set("currency = ?", arg.Currency).
+		Set("exchange_rate = ?", exchangeRateValue(arg.ExchangeRate)).
 		Set("date = ?", arg.Date).
 		Set("paid = ?", arg.Paid).
 		Set("paid_at = ?", paidAtValue(finalPaidAt)).
+		Set("paid_by_member_id = ?", nullableString(arg.PaidByMemberID)).
+		Set("category_id = ?", nullableString(arg.CategoryID)).
+		Set("event_id = ?", nullableString(arg.EventID)).
 		Where("id = ?", arg.ID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
+		Where("group_id = ?", arg.GroupID)
+	if arg.Version != 0 {
+		// Also catches a save that landed after the check above.
+		q = q.Where("version = ?", arg.Version)
+	}
+	res, err := q.Exec(ctx)
 	if err != nil {
 		return db.Expense{}, err
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 && arg.Version != 0 {
+		return db.Expense{}, &db.ConflictError{Table: "expenses", ID: arg.ID}
+	}
 	return GetExpense(ctx, GetExpenseParams{ID: arg.ID, GroupID: arg.GroupID})
 }
 
 func DeleteExpense(ctx context.Context, arg DeleteExpenseParams) error {
-	_, err := db.BunDB.NewDelete().Model((*db.Expense)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().Model((*db.Expense)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
 	return err
 }
 
 func DeleteExpenseByID(ctx context.Context, id string) error {
-	_, err := db.BunDB.NewDelete().Model((*db.Expense)(nil)).Where("id = ?", id).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().Model((*db.Expense)(nil)).Where("id = ?", id).Exec(ctx)
 	return err
 }
 
@@ -110,7 +164,7 @@ func ToggleExpensePaid(ctx context.Context, arg ToggleExpensePaidParams) (db.Exp
 		nextPaidAt = sql.NullString{}
 	}
 
-	_, err = db.BunDB.NewUpdate().Model((*db.Expense)(nil)).
+	_, err = db.Conn(ctx).NewUpdate().Model((*db.Expense)(nil)).
 		Set("paid = ?", nextPaid).
 		Set("paid_at = ?", paidAtValue(nextPaidAt)).
 		Where("id = ?", arg.ID).
@@ -157,6 +211,25 @@ func currentTimestampNullString() sql.NullString {
 	return sql.NullString{String: time.Now().UTC().Format("2006-01-02 15:04:05"), Valid: true}
 }
 
+// BaseAmount is the expense amount converted into the group's base currency.
+func BaseAmount(expense db.Expense) int64 {
+	return currency.ToBase(expense.Amount, expense.ExchangeRate)
+}
+
+// exchangeRateValue keeps invalid rates out of the CHECK constraint.
+func exchangeRateValue(rate float64) float64 {
+	if !currency.ValidRate(rate) {
+		return 1
+	}
+	return rate
+}
+
+// nullableString stores an empty optional reference as NULL.
+func nullableString(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 func paidAtValue(v sql.NullString) any {
 	if v.Valid {
 		return v.String
@@ -9,11 +9,13 @@ import (
 )
 
 type ExpenseTableFilter struct {
-	GroupID string
-	Search  string
-	Year    string
-	From    string
-	To      string
+	GroupID    string
+	Search     string
+	CategoryID string
+	Tag        string
+	Year       string
+	From       string
+	To         string
 }
 
 type ExpenseTableListParams struct {
@@ -30,7 +32,7 @@ type ExpenseTotals struct {
 }
 
 func CountExpensesTable(ctx context.Context, filter ExpenseTableFilter) (int64, error) {
-	q := db.BunDB.NewSelect().TableExpr("expenses")
+	q := db.Conn(ctx).NewSelect().TableExpr("expenses")
 	q = applyExpenseTableFilters(q, filter)
 	n, err := q.Count(ctx)
 	return int64(n), err
@@ -38,7 +40,7 @@ func CountExpensesTable(ctx context.Context, filter ExpenseTableFilter) (int64,
 
 func ListExpensesTable(ctx context.Context, params ExpenseTableListParams) ([]db.Expense, error) {
 	rows := make([]db.Expense, 0)
-	q := db.BunDB.NewSelect().Model(&rows)
+	q := db.Conn(ctx).NewSelect().Model(&rows)
 	q = applyExpenseTableFilters(q, params.ExpenseTableFilter)
 	q = orderExpenses(q, params.Sort, params.Dir)
 	if params.Limit > 0 {
@@ -53,9 +55,9 @@ func ListExpensesTable(ctx context.Context, params ExpenseTableListParams) ([]db
 
 func SumExpenseTotalsTable(ctx context.Context, filter ExpenseTableFilter) (ExpenseTotals, error) {
 	rows := make([]db.Expense, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		Model(&rows).
-		Column("amount", "paid")
+		Column("amount", "paid", "exchange_rate")
 	q = applyExpenseTableFilters(q, filter)
 	if err := q.Scan(ctx); err != nil {
 		return ExpenseTotals{}, err
@@ -63,9 +65,10 @@ func SumExpenseTotalsTable(ctx context.Context, filter ExpenseTableFilter) (Expe
 
 	totals := ExpenseTotals{}
 	for _, row := range rows {
-		totals.Total += row.Amount
+		amount := BaseAmount(row)
+		totals.Total += amount
 		if row.Paid == 1 {
-			totals.Paid += row.Amount
+			totals.Paid += amount
 		}
 	}
 	return totals, nil
@@ -79,6 +82,12 @@ func applyExpenseTableFilters(q *bun.SelectQuery, filter ExpenseTableFilter) *bu
 			return qq.Where("title LIKE ?", like).WhereOr("description LIKE ?", like)
 		})
 	})
+	if filter.CategoryID != "" {
+		q = q.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.Tag != "" {
+		q = q.Where("id IN (SELECT expense_id FROM expense_tags WHERE tag = ?)", filter.Tag)
+	}
 	return applyDateRangeOrYear(q, filter.From, filter.To, filter.Year, "date")
 }
 
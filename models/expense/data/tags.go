@@ -0,0 +1,106 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+// MaxTagLength caps a single tag, in characters.
+const MaxTagLength = 30
+
+// NormalizeTag lowercases and trims a tag, cutting it to MaxTagLength.
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if utf8.RuneCountInString(tag) > MaxTagLength {
+		tag = strings.TrimSpace(string([]rune(tag)[:MaxTagLength]))
+	}
+	return tag
+}
+
+// ParseTags splits comma-separated tags, normalizing each and dropping
+// blanks and duplicates.
+func ParseTags(raw string) []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		tag := NormalizeTag(part)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// SetExpenseTags replaces the tags of an expense.
+func SetExpenseTags(ctx context.Context, groupID, expenseID string, tags []string) error {
+	return db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return setExpenseTagsTx(ctx, tx, groupID, expenseID, tags)
+	})
+}
+
+func setExpenseTagsTx(ctx context.Context, tx bun.Tx, groupID, expenseID string, tags []string) error {
+	_, err := tx.NewDelete().Model((*db.ExpenseTag)(nil)).Where("expense_id = ?", expenseID).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]db.ExpenseTag, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, db.ExpenseTag{ExpenseID: expenseID, GroupID: groupID, Tag: tag})
+	}
+	_, err = tx.NewInsert().Model(&rows).Exec(ctx)
+	return err
+}
+
+func ListExpenseTags(ctx context.Context, expenseID string) ([]string, error) {
+	tags := make([]string, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model((*db.ExpenseTag)(nil)).
+		Column("tag").
+		Where("expense_id = ?", expenseID).
+		OrderExpr("tag ASC").
+		Scan(ctx, &tags)
+	return tags, err
+}
+
+// ListTagsByExpense returns the tags of the given expenses, keyed by expense.
+func ListTagsByExpense(ctx context.Context, expenseIDs []string) (map[string][]string, error) {
+	tags := make(map[string][]string, len(expenseIDs))
+	if len(expenseIDs) == 0 {
+		return tags, nil
+	}
+	rows := make([]db.ExpenseTag, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("expense_id IN (?)", bun.In(expenseIDs)).
+		OrderExpr("tag ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tags[row.ExpenseID] = append(tags[row.ExpenseID], row.Tag)
+	}
+	return tags, nil
+}
+
+// ListGroupTags returns every tag used in a group, alphabetically.
+func ListGroupTags(ctx context.Context, groupID string) ([]string, error) {
+	tags := make([]string, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model((*db.ExpenseTag)(nil)).
+		ColumnExpr("DISTINCT tag").
+		Where("group_id = ?", groupID).
+		OrderExpr("tag ASC").
+		Scan(ctx, &tags)
+	return tags, err
+}
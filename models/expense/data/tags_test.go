@@ -0,0 +1,22 @@
+package data
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	t.Parallel()
+
+	got := ParseTags(" Tour, van,,tour ,  ")
+	want := []string{"tour", "van"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ParseTags() = %v; want %v", got, want)
+	}
+
+	long := strings.Repeat("é", MaxTagLength+5)
+	if got := ParseTags(long); len(got) != 1 || got[0] != strings.Repeat("é", MaxTagLength) {
+		t.Fatalf("ParseTags(long) = %v; want one tag of %d runes", got, MaxTagLength)
+	}
+}
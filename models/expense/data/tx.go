@@ -0,0 +1,19 @@
+package data
+
+import (
+	"context"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+func CreateExpenseTx(ctx context.Context, tx bun.Tx, arg CreateExpenseParams) (db.Expense, error) {
+	expense := newExpenseRow(arg)
+	if _, err := tx.NewInsert().Model(&expense).Exec(ctx); err != nil {
+		return db.Expense{}, err
+	}
+
+	var row db.Expense
+	err := tx.NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
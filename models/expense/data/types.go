@@ -1,30 +1,46 @@
 package data
 
+import "database/sql"
+
 type GetExpenseParams struct {
 	ID      string `json:"id"`
 	GroupID string `json:"group_id"`
 }
 
 type CreateExpenseParams struct {
-	ID          string      `json:"id"`
-	GroupID     string      `json:"group_id"`
-	Title       string      `json:"title"`
-	Description string      `json:"description"`
-	Amount      int64       `json:"amount"`
-	Date        string      `json:"date"`
-	Paid        int64       `json:"paid"`
-	PaidAt      interface{} `json:"paid_at"`
+	ID             string         `json:"id"`
+	GroupID        string         `json:"group_id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Amount         int64          `json:"amount"`
+	Currency       string         `json:"currency"`
+	ExchangeRate   float64        `json:"exchange_rate"`
+	Date           string         `json:"date"`
+	Paid           int64          `json:"paid"`
+	PaidAt         interface{}    `json:"paid_at"`
+	RecurrenceID   sql.NullString `json:"recurrence_id"`
+	RecurrenceDate string         `json:"recurrence_date"`
+	PaidByMemberID string         `json:"paid_by_member_id"`
+	CategoryID     string         `json:"category_id"`
+	EventID        string         `json:"event_id"`
 }
 
 type UpdateExpenseParams struct {
-	Title       string      `json:"title"`
-	Description string      `json:"description"`
-	Amount      int64       `json:"amount"`
-	Date        string      `json:"date"`
-	Paid        int64       `json:"paid"`
-	PaidAt      interface{} `json:"paid_at"`
-	ID          string      `json:"id"`
-	GroupID     string      `json:"group_id"`
+	Title          string      `json:"title"`
+	Description    string      `json:"description"`
+	Amount         int64       `json:"amount"`
+	Currency       string      `json:"currency"`
+	ExchangeRate   float64     `json:"exchange_rate"`
+	Date           string      `json:"date"`
+	Paid           int64       `json:"paid"`
+	PaidAt         interface{} `json:"paid_at"`
+	PaidByMemberID string      `json:"paid_by_member_id"`
+	CategoryID     string      `json:"category_id"`
+	EventID        string      `json:"event_id"`
+	ID             string      `json:"id"`
+	GroupID        string      `json:"group_id"`
+	// Version is the one the edit form was opened at. Zero skips the check.
+	Version int64 `json:"version"`
 }
 
 type DeleteExpenseParams struct {
@@ -36,3 +52,26 @@ type ToggleExpensePaidParams struct {
 	ID      string `json:"id"`
 	GroupID string `json:"group_id"`
 }
+
+type GetCategoryParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
+
+type CreateCategoryParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+	Name    string `json:"name"`
+}
+
+type DeleteCategoryParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
+
+type CategoryReportFilter struct {
+	GroupID string
+	Year    string
+	From    string
+	To      string
+}
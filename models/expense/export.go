@@ -0,0 +1,64 @@
+package expense
+
+import (
+	"context"
+	"strconv"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/currency"
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+	expensestore "bandcash/models/expense/data"
+	groupstore "bandcash/models/group/data"
+)
+
+var exportColumns = []string{"date", "title", "description", "amount", "currency", "exchange_rate", "paid", "paid_at"}
+
+// exportRecords lists the expenses matching query, header first.
+func exportRecords(ctx context.Context, groupID string, query utils.TableQuery) ([][]string, spreadsheet.Format, error) {
+	format := spreadsheet.FormatForLocale(appi18n.LocaleCode(ctx))
+
+	expenses, err := expensestore.ListExpensesTable(ctx, expensestore.ExpenseTableListParams{
+		ExpenseTableFilter: expensestore.ExpenseTableFilter{
+			GroupID:    groupID,
+			Search:     query.Search,
+			CategoryID: query.Category,
+			Tag:        query.Tag,
+			Year:       query.Year,
+			From:       query.From,
+			To:         query.To,
+		},
+		Sort: query.Sort,
+		Dir:  query.Dir,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	header := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = ctxi18n.T(ctx, "csv.columns."+column)
+	}
+	records := [][]string{header}
+
+	for _, expense := range expenses {
+		records = append(records, []string{
+			expense.Date,
+			expense.Title,
+			expense.Description,
+			strconv.FormatInt(expense.Amount, 10),
+			currency.Of(expense.Currency, group.BaseCurrency),
+			spreadsheet.FormatRate(expense.ExchangeRate, format),
+			spreadsheet.FormatBool(expense.Paid == 1, format),
+			utils.FormatDateInput(expense.PaidAt.String),
+		})
+	}
+	return records, format, nil
+}
@@ -1,6 +1,8 @@
 package expense
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -8,9 +10,19 @@ import (
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 	"github.com/labstack/echo/v4"
 	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
 
+	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	"bandcash/models/attachment"
+	attachmentstore "bandcash/models/attachment/data"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
 	expensestore "bandcash/models/expense/data"
+	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
+	"bandcash/models/recurrence"
+	recurrencestore "bandcash/models/recurrence/data"
 )
 
 var (
@@ -18,10 +30,10 @@ var (
 		"mode":      "table",
 		"formState": "",
 		"editingId": "",
-		"formData":  map[string]any{"title": "", "description": "", "amount": 0, "date": "", "paid": false, "paidAt": ""},
-		"errors":    map[string]any{"title": "", "description": "", "amount": "", "date": ""},
+		"formData":  map[string]any{"title": "", "description": "", "amount": 0, "currency": "", "exchangeRate": 1, "date": "", "paid": false, "paidAt": "", "paidBy": "", "categoryId": "", "eventId": "", "tags": ""},
+		"errors":    map[string]any{"title": "", "description": "", "amount": "", "currency": "", "exchangeRate": "", "date": "", "paidBy": "", "categoryId": "", "eventId": "", "tags": ""},
 	}
-	expenseErrorFields = []string{"title", "description", "amount", "date"}
+	expenseErrorFields = []string{"title", "description", "amount", "currency", "exchangeRate", "date", "paidBy", "categoryId", "eventId", "tags"}
 )
 
 func Create(c echo.Context) error {
@@ -40,32 +52,63 @@ func Create(c echo.Context) error {
 	signals.FormData.Description = strings.TrimSpace(signals.FormData.Description)
 	signals.FormData.Date = strings.TrimSpace(signals.FormData.Date)
 	signals.FormData.PaidAt = normalizePaidAtInput(signals.FormData.PaidAt)
+	signals.FormData.PaidBy = strings.TrimSpace(signals.FormData.PaidBy)
+	signals.FormData.CategoryID = strings.TrimSpace(signals.FormData.CategoryID)
+	signals.FormData.EventID = strings.TrimSpace(signals.FormData.EventID)
 
 	if errs := utils.ValidateWithLocale(c.Request().Context(), signals.FormData); errs != nil {
 		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(expenseErrorFields, errs)})
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
+	if !validPaidBy(c, groupID, signals.FormData.PaidBy) || !validCategory(c, groupID, signals.FormData.CategoryID) || !validEvent(c, groupID, signals.FormData.EventID) {
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
 
-	_, err = expensestore.CreateExpense(c.Request().Context(), expensestore.CreateExpenseParams{
-		ID:          utils.GenerateID(utils.PrefixExpense),
-		GroupID:     groupID,
-		Title:       signals.FormData.Title,
-		Description: signals.FormData.Description,
-		Amount:      signals.FormData.Amount,
-		Date:        signals.FormData.Date,
-		Paid: func() int64 {
-			if signals.FormData.Paid {
-				return 1
-			}
-			return 0
-		}(),
-		PaidAt: paidAtArg(signals.FormData.Paid, signals.FormData.PaidAt),
+	entryCurrency, exchangeRate, err := groupstore.EntryCurrency(c.Request().Context(), groupID, signals.FormData.Currency, signals.FormData.ExchangeRate)
+	if err != nil {
+		slog.Error("expense.create.table: failed to resolve currency", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var expense db.Expense
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		expense, err = expensestore.CreateExpense(ctx, expensestore.CreateExpenseParams{
+			ID:           utils.GenerateID(utils.PrefixExpense),
+			GroupID:      groupID,
+			Title:        signals.FormData.Title,
+			Description:  signals.FormData.Description,
+			Amount:       signals.FormData.Amount,
+			Currency:     entryCurrency,
+			ExchangeRate: exchangeRate,
+			Date:         signals.FormData.Date,
+			Paid: func() int64 {
+				if signals.FormData.Paid {
+					return 1
+				}
+				return 0
+			}(),
+			PaidAt:         paidAtArg(signals.FormData.Paid, signals.FormData.PaidAt),
+			PaidByMemberID: signals.FormData.PaidBy,
+			CategoryID:     signals.FormData.CategoryID,
+			EventID:        signals.FormData.EventID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterExpense, EntityID: expense.ID, After: expense})
 	})
 	if err != nil {
 		slog.Error("expense.create.table: failed to create expense", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.create_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	if err := expensestore.SetExpenseTags(c.Request().Context(), groupID, expense.ID, expensestore.ParseTags(signals.FormData.Tags)); err != nil {
+		slog.Error("expense.create.table: failed to set tags", "expense_id", expense.ID, "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	publishExpense(c, groupID, expense)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.created"))
 
@@ -101,32 +144,93 @@ func Update(c echo.Context) error {
 	signals.FormData.Description = strings.TrimSpace(signals.FormData.Description)
 	signals.FormData.Date = strings.TrimSpace(signals.FormData.Date)
 	signals.FormData.PaidAt = normalizePaidAtInput(signals.FormData.PaidAt)
+	signals.FormData.PaidBy = strings.TrimSpace(signals.FormData.PaidBy)
+	signals.FormData.CategoryID = strings.TrimSpace(signals.FormData.CategoryID)
+	signals.FormData.EventID = strings.TrimSpace(signals.FormData.EventID)
 
 	if errs := utils.ValidateWithLocale(c.Request().Context(), signals.FormData); errs != nil {
 		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(expenseErrorFields, errs)})
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
+	if !validPaidBy(c, groupID, signals.FormData.PaidBy) || !validCategory(c, groupID, signals.FormData.CategoryID) || !validEvent(c, groupID, signals.FormData.EventID) {
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
 
-	_, err = expensestore.UpdateExpense(c.Request().Context(), expensestore.UpdateExpenseParams{
-		Title:       signals.FormData.Title,
-		Description: signals.FormData.Description,
-		Amount:      signals.FormData.Amount,
-		Date:        signals.FormData.Date,
-		Paid: func() int64 {
-			if signals.FormData.Paid {
-				return 1
+	before, err := expensestore.GetExpense(c.Request().Context(), expensestore.GetExpenseParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("expense.update: failed to get expense", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	entryCurrency, exchangeRate, err := groupstore.EntryCurrency(c.Request().Context(), groupID, signals.FormData.Currency, signals.FormData.ExchangeRate)
+	if err != nil {
+		slog.Error("expense.update: failed to resolve currency", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var updated db.Expense
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = expensestore.UpdateExpense(ctx, expensestore.UpdateExpenseParams{
+			Title:        signals.FormData.Title,
+			Description:  signals.FormData.Description,
+			Amount:       signals.FormData.Amount,
+			Currency:     entryCurrency,
+			ExchangeRate: exchangeRate,
+			Date:         signals.FormData.Date,
+			Paid: func() int64 {
+				if signals.FormData.Paid {
+					return 1
+				}
+				return 0
+			}(),
+			PaidAt:         paidAtArg(signals.FormData.Paid, signals.FormData.PaidAt),
+			PaidByMemberID: signals.FormData.PaidBy,
+			CategoryID:     signals.FormData.CategoryID,
+			EventID:        signals.FormData.EventID,
+			ID:             id,
+			GroupID:        groupID,
+			Version:        signals.FormData.Version,
+		})
+		if err != nil {
+			return err
+		}
+		if err := expensestore.SetExpenseTags(ctx, groupID, id, expensestore.ParseTags(signals.FormData.Tags)); err != nil {
+			return fmt.Errorf("set tags: %w", err)
+		}
+		changes := []audit.Change{{Action: audit.ActionUpdate, Entity: utils.EntityFilterExpense, EntityID: id, Before: before, After: updated}}
+
+		if signals.FormData.Scope == seriesScopeFuture && updated.RecurrenceID.Valid {
+			series, err := recurrence.SeriesChanges(ctx, recurrencestore.KindExpense, updated.RecurrenceID.String, groupID, func() error {
+				return recurrencestore.ApplyExpenseSeries(ctx, recurrencestore.ApplySeriesParams{
+					RecurrenceID: updated.RecurrenceID.String,
+					GroupID:      groupID,
+					FromDate:     updated.RecurrenceDate,
+					Title:        signals.FormData.Title,
+					Description:  signals.FormData.Description,
+					Amount:       signals.FormData.Amount,
+				})
+			})
+			if err != nil {
+				return fmt.Errorf("update series %s: %w", updated.RecurrenceID.String, err)
 			}
-			return 0
-		}(),
-		PaidAt:  paidAtArg(signals.FormData.Paid, signals.FormData.PaidAt),
-		ID:      id,
-		GroupID: groupID,
+			changes = append(changes, series...)
+		}
+		return audit.Record(ctx, c, changes...)
 	})
+	if db.IsConflict(err) {
+		if err := patchExpenseConflict(c, groupID, id, signals.FormData); err != nil {
+			slog.Error("expense.update: failed to open conflict dialog", "err", err)
+		}
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("expense.update: failed to update expense", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishExpense(c, groupID, before, updated)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.updated"))
 
@@ -159,15 +263,33 @@ func Destroy(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	err = expensestore.DeleteExpense(c.Request().Context(), expensestore.DeleteExpenseParams{
-		ID:      id,
-		GroupID: groupID,
+	before, err := expensestore.GetExpense(c.Request().Context(), expensestore.GetExpenseParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("expense.destroy: failed to get expense", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// Attachment rows cascade with the expense; their blobs are released below.
+	attachmentHashes := attachment.EntityHashes(c.Request().Context(), groupID, attachmentstore.EntityExpense, id)
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		err := expensestore.DeleteExpense(ctx, expensestore.DeleteExpenseParams{
+			ID:      id,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionDelete, Entity: utils.EntityFilterExpense, EntityID: id, Before: before})
 	})
 	if err != nil {
 		slog.Error("expense.destroy: failed to delete expense", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.delete_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	attachment.ReleaseBlobs(c.Request().Context(), attachmentHashes...)
+	publishExpense(c, groupID, before)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.deleted"))
 
@@ -325,21 +447,34 @@ func patchUpdatePaidAt(c echo.Context, groupID, id, mode string, tableQuery util
 		paid = 1
 	}
 
-	_, err = expensestore.UpdateExpense(c.Request().Context(), expensestore.UpdateExpenseParams{
-		Title:       expense.Title,
-		Description: expense.Description,
-		Amount:      expense.Amount,
-		Date:        expense.Date,
-		Paid:        paid,
-		PaidAt:      paidAt,
-		ID:          id,
-		GroupID:     groupID,
+	var updated db.Expense
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = expensestore.UpdateExpense(ctx, expensestore.UpdateExpenseParams{
+			Title:          expense.Title,
+			Description:    expense.Description,
+			Amount:         expense.Amount,
+			Currency:       expense.Currency,
+			ExchangeRate:   expense.ExchangeRate,
+			Date:           expense.Date,
+			Paid:           paid,
+			PaidAt:         paidAt,
+			PaidByMemberID: expense.PaidByMemberID.String,
+			CategoryID:     expense.CategoryID.String,
+			EventID:        expense.EventID.String,
+			ID:             id,
+			GroupID:        groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterExpense, EntityID: id, Before: expense, After: updated})
 	})
 	if err != nil {
 		slog.Error("expense.updatePaidAt: failed to update paid_at", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishExpense(c, groupID, updated)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
@@ -464,15 +599,30 @@ func TogglePaid(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	_, err = expensestore.ToggleExpensePaid(c.Request().Context(), expensestore.ToggleExpensePaidParams{
-		ID:      id,
-		GroupID: groupID,
+	before, err := expensestore.GetExpense(c.Request().Context(), expensestore.GetExpenseParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("expense.togglePaid: failed to get expense", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.toggle_paid_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var updated db.Expense
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err = expensestore.ToggleExpensePaid(ctx, expensestore.ToggleExpensePaidParams{
+			ID:      id,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updated.Paid), Entity: utils.EntityFilterExpense, EntityID: id, Before: before, After: updated})
 	})
 	if err != nil {
 		slog.Error("expense.togglePaid: failed to toggle paid status", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishExpense(c, groupID, updated)
 
 	slog.Debug("expense.togglePaid", "id", id)
 
@@ -517,3 +667,53 @@ func TogglePaid(c echo.Context) error {
 	utils.SSEHub.PatchHTML(c, html)
 	return c.NoContent(http.StatusOK)
 }
+
+// validPaidBy checks that the member who paid an expense belongs to the group,
+// patching a field error when not. An empty id means the band paid.
+func validPaidBy(c echo.Context, groupID, memberID string) bool {
+	if memberID == "" {
+		return true
+	}
+	ctx := c.Request().Context()
+	if _, err := memberstore.GetMember(ctx, memberstore.GetMemberParams{ID: memberID, GroupID: groupID}); err != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(expenseErrorFields, map[string]string{
+			"paidBy": ctxi18n.T(ctx, "validation.required"),
+		})})
+		return false
+	}
+	return true
+}
+
+// validCategory checks that an expense category belongs to the group,
+// patching a field error when not. An empty id leaves the expense
+// uncategorized.
+func validCategory(c echo.Context, groupID, categoryID string) bool {
+	if categoryID == "" {
+		return true
+	}
+	ctx := c.Request().Context()
+	if _, err := expensestore.GetCategory(ctx, expensestore.GetCategoryParams{ID: categoryID, GroupID: groupID}); err != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(expenseErrorFields, map[string]string{
+			"categoryId": ctxi18n.T(ctx, "validation.required"),
+		})})
+		return false
+	}
+	return true
+}
+
+// validEvent checks that the event an expense is linked to belongs to the
+// group, patching a field error when not. An empty id leaves the expense
+// unlinked.
+func validEvent(c echo.Context, groupID, eventID string) bool {
+	if eventID == "" {
+		return true
+	}
+	ctx := c.Request().Context()
+	if _, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID}); err != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(expenseErrorFields, map[string]string{
+			"eventId": ctxi18n.T(ctx, "validation.required"),
+		})})
+		return false
+	}
+	return true
+}
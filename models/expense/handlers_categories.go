@@ -0,0 +1,125 @@
+package expense
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/utils"
+	expensestore "bandcash/models/expense/data"
+)
+
+type categoryParams struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+type categorySignals struct {
+	TabID        string         `json:"tab_id"`
+	CategoryForm categoryParams `json:"categoryForm"`
+}
+
+var categoryErrorFields = []string{"name"}
+
+func categoriesSignals() map[string]any {
+	return map[string]any{
+		"categoryForm":   map[string]any{"name": ""},
+		"categoryErrors": map[string]any{"name": ""},
+	}
+}
+
+func CategoriesPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+	query := utils.ParseTableQuery(c, staticTableQueryable{spec: TableQuerySpec()})
+
+	data, err := GetCategoriesData(c.Request().Context(), groupID, query)
+	if err != nil {
+		slog.Error("expense.categories: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = categoriesSignals()
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, ExpenseCategoriesPage(data))
+}
+
+func CreateCategory(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	var signals categorySignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("expense.create_category: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := signals.CategoryForm
+	form.Name = strings.TrimSpace(form.Name)
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"categoryErrors": utils.WithErrors(categoryErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	_, err := expensestore.CreateCategory(ctx, expensestore.CreateCategoryParams{
+		ID:      utils.GenerateID(utils.PrefixCategory),
+		GroupID: groupID,
+		Name:    form.Name,
+	})
+	if err != nil {
+		slog.Error("expense.create_category: failed to create category", "group_id", groupID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "expenses.categories.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.InvalidateGroupCaches(groupID)
+	utils.Notify(c, ctxi18n.T(ctx, "expenses.categories.notifications.created"))
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/expenses/categories"); err != nil {
+		slog.Warn("expense.create_category: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func DeleteCategory(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixCategory) {
+		slog.Info("expense.delete_category: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals categorySignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("expense.delete_category: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	err := expensestore.DeleteCategory(ctx, expensestore.DeleteCategoryParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("expense.delete_category: failed to delete category", "group_id", groupID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "expenses.categories.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.InvalidateGroupCaches(groupID)
+	utils.Notify(c, ctxi18n.T(ctx, "expenses.categories.notifications.deleted"))
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/expenses/categories"); err != nil {
+		slog.Warn("expense.delete_category: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
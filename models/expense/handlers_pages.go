@@ -1,15 +1,21 @@
 package expense
 
 import (
+	"bytes"
 	"log/slog"
+	"mime"
 	"net/http"
+	"time"
 
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 	"github.com/labstack/echo/v4"
 
+	"bandcash/internal/spreadsheet"
 	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
 	expensestore "bandcash/models/expense/data"
 	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
 )
 
 func NewExpensePage(c echo.Context) error {
@@ -22,6 +28,30 @@ func NewExpensePage(c echo.Context) error {
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	members, err := memberstore.ListMembers(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("expense.new_page: failed to list members", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	categories, err := expensestore.ListCategories(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("expense.new_page: failed to list categories", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	events, err := eventstore.ListEventOptions(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("expense.new_page: failed to list events", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// Linking from an event's page preselects that event.
+	eventID := c.QueryParam("event")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		eventID = ""
+	}
+
 	data := NewExpensePageData{
 		Title: ctxi18n.T(c.Request().Context(), "expenses.page_title"),
 		Breadcrumbs: []utils.Crumb{
@@ -30,10 +60,14 @@ func NewExpensePage(c echo.Context) error {
 			{Label: ctxi18n.T(c.Request().Context(), "expenses.title"), Href: "/groups/" + groupID + "/expenses"},
 			{Label: ctxi18n.T(c.Request().Context(), "expenses.add")},
 		},
-		GroupID: groupID,
+		GroupID:      groupID,
+		BaseCurrency: group.BaseCurrency,
+		Members:      members,
+		Categories:   categories,
+		Events:       events,
 		Signals: map[string]any{
-			"formData": map[string]any{"title": "", "description": "", "amount": 0, "date": "", "paid": false, "paidAt": ""},
-			"errors":   map[string]any{"title": "", "description": "", "amount": "", "date": ""},
+			"formData": map[string]any{"title": "", "description": "", "amount": 0, "currency": "", "exchangeRate": 1, "date": "", "paid": false, "paidAt": "", "paidBy": "", "categoryId": "", "eventId": eventID, "tags": ""},
+			"errors":   map[string]any{"title": "", "description": "", "amount": "", "currency": "", "exchangeRate": "", "date": "", "paidBy": "", "categoryId": "", "eventId": "", "tags": ""},
 		},
 		IsAuthenticated: true,
 		IsSuperAdmin:    utils.IsSuperadmin(c),
@@ -66,6 +100,30 @@ func EditExpensePage(c echo.Context) error {
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	members, err := memberstore.ListMembers(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("expense.edit_page: failed to list members", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	categories, err := expensestore.ListCategories(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("expense.edit_page: failed to list categories", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	events, err := eventstore.ListEventOptions(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("expense.edit_page: failed to list events", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	tags, err := expensestore.ListExpenseTags(c.Request().Context(), id)
+	if err != nil {
+		slog.Error("expense.edit_page: failed to list tags", "expense_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
 	data := EditExpensePageData{
 		Title: ctxi18n.T(c.Request().Context(), "expenses.page_title"),
 		Breadcrumbs: []utils.Crumb{
@@ -75,27 +133,20 @@ func EditExpensePage(c echo.Context) error {
 			{Label: expense.Title, Href: "/groups/" + groupID + "/expenses/" + id},
 			{Label: ctxi18n.T(c.Request().Context(), "expenses.edit")},
 		},
-		GroupID: groupID,
-		Expense: &expense,
+		GroupID:      groupID,
+		BaseCurrency: group.BaseCurrency,
+		Expense:      &expense,
+		Members:      members,
+		Categories:   categories,
+		Events:       events,
 		Signals: map[string]any{
-			"formData": map[string]any{
-				"title":       expense.Title,
-				"description": expense.Description,
-				"amount":      expense.Amount,
-				"date":        expense.Date,
-				"paid":        expense.Paid == 1,
-				"paidAt": func() string {
-					if !expense.PaidAt.Valid {
-						return ""
-					}
-					return utils.FormatDateInput(expense.PaidAt.String)
-				}(),
-			},
-			"errors": map[string]any{"title": "", "description": "", "amount": "", "date": ""},
+			"formData": expenseEditFormData(expense, tags),
+			"errors":   map[string]any{"title": "", "description": "", "amount": "", "currency": "", "exchangeRate": "", "date": "", "paidBy": "", "categoryId": "", "eventId": "", "tags": ""},
 		},
 		IsAuthenticated: true,
 		IsSuperAdmin:    utils.IsSuperadmin(c),
 	}
+	utils.SSEHub.Join(c, utils.ExpenseTopic(id), false)
 	return utils.RenderPage(c, ExpenseEditPage(data))
 }
 
@@ -117,6 +168,29 @@ func IndexPage(c echo.Context) error {
 	return utils.RenderPage(c, ExpenseIndexPage(data))
 }
 
+// Export downloads the expenses matching the current table filters as CSV.
+// Paging is ignored so the file holds every matching expense.
+func Export(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	query := utils.ParseTableQuery(c, staticTableQueryable{spec: TableQuerySpec()})
+
+	records, format, err := exportRecords(c.Request().Context(), groupID, query)
+	if err != nil {
+		slog.Error("expense.export: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, format, records); err != nil {
+		slog.Error("expense.export: failed to write csv", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	fileName := "expenses-" + time.Now().Format("2006-01-02") + ".csv"
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
 func ShowPage(c echo.Context) error {
 	utils.EnsureTabID(c)
 	groupID := utils.GetGroupID(c)
@@ -137,5 +211,6 @@ func ShowPage(c echo.Context) error {
 	data.IsAuthenticated = true
 	data.IsSuperAdmin = utils.IsSuperadmin(c)
 
+	utils.SSEHub.Join(c, utils.ExpenseTopic(id), false)
 	return utils.RenderPage(c, ExpenseShowPage(data))
 }
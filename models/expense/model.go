@@ -7,8 +7,11 @@ import (
 
 	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	attachmentstore "bandcash/models/attachment/data"
+	eventstore "bandcash/models/event/data"
 	expensestore "bandcash/models/expense/data"
 	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
 )
 
 func TableQuerySpec() utils.TableQuerySpec {
@@ -26,11 +29,13 @@ func GetIndexData(ctx context.Context, groupID string, query utils.TableQuery) (
 	}
 
 	filters := expensestore.ExpenseTableFilter{
-		GroupID: groupID,
-		Search:  query.Search,
-		Year:    query.Year,
-		From:    query.From,
-		To:      query.To,
+		GroupID:    groupID,
+		Search:     query.Search,
+		CategoryID: query.Category,
+		Tag:        query.Tag,
+		Year:       query.Year,
+		From:       query.From,
+		To:         query.To,
 	}
 
 	totalItems, err := expensestore.CountExpensesTable(ctx, filters)
@@ -81,10 +86,26 @@ func buildExpensesData(ctx context.Context, groupID string, group db.Group, quer
 		return ExpensesData{}, err
 	}
 
+	categories, err := expensestore.ListCategories(ctx, groupID)
+	if err != nil {
+		return ExpensesData{}, err
+	}
+	expenseIDs := make([]string, 0, len(expenses))
+	for _, expense := range expenses {
+		expenseIDs = append(expenseIDs, expense.ID)
+	}
+	expenseTags, err := expensestore.ListTagsByExpense(ctx, expenseIDs)
+	if err != nil {
+		return ExpensesData{}, err
+	}
+
 	return ExpensesData{
 		Title:              ctxi18n.T(ctx, "expenses.page_title"),
 		GroupName:          group.Name,
+		BaseCurrency:       group.BaseCurrency,
 		Expenses:           expenses,
+		Categories:         categories,
+		ExpenseTags:        expenseTags,
 		RecentYears:        utils.RecentYears(3),
 		Query:              query,
 		Pager:              utils.BuildTablePagination(totals.TotalItems, query),
@@ -118,10 +139,57 @@ func GetShowData(ctx context.Context, groupID, expenseID string) (ExpenseData, e
 		return ExpenseData{}, err
 	}
 
+	attachments, err := attachmentstore.ListAttachments(ctx, attachmentstore.ListAttachmentsParams{
+		GroupID:  groupID,
+		Entity:   attachmentstore.EntityExpense,
+		EntityID: expenseID,
+	})
+	if err != nil {
+		return ExpenseData{}, err
+	}
+
+	paidByName := ""
+	if expense.PaidByMemberID.Valid {
+		member, err := memberstore.GetMember(ctx, memberstore.GetMemberParams{ID: expense.PaidByMemberID.String, GroupID: groupID})
+		if err != nil {
+			return ExpenseData{}, err
+		}
+		paidByName = member.Name
+	}
+
+	categoryName := ""
+	if expense.CategoryID.Valid {
+		category, err := expensestore.GetCategory(ctx, expensestore.GetCategoryParams{ID: expense.CategoryID.String, GroupID: groupID})
+		if err != nil {
+			return ExpenseData{}, err
+		}
+		categoryName = categoryLabel(ctx, category)
+	}
+
+	eventTitle := ""
+	if expense.EventID.Valid {
+		event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: expense.EventID.String, GroupID: groupID})
+		if err != nil {
+			return ExpenseData{}, err
+		}
+		eventTitle = event.Title
+	}
+
+	tags, err := expensestore.ListExpenseTags(ctx, expenseID)
+	if err != nil {
+		return ExpenseData{}, err
+	}
+
 	return ExpenseData{
-		Title:   "bandcash - " + expense.Title,
-		Expense: &expense,
-		GroupID: groupID,
+		Title:        "bandcash - " + expense.Title,
+		Expense:      &expense,
+		PaidByName:   paidByName,
+		CategoryName: categoryName,
+		EventTitle:   eventTitle,
+		Tags:         tags,
+		Attachments:  attachments,
+		GroupID:      groupID,
+		BaseCurrency: group.BaseCurrency,
 		Breadcrumbs: []utils.Crumb{
 			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
 			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
@@ -130,3 +198,67 @@ func GetShowData(ctx context.Context, groupID, expenseID string) (ExpenseData, e
 		},
 	}, nil
 }
+
+// GetCategoriesData builds the category report for the year or date range of
+// query. Every category is listed, including those without expenses.
+func GetCategoriesData(ctx context.Context, groupID string, query utils.TableQuery) (CategoriesData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return CategoriesData{}, err
+	}
+
+	categories, err := expensestore.ListCategories(ctx, groupID)
+	if err != nil {
+		return CategoriesData{}, err
+	}
+
+	totals, err := expensestore.SumExpensesByCategory(ctx, expensestore.CategoryReportFilter{
+		GroupID: groupID,
+		Year:    query.Year,
+		From:    query.From,
+		To:      query.To,
+	})
+	if err != nil {
+		return CategoriesData{}, err
+	}
+	byCategory := make(map[string]expensestore.CategoryTotal, len(totals))
+	for _, total := range totals {
+		byCategory[total.CategoryID] = total
+	}
+
+	rows := make([]CategoryReportRow, 0, len(categories)+1)
+	for _, category := range categories {
+		rows = append(rows, CategoryReportRow{
+			CategoryID:    category.ID,
+			Label:         categoryLabel(ctx, category),
+			CategoryTotal: byCategory[category.ID],
+		})
+	}
+	if total, ok := byCategory[""]; ok {
+		rows = append(rows, CategoryReportRow{
+			Label:         ctxi18n.T(ctx, "expenses.uncategorized"),
+			CategoryTotal: total,
+		})
+	}
+	sum := int64(0)
+	for _, total := range totals {
+		sum += total.Total
+	}
+
+	return CategoriesData{
+		Title:           ctxi18n.T(ctx, "expenses.categories.page_title"),
+		GroupID:         groupID,
+		BaseCurrency:    group.BaseCurrency,
+		Rows:            rows,
+		Total:           sum,
+		CategoriesTable: CategoriesTableLayout(),
+		RecentYears:     utils.RecentYears(3),
+		Query:           query,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "expenses.title"), Href: "/groups/" + groupID + "/expenses"},
+			{Label: ctxi18n.T(ctx, "expenses.categories.title")},
+		},
+	}, nil
+}
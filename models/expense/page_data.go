@@ -3,12 +3,16 @@ package expense
 import (
 	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	expensestore "bandcash/models/expense/data"
 )
 
 type ExpensesData struct {
 	Title              string
 	GroupName          string
+	BaseCurrency       string
 	Expenses           []db.Expense
+	Categories         []db.ExpenseCategory
+	ExpenseTags        map[string][]string
 	RecentYears        []int
 	Query              utils.TableQuery
 	Pager              utils.TablePagination
@@ -31,9 +35,15 @@ type ExpensesData struct {
 type ExpenseData struct {
 	Title           string
 	Expense         *db.Expense
+	PaidByName      string
+	CategoryName    string
+	EventTitle      string
+	Tags            []string
+	Attachments     []db.Attachment
 	Breadcrumbs     []utils.Crumb
 	Signals         map[string]any
 	GroupID         string
+	BaseCurrency    string
 	IsAdmin         bool
 	PaidAtDialog    PaidAtDialogState
 	IsAuthenticated bool
@@ -58,6 +68,10 @@ type NewExpensePageData struct {
 	Title           string
 	Breadcrumbs     []utils.Crumb
 	GroupID         string
+	BaseCurrency    string
+	Members         []db.Member
+	Categories      []db.ExpenseCategory
+	Events          []db.Event
 	Signals         map[string]any
 	IsAuthenticated bool
 	IsSuperAdmin    bool
@@ -67,8 +81,36 @@ type EditExpensePageData struct {
 	Title           string
 	Breadcrumbs     []utils.Crumb
 	GroupID         string
+	BaseCurrency    string
 	Expense         *db.Expense
+	Members         []db.Member
+	Categories      []db.ExpenseCategory
+	Events          []db.Event
 	Signals         map[string]any
 	IsAuthenticated bool
 	IsSuperAdmin    bool
 }
+
+// CategoryReportRow is one line of the category report. CategoryID is empty
+// for uncategorized expenses.
+type CategoryReportRow struct {
+	CategoryID string
+	Label      string
+	expensestore.CategoryTotal
+}
+
+type CategoriesData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	BaseCurrency    string
+	Rows            []CategoryReportRow
+	Total           int64
+	CategoriesTable utils.TableLayout
+	RecentYears     []int
+	Query           utils.TableQuery
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
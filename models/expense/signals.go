@@ -1,14 +1,31 @@
 package expense
 
-import "bandcash/internal/utils"
+import (
+	"strings"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
 
 type expenseParams struct {
-	Title       string `json:"title" validate:"required,min=1,max=255"`
-	Description string `json:"description" validate:"max=1000"`
-	Amount      int64  `json:"amount" validate:"required,gt=0"`
-	Date        string `json:"date" validate:"required"`
-	Paid        bool   `json:"paid"`
-	PaidAt      string `json:"paidAt"`
+	Title        string  `json:"title" validate:"required,min=1,max=255"`
+	Description  string  `json:"description" validate:"max=1000"`
+	Amount       int64   `json:"amount" validate:"required,gt=0"`
+	Currency     string  `json:"currency" validate:"omitempty,currency"`
+	ExchangeRate float64 `json:"exchangeRate" validate:"required_with=Currency,omitempty,gt=0"`
+	Date         string  `json:"date" validate:"required"`
+	Paid         bool    `json:"paid"`
+	PaidAt       string  `json:"paidAt"`
+	PaidBy       string  `json:"paidBy"`
+	CategoryID   string  `json:"categoryId"`
+	EventID      string  `json:"eventId"`
+	Tags         string  `json:"tags" validate:"max=255"`
+	Scope        string  `json:"scope"`
+	// Version is the expense's when the edit form opened.
+	Version int64 `json:"version"`
+	// Opened holds the expense's values when the edit form opened, so a
+	// conflict lists what others changed since.
+	Opened *expenseParams `json:"opened,omitempty" validate:"-"`
 }
 
 type expenseTableParams struct {
@@ -56,9 +73,40 @@ func expenseIndexSignals(query utils.TableQuery) map[string]any {
 			"url":         "",
 			"triggerID":   "",
 		},
-		"formData": map[string]any{"title": "", "description": "", "amount": 0, "date": "", "paid": false, "paidAt": ""},
-		"errors":   map[string]any{"title": "", "description": "", "amount": "", "date": ""},
+		"formData": map[string]any{"title": "", "description": "", "amount": 0, "currency": "", "exchangeRate": 1, "date": "", "paid": false, "paidAt": "", "paidBy": "", "categoryId": "", "eventId": "", "tags": ""},
+		"errors":   map[string]any{"title": "", "description": "", "amount": "", "currency": "", "exchangeRate": "", "date": "", "paidBy": "", "categoryId": "", "eventId": "", "tags": ""},
+	}
+}
+
+// expenseFormValues returns the editable fields of expense as the edit form
+// binds them.
+func expenseFormValues(expense db.Expense, tags []string) map[string]any {
+	paidAt := ""
+	if expense.PaidAt.Valid {
+		paidAt = utils.FormatDateInput(expense.PaidAt.String)
 	}
+	return map[string]any{
+		"title":        expense.Title,
+		"description":  expense.Description,
+		"amount":       expense.Amount,
+		"currency":     expense.Currency,
+		"exchangeRate": expense.ExchangeRate,
+		"date":         expense.Date,
+		"paid":         expense.Paid == 1,
+		"paidAt":       paidAt,
+		"paidBy":       expense.PaidByMemberID.String,
+		"categoryId":   expense.CategoryID.String,
+		"eventId":      expense.EventID.String,
+		"tags":         strings.Join(tags, ", "),
+	}
+}
+
+func expenseEditFormData(expense db.Expense, tags []string) map[string]any {
+	form := expenseFormValues(expense, tags)
+	form["scope"] = seriesScopeSingle
+	form["version"] = expense.Version
+	form["opened"] = expenseFormValues(expense, tags)
+	return form
 }
 
 func expenseShowSignals(data ExpenseData) map[string]any {
@@ -5,9 +5,20 @@ import "bandcash/internal/utils"
 func ExpensesIndexTableLayout() utils.TableLayout {
 	return utils.NewTableLayout([]utils.TableColumn{
 		{Key: "title"},
+		{Key: "category"},
 		{Key: "date"},
 		{Key: "amount"},
 		{Key: "paid", MaxWRem: 7, WRem: 7},
 		{Key: "paid_at", MaxWRem: 12, WRem: 12},
 	}, 0)
 }
+
+func CategoriesTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "name"},
+		{Key: "count", MaxWRem: 7, WRem: 7},
+		{Key: "paid", MaxWRem: 12, WRem: 12},
+		{Key: "total", MaxWRem: 12, WRem: 12},
+		{Key: "actions", MaxWRem: 8, WRem: 8},
+	}, 0)
+}
@@ -1,12 +1,26 @@
 package expense
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	expensestore "bandcash/models/expense/data"
+)
+
+// Edit scopes for rows generated from a recurrence template.
+const (
+	seriesScopeSingle = "single"
+	seriesScopeFuture = "future"
 )
 
 func normalizeCacheKeyPart(value string) string {
@@ -74,3 +88,96 @@ func paidAtArg(isPaid bool, paidAt string) sql.NullString {
 
 	return sql.NullString{String: normalized, Valid: true}
 }
+
+// categoryLabel is a category's name, or its translated preset until renamed.
+func categoryLabel(ctx context.Context, category db.ExpenseCategory) string {
+	if category.Name != "" || category.Preset == "" {
+		return category.Name
+	}
+	return ctxi18n.T(ctx, "expenses.categories.presets."+category.Preset)
+}
+
+// categoryLabelByID looks a category up among categories; empty when the
+// expense has none.
+func categoryLabelByID(ctx context.Context, categories []db.ExpenseCategory, id string) string {
+	for _, category := range categories {
+		if category.ID == id {
+			return categoryLabel(ctx, category)
+		}
+	}
+	return ""
+}
+
+// eventOptionLabel is how an event is listed in the expense form's event
+// picker: its date and title.
+func eventOptionLabel(event db.Event) string {
+	date := strings.TrimSpace(event.Date)
+	if date == "" {
+		date = utils.FormatDateInput(event.Time)
+	}
+	if date == "" {
+		return event.Title
+	}
+	return date + " · " + event.Title
+}
+
+// publishExpense tells other tabs that expenses changed: the group's pages
+// and those of the events they belong to and the members who paid them.
+func publishExpense(c echo.Context, groupID string, expenses ...db.Expense) {
+	topics := []string{utils.GroupTopic(groupID)}
+	for _, expense := range expenses {
+		if expense.EventID.Valid {
+			topics = append(topics, utils.EventTopic(expense.EventID.String))
+		}
+		if expense.PaidByMemberID.Valid {
+			topics = append(topics, utils.MemberTopic(expense.PaidByMemberID.String))
+		}
+	}
+	utils.SSEHub.Publish(c, topics...)
+}
+
+// expenseConflictFields lists, as audit field names, where the saved expense
+// and its tags differ from the ones the form was opened with.
+func expenseConflictFields(current db.Expense, tags []string, opened expenseParams) []string {
+	fields := make([]string, 0)
+	add := func(changed bool, field string) {
+		if changed {
+			fields = append(fields, field)
+		}
+	}
+	add(current.Title != opened.Title, "title")
+	add(current.Description != opened.Description, "description")
+	add(current.Amount != opened.Amount, "amount")
+	add(current.Currency != opened.Currency, "currency")
+	add(current.ExchangeRate != opened.ExchangeRate, "exchange_rate")
+	add(current.Date != opened.Date, "date")
+	add((current.Paid == 1) != opened.Paid, "paid")
+	add(current.PaidByMemberID.String != opened.PaidBy, "paid_by_member_id")
+	add(current.CategoryID.String != opened.CategoryID, "category_id")
+	add(current.EventID.String != opened.EventID, "event_id")
+	add(!slices.Equal(tags, expensestore.ParseTags(opened.Tags)), "tags")
+	return fields
+}
+
+// patchExpenseConflict opens the conflict dialog after a save of form was
+// rejected as stale, with the expense's current version to overwrite with.
+func patchExpenseConflict(c echo.Context, groupID, expenseID string, form expenseParams) error {
+	ctx := c.Request().Context()
+	current, err := expensestore.GetExpense(ctx, expensestore.GetExpenseParams{ID: expenseID, GroupID: groupID})
+	if err != nil {
+		return err
+	}
+	tags, err := expensestore.ListExpenseTags(ctx, expenseID)
+	if err != nil {
+		return err
+	}
+	opened := form
+	if form.Opened != nil {
+		opened = *form.Opened
+	}
+	labels := make([]string, 0)
+	for _, field := range expenseConflictFields(current, tags, opened) {
+		labels = append(labels, audit.FieldLabel(ctx, field))
+	}
+	return utils.PatchConflict(c, labels, map[string]int64{"version": current.Version})
+}
@@ -0,0 +1,29 @@
+package expense
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+
+	"bandcash/internal/db"
+)
+
+func TestExpenseConflictFields(t *testing.T) {
+	t.Parallel()
+
+	current := db.Expense{Title: "Strings", Amount: 40, ExchangeRate: 1, Date: "2026-05-01"}
+	tags := []string{"gear", "tour"}
+	opened := expenseParams{Title: "Strings", Amount: 40, ExchangeRate: 1, Date: "2026-05-01", Tags: "gear, tour"}
+	if got := expenseConflictFields(current, tags, opened); len(got) != 0 {
+		t.Errorf("expenseConflictFields() = %v; want none", got)
+	}
+
+	current.Currency = "EUR"
+	current.ExchangeRate = 395
+	current.PaidByMemberID = sql.NullString{String: "mem_a", Valid: true}
+	current.CategoryID = sql.NullString{String: "cat_a", Valid: true}
+	want := []string{"currency", "exchange_rate", "paid_by_member_id", "category_id", "tags"}
+	if got := expenseConflictFields(current, []string{"gear"}, opened); !slices.Equal(got, want) {
+		t.Errorf("expenseConflictFields() = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package group
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	authstore "bandcash/models/auth/data"
+)
+
+// recordAccess logs a change of a user's role in the current group. An
+// empty role means no access, so granting has no fromRole and removing has
+// no toRole. Call it in the transaction that changed the access.
+func recordAccess(ctx context.Context, c echo.Context, action, userID, fromRole, toRole string) error {
+	email := ""
+	if user, err := authstore.GetUserByID(ctx, userID); err == nil {
+		email = user.Email
+	}
+
+	change := audit.Change{Action: action, Entity: utils.EntityFilterAccess, EntityID: userID, Label: email}
+	if fromRole != "" {
+		change.Before = map[string]any{"email": email, "role": fromRole}
+	}
+	if toRole != "" {
+		change.After = map[string]any{"email": email, "role": toRole}
+	}
+	return audit.Record(ctx, c, change)
+}
@@ -3,13 +3,21 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 
+	"bandcash/internal/currency"
 	"bandcash/internal/db"
+
+	"github.com/uptrace/bun"
 )
 
+// ErrBaseCurrencyInUse is returned when changing the base currency of a group
+// that already has events or expenses. Their amounts are kept in it.
+var ErrBaseCurrencyInUse = errors.New("group: base currency is in use")
+
 func GetGroupByID(ctx context.Context, id string) (db.Group, error) {
 	var row db.Group
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
 	return row, err
 }
 
@@ -26,7 +34,7 @@ func CreateGroupAdmin(ctx context.Context, arg CreateGroupAdminParams) (CreateGr
 		Role:    "admin",
 	}
 	row := CreateGroupAdminRow{}
-	err := db.BunDB.NewInsert().
+	err := db.Conn(ctx).NewInsert().
 		TableExpr("group_access").
 		Model(&input).
 		On("CONFLICT(user_id, group_id) DO UPDATE").
@@ -50,7 +58,7 @@ func CreateGroupReader(ctx context.Context, arg CreateGroupReaderParams) (Create
 		Role:    "viewer",
 	}
 	row := CreateGroupReaderRow{}
-	err := db.BunDB.NewInsert().
+	err := db.Conn(ctx).NewInsert().
 		TableExpr("group_access").
 		Model(&input).
 		On("CONFLICT(user_id, group_id) DO UPDATE").
@@ -62,7 +70,7 @@ func CreateGroupReader(ctx context.Context, arg CreateGroupReaderParams) (Create
 }
 
 func RemoveGroupAdmin(ctx context.Context, arg RemoveGroupAdminParams) error {
-	_, err := db.BunDB.NewDelete().
+	_, err := db.Conn(ctx).NewDelete().
 		TableExpr("group_access").
 		Where("user_id = ?", arg.UserID).
 		Where("group_id = ?", arg.GroupID).
@@ -72,7 +80,7 @@ func RemoveGroupAdmin(ctx context.Context, arg RemoveGroupAdminParams) error {
 }
 
 func RemoveGroupReader(ctx context.Context, arg RemoveGroupReaderParams) error {
-	_, err := db.BunDB.NewDelete().
+	_, err := db.Conn(ctx).NewDelete().
 		TableExpr("group_access").
 		Where("user_id = ?", arg.UserID).
 		Where("group_id = ?", arg.GroupID).
@@ -82,7 +90,7 @@ func RemoveGroupReader(ctx context.Context, arg RemoveGroupReaderParams) error {
 }
 
 func UpdateGroupAdmin(ctx context.Context, arg UpdateGroupAdminParams) error {
-	_, err := db.BunDB.NewUpdate().
+	_, err := db.Conn(ctx).NewUpdate().
 		TableExpr("groups").
 		Set("admin_user_id = ?", arg.AdminUserID).
 		Where("id = ?", arg.ID).
@@ -92,7 +100,7 @@ func UpdateGroupAdmin(ctx context.Context, arg UpdateGroupAdminParams) error {
 
 func GetGroupAccessRole(ctx context.Context, arg GetGroupAccessRoleParams) (string, error) {
 	var role string
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("group_access").
 		Column("role").
 		Where("user_id = ?", arg.UserID).
@@ -102,7 +110,7 @@ func GetGroupAccessRole(ctx context.Context, arg GetGroupAccessRoleParams) (stri
 }
 
 func IsGroupReader(ctx context.Context, arg IsGroupReaderParams) (int64, error) {
-	n, err := db.BunDB.NewSelect().
+	n, err := db.Conn(ctx).NewSelect().
 		TableExpr("group_access").
 		Where("user_id = ?", arg.UserID).
 		Where("group_id = ?", arg.GroupID).
@@ -113,7 +121,7 @@ func IsGroupReader(ctx context.Context, arg IsGroupReaderParams) (int64, error)
 
 func ListGroupAdmins(ctx context.Context, groupID string) ([]db.User, error) {
 	rows := make([]db.User, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("users AS u").
 		ColumnExpr("u.id, u.email, u.created_at, u.preferred_lang").
 		Join("JOIN group_access ga ON ga.user_id = u.id").
@@ -126,7 +134,7 @@ func ListGroupAdmins(ctx context.Context, groupID string) ([]db.User, error) {
 
 func ListGroupUserAccess(ctx context.Context, groupID string) ([]ListGroupUserAccessRow, error) {
 	rows := make([]ListGroupUserAccessRow, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("users").
 		ColumnExpr("users.id").
 		ColumnExpr("users.email").
@@ -143,20 +151,20 @@ func ListGroupUserAccess(ctx context.Context, groupID string) ([]ListGroupUserAc
 }
 
 func CreateGroup(ctx context.Context, arg CreateGroupParams) (db.Group, error) {
-	group := db.Group{ID: arg.ID, Name: arg.Name, AdminUserID: arg.AdminUserID}
-	if _, err := db.BunDB.NewInsert().Model(&group).Exec(ctx); err != nil {
+	group := db.Group{ID: arg.ID, Name: arg.Name, AdminUserID: arg.AdminUserID, BaseCurrency: currency.Default}
+	if _, err := db.Conn(ctx).NewInsert().Model(&group).Exec(ctx); err != nil {
 		return db.Group{}, err
 	}
 	return GetGroupByID(ctx, arg.ID)
 }
 
 func DeleteGroup(ctx context.Context, id string) error {
-	_, err := db.BunDB.NewDelete().TableExpr("groups").Where("id = ?", id).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().TableExpr("groups").Where("id = ?", id).Exec(ctx)
 	return err
 }
 
 func UpdateGroupName(ctx context.Context, arg UpdateGroupNameParams) (db.Group, error) {
-	_, err := db.BunDB.NewUpdate().
+	_, err := db.Conn(ctx).NewUpdate().
 		TableExpr("groups").
 		Set("name = ?", arg.Name).
 		Where("id = ?", arg.ID).
@@ -167,9 +175,83 @@ func UpdateGroupName(ctx context.Context, arg UpdateGroupNameParams) (db.Group,
 	return GetGroupByID(ctx, arg.ID)
 }
 
+// UpdateGroupBilling stores the seller details printed on invoices. Issued
+// invoices keep their own copy, so this only affects new ones.
+func UpdateGroupBilling(ctx context.Context, arg UpdateGroupBillingParams) (db.Group, error) {
+	_, err := db.Conn(ctx).NewUpdate().
+		TableExpr("groups").
+		Set("billing_name = ?", arg.BillingName).
+		Set("billing_address = ?", arg.BillingAddress).
+		Set("tax_number = ?", arg.TaxNumber).
+		Where("id = ?", arg.ID).
+		Exec(ctx)
+	if err != nil {
+		return db.Group{}, err
+	}
+	return GetGroupByID(ctx, arg.ID)
+}
+
+// UpdateGroupBaseCurrency changes the currency the group's totals are kept
+// in. Amounts are never converted, so it returns ErrBaseCurrencyInUse once the
+// group has events or expenses.
+func UpdateGroupBaseCurrency(ctx context.Context, arg UpdateGroupBaseCurrencyParams) (db.Group, error) {
+	err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		for _, table := range []string{"events", "expenses"} {
+			used, err := tx.NewSelect().TableExpr(table).Where("group_id = ?", arg.ID).Exists(ctx)
+			if err != nil {
+				return err
+			}
+			if used {
+				return ErrBaseCurrencyInUse
+			}
+		}
+		_, err := tx.NewUpdate().
+			TableExpr("groups").
+			Set("base_currency = ?", arg.BaseCurrency).
+			Where("id = ?", arg.ID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return db.Group{}, err
+	}
+	return GetGroupByID(ctx, arg.ID)
+}
+
+// EntryCurrency resolves the currency and exchange rate to store on an event
+// or expense of the group. Entries in the base currency get no currency.
+func EntryCurrency(ctx context.Context, groupID, code string, rate float64) (string, float64, error) {
+	var base string
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("groups").
+		Column("base_currency").
+		Where("id = ?", groupID).
+		Scan(ctx, &base)
+	if err != nil {
+		return "", 0, err
+	}
+	code, rate = currency.ForEntry(code, rate, base)
+	return code, rate, nil
+}
+
+// UpdateGroupCalendarAmounts controls whether calendar feeds of the group
+// show event amounts.
+func UpdateGroupCalendarAmounts(ctx context.Context, arg UpdateGroupCalendarAmountsParams) error {
+	value := 0
+	if arg.ShowAmounts {
+		value = 1
+	}
+	_, err := db.Conn(ctx).NewUpdate().
+		TableExpr("groups").
+		Set("calendar_show_amounts = ?", value).
+		Where("id = ?", arg.ID).
+		Exec(ctx)
+	return err
+}
+
 func ListGroupsByAdmin(ctx context.Context, userID string) ([]db.Group, error) {
 	rows := make([]db.Group, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("groups AS g").
 		ColumnExpr("g.id, g.name, g.admin_user_id, g.created_at").
 		Join("JOIN group_access ga ON ga.group_id = g.id").
@@ -182,7 +264,7 @@ func ListGroupsByAdmin(ctx context.Context, userID string) ([]db.Group, error) {
 
 func ListGroupsByReader(ctx context.Context, userID string) ([]db.Group, error) {
 	rows := make([]db.Group, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("groups AS g").
 		ColumnExpr("g.id, g.name, g.admin_user_id, g.created_at").
 		Join("JOIN group_access ga ON ga.group_id = g.id").
@@ -195,7 +277,7 @@ func ListGroupsByReader(ctx context.Context, userID string) ([]db.Group, error)
 
 func ListGroupPendingInvites(ctx context.Context, groupID sql.NullString) ([]db.MagicLink, error) {
 	rows := make([]db.MagicLink, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		Model(&rows).
 		Where("action = 'invite'").
 		Where("used_at IS NULL")
@@ -210,7 +292,7 @@ func DeleteGroupPendingInvite(ctx context.Context, arg DeleteGroupPendingInviteP
 	if !arg.GroupID.Valid {
 		return sql.ErrNoRows
 	}
-	_, err := db.BunDB.NewDelete().
+	_, err := db.Conn(ctx).NewDelete().
 		TableExpr("magic_links").
 		Where("id = ?", arg.ID).
 		Where("action = 'invite'").
@@ -229,7 +311,7 @@ func CreateInviteMagicLink(ctx context.Context, arg CreateInviteMagicLinkParams)
 		GroupID:    arg.GroupID,
 		InviteRole: arg.InviteRole,
 	}
-	_, err := db.BunDB.NewInsert().
+	_, err := db.Conn(ctx).NewInsert().
 		Model(&row).
 		Value("expires_at", "CURRENT_TIMESTAMP").
 		Returning("id, token, email, action, group_id, expires_at, used_at, created_at, invite_role").
@@ -237,12 +319,14 @@ func CreateInviteMagicLink(ctx context.Context, arg CreateInviteMagicLinkParams)
 	return row, err
 }
 
+// ListPaidOutgoingPaymentsByGroup returns payouts and expenses that are paid
+// or partly paid out, latest first.
 func ListPaidOutgoingPaymentsByGroup(ctx context.Context, groupID string) ([]db.GroupOutgoingPayment, error) {
 	rows := make([]db.GroupOutgoingPayment, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("group_outgoing_payments").
 		Where("group_id = ?", groupID).
-		Where("paid = 1").
+		Where("(paid = 1 OR paid_amount > 0)").
 		OrderExpr("COALESCE(paid_at, updated_at) DESC").
 		OrderExpr("updated_at DESC").
 		OrderExpr("payment_kind ASC").
@@ -252,7 +336,7 @@ func ListPaidOutgoingPaymentsByGroup(ctx context.Context, groupID string) ([]db.
 
 func ListUnpaidOutgoingPaymentsByGroup(ctx context.Context, groupID string) ([]db.GroupOutgoingPayment, error) {
 	rows := make([]db.GroupOutgoingPayment, 0)
-	err := db.BunDB.NewSelect().
+	err := db.Conn(ctx).NewSelect().
 		TableExpr("group_outgoing_payments").
 		Where("group_id = ?", groupID).
 		Where("paid = 0").
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"bandcash/internal/db"
+)
+
+func TestUpdateGroupBaseCurrencyRefusesUsedGroups(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "group_test.sqlite")
+	if err := db.Init(dbPath); err != nil {
+		t.Fatalf("db.Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
+	}
+	ctx := context.Background()
+
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.BunDB.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	exec(`INSERT INTO users (id, email) VALUES ('usr_1', 'a@example.com')`)
+	exec(`INSERT INTO groups (id, name, admin_user_id) VALUES ('grp_1', 'Band', 'usr_1')`)
+	exec(`INSERT INTO groups (id, name, admin_user_id) VALUES ('grp_2', 'Duo', 'usr_1')`)
+
+	group, err := UpdateGroupBaseCurrency(ctx, UpdateGroupBaseCurrencyParams{ID: "grp_1", BaseCurrency: "EUR"})
+	if err != nil {
+		t.Fatalf("UpdateGroupBaseCurrency(empty group) failed: %v", err)
+	}
+	if group.BaseCurrency != "EUR" {
+		t.Fatalf("base currency = %q; want EUR", group.BaseCurrency)
+	}
+
+	exec(`INSERT INTO events (id, group_id, title, description, time, amount) VALUES ('evt_1', 'grp_1', 'Gig', '', '2026-05-01', 1000)`)
+	exec(`INSERT INTO expenses (id, group_id, title, description, date, amount) VALUES ('exp_1', 'grp_2', 'Strings', '', '2026-05-01', 50)`)
+	for _, id := range []string{"grp_1", "grp_2"} {
+		_, err = UpdateGroupBaseCurrency(ctx, UpdateGroupBaseCurrencyParams{ID: id, BaseCurrency: "USD"})
+		if !errors.Is(err, ErrBaseCurrencyInUse) {
+			t.Fatalf("UpdateGroupBaseCurrency(%s) error = %v; want ErrBaseCurrencyInUse", id, err)
+		}
+	}
+	group, err = GetGroupByID(ctx, "grp_1")
+	if err != nil {
+		t.Fatalf("GetGroupByID failed: %v", err)
+	}
+	if group.BaseCurrency != "EUR" {
+		t.Fatalf("base currency after refusal = %q; want EUR", group.BaseCurrency)
+	}
+}
@@ -9,15 +9,16 @@ import (
 )
 
 type UserGroupRow struct {
-	ID          string
-	Name        string
-	AdminUserID string
-	CreatedAt   sql.NullTime
-	Role        string
+	ID           string
+	Name         string
+	AdminUserID  string
+	CreatedAt    sql.NullTime
+	BaseCurrency string
+	Role         string
 }
 
 func CountUserGroupsTable(ctx context.Context, userID, search string) (int64, error) {
-	q := db.BunDB.NewSelect().TableExpr("group_access ga").Join("JOIN groups g ON g.id = ga.group_id")
+	q := db.Conn(ctx).NewSelect().TableExpr("group_access ga").Join("JOIN groups g ON g.id = ga.group_id")
 	q = q.Where("ga.user_id = ?", userID)
 	search = strings.TrimSpace(search)
 	if search != "" {
@@ -29,11 +30,12 @@ func CountUserGroupsTable(ctx context.Context, userID, search string) (int64, er
 
 func ListUserGroupsTable(ctx context.Context, userID, search string, limit, offset int) ([]UserGroupRow, error) {
 	rows := make([]UserGroupRow, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		ColumnExpr("g.id AS id").
 		ColumnExpr("g.name AS name").
 		ColumnExpr("g.admin_user_id AS admin_user_id").
 		ColumnExpr("g.created_at AS created_at").
+		ColumnExpr("g.base_currency AS base_currency").
 		ColumnExpr("ga.role AS role").
 		TableExpr("group_access ga").
 		Join("JOIN groups g ON g.id = ga.group_id").
@@ -54,6 +54,23 @@ type UpdateGroupNameParams struct {
 	ID   string `json:"id"`
 }
 
+type UpdateGroupBillingParams struct {
+	BillingName    string `json:"billing_name"`
+	BillingAddress string `json:"billing_address"`
+	TaxNumber      string `json:"tax_number"`
+	ID             string `json:"id"`
+}
+
+type UpdateGroupBaseCurrencyParams struct {
+	BaseCurrency string `json:"base_currency"`
+	ID           string `json:"id"`
+}
+
+type UpdateGroupCalendarAmountsParams struct {
+	ShowAmounts bool   `json:"show_amounts"`
+	ID          string `json:"id"`
+}
+
 type CreateInviteMagicLinkParams struct {
 	ID         string         `json:"id"`
 	Token      string         `json:"token"`
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -16,11 +17,15 @@ import (
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 	"github.com/labstack/echo/v4"
 	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
 
 	internalbilling "bandcash/internal/billing"
+	"bandcash/internal/currency"
 	"bandcash/internal/db"
 	"bandcash/internal/email"
 	"bandcash/internal/utils"
+	"bandcash/models/attachment"
+	"bandcash/models/audit"
 	authstore "bandcash/models/auth/data"
 	eventstore "bandcash/models/event/data"
 	expensestore "bandcash/models/expense/data"
@@ -110,10 +115,16 @@ type updateGroupSignals struct {
 	Mode       string           `json:"mode"`
 	TableQuery utils.TableQuery `json:"tableQuery"`
 	FormData   struct {
-		Name string `json:"name" validate:"required,min=1,max=255"`
+		Name           string `json:"name" validate:"required,min=1,max=255"`
+		BaseCurrency   string `json:"baseCurrency" validate:"required,currency"`
+		BillingName    string `json:"billingName" validate:"max=255"`
+		BillingAddress string `json:"billingAddress" validate:"max=1000"`
+		TaxNumber      string `json:"taxNumber" validate:"max=64"`
 	} `json:"formData"`
 }
 
+var groupEditErrorFields = []string{"name", "baseCurrency", "billingName", "billingAddress", "taxNumber"}
+
 type deleteGroupSignals struct {
 	TabID      string           `json:"tab_id"`
 	Mode       string           `json:"mode"`
@@ -234,12 +245,29 @@ func (g *Group) TogglePaymentEventPaid(c echo.Context) error {
 	if err != nil {
 		return c.NoContent(http.StatusInternalServerError)
 	}
-	updatedEvent, err := eventstore.ToggleEventPaid(c.Request().Context(), eventstore.ToggleEventPaidParams{ID: eventID, GroupID: groupID})
+	event, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	var updatedEvent db.Event
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updatedEvent, err = eventstore.ToggleEventPaid(ctx, eventstore.ToggleEventPaidParams{ID: eventID, GroupID: groupID, PaymentID: utils.GenerateID(utils.PrefixPayment)})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updatedEvent.Paid), Entity: utils.EntityFilterEvent, EntityID: eventID, Before: event, After: updatedEvent})
+	})
+	if errors.Is(err, eventstore.ErrEventHasPayments) {
+		slog.Info("group.payments.toggle_event_paid: event has payments", "group_id", groupID, "event_id", eventID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.unpaid_has_payments"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("group.payments.toggle_event_paid: failed", "group_id", groupID, "event_id", eventID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID), utils.EventTopic(eventID))
 	notifyPaidToggleResult(c, updatedEvent.Paid)
 	utils.InvalidateGroupCaches(groupID)
 	if shouldApplyFade {
@@ -275,19 +303,30 @@ func (g *Group) UpdatePaymentEventPaidAt(c echo.Context) error {
 	if err != nil {
 		return c.NoContent(http.StatusInternalServerError)
 	}
-	updatedEvent, err := eventstore.UpdateEventPaidAt(c.Request().Context(), eventstore.UpdateEventPaidAtParams{
-		PaidAt:  paidAt,
-		ID:      eventID,
-		GroupID: groupID,
+	event, err := eventstore.GetEvent(c.Request().Context(), eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updatedEvent, err := eventstore.UpdateEventPaidAt(ctx, eventstore.UpdateEventPaidAtParams{
+			PaidAt:    paidAt,
+			PaymentID: utils.GenerateID(utils.PrefixPayment),
+			ID:        eventID,
+			GroupID:   groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterEvent, EntityID: eventID, Before: event, After: updatedEvent})
 	})
 	if err != nil {
 		slog.Error("group.payments.update_event_paid_at: failed", "group_id", groupID, "event_id", eventID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID), utils.EventTopic(eventID))
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "events.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
-	_ = updatedEvent
 	if shouldApplyFade {
 		utils.SSEHub.PatchHTML(c, fadeHTML)
 		time.Sleep(paymentsFadeDuration)
@@ -319,16 +358,38 @@ func (g *Group) TogglePaymentParticipantPaid(c echo.Context) error {
 	if err != nil {
 		return c.NoContent(http.StatusInternalServerError)
 	}
-	updatedParticipant, err := eventstore.ToggleParticipantPaid(c.Request().Context(), eventstore.ToggleParticipantPaidParams{
+	participant, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  eventID,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	var updatedParticipant db.Participant
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updatedParticipant, err = eventstore.ToggleParticipantPaid(ctx, eventstore.ToggleParticipantPaidParams{
+			EventID:  eventID,
+			MemberID: memberID,
+			GroupID:  groupID,
+			PayoutID: utils.GenerateID(utils.PrefixPayout),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updatedParticipant.Paid), Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(eventID, memberID), Before: participant, After: updatedParticipant})
+	})
+	if errors.Is(err, eventstore.ErrParticipantHasPayouts) {
+		slog.Info("group.payments.toggle_participant_paid: participant has payouts", "group_id", groupID, "event_id", eventID, "member_id", memberID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.unpaid_has_payouts"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("group.payments.toggle_participant_paid: failed", "group_id", groupID, "event_id", eventID, "member_id", memberID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID), utils.EventTopic(eventID), utils.MemberTopic(memberID))
 	notifyPaidToggleResult(c, updatedParticipant.Paid)
 	utils.InvalidateGroupCaches(groupID)
 	if shouldApplyFade {
@@ -365,20 +426,35 @@ func (g *Group) UpdatePaymentParticipantPaidAt(c echo.Context) error {
 	if err != nil {
 		return c.NoContent(http.StatusInternalServerError)
 	}
-	updatedParticipant, err := eventstore.UpdateParticipantPaidAt(c.Request().Context(), eventstore.UpdateParticipantPaidAtParams{
-		PaidAt:   paidAt,
+	participant, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  eventID,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updatedParticipant, err := eventstore.UpdateParticipantPaidAt(ctx, eventstore.UpdateParticipantPaidAtParams{
+			PaidAt:   paidAt,
+			EventID:  eventID,
+			MemberID: memberID,
+			GroupID:  groupID,
+			PayoutID: utils.GenerateID(utils.PrefixPayout),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(eventID, memberID), Before: participant, After: updatedParticipant})
+	})
 	if err != nil {
 		slog.Error("group.payments.update_participant_paid_at: failed", "group_id", groupID, "event_id", eventID, "member_id", memberID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID), utils.EventTopic(eventID), utils.MemberTopic(memberID))
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
-	_ = updatedParticipant
 	if shouldApplyFade {
 		utils.SSEHub.PatchHTML(c, fadeHTML)
 		time.Sleep(paymentsFadeDuration)
@@ -409,12 +485,24 @@ func (g *Group) TogglePaymentExpensePaid(c echo.Context) error {
 	if err != nil {
 		return c.NoContent(http.StatusInternalServerError)
 	}
-	updatedExpense, err := expensestore.ToggleExpensePaid(c.Request().Context(), expensestore.ToggleExpensePaidParams{ID: expenseID, GroupID: groupID})
+	expense, err := expensestore.GetExpense(c.Request().Context(), expensestore.GetExpenseParams{ID: expenseID, GroupID: groupID})
+	if err != nil {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	var updatedExpense db.Expense
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updatedExpense, err = expensestore.ToggleExpensePaid(ctx, expensestore.ToggleExpensePaidParams{ID: expenseID, GroupID: groupID})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updatedExpense.Paid), Entity: utils.EntityFilterExpense, EntityID: expenseID, Before: expense, After: updatedExpense})
+	})
 	if err != nil {
 		slog.Error("group.payments.toggle_expense_paid: failed", "group_id", groupID, "expense_id", expenseID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, expenseTopics(groupID, updatedExpense)...)
 	notifyPaidToggleResult(c, updatedExpense.Paid)
 	utils.InvalidateGroupCaches(groupID)
 	if shouldApplyFade {
@@ -457,24 +545,36 @@ func (g *Group) UpdatePaymentExpensePaidAt(c echo.Context) error {
 	if err != nil {
 		return c.NoContent(http.StatusInternalServerError)
 	}
-	updatedExpense, err := expensestore.UpdateExpense(c.Request().Context(), expensestore.UpdateExpenseParams{
-		Title:       expense.Title,
-		Description: expense.Description,
-		Amount:      expense.Amount,
-		Date:        expense.Date,
-		Paid:        paid,
-		PaidAt:      paidAtValue,
-		ID:          expenseID,
-		GroupID:     groupID,
+	var updatedExpense db.Expense
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updatedExpense, err = expensestore.UpdateExpense(ctx, expensestore.UpdateExpenseParams{
+			Title:          expense.Title,
+			Description:    expense.Description,
+			Amount:         expense.Amount,
+			Currency:       expense.Currency,
+			ExchangeRate:   expense.ExchangeRate,
+			Date:           expense.Date,
+			Paid:           paid,
+			PaidAt:         paidAtValue,
+			PaidByMemberID: expense.PaidByMemberID.String,
+			CategoryID:     expense.CategoryID.String,
+			EventID:        expense.EventID.String,
+			ID:             expenseID,
+			GroupID:        groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterExpense, EntityID: expenseID, Before: expense, After: updatedExpense})
 	})
 	if err != nil {
 		slog.Error("group.payments.update_expense_paid_at: failed", "group_id", groupID, "expense_id", expenseID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, expenseTopics(groupID, updatedExpense)...)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "expenses.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
-	_ = updatedExpense
 	if shouldApplyFade {
 		utils.SSEHub.PatchHTML(c, fadeHTML)
 		time.Sleep(paymentsFadeDuration)
@@ -498,22 +598,67 @@ func (g *Group) UpdateGroup(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
+	signals.FormData.BillingName = strings.TrimSpace(signals.FormData.BillingName)
+	signals.FormData.BillingAddress = strings.TrimSpace(signals.FormData.BillingAddress)
+	signals.FormData.TaxNumber = strings.TrimSpace(signals.FormData.TaxNumber)
+
 	if errs := utils.ValidateWithLocale(c.Request().Context(), signals.FormData); errs != nil {
-		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors([]string{"name"}, errs)})
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(groupEditErrorFields, errs)})
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
 
-	_, err := groupstore.UpdateGroupName(c.Request().Context(), groupstore.UpdateGroupNameParams{
-		Name: signals.FormData.Name,
-		ID:   groupID,
+	baseCurrencyChanged := false
+	err := db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		_, err := groupstore.UpdateGroupName(ctx, groupstore.UpdateGroupNameParams{
+			Name: signals.FormData.Name,
+			ID:   groupID,
+		})
+		if err != nil {
+			return fmt.Errorf("update name: %w", err)
+		}
+		_, err = groupstore.UpdateGroupBilling(ctx, groupstore.UpdateGroupBillingParams{
+			BillingName:    signals.FormData.BillingName,
+			BillingAddress: signals.FormData.BillingAddress,
+			TaxNumber:      signals.FormData.TaxNumber,
+			ID:             groupID,
+		})
+		if err != nil {
+			return fmt.Errorf("update billing details: %w", err)
+		}
+		group, err := groupstore.GetGroupByID(ctx, groupID)
+		if err != nil {
+			return fmt.Errorf("get group: %w", err)
+		}
+		baseCurrency := currency.Normalize(signals.FormData.BaseCurrency)
+		if baseCurrency == group.BaseCurrency {
+			return nil
+		}
+		_, err = groupstore.UpdateGroupBaseCurrency(ctx, groupstore.UpdateGroupBaseCurrencyParams{
+			BaseCurrency: baseCurrency,
+			ID:           groupID,
+		})
+		if err != nil {
+			return fmt.Errorf("update base currency: %w", err)
+		}
+		baseCurrencyChanged = true
+		return nil
 	})
+	if errors.Is(err, groupstore.ErrBaseCurrencyInUse) {
+		slog.Info("group.update: base currency is in use", "group_id", groupID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.errors.base_currency_in_use"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("group.update: failed to update group", "group_id", groupID, "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.errors.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	if baseCurrencyChanged {
+		utils.InvalidateGroupCaches(groupID)
+	}
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.messages.updated"))
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 
 	err = utils.SSEHub.Redirect(c, "/groups/"+groupID+"/about")
 	if err != nil {
@@ -553,8 +698,15 @@ func (g *Group) LeaveGroup(c echo.Context) error {
 		}
 		return c.NoContent(http.StatusOK)
 	}
+	role, _ := getGroupAccessRole(c.Request().Context(), groupID, userID)
 	if isAdminUser(c.Request().Context(), groupID, userID) {
-		if err := g.removeAdminAccess(c.Request().Context(), groupID, userID); err != nil {
+		err := db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+			if err := g.removeAdminAccess(ctx, groupID, userID); err != nil {
+				return err
+			}
+			return recordAccess(ctx, c, audit.ActionLeave, userID, role, "")
+		})
+		if err != nil {
 			if err == errAtLeastOneAdmin {
 				utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.errors.at_least_one_admin"))
 			} else {
@@ -567,6 +719,7 @@ func (g *Group) LeaveGroup(c echo.Context) error {
 			}
 			return c.NoContent(http.StatusOK)
 		}
+		utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.messages.left"))
 		err = utils.SSEHub.Redirect(c, "/groups")
@@ -576,9 +729,15 @@ func (g *Group) LeaveGroup(c echo.Context) error {
 		return c.NoContent(http.StatusOK)
 	}
 
-	err = groupstore.RemoveGroupReader(c.Request().Context(), groupstore.RemoveGroupReaderParams{
-		UserID:  userID,
-		GroupID: groupID,
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		err := groupstore.RemoveGroupReader(ctx, groupstore.RemoveGroupReaderParams{
+			UserID:  userID,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAccess(ctx, c, audit.ActionLeave, userID, role, "")
 	})
 	if err != nil {
 		slog.Error("group: failed to leave", "err", err)
@@ -589,6 +748,7 @@ func (g *Group) LeaveGroup(c echo.Context) error {
 		}
 		return c.NoContent(http.StatusOK)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.messages.left"))
 	err = utils.SSEHub.Redirect(c, "/groups")
@@ -620,6 +780,8 @@ func (g *Group) DeleteGroup(c echo.Context) error {
 		}
 		return c.NoContent(http.StatusOK)
 	}
+	// Attachment rows cascade with the group; their blobs are released below.
+	attachmentHashes := attachment.GroupHashes(c.Request().Context(), groupID)
 	if err := groupstore.DeleteGroup(c.Request().Context(), groupID); err != nil {
 		slog.Error("group: failed to delete", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.errors.delete_failed"))
@@ -629,8 +791,10 @@ func (g *Group) DeleteGroup(c echo.Context) error {
 		}
 		return c.NoContent(http.StatusOK)
 	}
+	attachment.ReleaseBlobs(c.Request().Context(), attachmentHashes...)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "groups.messages.deleted"))
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 	if signals.Mode == "table" {
 		query := utils.NormalizeTableQuery(signals.TableQuery, g.model.TableQuerySpec())
 		data, err := g.model.GetGroupsPageData(c.Request().Context(), userID, query)
@@ -762,21 +926,29 @@ func (g *Group) AddViewer(c echo.Context) error {
 		}
 		if roleErr == nil && userRole == "viewer" {
 			if inviteRole == "admin" {
-				err = groupstore.RemoveGroupReader(c.Request().Context(), groupstore.RemoveGroupReaderParams{
-					UserID:  user.ID,
-					GroupID: groupID,
-				})
-				if err == nil {
-					_, err = groupstore.CreateGroupAdmin(c.Request().Context(), groupstore.CreateGroupAdminParams{
+				err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+					err := groupstore.RemoveGroupReader(ctx, groupstore.RemoveGroupReaderParams{
+						UserID:  user.ID,
+						GroupID: groupID,
+					})
+					if err != nil {
+						return err
+					}
+					_, err = groupstore.CreateGroupAdmin(ctx, groupstore.CreateGroupAdminParams{
 						ID:      utils.GenerateID("gad"),
 						UserID:  user.ID,
 						GroupID: groupID,
 					})
-				}
+					if err != nil {
+						return err
+					}
+					return recordAccess(ctx, c, audit.ActionRole, user.ID, "viewer", "admin")
+				})
 				if err != nil {
 					slog.Error("group: failed to promote viewer to admin", "group_id", groupID, "user_id", user.ID, "err", err)
 					return g.patchUsersPageWithState(c, groupID, signals.TableQuery, "", "groups.errors.promote_failed")
 				}
+				utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 				if err := sendRoleChangeEmail(c.Request().Context(), user, group.Name, group.ID, "admin"); err != nil {
 					slog.Warn("group: failed to send role-change email", "group_id", groupID, "user_id", user.ID, "err", err)
 				}
@@ -789,17 +961,30 @@ func (g *Group) AddViewer(c echo.Context) error {
 	// Create invite magic link that does not expire.
 	token := utils.GenerateID("tok")
 
-	_, err = groupstore.CreateInviteMagicLink(c.Request().Context(), groupstore.CreateInviteMagicLinkParams{
-		ID:         utils.GenerateID("mag"),
-		Token:      token,
-		Email:      emailAddress,
-		GroupID:    sql.NullString{String: groupID, Valid: true},
-		InviteRole: inviteRole,
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		invite, err := groupstore.CreateInviteMagicLink(ctx, groupstore.CreateInviteMagicLinkParams{
+			ID:         utils.GenerateID("mag"),
+			Token:      token,
+			Email:      emailAddress,
+			GroupID:    sql.NullString{String: groupID, Valid: true},
+			InviteRole: inviteRole,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{
+			Action:   audit.ActionInvite,
+			Entity:   utils.EntityFilterAccess,
+			EntityID: invite.ID,
+			Label:    emailAddress,
+			After:    map[string]any{"email": emailAddress, "role": inviteRole},
+		})
 	})
 	if err != nil {
 		slog.Error("group: failed to create invite link", "err", err)
 		return g.patchUsersPageWithState(c, groupID, signals.TableQuery, "", "groups.errors.invite_failed")
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 
 	err = email.Email().SendGroupInvitation(c.Request().Context(), emailAddress, group.Name, token, utils.Env().URL)
 	if err != nil {
@@ -841,26 +1026,43 @@ func (g *Group) RemoveViewer(c echo.Context) error {
 			return g.redirectUsersPage(c, groupID, "", "groups.errors.owner_cannot_leave", http.StatusConflict)
 		}
 	}
+	role, _ := getGroupAccessRole(ctx, groupID, userID)
 	if isAdminUser(ctx, groupID, userID) {
-		if err := g.removeAdminAccess(ctx, groupID, userID); err != nil {
+		err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+			if err := g.removeAdminAccess(ctx, groupID, userID); err != nil {
+				return err
+			}
+			return recordAccess(ctx, c, audit.ActionRemove, userID, role, "")
+		})
+		if err != nil {
 			if err == errAtLeastOneAdmin {
 				return g.redirectUsersPage(c, groupID, "", "groups.errors.at_least_one_admin", http.StatusConflict)
 			}
 			slog.Error("group: failed to remove admin access", "group_id", groupID, "user_id", userID, "err", err)
 			return g.redirectUsersPage(c, groupID, "", "groups.errors.remove_failed", http.StatusInternalServerError)
 		}
+		utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+		pushAccessChange(c, groupID, userID, "")
 		notifyAccessRemoved(ctx, groupID, userID)
 		return g.redirectUsersPage(c, groupID, "groups.messages.viewer_removed", "", http.StatusOK)
 	}
 
-	err := groupstore.RemoveGroupReader(ctx, groupstore.RemoveGroupReaderParams{
-		UserID:  userID,
-		GroupID: groupID,
+	err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := groupstore.RemoveGroupReader(ctx, groupstore.RemoveGroupReaderParams{
+			UserID:  userID,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAccess(ctx, c, audit.ActionRemove, userID, role, "")
 	})
 	if err != nil {
 		slog.Error("group: failed to remove viewer", "err", err)
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.remove_failed", http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	pushAccessChange(c, groupID, userID, "")
 	notifyAccessRemoved(ctx, groupID, userID)
 
 	return g.redirectUsersPage(c, groupID, "groups.messages.viewer_removed", "", http.StatusOK)
@@ -902,22 +1104,23 @@ func (g *Group) PromoteViewerToAdmin(c echo.Context) error {
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.promote_failed", http.StatusInternalServerError)
 	}
 
-	err := groupstore.RemoveGroupReader(ctx, groupstore.RemoveGroupReaderParams{
-		UserID:  userID,
-		GroupID: groupID,
-	})
-	if err != nil {
-		slog.Error("group: failed to remove viewer while promoting", "group_id", groupID, "user_id", userID, "err", err)
-		if signals.Mode == "table" {
-			return g.patchUsersPageWithState(c, groupID, signals.TableQuery, "", "groups.errors.promote_failed")
+	err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := groupstore.RemoveGroupReader(ctx, groupstore.RemoveGroupReaderParams{
+			UserID:  userID,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
 		}
-		return g.redirectUsersPage(c, groupID, "", "groups.errors.promote_failed", http.StatusInternalServerError)
-	}
-
-	_, err = groupstore.CreateGroupAdmin(ctx, groupstore.CreateGroupAdminParams{
-		ID:      utils.GenerateID("gad"),
-		UserID:  userID,
-		GroupID: groupID,
+		_, err = groupstore.CreateGroupAdmin(ctx, groupstore.CreateGroupAdminParams{
+			ID:      utils.GenerateID("gad"),
+			UserID:  userID,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAccess(ctx, c, audit.ActionRole, userID, role, "admin")
 	})
 	if err != nil {
 		slog.Error("group: failed to promote viewer", "group_id", groupID, "user_id", userID, "err", err)
@@ -926,6 +1129,8 @@ func (g *Group) PromoteViewerToAdmin(c echo.Context) error {
 		}
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.promote_failed", http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	pushAccessChange(c, groupID, userID, "admin")
 
 	group, err := groupstore.GetGroupByID(ctx, groupID)
 	if err == nil {
@@ -969,13 +1174,20 @@ func (g *Group) DemoteAdminToViewer(c echo.Context) error {
 		}
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.invalid_user", http.StatusBadRequest)
 	}
-	if !isAdminUser(ctx, groupID, userID) {
+	role, err := getGroupAccessRole(ctx, groupID, userID)
+	if err != nil || (role != "owner" && role != "admin") {
 		if signals.Mode == "table" {
 			return g.patchUsersPageWithState(c, groupID, signals.TableQuery, "", "groups.errors.demote_failed")
 		}
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.demote_failed", http.StatusInternalServerError)
 	}
-	if err := g.demoteAdminToViewer(ctx, groupID, userID); err != nil {
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		if err := g.demoteAdminToViewer(ctx, groupID, userID); err != nil {
+			return err
+		}
+		return recordAccess(ctx, c, audit.ActionRole, userID, role, "viewer")
+	})
+	if err != nil {
 		if err == errAtLeastOneAdmin {
 			if signals.Mode == "table" {
 				return g.patchUsersPageWithState(c, groupID, signals.TableQuery, "", "groups.errors.at_least_one_admin")
@@ -988,6 +1200,8 @@ func (g *Group) DemoteAdminToViewer(c echo.Context) error {
 		}
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.demote_failed", http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	pushAccessChange(c, groupID, userID, "viewer")
 	group, err := groupstore.GetGroupByID(ctx, groupID)
 	if err == nil {
 		if user, userErr := authstore.GetUserByID(ctx, userID); userErr == nil {
@@ -1041,14 +1255,39 @@ func (g *Group) CancelInvite(c echo.Context) error {
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.invalid_invite", http.StatusBadRequest)
 	}
 
-	err := groupstore.DeleteGroupPendingInvite(c.Request().Context(), groupstore.DeleteGroupPendingInviteParams{
-		ID:      inviteID,
-		GroupID: sql.NullString{String: groupID, Valid: true},
+	invites, err := groupstore.ListGroupPendingInvites(c.Request().Context(), sql.NullString{String: groupID, Valid: true})
+	if err != nil {
+		slog.Error("group: failed to list invites", "err", err)
+		return g.redirectUsersPage(c, groupID, "", "groups.errors.invite_cancel_failed", http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		err := groupstore.DeleteGroupPendingInvite(ctx, groupstore.DeleteGroupPendingInviteParams{
+			ID:      inviteID,
+			GroupID: sql.NullString{String: groupID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		for _, invite := range invites {
+			if invite.ID != inviteID {
+				continue
+			}
+			return audit.Record(ctx, c, audit.Change{
+				Action:   audit.ActionRemove,
+				Entity:   utils.EntityFilterAccess,
+				EntityID: invite.ID,
+				Label:    invite.Email,
+				Before:   map[string]any{"email": invite.Email, "role": invite.InviteRole},
+			})
+		}
+		return nil
 	})
 	if err != nil {
 		slog.Error("group: failed to cancel invite", "err", err)
 		return g.redirectUsersPage(c, groupID, "", "groups.errors.invite_cancel_failed", http.StatusInternalServerError)
 	}
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
 
 	return g.redirectUsersPage(c, groupID, "groups.messages.invite_cancelled", "", http.StatusOK)
 }
@@ -1304,6 +1543,27 @@ func sendRoleChangeEmail(ctx context.Context, user db.User, groupName, groupID,
 	return email.Email().SendRoleDowngradedToViewer(mailCtx, user.Email, groupName, groupID, baseURL)
 }
 
+// pushAccessChange tells the open tabs of a user that their access to the
+// group changed. Removed users are sent to /groups; otherwise their pages on
+// the group reload to show the controls of the new role.
+func pushAccessChange(c echo.Context, groupID, userID, role string) {
+	group, err := groupstore.GetGroupByID(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Warn("group: failed to load group for access-change push", "group_id", groupID, "user_id", userID, "err", err)
+		return
+	}
+
+	if role == "" {
+		utils.SSEHub.RedirectUser(c, userID, groupID, "/groups", func(ctx context.Context) string {
+			return ctxi18n.T(ctx, "groups.messages.access_removed", group.Name)
+		})
+		return
+	}
+	utils.SSEHub.ReloadUser(c, userID, groupID, func(ctx context.Context) string {
+		return ctxi18n.T(ctx, "groups.messages.role_changed_"+role, group.Name)
+	})
+}
+
 func notifyAccessRemoved(ctx context.Context, groupID, userID string) {
 	group, err := groupstore.GetGroupByID(ctx, groupID)
 	if err != nil {
@@ -1641,6 +1901,18 @@ func notifyPaidToggleResult(c echo.Context, paid int64) {
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "paid_status.marked_as_unpaid"))
 }
 
+// expenseTopics are the hub topics to publish when an expense changes.
+func expenseTopics(groupID string, expense db.Expense) []string {
+	topics := []string{utils.GroupTopic(groupID)}
+	if expense.EventID.Valid {
+		topics = append(topics, utils.EventTopic(expense.EventID.String))
+	}
+	if expense.PaidByMemberID.Valid {
+		topics = append(topics, utils.MemberTopic(expense.PaidByMemberID.String))
+	}
+	return topics
+}
+
 func paymentsPaidAtFromNullString(value sql.NullString) string {
 	if !value.Valid {
 		return ""
@@ -1739,14 +2011,24 @@ func (g *Group) toReceivePageData(c echo.Context, groupID string, query utils.Ta
 	if err != nil {
 		return GroupToReceivePageData{}, err
 	}
+	payments, err := eventstore.SumEventPaymentsByGroup(ctx, groupID)
+	if err != nil {
+		return GroupToReceivePageData{}, err
+	}
 	events := make([]GroupPaymentEventRow, 0, len(rows))
 	for _, row := range rows {
+		received := payments[row.ID].Received
+		outstanding := eventstore.OutstandingAmount(row, received)
 		events = append(events, GroupPaymentEventRow{
-			ID:     row.ID,
-			Title:  row.Title,
-			Amount: row.Amount,
-			PaidAt: paymentsPaidAtFromNullString(row.PaidAt),
-			Date:   utils.FormatDateInput(row.Time),
+			ID:          row.ID,
+			Title:       row.Title,
+			Amount:      outstanding,
+			Received:    received,
+			Outstanding: outstanding,
+			Currency:    currency.Of(row.Currency, group.BaseCurrency),
+			PaidAt:      paymentsPaidAtFromNullString(row.PaidAt),
+			Date:        utils.FormatDateInput(row.Time),
+			Cancelled:   row.Status == eventstore.EventStatusCancelled,
 		})
 	}
 	events = filterPaymentEventRows(events, query)
@@ -1790,17 +2072,22 @@ func (g *Group) toPayPageData(c echo.Context, groupID string, query utils.TableQ
 	}
 	rows := make([]GroupOutgoingPaymentRow, 0, len(outgoingRows))
 	for _, row := range outgoingRows {
+		paid := eventstore.PaidOutAmount(row.Amount, row.PaidAmount, row.Paid)
+		outstanding := row.Amount - paid
 		rows = append(rows, GroupOutgoingPaymentRow{
-			Kind:       row.PaymentKind,
-			PaymentID:  row.PaymentID,
-			Title:      row.Title,
-			EventID:    row.EventID,
-			EventTitle: row.EventTitle,
-			MemberID:   row.MemberID,
-			MemberName: row.MemberName,
-			Amount:     row.Amount,
-			PaidAt:     paymentsPaidAtFromNullString(row.PaidAt),
-			Date:       utils.FormatDateInput(row.SortDate),
+			Kind:        row.PaymentKind,
+			PaymentID:   row.PaymentID,
+			Title:       row.Title,
+			EventID:     row.EventID,
+			EventTitle:  row.EventTitle,
+			MemberID:    row.MemberID,
+			MemberName:  row.MemberName,
+			Amount:      outstanding,
+			PaidAmount:  paid,
+			Outstanding: outstanding,
+			Currency:    currency.Of(row.Currency, group.BaseCurrency),
+			PaidAt:      paymentsPaidAtFromNullString(row.PaidAt),
+			Date:        utils.FormatDateInput(row.SortDate),
 		})
 	}
 	rows = filterOutgoingPaymentRows(rows, query)
@@ -1842,14 +2129,31 @@ func (g *Group) recentIncomePageData(c echo.Context, groupID string, query utils
 	if err != nil {
 		return GroupRecentIncomePageData{}, err
 	}
+	payments, err := eventstore.SumEventPaymentsByGroup(ctx, groupID)
+	if err != nil {
+		return GroupRecentIncomePageData{}, err
+	}
 	events := make([]GroupPaymentEventRow, 0, len(rows))
 	for _, row := range rows {
+		summary := payments[row.ID]
+		received := summary.Received
+		paidAt := paymentsPaidAtFromNullString(row.PaidAt)
+		if row.Paid == 0 {
+			paidAt = summary.LastPaidAt
+		} else if received == 0 {
+			received = eventstore.IncomeAmount(row)
+		}
 		events = append(events, GroupPaymentEventRow{
-			ID:     row.ID,
-			Title:  row.Title,
-			Amount: row.Amount,
-			PaidAt: paymentsPaidAtFromNullString(row.PaidAt),
-			Date:   utils.FormatDateInput(row.Time),
+			ID:          row.ID,
+			Title:       row.Title,
+			Amount:      received,
+			Received:    received,
+			Outstanding: eventstore.OutstandingAmount(row, received),
+			Paid:        row.Paid == 1,
+			Currency:    currency.Of(row.Currency, group.BaseCurrency),
+			PaidAt:      paidAt,
+			Date:        utils.FormatDateInput(row.Time),
+			Cancelled:   row.Status == eventstore.EventStatusCancelled,
 		})
 	}
 	events = filterPaymentEventRows(events, query)
@@ -1893,17 +2197,22 @@ func (g *Group) recentOutgoingPageData(c echo.Context, groupID string, query uti
 	}
 	rows := make([]GroupOutgoingPaymentRow, 0, len(outgoingRows))
 	for _, row := range outgoingRows {
+		paid := eventstore.PaidOutAmount(row.Amount, row.PaidAmount, row.Paid)
 		rows = append(rows, GroupOutgoingPaymentRow{
-			Kind:       row.PaymentKind,
-			PaymentID:  row.PaymentID,
-			Title:      row.Title,
-			EventID:    row.EventID,
-			EventTitle: row.EventTitle,
-			MemberID:   row.MemberID,
-			MemberName: row.MemberName,
-			Amount:     row.Amount,
-			PaidAt:     paymentsPaidAtFromNullString(row.PaidAt),
-			Date:       utils.FormatDateInput(row.SortDate),
+			Kind:        row.PaymentKind,
+			PaymentID:   row.PaymentID,
+			Title:       row.Title,
+			EventID:     row.EventID,
+			EventTitle:  row.EventTitle,
+			MemberID:    row.MemberID,
+			MemberName:  row.MemberName,
+			Amount:      paid,
+			PaidAmount:  paid,
+			Outstanding: row.Amount - paid,
+			Paid:        row.Paid == 1,
+			Currency:    currency.Of(row.Currency, group.BaseCurrency),
+			PaidAt:      paymentsPaidAtFromNullString(row.PaidAt),
+			Date:        utils.FormatDateInput(row.SortDate),
 		})
 	}
 	rows = filterOutgoingPaymentRows(rows, query)
@@ -10,6 +10,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	internalbilling "bandcash/internal/billing"
+	"bandcash/internal/currency"
 	"bandcash/internal/utils"
 	authstore "bandcash/models/auth/data"
 	groupstore "bandcash/models/group/data"
@@ -51,8 +52,14 @@ func (g *Group) EditGroupPage(c echo.Context) error {
 		GroupID: groupID,
 		Group:   group,
 		Signals: map[string]any{
-			"formData": map[string]any{"name": group.Name},
-			"errors":   map[string]any{"name": ""},
+			"formData": map[string]any{
+				"name":           group.Name,
+				"baseCurrency":   currency.Of("", group.BaseCurrency),
+				"billingName":    group.BillingName,
+				"billingAddress": group.BillingAddress,
+				"taxNumber":      group.TaxNumber,
+			},
+			"errors": utils.GetEmptyErrors(groupEditErrorFields),
 		},
 		IsAuthenticated: true,
 		IsSuperAdmin:    utils.IsSuperadmin(c),
@@ -55,10 +55,11 @@ func convertUserGroupRowsToGroupWithRole(rows []groupstore.UserGroupRow) []Group
 	for i, r := range rows {
 		result[i] = GroupWithRole{
 			Group: db.Group{
-				ID:          r.ID,
-				Name:        r.Name,
-				AdminUserID: r.AdminUserID,
-				CreatedAt:   r.CreatedAt,
+				ID:           r.ID,
+				Name:         r.Name,
+				AdminUserID:  r.AdminUserID,
+				CreatedAt:    r.CreatedAt,
+				BaseCurrency: r.BaseCurrency,
 			},
 			Role: r.Role,
 		}
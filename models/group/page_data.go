@@ -213,25 +213,40 @@ type GroupRecentOutgoingPageData struct {
 	FadeRowKey      string
 }
 
+// GroupOutgoingPaymentRow is a payout or expense on the outgoing pages.
+// Amount is the outstanding remainder on pending payouts and the paid part
+// on recent ones.
 type GroupOutgoingPaymentRow struct {
-	Kind       string
-	PaymentID  string
-	Title      string
-	EventID    string
-	EventTitle string
-	MemberID   string
-	MemberName string
-	Amount     int64
-	PaidAt     string
-	Date       string
+	Kind        string
+	PaymentID   string
+	Title       string
+	EventID     string
+	EventTitle  string
+	MemberID    string
+	MemberName  string
+	Amount      int64
+	PaidAmount  int64
+	Outstanding int64
+	Paid        bool
+	Currency    string
+	PaidAt      string
+	Date        string
 }
 
+// GroupPaymentEventRow is an event on the income pages. Amount is what the
+// page is about: the outstanding remainder on pending incomes, the received
+// part on recent incomes.
 type GroupPaymentEventRow struct {
-	ID     string
-	Title  string
-	Amount int64
-	PaidAt string
-	Date   string
+	ID          string
+	Title       string
+	Amount      int64
+	Received    int64
+	Outstanding int64
+	Paid        bool
+	Currency    string
+	PaidAt      string
+	Date        string
+	Cancelled   bool
 }
 
 type GroupPaymentParticipantRow struct {
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"context"
+	"strings"
+
+	ctxi18nlib "github.com/invopop/ctxi18n"
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+)
+
+// Import kinds, also used as the list page each import returns to.
+const (
+	kindMembers  = "members"
+	kindEvents   = "events"
+	kindExpenses = "expenses"
+)
+
+// kinds lists the import kinds in the order they are offered.
+var kinds = []string{kindMembers, kindEvents, kindExpenses}
+
+// field is a value the importer can fill from a column. Keys double as CSV
+// column names (csv.columns.*), so exported files map themselves.
+type field struct {
+	Key      string
+	Required bool
+}
+
+var kindFields = map[string][]field{
+	kindMembers: {
+		{Key: "name", Required: true},
+		{Key: "description"},
+	},
+	kindEvents: {
+		{Key: "title", Required: true},
+		{Key: "date", Required: true},
+		{Key: "time", Required: true},
+		{Key: "place"},
+		{Key: "description"},
+		{Key: "amount", Required: true},
+		{Key: "currency"},
+		{Key: "exchange_rate"},
+		{Key: "paid"},
+		{Key: "paid_at"},
+		{Key: "member"},
+		{Key: "member_amount"},
+		{Key: "member_expense"},
+		{Key: "member_paid"},
+		{Key: "member_paid_at"},
+	},
+	kindExpenses: {
+		{Key: "title", Required: true},
+		{Key: "date", Required: true},
+		{Key: "description"},
+		{Key: "amount", Required: true},
+		{Key: "currency"},
+		{Key: "exchange_rate"},
+		{Key: "paid"},
+		{Key: "paid_at"},
+	},
+}
+
+func isValidKind(kind string) bool {
+	_, ok := kindFields[kind]
+	return ok
+}
+
+func fieldLabel(ctx context.Context, key string) string {
+	return ctxi18n.T(ctx, "csv.columns."+key)
+}
+
+// guessMapping matches header cells to fields by key or by the column label
+// in any supported language. Unmatched fields map to "".
+func guessMapping(ctx context.Context, kind string, header []string) map[string]string {
+	columns := make(map[string]int, len(header))
+	for i, cell := range header {
+		name := normalizeHeader(cell)
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+
+	mapping := make(map[string]string, len(kindFields[kind]))
+	for _, f := range kindFields[kind] {
+		mapping[f.Key] = ""
+		for _, name := range headerNames(ctx, f.Key) {
+			if i, ok := columns[name]; ok {
+				mapping[f.Key] = columnValue(i)
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+func headerNames(ctx context.Context, key string) []string {
+	names := []string{normalizeHeader(key)}
+	for _, locale := range []string{"en", "hu"} {
+		localized, err := ctxi18nlib.WithLocale(ctx, locale)
+		if err != nil {
+			continue
+		}
+		names = append(names, normalizeHeader(fieldLabel(localized, key)))
+	}
+	return names
+}
+
+func normalizeHeader(s string) string {
+	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(s, "_", " ")))
+}
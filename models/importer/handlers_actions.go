@@ -0,0 +1,238 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
+)
+
+// Upload reads the file from a multipart form, guesses the column mapping
+// and hands the file back to the page in signals for the next steps.
+func Upload(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	if !utils.SetTabID(c, c.FormValue("tab_id")) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	kind := c.FormValue("kind")
+	format, ok := spreadsheet.ParseFormat(c.FormValue("format"))
+	if !isValidKind(kind) || !ok {
+		slog.Info("import.upload: invalid kind or format", "kind", kind)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Notify(c, ctxi18n.T(ctx, "csv.errors.missing_file"))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+	if fileHeader.Size > maxImportBytes {
+		utils.Notify(c, ctxi18n.T(ctx, "csv.errors.too_large", maxImportBytes>>10))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("import.upload: failed to open file", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	content, err := io.ReadAll(io.LimitReader(file, maxImportBytes+1))
+	if err != nil || len(content) > maxImportBytes {
+		utils.Notify(c, ctxi18n.T(ctx, "csv.errors.too_large", maxImportBytes>>10))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	table, ok := readTable(c, string(content))
+	if !ok {
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	if err := utils.SSEHub.PatchSignals(c, map[string]any{"importForm": map[string]any{
+		"kind":     kind,
+		"format":   string(format),
+		"csv":      string(content),
+		"fileName": fileHeader.Filename,
+		"mapping":  guessMapping(ctx, kind, table.Header),
+	}}); err != nil {
+		slog.Warn("import.upload: failed to patch signals", "err", err)
+	}
+
+	mapping, err := utils.RenderHTMLForRequest(c, ImportMapping(MappingProps{
+		GroupID:  groupID,
+		Kind:     kind,
+		FileName: fileHeader.Filename,
+		Header:   table.Header,
+		Fields:   kindFields[kind],
+	}))
+	if err != nil {
+		slog.Error("import.upload: failed to render", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	utils.SSEHub.PatchHTML(c, mapping)
+	utils.SSEHub.PatchHTML(c, `<div id="import-preview"></div>`)
+	return c.NoContent(http.StatusOK)
+}
+
+// Preview checks every row against the current mapping without writing.
+func Preview(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+
+	form, table, ok := readForm(c)
+	if !ok {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	p, err := planForm(c.Request().Context(), groupID, form, table)
+	if err != nil {
+		slog.Error("import.preview: failed to load members", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if err := patchPreview(c, groupID, form.Kind, p, len(table.Rows)); err != nil {
+		slog.Error("import.preview: failed to render", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// Commit checks the file again and writes every row in one transaction, or
+// nothing when any row is invalid.
+func Commit(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	form, table, ok := readForm(c)
+	if !ok {
+		return c.NoContent(http.StatusBadRequest)
+	}
+	p, err := planForm(ctx, groupID, form, table)
+	if err != nil {
+		slog.Error("import.commit: failed to load members", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !p.ok() {
+		if err := patchPreview(c, groupID, form.Kind, p, len(table.Rows)); err != nil {
+			slog.Error("import.commit: failed to render", "err", err)
+		}
+		utils.Notify(c, ctxi18n.T(ctx, "csv.notifications.fix_errors"))
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		changes, err := commitPlan(ctx, p)
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, changes...)
+	})
+	if err != nil {
+		slog.Error("import.commit: failed to import", "group_id", groupID, "kind", form.Kind, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "csv.notifications.import_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	utils.InvalidateGroupCaches(groupID)
+
+	slog.Info("import.commit: imported", "group_id", groupID, "kind", form.Kind, "rows", len(table.Rows))
+	utils.Notify(c, ctxi18n.T(ctx, "csv.notifications.imported", planSummary(ctx, form.Kind, p)))
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, listPath(groupID, form.Kind)); err != nil {
+		slog.Warn("import.commit: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func readForm(c echo.Context) (importFormData, spreadsheet.Table, bool) {
+	var signals importParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("import: failed to read signals", "err", err)
+		return importFormData{}, spreadsheet.Table{}, false
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return importFormData{}, spreadsheet.Table{}, false
+	}
+
+	form := signals.ImportForm
+	if _, ok := spreadsheet.ParseFormat(form.Format); !ok || !isValidKind(form.Kind) || len(form.CSV) > maxImportBytes {
+		slog.Info("import: invalid form", "kind", form.Kind)
+		return importFormData{}, spreadsheet.Table{}, false
+	}
+	table, ok := readTable(c, form.CSV)
+	return form, table, ok
+}
+
+// readTable parses the file and notifies the user when it cannot be read.
+func readTable(c echo.Context, content string) (spreadsheet.Table, bool) {
+	ctx := c.Request().Context()
+	table, err := spreadsheet.Read(strings.NewReader(content), maxImportRows)
+	switch {
+	case err == nil:
+		return table, true
+	case errors.Is(err, spreadsheet.ErrTooManyRows):
+		utils.Notify(c, ctxi18n.T(ctx, "csv.errors.too_many_rows", maxImportRows))
+	case errors.Is(err, spreadsheet.ErrEmpty):
+		utils.Notify(c, ctxi18n.T(ctx, "csv.errors.empty"))
+	default:
+		slog.Info("import: unreadable csv", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "csv.errors.unreadable"))
+	}
+	return spreadsheet.Table{}, false
+}
+
+func planForm(ctx context.Context, groupID string, form importFormData, table spreadsheet.Table) (plan, error) {
+	members, err := memberstore.ListMembers(ctx, groupID)
+	if err != nil {
+		return plan{}, err
+	}
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return plan{}, err
+	}
+	format, _ := spreadsheet.ParseFormat(form.Format)
+	return buildPlan(ctx, planInput{
+		GroupID:      groupID,
+		Kind:         form.Kind,
+		Format:       format,
+		Table:        table,
+		Mapping:      form.Mapping,
+		Members:      members,
+		BaseCurrency: group.BaseCurrency,
+	}), nil
+}
+
+func patchPreview(c echo.Context, groupID, kind string, p plan, total int) error {
+	html, err := utils.RenderHTMLForRequest(c, ImportPreview(PreviewProps{
+		GroupID:  groupID,
+		Kind:     kind,
+		Fields:   kindFields[kind],
+		Problems: p.Problems,
+		Errors:   p.Errors,
+		Rows:     p.Preview,
+		Total:    total,
+		Summary:  planSummary(c.Request().Context(), kind, p),
+		Ready:    p.ok(),
+	}))
+	if err != nil {
+		return err
+	}
+	return utils.SSEHub.PatchHTML(c, html)
+}
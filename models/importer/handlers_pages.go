@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+)
+
+func IndexPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	kind := c.QueryParam("kind")
+	if !isValidKind(kind) {
+		kind = kindEvents
+	}
+	format := string(spreadsheet.FormatForLocale(appi18n.LocaleCode(ctx)))
+
+	data, err := GetPageData(ctx, groupID, kind, format)
+	if err != nil {
+		slog.Error("import.index: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.Signals = importSignals(kind, format)
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, ImportPage(data))
+}
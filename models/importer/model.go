@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"context"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
+	expensestore "bandcash/models/expense/data"
+	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
+)
+
+func GetPageData(ctx context.Context, groupID, kind, format string) (ImportData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return ImportData{}, err
+	}
+
+	return ImportData{
+		Title:   ctxi18n.T(ctx, "csv.page_title"),
+		GroupID: groupID,
+		Kind:    kind,
+		Format:  format,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, kind+".title"), Href: listPath(groupID, kind)},
+			{Label: ctxi18n.T(ctx, "csv.import")},
+		},
+	}, nil
+}
+
+// commitPlan writes everything in one transaction, so a failing row leaves
+// the group untouched. It returns the created records for the audit log.
+func commitPlan(ctx context.Context, p plan) ([]audit.Change, error) {
+	var changes []audit.Change
+	err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		changes = changes[:0]
+		for _, member := range p.Members {
+			created, err := memberstore.CreateMemberTx(ctx, tx, member)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterMember, EntityID: created.ID, After: created})
+		}
+		for _, event := range p.Events {
+			created, err := eventstore.CreateEventTx(ctx, tx, event.Event)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterEvent, EntityID: created.ID, After: created})
+			for _, participant := range event.Participants {
+				added, err := eventstore.AddParticipantTx(ctx, tx, participant)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(added.EventID, added.MemberID), After: added})
+			}
+		}
+		for _, expense := range p.Expenses {
+			created, err := expensestore.CreateExpenseTx(ctx, tx, expense)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterExpense, EntityID: created.ID, After: created})
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// planSummary describes what an import will create.
+func planSummary(ctx context.Context, kind string, p plan) string {
+	switch kind {
+	case kindMembers:
+		return ctxi18n.T(ctx, "csv.summary.members", len(p.Members))
+	case kindEvents:
+		return ctxi18n.T(ctx, "csv.summary.events", len(p.Events), p.participantCount())
+	default:
+		return ctxi18n.T(ctx, "csv.summary.expenses", len(p.Expenses))
+	}
+}
@@ -0,0 +1,37 @@
+package importer
+
+import (
+	"bandcash/internal/utils"
+)
+
+type ImportData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	Kind            string
+	Format          string
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
+
+type MappingProps struct {
+	GroupID  string
+	Kind     string
+	FileName string
+	Header   []string
+	Fields   []field
+}
+
+type PreviewProps struct {
+	GroupID  string
+	Kind     string
+	Fields   []field
+	Problems []string
+	Errors   []rowError
+	Rows     []previewRow
+	// Total is the number of data rows in the file.
+	Total   int
+	Summary string
+	Ready   bool
+}
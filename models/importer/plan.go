@@ -0,0 +1,434 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	expensestore "bandcash/models/expense/data"
+	memberstore "bandcash/models/member/data"
+)
+
+type planInput struct {
+	GroupID string
+	Kind    string
+	Format  spreadsheet.Format
+	Table   spreadsheet.Table
+	Mapping map[string]string
+	// Members are the group's existing members, matched by name.
+	Members []db.Member
+	// BaseCurrency is the group's, which rows without a currency are in.
+	BaseCurrency string
+}
+
+type plannedEvent struct {
+	Event        eventstore.CreateEventParams
+	Participants []eventstore.AddParticipantParams
+}
+
+// plan is the result of checking a file: what would be created, or why not.
+// Nothing is written unless Errors and Problems are both empty.
+type plan struct {
+	Members  []memberstore.CreateMemberParams
+	Events   []plannedEvent
+	Expenses []expensestore.CreateExpenseParams
+	// Problems are file-level issues, such as a required field left unmapped.
+	Problems []string
+	Errors   []rowError
+	Preview  []previewRow
+}
+
+type rowError struct {
+	Line     int
+	Messages []string
+}
+
+type previewRow struct {
+	Line   int
+	Values []string
+}
+
+func (p plan) ok() bool {
+	return len(p.Problems) == 0 && len(p.Errors) == 0 && p.count() > 0
+}
+
+func (p plan) count() int {
+	return len(p.Members) + len(p.Events) + len(p.Expenses)
+}
+
+func (p plan) participantCount() int {
+	n := 0
+	for _, event := range p.Events {
+		n += len(event.Participants)
+	}
+	return n
+}
+
+// previewLimit caps the rows echoed back; errors are always listed in full.
+const previewLimit = 20
+
+// Row shapes validated with the same rules as the create forms.
+type memberRow struct {
+	Name        string `json:"name" validate:"required,min=1,max=255"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+type eventRow struct {
+	Title         string `json:"title" validate:"required,min=1,max=255"`
+	Date          string `json:"date" validate:"required"`
+	Time          string `json:"time" validate:"required"`
+	Place         string `json:"place" validate:"max=255"`
+	Description   string `json:"description" validate:"max=1000"`
+	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	MemberAmount  int64  `json:"member_amount" validate:"gte=0"`
+	MemberExpense int64  `json:"member_expense" validate:"gte=0"`
+}
+
+type expenseRow struct {
+	Title       string `json:"title" validate:"required,min=1,max=255"`
+	Description string `json:"description" validate:"max=1000"`
+	Amount      int64  `json:"amount" validate:"required,gt=0"`
+	Date        string `json:"date" validate:"required"`
+}
+
+// buildPlan parses and validates every row. Event rows describing the same
+// gig (title, date, time and place) are merged, each adding a participant,
+// which is how the event export flattens them.
+func buildPlan(ctx context.Context, in planInput) plan {
+	var out plan
+	fields := kindFields[in.Kind]
+
+	columns := make(map[string]int, len(fields))
+	for _, f := range fields {
+		column, ok := parseColumn(in.Mapping[f.Key], len(in.Table.Header))
+		if ok {
+			columns[f.Key] = column
+		} else if f.Required {
+			out.Problems = append(out.Problems, ctxi18n.T(ctx, "csv.errors.unmapped", fieldLabel(ctx, f.Key)))
+		}
+	}
+	if len(out.Problems) > 0 {
+		return out
+	}
+
+	membersByName := make(map[string]string, len(in.Members))
+	for _, member := range in.Members {
+		membersByName[memberKey(member.Name)] = member.ID
+	}
+	eventIndex := make(map[string]int)
+
+	for _, row := range in.Table.Rows {
+		r := rowReader{ctx: ctx, format: in.Format, fields: fields, row: row, columns: columns}
+
+		switch in.Kind {
+		case kindMembers:
+			values := memberRow{Name: r.text("name"), Description: r.text("description")}
+			r.validate(values)
+			key := memberKey(values.Name)
+			if _, exists := membersByName[key]; exists && values.Name != "" {
+				r.fail("name", ctxi18n.T(ctx, "csv.errors.duplicate_member", values.Name))
+			}
+			if r.failed() {
+				break
+			}
+			membersByName[key] = ""
+			out.Members = append(out.Members, memberstore.CreateMemberParams{
+				ID:          utils.GenerateID(utils.PrefixMember),
+				GroupID:     in.GroupID,
+				Name:        values.Name,
+				Description: values.Description,
+			})
+
+		case kindEvents:
+			values := eventRow{
+				Title:         r.text("title"),
+				Date:          r.date("date"),
+				Time:          r.timeOfDay("time"),
+				Place:         r.text("place"),
+				Description:   r.text("description"),
+				Amount:        r.amount("amount"),
+				MemberAmount:  r.amount("member_amount"),
+				MemberExpense: r.amount("member_expense"),
+			}
+			entryCurrency, exchangeRate := r.entryCurrency(in.BaseCurrency)
+			paid := r.yesNo("paid")
+			paidAt := r.date("paid_at")
+			memberName := r.text("member")
+			memberPaid := r.yesNo("member_paid")
+			memberPaidAt := r.date("member_paid_at")
+			r.validate(values)
+
+			memberID := ""
+			if memberName != "" {
+				id, ok := membersByName[memberKey(memberName)]
+				if !ok || id == "" {
+					r.fail("member", ctxi18n.T(ctx, "csv.errors.unknown_member", memberName))
+				}
+				memberID = id
+			} else if values.MemberAmount != 0 || values.MemberExpense != 0 {
+				r.fail("member", ctxi18n.T(ctx, "validation.required"))
+			}
+
+			key := strings.Join([]string{values.Title, values.Date, values.Time, values.Place}, "\x00")
+			index, seen := eventIndex[key]
+			if seen && memberID != "" {
+				for _, participant := range out.Events[index].Participants {
+					if participant.MemberID == memberID {
+						r.fail("member", ctxi18n.T(ctx, "csv.errors.duplicate_participant", memberName))
+						break
+					}
+				}
+			}
+			if r.failed() {
+				break
+			}
+
+			if !seen {
+				eventID := utils.GenerateID(utils.PrefixEvent)
+				out.Events = append(out.Events, plannedEvent{Event: eventstore.CreateEventParams{
+					ID:           eventID,
+					GroupID:      in.GroupID,
+					Title:        values.Title,
+					Date:         values.Date,
+					EventTime:    values.Time,
+					Place:        values.Place,
+					Description:  values.Description,
+					Amount:       values.Amount,
+					Currency:     entryCurrency,
+					ExchangeRate: exchangeRate,
+					Paid:         boolInt(paid),
+					PaidAt:       paidAtArg(paid, paidAt),
+					PaymentID:    utils.GenerateID(utils.PrefixPayment),
+				}})
+				index = len(out.Events) - 1
+				eventIndex[key] = index
+			}
+			if memberID != "" {
+				event := &out.Events[index]
+				event.Participants = append(event.Participants, eventstore.AddParticipantParams{
+					GroupID:  in.GroupID,
+					EventID:  event.Event.ID,
+					MemberID: memberID,
+					Amount:   values.MemberAmount,
+					Expense:  values.MemberExpense,
+					Paid:     boolInt(memberPaid),
+					PaidAt:   paidAtArg(memberPaid, memberPaidAt),
+					PayoutID: utils.GenerateID(utils.PrefixPayout),
+				})
+			}
+
+		case kindExpenses:
+			values := expenseRow{
+				Title:       r.text("title"),
+				Description: r.text("description"),
+				Amount:      r.amount("amount"),
+				Date:        r.date("date"),
+			}
+			entryCurrency, exchangeRate := r.entryCurrency(in.BaseCurrency)
+			paid := r.yesNo("paid")
+			paidAt := r.date("paid_at")
+			r.validate(values)
+			if r.failed() {
+				break
+			}
+			out.Expenses = append(out.Expenses, expensestore.CreateExpenseParams{
+				ID:           utils.GenerateID(utils.PrefixExpense),
+				GroupID:      in.GroupID,
+				Title:        values.Title,
+				Description:  values.Description,
+				Amount:       values.Amount,
+				Currency:     entryCurrency,
+				ExchangeRate: exchangeRate,
+				Date:         values.Date,
+				Paid:         boolInt(paid),
+				PaidAt:       paidAtArg(paid, paidAt),
+			})
+		}
+
+		if r.failed() {
+			out.Errors = append(out.Errors, rowError{Line: row.Line, Messages: r.messages})
+		}
+		if len(out.Preview) < previewLimit {
+			values := make([]string, len(fields))
+			for i, f := range fields {
+				values[i] = r.display(f.Key)
+			}
+			out.Preview = append(out.Preview, previewRow{Line: row.Line, Values: values})
+		}
+	}
+	return out
+}
+
+// rowReader reads mapped cells of one row and collects their errors, one
+// message per field.
+type rowReader struct {
+	ctx      context.Context
+	format   spreadsheet.Format
+	fields   []field
+	row      spreadsheet.Row
+	columns  map[string]int
+	messages []string
+	failedOn map[string]bool
+}
+
+func (r *rowReader) raw(key string) string {
+	column, ok := r.columns[key]
+	if !ok {
+		return ""
+	}
+	return r.row.Field(column)
+}
+
+func (r *rowReader) text(key string) string {
+	return r.raw(key)
+}
+
+func (r *rowReader) amount(key string) int64 {
+	value, err := spreadsheet.ParseAmount(r.raw(key), r.format)
+	if errors.Is(err, spreadsheet.ErrFractional) {
+		r.fail(key, ctxi18n.T(r.ctx, "csv.errors.whole_number"))
+	} else if err != nil {
+		r.fail(key, ctxi18n.T(r.ctx, "csv.errors.number"))
+	}
+	return value
+}
+
+// entryCurrency reads the currency and exchange_rate columns into what is
+// stored on an event or expense. An empty currency is the base currency; any
+// other needs a rate.
+func (r *rowReader) entryCurrency(base string) (string, float64) {
+	code := currency.Normalize(r.raw("currency"))
+	if code == "" && r.raw("currency") != "" {
+		r.fail("currency", ctxi18n.T(r.ctx, "validation.currency"))
+	}
+	rate, err := spreadsheet.ParseRate(r.raw("exchange_rate"), r.format)
+	if err != nil {
+		r.fail("exchange_rate", ctxi18n.T(r.ctx, "csv.errors.number"))
+	} else if code != "" && code != currency.Of("", base) && !currency.ValidRate(rate) {
+		r.fail("exchange_rate", ctxi18n.T(r.ctx, "validation.required"))
+	}
+	return currency.ForEntry(code, rate, base)
+}
+
+func (r *rowReader) date(key string) string {
+	value, err := spreadsheet.ParseDate(r.raw(key), r.format)
+	if err != nil {
+		r.fail(key, ctxi18n.T(r.ctx, "csv.errors.date."+string(r.format)))
+	}
+	return value
+}
+
+func (r *rowReader) timeOfDay(key string) string {
+	value, err := spreadsheet.ParseTime(r.raw(key), r.format)
+	if err != nil {
+		r.fail(key, ctxi18n.T(r.ctx, "csv.errors.time"))
+	}
+	return value
+}
+
+func (r *rowReader) yesNo(key string) bool {
+	value, err := spreadsheet.ParseBool(r.raw(key))
+	if err != nil {
+		r.fail(key, ctxi18n.T(r.ctx, "csv.errors.yes_no"))
+	}
+	return value
+}
+
+// validate applies the struct's validate tags. Fields that already failed to
+// parse keep their parse error.
+func (r *rowReader) validate(values any) {
+	errs := utils.ValidateWithLocale(r.ctx, values)
+	for _, f := range r.fields {
+		if message, ok := errs[f.Key]; ok {
+			r.fail(f.Key, message)
+		}
+	}
+}
+
+func (r *rowReader) fail(key, message string) {
+	if r.failedOn == nil {
+		r.failedOn = make(map[string]bool)
+	}
+	if r.failedOn[key] {
+		return
+	}
+	r.failedOn[key] = true
+	r.messages = append(r.messages, ctxi18n.T(r.ctx, "csv.field_error", fieldLabel(r.ctx, key), message))
+}
+
+func (r *rowReader) failed() bool {
+	return len(r.messages) > 0
+}
+
+// display shows a cell the way it will be stored, or as typed when it did not
+// parse.
+func (r *rowReader) display(key string) string {
+	raw := r.raw(key)
+	if raw == "" || r.failedOn[key] {
+		return raw
+	}
+	switch key {
+	case "date", "paid_at", "member_paid_at":
+		value, _ := spreadsheet.ParseDate(raw, r.format)
+		return utils.FormatDateLocalized(r.ctx, value)
+	case "time":
+		value, _ := spreadsheet.ParseTime(raw, r.format)
+		return value
+	case "amount", "member_amount", "member_expense":
+		value, _ := spreadsheet.ParseAmount(raw, r.format)
+		return utils.FormatNumberLocalized(r.ctx, value)
+	case "currency":
+		return strings.ToUpper(raw)
+	case "exchange_rate":
+		value, _ := spreadsheet.ParseRate(raw, r.format)
+		return utils.FormatExchangeRateLocalized(r.ctx, value)
+	case "paid", "member_paid":
+		value, _ := spreadsheet.ParseBool(raw)
+		if value {
+			return ctxi18n.T(r.ctx, "csv.yes")
+		}
+		return ctxi18n.T(r.ctx, "csv.no")
+	default:
+		return raw
+	}
+}
+
+func memberKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func columnValue(i int) string {
+	return strconv.Itoa(i)
+}
+
+func parseColumn(value string, columns int) (int, bool) {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 || i >= columns {
+		return 0, false
+	}
+	return i, true
+}
+
+func boolInt(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// paidAtArg leaves paid_at empty for unpaid rows; paid rows without a date
+// get the current time from the store.
+func paidAtArg(paid bool, date string) any {
+	if !paid || date == "" {
+		return nil
+	}
+	return date
+}
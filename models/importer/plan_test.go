@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"context"
+	"testing"
+
+	"bandcash/internal/db"
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/spreadsheet"
+)
+
+func TestGuessMapping(t *testing.T) {
+	if err := appi18n.Load(); err != nil {
+		t.Fatalf("load locales: %v", err)
+	}
+
+	header := []string{"Dátum", "Cím", "time", "Összeg", "Whatever", "Tag összege", "Member"}
+	got := guessMapping(context.Background(), kindEvents, header)
+
+	want := map[string]string{
+		"date":          "0",
+		"title":         "1",
+		"time":          "2",
+		"amount":        "3",
+		"member_amount": "5",
+		"member":        "6",
+		"place":         "",
+		"paid":          "",
+	}
+	for key, column := range want {
+		if got[key] != column {
+			t.Errorf("mapping[%q] = %q, want %q", key, got[key], column)
+		}
+	}
+}
+
+func TestBuildPlanEvents(t *testing.T) {
+	t.Parallel()
+
+	in := planInput{
+		GroupID: "grp_1",
+		Kind:    kindEvents,
+		Format:  spreadsheet.FormatHungarian,
+		Table: spreadsheet.Table{
+			Header: []string{"title", "date", "time", "amount", "member", "member_amount"},
+			Rows: []spreadsheet.Row{
+				{Line: 2, Fields: []string{"Gig", "2026.03.14.", "20:00", "120.000", "Anna", "60 000"}},
+				{Line: 3, Fields: []string{"Gig", "2026.03.14.", "20:00", "120.000", "bob", "60000"}},
+				{Line: 4, Fields: []string{"Festival", "2026-04-01", "18.30", "50000", "", ""}},
+			},
+		},
+		Mapping: map[string]string{"title": "0", "date": "1", "time": "2", "amount": "3", "member": "4", "member_amount": "5"},
+		Members: []db.Member{{ID: "mem_a", Name: "Anna"}, {ID: "mem_b", Name: "Bob"}},
+	}
+
+	p := buildPlan(context.Background(), in)
+	if !p.ok() {
+		t.Fatalf("plan not ok: problems %v, errors %v", p.Problems, p.Errors)
+	}
+	if len(p.Events) != 2 {
+		t.Fatalf("got %d events, want 2", len(p.Events))
+	}
+
+	gig := p.Events[0]
+	if gig.Event.Date != "2026-03-14" || gig.Event.EventTime != "20:00" || gig.Event.Amount != 120000 {
+		t.Errorf("unexpected gig: %+v", gig.Event)
+	}
+	if len(gig.Participants) != 2 || gig.Participants[0].MemberID != "mem_a" || gig.Participants[1].MemberID != "mem_b" {
+		t.Fatalf("unexpected participants: %+v", gig.Participants)
+	}
+	if gig.Participants[0].Amount != 60000 || gig.Participants[0].EventID != gig.Event.ID {
+		t.Errorf("unexpected participant: %+v", gig.Participants[0])
+	}
+
+	festival := p.Events[1]
+	if festival.Event.EventTime != "18:30" || len(festival.Participants) != 0 {
+		t.Errorf("unexpected festival: %+v", festival)
+	}
+	if p.participantCount() != 2 || len(p.Preview) != 3 {
+		t.Errorf("participants %d, preview %d", p.participantCount(), len(p.Preview))
+	}
+}
+
+func TestBuildPlanErrors(t *testing.T) {
+	t.Parallel()
+
+	in := planInput{
+		GroupID: "grp_1",
+		Kind:    kindEvents,
+		Format:  spreadsheet.FormatHungarian,
+		Table: spreadsheet.Table{
+			Header: []string{"title", "date", "time", "amount", "member"},
+			Rows: []spreadsheet.Row{
+				{Line: 2, Fields: []string{"Gig", "2026.03.14.", "20:00", "100", "Nobody"}},
+				{Line: 3, Fields: []string{"Gig", "tomorrow", "20:00", "12,5", ""}},
+				{Line: 4, Fields: []string{"Gig", "2026.03.15.", "21:00", "100", ""}},
+			},
+		},
+		Mapping: map[string]string{"title": "0", "date": "1", "time": "2", "amount": "3", "member": "4"},
+	}
+
+	p := buildPlan(context.Background(), in)
+	if p.ok() {
+		t.Fatalf("plan with errors is ok")
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Line != 2 || p.Errors[1].Line != 3 {
+		t.Fatalf("unexpected errors: %+v", p.Errors)
+	}
+	if len(p.Errors[1].Messages) != 2 {
+		t.Errorf("line 3 messages = %v, want date and amount", p.Errors[1].Messages)
+	}
+	if len(p.Events) != 1 {
+		t.Errorf("got %d valid events, want 1", len(p.Events))
+	}
+
+	in.Mapping = map[string]string{"title": "0", "date": "1", "time": "9"}
+	p = buildPlan(context.Background(), in)
+	if len(p.Problems) != 2 || p.count() != 0 {
+		t.Errorf("unmapped time and amount: problems %v, count %d", p.Problems, p.count())
+	}
+}
+
+func TestBuildPlanMembers(t *testing.T) {
+	t.Parallel()
+
+	in := planInput{
+		GroupID: "grp_1",
+		Kind:    kindMembers,
+		Format:  spreadsheet.FormatEnglish,
+		Table: spreadsheet.Table{
+			Header: []string{"name"},
+			Rows: []spreadsheet.Row{
+				{Line: 2, Fields: []string{"Cecil"}},
+				{Line: 3, Fields: []string{"anna"}},
+				{Line: 4, Fields: []string{"CECIL"}},
+			},
+		},
+		Mapping: map[string]string{"name": "0"},
+		Members: []db.Member{{ID: "mem_a", Name: "Anna"}},
+	}
+
+	p := buildPlan(context.Background(), in)
+	if len(p.Members) != 1 || p.Members[0].Name != "Cecil" {
+		t.Errorf("unexpected members: %+v", p.Members)
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Line != 3 || p.Errors[1].Line != 4 {
+		t.Errorf("unexpected errors: %+v", p.Errors)
+	}
+}
+
+func TestBuildPlanExpenseCurrency(t *testing.T) {
+	if err := appi18n.Load(); err != nil {
+		t.Fatalf("load locales: %v", err)
+	}
+
+	in := planInput{
+		GroupID:      "grp_1",
+		Kind:         kindExpenses,
+		Format:       spreadsheet.FormatHungarian,
+		BaseCurrency: "HUF",
+		Table: spreadsheet.Table{
+			Header: []string{"title", "date", "amount", "currency", "exchange_rate"},
+			Rows: []spreadsheet.Row{
+				{Line: 2, Fields: []string{"Strings", "2026.03.14.", "40", "eur", "395,5"}},
+				{Line: 3, Fields: []string{"Cables", "2026.03.15.", "9000", "HUF", ""}},
+				{Line: 4, Fields: []string{"Picks", "2026.03.16.", "10", "", ""}},
+				{Line: 5, Fields: []string{"Drums", "2026.03.17.", "10", "USD", ""}},
+				{Line: 6, Fields: []string{"Stand", "2026.03.18.", "10", "XYZ", "2"}},
+			},
+		},
+		Mapping: map[string]string{"title": "0", "date": "1", "amount": "2", "currency": "3", "exchange_rate": "4"},
+	}
+
+	p := buildPlan(context.Background(), in)
+	if len(p.Expenses) != 3 {
+		t.Fatalf("got %d expenses, want 3: errors %+v", len(p.Expenses), p.Errors)
+	}
+	if got := p.Expenses[0]; got.Currency != "EUR" || got.ExchangeRate != 395.5 {
+		t.Errorf("foreign expense = %s @ %v, want EUR @ 395.5", got.Currency, got.ExchangeRate)
+	}
+	for _, got := range p.Expenses[1:] {
+		if got.Currency != "" || got.ExchangeRate != 1 {
+			t.Errorf("%s = %q @ %v, want base currency", got.Title, got.Currency, got.ExchangeRate)
+		}
+	}
+	if len(p.Errors) != 2 || p.Errors[0].Line != 5 || p.Errors[1].Line != 6 {
+		t.Errorf("unexpected errors: %+v", p.Errors)
+	}
+}
@@ -0,0 +1,34 @@
+package importer
+
+// maxImportBytes keeps the file, which travels back in signals on every
+// step, well under GlobalBodyLimit.
+const (
+	maxImportBytes = 256 << 10
+	maxImportRows  = 2000
+)
+
+type importFormData struct {
+	Kind     string            `json:"kind"`
+	Format   string            `json:"format"`
+	CSV      string            `json:"csv"`
+	FileName string            `json:"fileName"`
+	Mapping  map[string]string `json:"mapping"`
+}
+
+type importParams struct {
+	TabID      string         `json:"tab_id"`
+	ImportForm importFormData `json:"importForm"`
+}
+
+func importSignals(kind, format string) map[string]any {
+	return map[string]any{
+		"importForm": map[string]any{
+			"kind":     kind,
+			"format":   format,
+			"csv":      "",
+			"fileName": "",
+			"mapping":  map[string]string{},
+		},
+		"_fetching": false,
+	}
+}
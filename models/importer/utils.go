@@ -0,0 +1,9 @@
+package importer
+
+func importPath(groupID string) string {
+	return "/groups/" + groupID + "/import"
+}
+
+func listPath(groupID, kind string) string {
+	return "/groups/" + groupID + "/" + kind
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"bandcash/internal/db"
+	quotestore "bandcash/models/quote/data"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrAlreadyIssued = errors.New("invoice: event already has an invoice")
+	ErrNoLines       = errors.New("invoice: invoice has no billable lines")
+)
+
+// GetInvoiceByEvent returns the invoice issued for an event without the PDF
+// body.
+func GetInvoiceByEvent(ctx context.Context, arg GetInvoiceByEventParams) (db.Invoice, error) {
+	var row db.Invoice
+	err := db.Conn(ctx).NewSelect().
+		Model(&row).
+		ExcludeColumn("pdf").
+		Where("event_id = ?", arg.EventID).
+		Where("group_id = ?", arg.GroupID).
+		Scan(ctx)
+	return row, err
+}
+
+// GetInvoicePDF returns the stored document for an event's invoice.
+func GetInvoicePDF(ctx context.Context, arg GetInvoiceByEventParams) (db.Invoice, error) {
+	var row db.Invoice
+	err := db.Conn(ctx).NewSelect().
+		Model(&row).
+		Where("event_id = ?", arg.EventID).
+		Where("group_id = ?", arg.GroupID).
+		Scan(ctx)
+	return row, err
+}
+
+func ListInvoiceLines(ctx context.Context, invoiceID string) ([]db.InvoiceLine, error) {
+	rows := make([]db.InvoiceLine, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("invoice_id = ?", invoiceID).
+		OrderExpr("position ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+// IssueInvoice assigns the next number of the group, renders the document and
+// stores it in one transaction. A failed render or insert rolls the number
+// back, so issued numbers never have gaps. The UNIQUE(group_id, number)
+// constraint catches concurrent issuers; the loser gets an error and nothing
+// is stored. An accepted quote behind the event moves to invoiced.
+func IssueInvoice(ctx context.Context, arg IssueInvoiceParams) (db.Invoice, error) {
+	doc := arg.Document
+	if len(doc.Lines) == 0 {
+		return db.Invoice{}, ErrNoLines
+	}
+	doc.Total = InvoiceTotal(doc.Lines)
+
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		exists, err := tx.NewSelect().
+			Model((*db.Invoice)(nil)).
+			Where("event_id = ?", arg.EventID).
+			Where("group_id = ?", arg.GroupID).
+			Exists(ctx)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrAlreadyIssued
+		}
+
+		err = tx.NewSelect().
+			Model((*db.Invoice)(nil)).
+			ColumnExpr("COALESCE(MAX(number), 0) + 1").
+			Where("group_id = ?", arg.GroupID).
+			Scan(ctx, &doc.Number)
+		if err != nil {
+			return err
+		}
+
+		body, err := arg.Render(doc)
+		if err != nil {
+			return err
+		}
+
+		row := db.Invoice{
+			ID:              arg.ID,
+			GroupID:         arg.GroupID,
+			EventID:         sql.NullString{String: arg.EventID, Valid: true},
+			Number:          doc.Number,
+			IssueDate:       doc.IssueDate,
+			PerformanceDate: doc.PerformanceDate,
+			SellerName:      doc.SellerName,
+			SellerAddress:   doc.SellerAddress,
+			SellerTaxNumber: doc.SellerTaxNumber,
+			BuyerName:       doc.BuyerName,
+			BuyerAddress:    doc.BuyerAddress,
+			BuyerTaxNumber:  doc.BuyerTaxNumber,
+			Total:           doc.Total,
+			Currency:        doc.Currency,
+			PDF:             body,
+			IssuedBy:        sql.NullString{String: arg.IssuedBy, Valid: arg.IssuedBy != ""},
+		}
+		if _, err := tx.NewInsert().Model(&row).ExcludeColumn("created_at").Exec(ctx); err != nil {
+			return err
+		}
+
+		for i, line := range doc.Lines {
+			lineRow := db.InvoiceLine{
+				InvoiceID:   arg.ID,
+				Position:    int64(i + 1),
+				Description: line.Description,
+				Quantity:    line.Quantity,
+				UnitPrice:   line.UnitPrice,
+			}
+			if _, err := tx.NewInsert().Model(&lineRow).Exec(ctx); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.NewUpdate().Model((*db.Quote)(nil)).
+			Set("status = ?", quotestore.StatusInvoiced).
+			Where("event_id = ?", arg.EventID).
+			Where("group_id = ?", arg.GroupID).
+			Where("status = ?", quotestore.StatusAccepted).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return db.Invoice{}, err
+	}
+	return GetInvoiceByEvent(ctx, GetInvoiceByEventParams{EventID: arg.EventID, GroupID: arg.GroupID})
+}
+
+func InvoiceTotal(lines []InvoiceLineParams) int64 {
+	var total int64
+	for _, line := range lines {
+		total += line.Quantity * line.UnitPrice
+	}
+	return total
+}
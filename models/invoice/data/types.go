@@ -0,0 +1,43 @@
+package data
+
+type GetInvoiceByEventParams struct {
+	EventID string `json:"event_id"`
+	GroupID string `json:"group_id"`
+}
+
+type InvoiceLineParams struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unit_price"`
+}
+
+// RenderFunc produces the PDF for an invoice once its number is known.
+type RenderFunc func(invoice InvoiceDocument) ([]byte, error)
+
+// InvoiceDocument is everything printed on an invoice.
+type InvoiceDocument struct {
+	Number          int64
+	IssueDate       string
+	PerformanceDate string
+	SellerName      string
+	SellerAddress   string
+	SellerTaxNumber string
+	BuyerName       string
+	BuyerAddress    string
+	BuyerTaxNumber  string
+	Lines           []InvoiceLineParams
+	Total           int64
+	// Currency is the code every amount is printed in.
+	Currency string
+}
+
+// IssueInvoiceParams describes a new invoice. Number and Total are filled in
+// by IssueInvoice.
+type IssueInvoiceParams struct {
+	ID       string          `json:"id"`
+	GroupID  string          `json:"group_id"`
+	EventID  string          `json:"event_id"`
+	IssuedBy string          `json:"issued_by"`
+	Document InvoiceDocument `json:"document"`
+	Render   RenderFunc      `json:"-"`
+}
@@ -0,0 +1,129 @@
+package invoice
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	groupstore "bandcash/models/group/data"
+	invoicestore "bandcash/models/invoice/data"
+	quotestore "bandcash/models/quote/data"
+)
+
+// Issue creates the invoice for an event with the group's current billing
+// details. Each event can be invoiced once.
+func Issue(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	eventID := c.Param("id")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		slog.Info("invoice.issue: invalid event id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals issueParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("invoice.issue: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := invoiceFormData{
+		BuyerName:      strings.TrimSpace(signals.InvoiceForm.BuyerName),
+		BuyerAddress:   strings.TrimSpace(signals.InvoiceForm.BuyerAddress),
+		BuyerTaxNumber: strings.TrimSpace(signals.InvoiceForm.BuyerTaxNumber),
+	}
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"invoiceErrors": utils.WithErrors(invoiceErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("invoice.issue: event not found", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		slog.Error("invoice.issue: failed to load group", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if group.BillingName == "" {
+		utils.Notify(c, ctxi18n.T(ctx, "invoices.errors.billing_missing"))
+		return c.NoContent(http.StatusConflict)
+	}
+
+	var quoteItems []db.QuoteItem
+	quote, err := quotestore.GetQuoteByEventID(ctx, groupID, eventID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("invoice.issue: failed to load quote", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if err == nil {
+		quoteItems, err = quotestore.ListQuoteItems(ctx, quote.ID)
+		if err != nil {
+			slog.Error("invoice.issue: failed to load quote items", "quote_id", quote.ID, "err", err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+	}
+
+	invoice, err := invoicestore.IssueInvoice(ctx, invoicestore.IssueInvoiceParams{
+		ID:       utils.GenerateID(utils.PrefixInvoice),
+		GroupID:  groupID,
+		EventID:  eventID,
+		IssuedBy: utils.GetUserID(c),
+		Document: invoicestore.InvoiceDocument{
+			IssueDate:       time.Now().Format("2006-01-02"),
+			PerformanceDate: event.Date,
+			SellerName:      group.BillingName,
+			SellerAddress:   group.BillingAddress,
+			SellerTaxNumber: group.TaxNumber,
+			BuyerName:       form.BuyerName,
+			BuyerAddress:    form.BuyerAddress,
+			BuyerTaxNumber:  form.BuyerTaxNumber,
+			Lines:           invoiceLines(ctx, event, quoteItems),
+			Currency:        currency.Of(event.Currency, group.BaseCurrency),
+		},
+		Render: func(doc invoicestore.InvoiceDocument) ([]byte, error) {
+			return renderPDF(ctx, doc)
+		},
+	})
+	if errors.Is(err, invoicestore.ErrAlreadyIssued) {
+		slog.Info("invoice.issue: already issued", "event_id", eventID)
+		utils.Notify(c, ctxi18n.T(ctx, "invoices.errors.already_issued"))
+		return c.NoContent(http.StatusConflict)
+	}
+	if errors.Is(err, invoicestore.ErrNoLines) {
+		slog.Info("invoice.issue: nothing to bill", "event_id", eventID)
+		utils.Notify(c, ctxi18n.T(ctx, "invoices.errors.nothing_to_bill"))
+		return c.NoContent(http.StatusConflict)
+	}
+	if err != nil {
+		slog.Error("invoice.issue: failed to issue invoice", "event_id", eventID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "invoices.notifications.issue_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "invoices.notifications.issued", FormatNumber(invoice.Number)))
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID), utils.EventTopic(eventID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/events/"+eventID); err != nil {
+		slog.Warn("invoice.issue: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,36 @@
+package invoice
+
+import (
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
+	invoicestore "bandcash/models/invoice/data"
+)
+
+// Download serves the stored PDF exactly as it was issued.
+func Download(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	eventID := c.Param("id")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		slog.Info("invoice.download: invalid event id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	row, err := invoicestore.GetInvoicePDF(ctx, invoicestore.GetInvoiceByEventParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("invoice.download: invoice not found", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": fileName(row.Number)}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private, max-age=3600")
+	return c.Blob(http.StatusOK, "application/pdf", row.PDF)
+}
@@ -0,0 +1,15 @@
+package invoice
+
+import "bandcash/internal/db"
+
+type InvoiceSectionProps struct {
+	GroupID string
+	EventID string
+	IsAdmin bool
+	// Invoice is nil until one is issued.
+	Invoice *db.Invoice
+	// BillingReady is false while the group has no billing name.
+	BillingReady bool
+	// BuyerName prefills the form, usually with the quote's client.
+	BuyerName string
+}
@@ -0,0 +1,99 @@
+package invoice
+
+import (
+	"context"
+	"strings"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/pdf"
+	"bandcash/internal/utils"
+	invoicestore "bandcash/models/invoice/data"
+)
+
+const (
+	marginX      = 50.0
+	contentRight = pdf.PageWidth - marginX
+	pageBottom   = pdf.PageHeight - 60
+	bodySize     = 10.0
+	lineHeight   = 14.0
+)
+
+// Column right edges of the line table; the description fills the rest.
+var (
+	colQuantity  = contentRight - 190
+	colUnitPrice = contentRight - 95
+	colAmount    = contentRight
+)
+
+// renderPDF lays out an invoice in the locale of ctx.
+func renderPDF(ctx context.Context, doc invoicestore.InvoiceDocument) ([]byte, error) {
+	number := FormatNumber(doc.Number)
+	out := pdf.New(ctxi18n.T(ctx, "invoices.pdf_title") + " " + number)
+	out.AddPage()
+
+	out.Text(marginX, 70, 20, pdf.Bold, ctxi18n.T(ctx, "invoices.pdf_title"))
+	out.TextRight(contentRight, 62, bodySize, pdf.Bold, ctxi18n.T(ctx, "invoices.number", number))
+	out.TextRight(contentRight, 62+lineHeight, bodySize, pdf.Regular,
+		ctxi18n.T(ctx, "invoices.issue_date")+": "+utils.FormatDateLocalized(ctx, doc.IssueDate))
+	out.TextRight(contentRight, 62+2*lineHeight, bodySize, pdf.Regular,
+		ctxi18n.T(ctx, "invoices.performance_date")+": "+utils.FormatDateLocalized(ctx, doc.PerformanceDate))
+	out.Line(marginX, 120, contentRight, 120, 0.5)
+
+	partyWidth := (contentRight-marginX)/2 - 10
+	sellerEnd := party(ctx, out, marginX, 145, partyWidth, ctxi18n.T(ctx, "invoices.seller"), doc.SellerName, doc.SellerAddress, doc.SellerTaxNumber)
+	buyerEnd := party(ctx, out, marginX+partyWidth+20, 145, partyWidth, ctxi18n.T(ctx, "invoices.buyer"), doc.BuyerName, doc.BuyerAddress, doc.BuyerTaxNumber)
+
+	y := max(sellerEnd, buyerEnd) + 30
+	y = tableHeader(ctx, out, y)
+	for _, line := range doc.Lines {
+		if y > pageBottom {
+			out.AddPage()
+			y = tableHeader(ctx, out, 70)
+		}
+		out.Text(marginX, y, bodySize, pdf.Regular, pdf.Fit(line.Description, colQuantity-marginX-60, bodySize, pdf.Regular))
+		out.TextRight(colQuantity, y, bodySize, pdf.Regular, utils.FormatNumberLocalized(ctx, line.Quantity))
+		out.TextRight(colUnitPrice, y, bodySize, pdf.Regular, utils.FormatMoneyLocalized(ctx, line.UnitPrice, doc.Currency))
+		out.TextRight(colAmount, y, bodySize, pdf.Regular, utils.FormatMoneyLocalized(ctx, line.Quantity*line.UnitPrice, doc.Currency))
+		y += lineHeight + 4
+	}
+
+	if y > pageBottom {
+		out.AddPage()
+		y = 70
+	}
+	out.Line(colQuantity-60, y-8, contentRight, y-8, 0.5)
+	out.Text(colQuantity-60, y+8, 12, pdf.Bold, ctxi18n.T(ctx, "invoices.total"))
+	out.TextRight(colAmount, y+8, 12, pdf.Bold, utils.FormatMoneyLocalized(ctx, doc.Total, doc.Currency))
+
+	return out.Bytes()
+}
+
+// party prints a seller or buyer block and returns the y below it.
+func party(ctx context.Context, out *pdf.Document, x, y, width float64, title, name, address, taxNumber string) float64 {
+	out.Text(x, y, 9, pdf.Bold, strings.ToUpper(title))
+	y += lineHeight + 2
+	out.Text(x, y, bodySize+1, pdf.Bold, pdf.Fit(name, width, bodySize+1, pdf.Bold))
+	for _, row := range strings.Split(address, "\n") {
+		row = strings.TrimSpace(row)
+		if row == "" {
+			continue
+		}
+		y += lineHeight
+		out.Text(x, y, bodySize, pdf.Regular, pdf.Fit(row, width, bodySize, pdf.Regular))
+	}
+	if taxNumber != "" {
+		y += lineHeight
+		out.Text(x, y, bodySize, pdf.Regular, ctxi18n.T(ctx, "invoices.tax_number_line", taxNumber))
+	}
+	return y
+}
+
+func tableHeader(ctx context.Context, out *pdf.Document, y float64) float64 {
+	out.Text(marginX, y, 9, pdf.Bold, ctxi18n.T(ctx, "invoices.description"))
+	out.TextRight(colQuantity, y, 9, pdf.Bold, ctxi18n.T(ctx, "invoices.quantity"))
+	out.TextRight(colUnitPrice, y, 9, pdf.Bold, ctxi18n.T(ctx, "invoices.unit_price"))
+	out.TextRight(colAmount, y, 9, pdf.Bold, ctxi18n.T(ctx, "invoices.amount"))
+	out.Line(marginX, y+6, contentRight, y+6, 0.5)
+	return y + lineHeight + 8
+}
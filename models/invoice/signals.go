@@ -0,0 +1,31 @@
+package invoice
+
+type invoiceFormData struct {
+	BuyerName      string `json:"buyerName" validate:"required,min=1,max=255"`
+	BuyerAddress   string `json:"buyerAddress" validate:"max=1000"`
+	BuyerTaxNumber string `json:"buyerTaxNumber" validate:"max=64"`
+}
+
+// issueParams uses its own signal names so the form can live on the event
+// page next to the event's formData.
+type issueParams struct {
+	TabID       string          `json:"tab_id"`
+	InvoiceForm invoiceFormData `json:"invoiceForm"`
+}
+
+var invoiceErrorFields = []string{"buyerName", "buyerAddress", "buyerTaxNumber"}
+
+func invoiceSectionSignals(buyerName string) map[string]any {
+	return map[string]any{
+		"invoiceForm": map[string]any{
+			"buyerName":      buyerName,
+			"buyerAddress":   "",
+			"buyerTaxNumber": "",
+		},
+		"invoiceErrors": map[string]any{
+			"buyerName":      "",
+			"buyerAddress":   "",
+			"buyerTaxNumber": "",
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	invoicestore "bandcash/models/invoice/data"
+)
+
+// FormatNumber renders an invoice number the way it is printed.
+func FormatNumber(number int64) string {
+	return fmt.Sprintf("%06d", number)
+}
+
+func pdfURL(groupID, eventID string) string {
+	return fmt.Sprintf("/groups/%s/events/%s/invoice.pdf", groupID, eventID)
+}
+
+func fileName(number int64) string {
+	return "invoice-" + FormatNumber(number) + ".pdf"
+}
+
+// invoiceLines bills the event. Items of the source quote are reused while
+// they still add up to the event amount; otherwise the event is one line. A
+// cancelled event is billed its cancellation fee.
+func invoiceLines(ctx context.Context, event db.Event, quoteItems []db.QuoteItem) []invoicestore.InvoiceLineParams {
+	if event.Status == eventstore.EventStatusCancelled {
+		if event.CancellationFee <= 0 {
+			return nil
+		}
+		return []invoicestore.InvoiceLineParams{{
+			Description: ctxi18n.T(ctx, "invoices.cancellation_fee_line", event.Title),
+			Quantity:    1,
+			UnitPrice:   event.CancellationFee,
+		}}
+	}
+
+	if len(quoteItems) > 0 {
+		lines := make([]invoicestore.InvoiceLineParams, 0, len(quoteItems))
+		for _, item := range quoteItems {
+			lines = append(lines, invoicestore.InvoiceLineParams{
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.UnitPrice,
+			})
+		}
+		if invoicestore.InvoiceTotal(lines) == event.Amount {
+			return lines
+		}
+	}
+
+	if event.Amount <= 0 {
+		return nil
+	}
+	return []invoicestore.InvoiceLineParams{{
+		Description: event.Title,
+		Quantity:    1,
+		UnitPrice:   event.Amount,
+	}}
+}
@@ -0,0 +1,50 @@
+package invoice
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	invoicestore "bandcash/models/invoice/data"
+)
+
+func TestInvoiceLines(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	event := db.Event{Title: "Wedding", Amount: 1200, Status: "active"}
+	items := []db.QuoteItem{
+		{Description: "Set", Quantity: 2, UnitPrice: 500},
+		{Description: "Travel", Quantity: 1, UnitPrice: 200},
+	}
+
+	got := invoiceLines(ctx, event, items)
+	want := []invoicestore.InvoiceLineParams{
+		{Description: "Set", Quantity: 2, UnitPrice: 500},
+		{Description: "Travel", Quantity: 1, UnitPrice: 200},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("quote items: got %v, want %v", got, want)
+	}
+
+	event.Amount = 1500
+	got = invoiceLines(ctx, event, items)
+	want = []invoicestore.InvoiceLineParams{{Description: "Wedding", Quantity: 1, UnitPrice: 1500}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("changed amount: got %v, want %v", got, want)
+	}
+
+	event.Status = eventstore.EventStatusCancelled
+	event.CancellationFee = 300
+	got = invoiceLines(ctx, event, items)
+	if len(got) != 1 || got[0].UnitPrice != 300 || got[0].Quantity != 1 {
+		t.Fatalf("cancelled: got %v", got)
+	}
+
+	event.CancellationFee = 0
+	if got := invoiceLines(ctx, event, items); len(got) != 0 {
+		t.Fatalf("cancelled without fee: got %v", got)
+	}
+}
@@ -0,0 +1,302 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	"github.com/uptrace/bun"
+)
+
+// MemberBalance is where a member stands with the band across all events, in
+// the group's base currency.
+type MemberBalance struct {
+	MemberID string `bun:"id"`
+	Name     string `bun:"name"`
+	// Earned is the member's cuts less tax withheld, or the compensation of
+	// cancelled events.
+	Earned int64 `bun:"-"`
+	// Expenses is what the member spent for the band and gets reimbursed:
+	// event expenses and expenses they paid out of pocket.
+	Expenses int64 `bun:"-"`
+	// PaidOut is the part of Earned and Expenses already paid out.
+	PaidOut int64 `bun:"-"`
+	// Collected is event income the member took in on the band's behalf.
+	Collected int64 `bun:"-"`
+	// PassedOn is collected money the member paid out to participants or
+	// handed back to the band.
+	PassedOn int64 `bun:"-"`
+}
+
+// Owed is what the band still has to pay the member.
+func (b MemberBalance) Owed() int64 {
+	return b.Earned + b.Expenses - b.PaidOut
+}
+
+// Held is band money the member still holds.
+func (b MemberBalance) Held() int64 {
+	return b.Collected - b.PassedOn
+}
+
+// Balance is what the band owes the member once the money they hold is
+// counted; negative when the member owes the band.
+func (b MemberBalance) Balance() int64 {
+	return b.Owed() - b.Held()
+}
+
+// OutstandingParticipantRow is a participation not paid out in full yet, in
+// the event's currency.
+type OutstandingParticipantRow struct {
+	EventID      string  `bun:"event_id"`
+	MemberID     string  `bun:"member_id"`
+	Owed         int64   `bun:"owed"`
+	PaidOut      int64   `bun:"paid_out"`
+	ExchangeRate float64 `bun:"exchange_rate"`
+}
+
+// Outstanding is the part of the participation not paid out yet.
+func (row OutstandingParticipantRow) Outstanding() int64 {
+	return eventstore.OutstandingPayout(row.Owed, row.PaidOut)
+}
+
+// OutstandingReimbursementRow is an expense a member paid that the band has
+// not paid back yet, in the expense's currency.
+type OutstandingReimbursementRow struct {
+	ExpenseID    string  `bun:"id"`
+	MemberID     string  `bun:"paid_by_member_id"`
+	Amount       int64   `bun:"amount"`
+	ExchangeRate float64 `bun:"exchange_rate"`
+}
+
+// BaseAmount is the reimbursement in the group's base currency.
+func (row OutstandingReimbursementRow) BaseAmount() int64 {
+	return currency.ToBase(row.Amount, row.ExchangeRate)
+}
+
+type ReimburseExpenseParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+	PaidAt  string `json:"paid_at"`
+}
+
+type CreateMemberHandoverParams struct {
+	ID       string `json:"id"`
+	GroupID  string `json:"group_id"`
+	MemberID string `json:"member_id"`
+	Amount   int64  `json:"amount"`
+	HandedAt string `json:"handed_at"`
+}
+
+type RecordSettlementParams struct {
+	Payouts        []eventstore.CreateParticipantPayoutParams
+	Reimbursements []ReimburseExpenseParams
+	Handovers      []CreateMemberHandoverParams
+}
+
+// ListMemberBalances returns the balance of every member of a group, by name.
+func ListMemberBalances(ctx context.Context, groupID string) ([]MemberBalance, error) {
+	balances := make([]MemberBalance, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("members").
+		ColumnExpr("id").
+		ColumnExpr("name").
+		Where("group_id = ?", groupID).
+		OrderExpr("name ASC").
+		OrderExpr("id ASC").
+		Scan(ctx, &balances)
+	if err != nil {
+		return nil, err
+	}
+	byMember := make(map[string]*MemberBalance, len(balances))
+	for i := range balances {
+		byMember[balances[i].MemberID] = &balances[i]
+	}
+
+	participants := make([]memberParticipantRow, 0)
+	err = memberParticipantsQuery(ctx).Where("participants.group_id = ?", groupID).Scan(ctx, &participants)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range participants {
+		balance, ok := byMember[row.MemberID]
+		if !ok {
+			continue
+		}
+		owed, paid := row.split()
+		expense := int64(0)
+		if row.Status != eventstore.EventStatusCancelled {
+			expense = currency.ToBase(row.Expense, row.ExchangeRate)
+		}
+		balance.Earned += owed - expense
+		balance.Expenses += expense
+		balance.PaidOut += paid
+	}
+
+	collected, err := sumByMember(ctx, db.Conn(ctx).NewSelect().
+		TableExpr("event_payments").
+		ColumnExpr("event_payments.collected_by AS member_id").
+		ColumnExpr("event_payments.amount").
+		ColumnExpr("events.exchange_rate").
+		Join("JOIN events ON events.id = event_payments.event_id").
+		Where("event_payments.group_id = ?", groupID).
+		Where("event_payments.collected_by IS NOT NULL"))
+	if err != nil {
+		return nil, err
+	}
+	passedOn, err := sumByMember(ctx, db.Conn(ctx).NewSelect().
+		TableExpr("participant_payouts").
+		ColumnExpr("participant_payouts.paid_by AS member_id").
+		ColumnExpr("participant_payouts.amount").
+		ColumnExpr("events.exchange_rate").
+		Join("JOIN events ON events.id = participant_payouts.event_id").
+		Where("participant_payouts.group_id = ?", groupID).
+		Where("participant_payouts.paid_by IS NOT NULL"))
+	if err != nil {
+		return nil, err
+	}
+	handedOver, err := sumByMember(ctx, db.Conn(ctx).NewSelect().
+		TableExpr("member_handovers").
+		ColumnExpr("member_id").
+		ColumnExpr("amount").
+		ColumnExpr("1.0 AS exchange_rate").
+		Where("group_id = ?", groupID))
+	if err != nil {
+		return nil, err
+	}
+	spent, err := sumByMember(ctx, memberExpensesQuery(ctx, groupID))
+	if err != nil {
+		return nil, err
+	}
+	reimbursed, err := sumByMember(ctx, memberExpensesQuery(ctx, groupID).Where("paid = 1"))
+	if err != nil {
+		return nil, err
+	}
+	for memberID, balance := range byMember {
+		balance.Expenses += spent[memberID]
+		balance.PaidOut += reimbursed[memberID]
+		balance.Collected = collected[memberID]
+		balance.PassedOn = passedOn[memberID] + handedOver[memberID]
+	}
+	return balances, nil
+}
+
+// GetMemberBalance returns the balance of one member.
+func GetMemberBalance(ctx context.Context, groupID, memberID string) (MemberBalance, error) {
+	balances, err := ListMemberBalances(ctx, groupID)
+	if err != nil {
+		return MemberBalance{}, err
+	}
+	for _, balance := range balances {
+		if balance.MemberID == memberID {
+			return balance, nil
+		}
+	}
+	return MemberBalance{}, sql.ErrNoRows
+}
+
+// memberExpensesQuery selects the expenses of a group paid by a member.
+func memberExpensesQuery(ctx context.Context, groupID string) *bun.SelectQuery {
+	return db.Conn(ctx).NewSelect().
+		TableExpr("expenses").
+		ColumnExpr("paid_by_member_id AS member_id").
+		ColumnExpr("amount").
+		ColumnExpr("exchange_rate").
+		Where("group_id = ?", groupID).
+		Where("paid_by_member_id IS NOT NULL")
+}
+
+// sumByMember totals the amounts q selects per member, in the base currency.
+func sumByMember(ctx context.Context, q *bun.SelectQuery) (map[string]int64, error) {
+	rows := make([]struct {
+		MemberID     string  `bun:"member_id"`
+		Amount       int64   `bun:"amount"`
+		ExchangeRate float64 `bun:"exchange_rate"`
+	}, 0)
+	if err := q.Scan(ctx, &rows); err != nil {
+		return nil, err
+	}
+	sums := make(map[string]int64)
+	for _, row := range rows {
+		sums[row.MemberID] += currency.ToBase(row.Amount, row.ExchangeRate)
+	}
+	return sums, nil
+}
+
+// ListOutstandingParticipants returns the participations of a group not paid
+// out in full, oldest event first.
+func ListOutstandingParticipants(ctx context.Context, groupID string) ([]OutstandingParticipantRow, error) {
+	rows := make([]OutstandingParticipantRow, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("participants").
+		ColumnExpr("participants.event_id").
+		ColumnExpr("participants.member_id").
+		ColumnExpr(eventstore.ParticipantOwedExpr+" AS owed").
+		ColumnExpr(eventstore.ParticipantPaidOutExpr+" AS paid_out").
+		ColumnExpr("events.exchange_rate").
+		Join("JOIN events ON events.id = participants.event_id").
+		Where("participants.group_id = ?", groupID).
+		Where("participants.paid = 0").
+		OrderExpr("events.time ASC").
+		OrderExpr("events.id ASC").
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+// ListOutstandingReimbursements returns the member-paid expenses of a group
+// not paid back yet, oldest first.
+func ListOutstandingReimbursements(ctx context.Context, groupID string) ([]OutstandingReimbursementRow, error) {
+	rows := make([]OutstandingReimbursementRow, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("expenses").
+		ColumnExpr("id").
+		ColumnExpr("paid_by_member_id").
+		ColumnExpr("amount").
+		ColumnExpr("exchange_rate").
+		Where("group_id = ?", groupID).
+		Where("paid_by_member_id IS NOT NULL").
+		Where("paid = 0").
+		OrderExpr("date ASC").
+		OrderExpr("id ASC").
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+// RecordSettlement records the payouts, reimbursements and handovers of a
+// settlement in one transaction.
+func RecordSettlement(ctx context.Context, arg RecordSettlementParams) error {
+	return db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		for _, payout := range arg.Payouts {
+			if err := eventstore.CreateParticipantPayoutTx(ctx, tx, payout); err != nil {
+				return err
+			}
+		}
+		for _, reimbursement := range arg.Reimbursements {
+			_, err := tx.NewUpdate().
+				Model((*db.Expense)(nil)).
+				Set("paid = 1").
+				Set("paid_at = ?", reimbursement.PaidAt).
+				Where("id = ?", reimbursement.ID).
+				Where("group_id = ?", reimbursement.GroupID).
+				Where("paid = 0").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		for _, handover := range arg.Handovers {
+			row := db.MemberHandover{
+				ID:       handover.ID,
+				GroupID:  handover.GroupID,
+				MemberID: handover.MemberID,
+				Amount:   handover.Amount,
+				HandedAt: handover.HandedAt,
+			}
+			if _, err := tx.NewInsert().Model(&row).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -4,53 +4,88 @@ import (
 	"context"
 
 	"bandcash/internal/db"
+	"bandcash/internal/tax"
 )
 
+// newMember builds the row for a new member. Members paid gross keep no
+// withholding rate.
+func newMember(arg CreateMemberParams) db.Member {
+	return db.Member{
+		ID:              arg.ID,
+		GroupID:         arg.GroupID,
+		Name:            arg.Name,
+		Description:     arg.Description,
+		TaxMode:         tax.NormalizeMode(arg.TaxMode),
+		WithholdingRate: withholdingRate(arg.TaxMode, arg.WithholdingRate),
+		Version:         1,
+	}
+}
+
+func withholdingRate(mode string, rate float64) float64 {
+	if tax.NormalizeMode(mode) != tax.ModeWithholding {
+		return 0
+	}
+	return rate
+}
+
 func ListMembers(ctx context.Context, groupID string) ([]db.Member, error) {
 	rows := make([]db.Member, 0)
-	err := db.BunDB.NewSelect().Model(&rows).Where("group_id = ?", groupID).OrderExpr("created_at DESC").Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&rows).Where("group_id = ?", groupID).OrderExpr("created_at DESC").Scan(ctx)
 	return rows, err
 }
 
 func GetMember(ctx context.Context, arg GetMemberParams) (db.Member, error) {
 	var row db.Member
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
 	return row, err
 }
 
 func GetMemberByID(ctx context.Context, id string) (db.Member, error) {
 	var row db.Member
-	err := db.BunDB.NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", id).Scan(ctx)
 	return row, err
 }
 
 func CreateMember(ctx context.Context, arg CreateMemberParams) (db.Member, error) {
-	member := db.Member{ID: arg.ID, GroupID: arg.GroupID, Name: arg.Name, Description: arg.Description}
-	if _, err := db.BunDB.NewInsert().Model(&member).Exec(ctx); err != nil {
+	member := newMember(arg)
+	if _, err := db.Conn(ctx).NewInsert().Model(&member).Exec(ctx); err != nil {
 		return db.Member{}, err
 	}
 	return GetMember(ctx, GetMemberParams{ID: arg.ID, GroupID: arg.GroupID})
 }
 
 func UpdateMember(ctx context.Context, arg UpdateMemberParams) (db.Member, error) {
-	_, err := db.BunDB.NewUpdate().Model((*db.Member)(nil)).
+	q := db.Conn(ctx).NewUpdate().Model((*db.Member)(nil)).
 		Set("name = ?", arg.Name).
 		Set("description = ?", arg.Description).
+		Set("tax_mode = ?", tax.NormalizeMode(arg.TaxMode)).
+		Set("withholding_rate = ?", withholdingRate(arg.TaxMode, arg.WithholdingRate)).
 		Where("id = ?", arg.ID).
-		Where("group_id = ?", arg.GroupID).
-		Exec(ctx)
+		Where("group_id = ?", arg.GroupID)
+	if arg.Version != 0 {
+		q = q.Where("version = ?", arg.Version)
+	}
+	res, err := q.Exec(ctx)
 	if err != nil {
 		return db.Member{}, err
 	}
-	return GetMember(ctx, GetMemberParams{ID: arg.ID, GroupID: arg.GroupID})
+	current, err := GetMember(ctx, GetMemberParams{ID: arg.ID, GroupID: arg.GroupID})
+	if err != nil {
+		return db.Member{}, err
+	}
+	// Nothing matched although the member exists: the version was stale.
+	if n, err := res.RowsAffected(); err == nil && n == 0 && arg.Version != 0 {
+		return db.Member{}, &db.ConflictError{Table: "members", ID: arg.ID}
+	}
+	return current, nil
 }
 
 func DeleteMember(ctx context.Context, arg DeleteMemberParams) error {
-	_, err := db.BunDB.NewDelete().Model((*db.Member)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().Model((*db.Member)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
 	return err
 }
 
 func DeleteMemberByID(ctx context.Context, id string) error {
-	_, err := db.BunDB.NewDelete().Model((*db.Member)(nil)).Where("id = ?", id).Exec(ctx)
+	_, err := db.Conn(ctx).NewDelete().Model((*db.Member)(nil)).Where("id = ?", id).Exec(ctx)
 	return err
 }
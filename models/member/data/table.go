@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"strings"
 
+	"bandcash/internal/currency"
 	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
 	"github.com/uptrace/bun"
 )
 
@@ -38,24 +40,32 @@ type MemberEventFilter struct {
 }
 
 type MemberEventRow struct {
-	ID                 string         `bun:"id"`
-	GroupID            string         `bun:"group_id"`
-	Title              string         `bun:"title"`
-	Time               string         `bun:"time"`
-	Description        string         `bun:"description"`
-	Amount             int64          `bun:"amount"`
-	ParticipantAmount  int64          `bun:"participant_amount"`
-	ParticipantExpense int64          `bun:"participant_expense"`
-	ParticipantPaid    int64          `bun:"participant_paid"`
-	ParticipantPaidAt  sql.NullString `bun:"participant_paid_at"`
+	ID                      string         `bun:"id"`
+	GroupID                 string         `bun:"group_id"`
+	Title                   string         `bun:"title"`
+	Time                    string         `bun:"time"`
+	Date                    string         `bun:"date"`
+	Description             string         `bun:"description"`
+	Amount                  int64          `bun:"amount"`
+	Status                  string         `bun:"status"`
+	ParticipantAmount       int64          `bun:"participant_amount"`
+	ParticipantWithheld     int64          `bun:"participant_withheld"`
+	ParticipantExpense      int64          `bun:"participant_expense"`
+	ParticipantCompensation int64          `bun:"participant_compensation"`
+	ParticipantPaid         int64          `bun:"participant_paid"`
+	ParticipantPaidAt       sql.NullString `bun:"participant_paid_at"`
+	ParticipantPaidOut      int64          `bun:"participant_paid_out"`
+	Currency                string         `bun:"currency"`
+	ExchangeRate            float64        `bun:"exchange_rate"`
 }
 
 type MemberEventTotals struct {
-	TotalCut     int64 `bun:"total_cut"`
-	TotalExpense int64 `bun:"total_expense"`
-	TotalPayout  int64 `bun:"total_payout"`
-	TotalPaid    int64 `bun:"total_paid"`
-	TotalUnpaid  int64 `bun:"total_unpaid"`
+	TotalCut      int64 `bun:"total_cut"`
+	TotalWithheld int64 `bun:"total_withheld"`
+	TotalExpense  int64 `bun:"total_expense"`
+	TotalPayout   int64 `bun:"total_payout"`
+	TotalPaid     int64 `bun:"total_paid"`
+	TotalUnpaid   int64 `bun:"total_unpaid"`
 }
 
 type MemberEventListParams struct {
@@ -67,7 +77,7 @@ type MemberEventListParams struct {
 }
 
 func CountMembersTable(ctx context.Context, groupID, search string) (int64, error) {
-	q := db.BunDB.NewSelect().TableExpr("members").Where("group_id = ?", groupID)
+	q := db.Conn(ctx).NewSelect().TableExpr("members").Where("group_id = ?", groupID)
 	q = applySearch(q, search, func(sq *bun.SelectQuery, s string) *bun.SelectQuery {
 		like := "%" + s + "%"
 		return sq.WhereGroup(" AND ", func(qq *bun.SelectQuery) *bun.SelectQuery {
@@ -80,7 +90,7 @@ func CountMembersTable(ctx context.Context, groupID, search string) (int64, erro
 
 func ListMembersTable(ctx context.Context, params MemberTableListParams) ([]MemberTableRow, error) {
 	rows := make([]MemberTableRow, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("members").
 		ColumnExpr("members.id").
 		ColumnExpr("members.group_id").
@@ -88,8 +98,9 @@ func ListMembersTable(ctx context.Context, params MemberTableListParams) ([]Memb
 		ColumnExpr("members.description").
 		ColumnExpr("members.created_at").
 		ColumnExpr("members.updated_at").
-		ColumnExpr("COALESCE(SUM(CASE WHEN participants.paid = 0 THEN participants.amount + participants.expense ELSE 0 END), 0) AS unpaid").
+		ColumnExpr("CAST(ROUND(COALESCE(SUM(CASE WHEN participants.paid = 0 THEN MAX(("+eventstore.ParticipantOwedExpr+") - "+eventstore.ParticipantPaidOutExpr+", 0) * COALESCE(events.exchange_rate, 1) ELSE 0 END), 0)) AS INTEGER) AS unpaid").
 		Join("LEFT JOIN participants ON participants.member_id = members.id AND participants.group_id = members.group_id").
+		Join("LEFT JOIN events ON events.id = participants.event_id").
 		Where("members.group_id = ?", params.GroupID)
 	q = applySearch(q, params.Search, func(sq *bun.SelectQuery, s string) *bun.SelectQuery {
 		like := "%" + s + "%"
@@ -126,7 +137,7 @@ func ListMembersTable(ctx context.Context, params MemberTableListParams) ([]Memb
 }
 
 func CountMemberEventsTable(ctx context.Context, filter MemberEventFilter) (int64, error) {
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("events").
 		Join("JOIN participants ON participants.event_id = events.id")
 	q = applyMemberEventFilters(q, filter)
@@ -134,52 +145,95 @@ func CountMemberEventsTable(ctx context.Context, filter MemberEventFilter) (int6
 	return int64(n), err
 }
 
-func SumMemberEventTotalsTable(ctx context.Context, filter MemberEventFilter) (MemberEventTotals, error) {
-	rows := make([]struct {
-		Amount  int64 `bun:"amount"`
-		Expense int64 `bun:"expense"`
-		Paid    int64 `bun:"paid"`
-	}, 0)
-	q := db.BunDB.NewSelect().
+// memberParticipantRow is one participation of a member with what it is
+// worth, as totalled by the member's event list and balance sheet.
+type memberParticipantRow struct {
+	MemberID     string  `bun:"member_id"`
+	Amount       int64   `bun:"amount"`
+	Withheld     int64   `bun:"withheld"`
+	Expense      int64   `bun:"expense"`
+	Compensation int64   `bun:"compensation"`
+	Paid         int64   `bun:"paid"`
+	PaidOut      int64   `bun:"paid_out"`
+	Status       string  `bun:"status"`
+	ExchangeRate float64 `bun:"exchange_rate"`
+}
+
+// split returns what the participant is owed and the part of it paid out, in
+// the group's base currency.
+func (row memberParticipantRow) split() (int64, int64) {
+	owed := eventstore.PayoutAmount(row.Status, row.Amount, row.Withheld, row.Expense, row.Compensation)
+	paid := eventstore.PaidOutAmount(owed, row.PaidOut, row.Paid)
+	return currency.ToBase(owed, row.ExchangeRate), currency.ToBase(paid, row.ExchangeRate)
+}
+
+func memberParticipantsQuery(ctx context.Context) *bun.SelectQuery {
+	return db.Conn(ctx).NewSelect().
 		TableExpr("events").
+		ColumnExpr("participants.member_id").
 		ColumnExpr("participants.amount").
+		ColumnExpr("participants.withheld").
 		ColumnExpr("participants.expense").
+		ColumnExpr("participants.compensation").
 		ColumnExpr("participants.paid").
+		ColumnExpr(eventstore.ParticipantPaidOutExpr + " AS paid_out").
+		ColumnExpr("events.status").
+		ColumnExpr("events.exchange_rate").
 		Join("JOIN participants ON participants.event_id = events.id")
-	q = applyMemberEventFilters(q, filter)
+}
+
+func SumMemberEventTotalsTable(ctx context.Context, filter MemberEventFilter) (MemberEventTotals, error) {
+	rows := make([]memberParticipantRow, 0)
+	q := applyMemberEventFilters(memberParticipantsQuery(ctx), filter)
 	if err := q.Scan(ctx, &rows); err != nil {
 		return MemberEventTotals{}, err
 	}
 
+	return sumMemberParticipantRows(rows), nil
+}
+
+// sumMemberParticipantRows adds up the rows of a member's events in the
+// group's base currency. A cancelled event counts its compensation as the cut,
+// with nothing withheld and no expense, like the lines of the table.
+func sumMemberParticipantRows(rows []memberParticipantRow) MemberEventTotals {
 	totals := MemberEventTotals{}
 	for _, row := range rows {
-		totals.TotalCut += row.Amount
-		totals.TotalExpense += row.Expense
-		payout := row.Amount + row.Expense
-		totals.TotalPayout += payout
-		if row.Paid == 1 {
-			totals.TotalPaid += payout
+		if row.Status == eventstore.EventStatusCancelled {
+			totals.TotalCut += currency.ToBase(row.Compensation, row.ExchangeRate)
 		} else {
-			totals.TotalUnpaid += payout
+			totals.TotalCut += currency.ToBase(row.Amount, row.ExchangeRate)
+			totals.TotalWithheld += currency.ToBase(row.Withheld, row.ExchangeRate)
+			totals.TotalExpense += currency.ToBase(row.Expense, row.ExchangeRate)
 		}
+		payout, paid := row.split()
+		totals.TotalPayout += payout
+		totals.TotalPaid += paid
+		totals.TotalUnpaid += payout - paid
 	}
-	return totals, nil
+	return totals
 }
 
 func ListMemberEventsTable(ctx context.Context, params MemberEventListParams) ([]MemberEventRow, error) {
 	rows := make([]MemberEventRow, 0)
-	q := db.BunDB.NewSelect().
+	q := db.Conn(ctx).NewSelect().
 		TableExpr("events").
 		ColumnExpr("events.id").
 		ColumnExpr("events.group_id").
 		ColumnExpr("events.title").
 		ColumnExpr("events.time").
+		ColumnExpr(eventDateExpr + " AS date").
 		ColumnExpr("events.description").
 		ColumnExpr("events.amount").
+		ColumnExpr("events.status").
 		ColumnExpr("participants.amount AS participant_amount").
+		ColumnExpr("participants.withheld AS participant_withheld").
 		ColumnExpr("participants.expense AS participant_expense").
+		ColumnExpr("participants.compensation AS participant_compensation").
 		ColumnExpr("participants.paid AS participant_paid").
 		ColumnExpr("participants.paid_at AS participant_paid_at").
+		ColumnExpr(eventstore.ParticipantPaidOutExpr + " AS participant_paid_out").
+		ColumnExpr("events.currency").
+		ColumnExpr("events.exchange_rate").
 		Join("JOIN participants ON participants.event_id = events.id")
 
 	q = applyMemberEventFilters(q, params.MemberEventFilter)
@@ -189,6 +243,8 @@ func ListMemberEventsTable(ctx context.Context, params MemberEventListParams) ([
 		q = q.OrderExpr("events.title " + d)
 	case "time":
 		q = q.OrderExpr("events.time " + d)
+	case "date":
+		q = q.OrderExpr(eventDateExpr + " " + d)
 	case "participant_amount":
 		q = q.OrderExpr("participants.amount " + d)
 	case "participant_expense":
@@ -218,7 +274,7 @@ func applyMemberEventFilters(q *bun.SelectQuery, filter MemberEventFilter) *bun.
 	q = applySearch(q, filter.Search, func(sq *bun.SelectQuery, s string) *bun.SelectQuery {
 		return sq.Where("(events.title LIKE '%' || ? || '%' OR events.description LIKE '%' || ? || '%')", s, s)
 	})
-	return applyDateRangeOrYear(q, filter.From, filter.To, filter.Year, "events.time")
+	return applyDateRangeOrYear(q, filter.From, filter.To, filter.Year, eventDateExpr)
 }
 
 func applySearch(q *bun.SelectQuery, search string, fn func(*bun.SelectQuery, string) *bun.SelectQuery) *bun.SelectQuery {
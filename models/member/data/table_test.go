@@ -0,0 +1,31 @@
+package data
+
+import (
+	"testing"
+
+	eventstore "bandcash/models/event/data"
+)
+
+func TestSumMemberParticipantRows(t *testing.T) {
+	t.Parallel()
+
+	rows := []memberParticipantRow{
+		// Active gig in the base currency, half paid out.
+		{Amount: 1000, Withheld: 150, Expense: 50, PaidOut: 400, Status: eventstore.EventStatusActive, ExchangeRate: 1},
+		// Cancelled gig in EUR: only the compensation counts.
+		{Amount: 100, Withheld: 15, Expense: 10, Compensation: 20, Status: eventstore.EventStatusCancelled, ExchangeRate: 400},
+	}
+
+	got := sumMemberParticipantRows(rows)
+	want := MemberEventTotals{
+		TotalCut:      1000 + 8000,
+		TotalWithheld: 150,
+		TotalExpense:  50,
+		TotalPayout:   900 + 8000,
+		TotalPaid:     400,
+		TotalUnpaid:   500 + 8000,
+	}
+	if got != want {
+		t.Fatalf("sumMemberParticipantRows() = %+v; want %+v", got, want)
+	}
+}
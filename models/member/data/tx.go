@@ -0,0 +1,19 @@
+package data
+
+import (
+	"context"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+func CreateMemberTx(ctx context.Context, tx bun.Tx, arg CreateMemberParams) (db.Member, error) {
+	member := newMember(arg)
+	if _, err := tx.NewInsert().Model(&member).Exec(ctx); err != nil {
+		return db.Member{}, err
+	}
+
+	var row db.Member
+	err := tx.NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
@@ -6,17 +6,23 @@ type GetMemberParams struct {
 }
 
 type CreateMemberParams struct {
-	ID          string `json:"id"`
-	GroupID     string `json:"group_id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID              string  `json:"id"`
+	GroupID         string  `json:"group_id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	TaxMode         string  `json:"tax_mode"`
+	WithholdingRate float64 `json:"withholding_rate"`
 }
 
 type UpdateMemberParams struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	ID          string `json:"id"`
-	GroupID     string `json:"group_id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	TaxMode         string  `json:"tax_mode"`
+	WithholdingRate float64 `json:"withholding_rate"`
+	ID              string  `json:"id"`
+	GroupID         string  `json:"group_id"`
+	// Version is the one the edit form was opened at. Zero skips the check.
+	Version int64 `json:"version"`
 }
 
 type DeleteMemberParams struct {
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+)
+
+// eventDateExpr is an event's date: the date column, or the day of its start.
+const eventDateExpr = "COALESCE(NULLIF(events.date, ''), substr(events.time, 1, 10))"
+
+// MemberWithholding is the tax withheld from a member's cuts over a year, in
+// the group's base currency.
+type MemberWithholding struct {
+	MemberID string
+	Name     string
+	// Gross is the member's cuts before withholding.
+	Gross    int64
+	Withheld int64
+}
+
+// Net is what the member is paid of their cuts.
+func (w MemberWithholding) Net() int64 {
+	return w.Gross - w.Withheld
+}
+
+// ListMemberWithholdings returns the tax withheld from each member's cuts for
+// the events of year, by name. Cancelled events withhold nothing, and members
+// with nothing withheld are left out.
+func ListMemberWithholdings(ctx context.Context, groupID string, year int) ([]MemberWithholding, error) {
+	rows := make([]struct {
+		MemberID     string  `bun:"member_id"`
+		Name         string  `bun:"name"`
+		Amount       int64   `bun:"amount"`
+		Withheld     int64   `bun:"withheld"`
+		ExchangeRate float64 `bun:"exchange_rate"`
+	}, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("participants").
+		ColumnExpr("participants.member_id").
+		ColumnExpr("members.name").
+		ColumnExpr("participants.amount").
+		ColumnExpr("participants.withheld").
+		ColumnExpr("events.exchange_rate").
+		Join("JOIN events ON events.id = participants.event_id").
+		Join("JOIN members ON members.id = participants.member_id").
+		Where("participants.group_id = ?", groupID).
+		Where("participants.withheld > 0").
+		Where("events.status != ?", eventstore.EventStatusCancelled).
+		Where("substr("+eventDateExpr+", 1, 4) = ?", strconv.Itoa(year)).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	withholdings := make([]MemberWithholding, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.MemberID]
+		if !ok {
+			i = len(withholdings)
+			index[row.MemberID] = i
+			withholdings = append(withholdings, MemberWithholding{MemberID: row.MemberID, Name: row.Name})
+		}
+		withholdings[i].Gross += currency.ToBase(row.Amount, row.ExchangeRate)
+		withholdings[i].Withheld += currency.ToBase(row.Withheld, row.ExchangeRate)
+	}
+	sort.SliceStable(withholdings, func(i, j int) bool {
+		if withholdings[i].Name != withholdings[j].Name {
+			return withholdings[i].Name < withholdings[j].Name
+		}
+		return withholdings[i].MemberID < withholdings[j].MemberID
+	})
+	return withholdings, nil
+}
@@ -0,0 +1,45 @@
+package member
+
+import (
+	"context"
+	"strconv"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+	memberstore "bandcash/models/member/data"
+)
+
+var exportColumns = []string{"name", "description", "unpaid"}
+
+// exportRecords lists the members matching query, header first.
+func exportRecords(ctx context.Context, groupID string, query utils.TableQuery) ([][]string, spreadsheet.Format, error) {
+	format := spreadsheet.FormatForLocale(appi18n.LocaleCode(ctx))
+
+	members, err := memberstore.ListMembersTable(ctx, memberstore.MemberTableListParams{
+		GroupID: groupID,
+		Search:  query.Search,
+		Sort:    query.Sort,
+		Dir:     query.Dir,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	header := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = ctxi18n.T(ctx, "csv.columns."+column)
+	}
+	records := [][]string{header}
+
+	for _, member := range members {
+		records = append(records, []string{
+			member.Name,
+			member.Description,
+			strconv.FormatInt(member.Unpaid, 10),
+		})
+	}
+	return records, format, nil
+}
@@ -1,6 +1,8 @@
 package member
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -8,8 +10,11 @@ import (
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 	"github.com/labstack/echo/v4"
 	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
 
+	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	"bandcash/models/audit"
 	eventstore "bandcash/models/event/data"
 	memberstore "bandcash/models/member/data"
 )
@@ -20,10 +25,10 @@ var (
 		"mode":      "table",
 		"formState": "",
 		"editingId": "",
-		"formData":  map[string]any{"name": "", "description": ""},
-		"errors":    map[string]any{"name": "", "description": ""},
+		"formData":  newMemberFormData(),
+		"errors":    newMemberErrors(),
 	}
-	memberErrorFields = []string{"name", "description"}
+	memberErrorFields = []string{"name", "description", "taxMode", "withholdingRate"}
 )
 
 func Create(c echo.Context) error {
@@ -42,16 +47,25 @@ func Create(c echo.Context) error {
 	signals.FormData.Description = strings.TrimSpace(signals.FormData.Description)
 
 	// Validate
-	if errs := utils.ValidateWithLocale(c.Request().Context(), signals.FormData); errs != nil {
+	if errs := validateMember(c.Request().Context(), signals.FormData); errs != nil {
 		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(memberErrorFields, errs)})
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
 
-	member, err := memberstore.CreateMember(c.Request().Context(), memberstore.CreateMemberParams{
-		ID:          utils.GenerateID(utils.PrefixMember),
-		GroupID:     groupID,
-		Name:        signals.FormData.Name,
-		Description: signals.FormData.Description,
+	var member db.Member
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		member, err = memberstore.CreateMember(ctx, memberstore.CreateMemberParams{
+			ID:              utils.GenerateID(utils.PrefixMember),
+			GroupID:         groupID,
+			Name:            signals.FormData.Name,
+			Description:     signals.FormData.Description,
+			TaxMode:         signals.FormData.TaxMode,
+			WithholdingRate: signals.FormData.WithholdingRate,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterMember, EntityID: member.ID, After: member})
 	})
 	if err != nil {
 		slog.Error("member.create.table: failed to create member", "err", err)
@@ -59,6 +73,8 @@ func Create(c echo.Context) error {
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	publishMember(c, groupID, member.ID)
+
 	slog.Debug("member.create.table", "id", member.ID, "name", member.Name)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "members.notifications.created"))
 
@@ -91,23 +107,47 @@ func Update(c echo.Context) error {
 	signals.FormData.Description = strings.TrimSpace(signals.FormData.Description)
 
 	// Validate
-	if errs := utils.ValidateWithLocale(c.Request().Context(), signals.FormData); errs != nil {
+	if errs := validateMember(c.Request().Context(), signals.FormData); errs != nil {
 		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(memberErrorFields, errs)})
 		return c.NoContent(http.StatusUnprocessableEntity)
 	}
 
-	_, err = memberstore.UpdateMember(c.Request().Context(), memberstore.UpdateMemberParams{
-		Name:        signals.FormData.Name,
-		Description: signals.FormData.Description,
-		ID:          id,
-		GroupID:     groupID,
+	before, err := memberstore.GetMember(c.Request().Context(), memberstore.GetMemberParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("member.update: failed to get member", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "members.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err := memberstore.UpdateMember(ctx, memberstore.UpdateMemberParams{
+			Name:            signals.FormData.Name,
+			Description:     signals.FormData.Description,
+			TaxMode:         signals.FormData.TaxMode,
+			WithholdingRate: signals.FormData.WithholdingRate,
+			ID:              id,
+			GroupID:         groupID,
+			Version:         signals.FormData.Version,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionUpdate, Entity: utils.EntityFilterMember, EntityID: id, Before: before, After: updated})
 	})
+	if db.IsConflict(err) {
+		if err := patchMemberConflict(c, groupID, id, signals.FormData); err != nil {
+			slog.Error("member.update: failed to open conflict dialog", "err", err)
+		}
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("member.update: failed to update member", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "members.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	publishMember(c, groupID, id)
+
 	slog.Debug("member.update", "id", id)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "members.notifications.updated"))
 
@@ -137,9 +177,22 @@ func Destroy(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	err = memberstore.DeleteMember(c.Request().Context(), memberstore.DeleteMemberParams{
-		ID:      id,
-		GroupID: groupID,
+	before, err := memberstore.GetMember(c.Request().Context(), memberstore.GetMemberParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("member.destroy: failed to get member", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "members.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		err := memberstore.DeleteMember(ctx, memberstore.DeleteMemberParams{
+			ID:      id,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionDelete, Entity: utils.EntityFilterMember, EntityID: id, Before: before})
 	})
 	if err != nil {
 		slog.Error("member.destroy: failed to delete member", "err", err)
@@ -147,6 +200,8 @@ func Destroy(c echo.Context) error {
 		return c.NoContent(http.StatusInternalServerError)
 	}
 
+	publishMember(c, groupID, id)
+
 	slog.Debug("member.destroy", "id", id)
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "members.notifications.deleted"))
 
@@ -205,16 +260,40 @@ func ToggleParticipantPaid(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	_, err = eventstore.ToggleParticipantPaid(c.Request().Context(), eventstore.ToggleParticipantPaidParams{
+	before, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  eventID,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		slog.Error("member.toggleParticipantPaid: failed to get participant", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.toggle_paid_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err := eventstore.ToggleParticipantPaid(ctx, eventstore.ToggleParticipantPaidParams{
+			EventID:  eventID,
+			MemberID: memberID,
+			GroupID:  groupID,
+			PayoutID: utils.GenerateID(utils.PrefixPayout),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.PaidAction(updated.Paid), Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(eventID, memberID), Before: before, After: updated})
+	})
+	if errors.Is(err, eventstore.ErrParticipantHasPayouts) {
+		slog.Info("member.toggleParticipantPaid: participant has payouts", "event_id", eventID, "member_id", memberID)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.unpaid_has_payouts"))
+		return c.NoContent(http.StatusConflict)
+	}
 	if err != nil {
 		slog.Error("member.toggleParticipantPaid: failed to toggle paid status", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.toggle_paid_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishMember(c, groupID, memberID, eventID)
 
 	query := utils.NormalizeTableQuery(signals.TableQuery, MemberEventsTableQuerySpec())
 	data, err := GetShowData(c.Request().Context(), groupID, memberID, query)
@@ -337,17 +416,36 @@ func UpdateParticipantPaidAt(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	_, err = eventstore.UpdateParticipantPaidAt(c.Request().Context(), eventstore.UpdateParticipantPaidAtParams{
-		PaidAt:   normalizePaidAtInput(signals.ParticipantPaidAtDialog.Value),
+	before, err := eventstore.GetParticipant(c.Request().Context(), eventstore.GetParticipantParams{
 		EventID:  eventID,
 		MemberID: memberID,
 		GroupID:  groupID,
 	})
+	if err != nil {
+		slog.Error("member.updateParticipantPaidAt: failed to get participant", "err", err)
+		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(c.Request().Context(), func(ctx context.Context, tx bun.Tx) error {
+		updated, err := eventstore.UpdateParticipantPaidAt(ctx, eventstore.UpdateParticipantPaidAtParams{
+			PaidAt:   normalizePaidAtInput(signals.ParticipantPaidAtDialog.Value),
+			EventID:  eventID,
+			MemberID: memberID,
+			GroupID:  groupID,
+			PayoutID: utils.GenerateID(utils.PrefixPayout),
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionPaidAt, Entity: utils.EntityFilterParticipant, EntityID: audit.ParticipantID(eventID, memberID), Before: before, After: updated})
+	})
 	if err != nil {
 		slog.Error("member.updateParticipantPaidAt: failed to update paid_at", "err", err)
 		utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.update_failed"))
 		return c.NoContent(http.StatusInternalServerError)
 	}
+	publishMember(c, groupID, memberID, eventID)
 
 	utils.Notify(c, ctxi18n.T(c.Request().Context(), "participants.notifications.updated"))
 	utils.InvalidateGroupCaches(groupID)
@@ -379,3 +477,120 @@ func UpdateParticipantPaidAt(c echo.Context) error {
 	})
 	return c.NoContent(http.StatusOK)
 }
+
+// ConfirmSettlement records the transfers the settle page proposes. The plan
+// is worked out again from the current balances, and its payouts and
+// handovers are written in one transaction.
+func ConfirmSettlement(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	var signals settleParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("member.settle: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := signals.SettleForm
+	form.PaidAt = utils.FormatDateInput(strings.TrimSpace(form.PaidAt))
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"settleErrors": utils.WithErrors(settleErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	balances, err := memberstore.ListMemberBalances(ctx, groupID)
+	if err != nil {
+		slog.Error("member.settle: failed to list balances", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	transfers := PlanSettlement(balances)
+	if len(transfers) == 0 {
+		utils.Notify(c, ctxi18n.T(ctx, "settle.notifications.nothing"))
+		return c.NoContent(http.StatusOK)
+	}
+	outstanding, err := memberstore.ListOutstandingParticipants(ctx, groupID)
+	if err != nil {
+		slog.Error("member.settle: failed to list outstanding participants", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	reimbursements, err := memberstore.ListOutstandingReimbursements(ctx, groupID)
+	if err != nil {
+		slog.Error("member.settle: failed to list outstanding reimbursements", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	settlement := buildSettlement(groupID, form.PaidAt, form.Method, transfers, outstanding, reimbursements)
+	names := make(map[string]string, len(balances))
+	for _, balance := range balances {
+		names[balance.MemberID] = balance.Name
+	}
+	changes := make([]audit.Change, 0, len(settlement.Payouts)+len(settlement.Reimbursements)+len(settlement.Handovers))
+	for _, payout := range settlement.Payouts {
+		changes = append(changes, audit.Change{
+			Action:   audit.ActionPayout,
+			Entity:   utils.EntityFilterParticipant,
+			EntityID: audit.ParticipantID(payout.EventID, payout.MemberID),
+			After: map[string]any{
+				"amount":  payout.Amount,
+				"paid_at": payout.PaidAt,
+				"method":  payout.Method,
+				"paid_by": payout.PaidBy,
+			},
+		})
+	}
+	for _, reimbursement := range settlement.Reimbursements {
+		changes = append(changes, audit.Change{
+			Action:   audit.ActionMarkPaid,
+			Entity:   utils.EntityFilterExpense,
+			EntityID: reimbursement.ID,
+			After: map[string]any{
+				"paid":    1,
+				"paid_at": reimbursement.PaidAt,
+			},
+		})
+	}
+	for _, handover := range settlement.Handovers {
+		changes = append(changes, audit.Change{
+			Action:   audit.ActionHandover,
+			Entity:   utils.EntityFilterMember,
+			EntityID: handover.MemberID,
+			Label:    names[handover.MemberID],
+			After: map[string]any{
+				"amount":    handover.Amount,
+				"handed_at": handover.HandedAt,
+			},
+		})
+	}
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		if err := memberstore.RecordSettlement(ctx, settlement); err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, changes...)
+	})
+	if err != nil {
+		slog.Error("member.settle: failed to record settlement", "group_id", groupID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "settle.notifications.failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	topics := []string{utils.GroupTopic(groupID)}
+	for _, balance := range balances {
+		topics = append(topics, utils.MemberTopic(balance.MemberID))
+	}
+	for _, payout := range settlement.Payouts {
+		topics = append(topics, utils.EventTopic(payout.EventID))
+	}
+	utils.SSEHub.Publish(c, topics...)
+	utils.InvalidateGroupCaches(groupID)
+
+	slog.Debug("member.settle", "group_id", groupID, "payouts", len(settlement.Payouts), "reimbursements", len(settlement.Reimbursements), "handovers", len(settlement.Handovers))
+	utils.Notify(c, ctxi18n.T(ctx, "settle.notifications.settled"))
+
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/members/settle"); err != nil {
+		slog.Warn("member.settle: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
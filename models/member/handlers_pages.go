@@ -1,12 +1,19 @@
 package member
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
+	"time"
 
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 	"github.com/labstack/echo/v4"
 
+	"bandcash/internal/spreadsheet"
 	"bandcash/internal/utils"
 	groupstore "bandcash/models/group/data"
 	memberstore "bandcash/models/member/data"
@@ -32,8 +39,8 @@ func NewMemberPage(c echo.Context) error {
 		},
 		GroupID: groupID,
 		Signals: map[string]any{
-			"formData": map[string]any{"name": "", "description": ""},
-			"errors":   map[string]any{"name": "", "description": ""},
+			"formData": newMemberFormData(),
+			"errors":   newMemberErrors(),
 		},
 		IsAuthenticated: true,
 		IsSuperAdmin:    utils.IsSuperadmin(c),
@@ -78,12 +85,13 @@ func EditMemberPage(c echo.Context) error {
 		GroupID: groupID,
 		Member:  &member,
 		Signals: map[string]any{
-			"formData": map[string]any{"name": member.Name, "description": member.Description},
-			"errors":   map[string]any{"name": "", "description": ""},
+			"formData": memberFormData(member),
+			"errors":   newMemberErrors(),
 		},
 		IsAuthenticated: true,
 		IsSuperAdmin:    utils.IsSuperadmin(c),
 	}
+	utils.SSEHub.Subscribe(c, utils.MemberTopic(id))
 	return utils.RenderPage(c, MemberEditPage(data))
 }
 
@@ -106,6 +114,29 @@ func Index(c echo.Context) error {
 	return utils.RenderPage(c, MemberIndex(data))
 }
 
+// Export downloads the members matching the current table filters as CSV.
+// Paging is ignored so the file holds every matching member.
+func Export(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	query := utils.ParseTableQuery(c, staticTableQueryable{spec: TableQuerySpec()})
+
+	records, format, err := exportRecords(c.Request().Context(), groupID, query)
+	if err != nil {
+		slog.Error("member.export: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, format, records); err != nil {
+		slog.Error("member.export: failed to write csv", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	fileName := "members-" + time.Now().Format("2006-01-02") + ".csv"
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
 func Show(c echo.Context) error {
 	utils.EnsureTabID(c)
 	groupID := utils.GetGroupID(c)
@@ -127,5 +158,150 @@ func Show(c echo.Context) error {
 	data.IsAuthenticated = true
 	data.IsSuperAdmin = utils.IsSuperadmin(c)
 
+	utils.SSEHub.Subscribe(c, utils.MemberTopic(id))
 	return utils.RenderPage(c, MemberShow(data))
 }
+
+// Settle shows every member's balance and the transfers that would settle
+// them.
+func Settle(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	data, err := GetSettleData(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("member.settle: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = settleSignals()
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, MemberSettle(data))
+}
+
+func Withholding(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	data, err := GetWithholdingData(c.Request().Context(), groupID, parseYear(c.QueryParam("year")))
+	if err != nil {
+		slog.Error("member.withholding: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.Signals = map[string]any{}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, MemberWithholding(data))
+}
+
+// StatementPage shows a member's earnings for a year as a printable page.
+func StatementPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixMember) {
+		slog.Info("member.statement: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	data, err := GetStatementData(c.Request().Context(), groupID, id, parseYear(c.QueryParam("year")))
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.NoContent(http.StatusNotFound)
+	}
+	if err != nil {
+		slog.Error("member.statement: failed to get data", "member_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = map[string]any{}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	utils.SSEHub.Subscribe(c, utils.MemberTopic(id))
+	return utils.RenderPage(c, MemberStatement(data))
+}
+
+// StatementPDF serves a member's statement for a year as a PDF.
+func StatementPDF(c echo.Context) error {
+	statement, ok, err := statementFromRequest(c, "member.statement_pdf")
+	if !ok {
+		return err
+	}
+
+	content, err := statementPDF(c.Request().Context(), statement)
+	if err != nil {
+		slog.Error("member.statement_pdf: failed to render", "member_id", statement.Member.ID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": statementFileName(statement, "pdf")}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	return c.Blob(http.StatusOK, "application/pdf", content)
+}
+
+// StatementCSV downloads a member's statement for a year as CSV.
+func StatementCSV(c echo.Context) error {
+	statement, ok, err := statementFromRequest(c, "member.statement_csv")
+	if !ok {
+		return err
+	}
+
+	content, err := statementCSV(c.Request().Context(), statement)
+	if err != nil {
+		slog.Error("member.statement_csv: failed to write csv", "member_id", statement.Member.ID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": statementFileName(statement, "csv")}))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", content)
+}
+
+// StatementsZip downloads the PDF statements of every member with events in
+// a year as one zip.
+func StatementsZip(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	year := parseYear(c.QueryParam("year"))
+
+	statements, err := ListStatements(c.Request().Context(), groupID, year)
+	if err != nil {
+		slog.Error("member.statements_zip: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	content, err := statementsZip(c.Request().Context(), statements)
+	if err != nil {
+		slog.Error("member.statements_zip: failed to render", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	fileName := fmt.Sprintf("statements-%d.zip", year)
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	return c.Blob(http.StatusOK, "application/zip", content)
+}
+
+// statementFromRequest loads the statement a download asks for. When ok is
+// false the response has been written and err is what the handler returns.
+func statementFromRequest(c echo.Context, op string) (Statement, bool, error) {
+	groupID := utils.GetGroupID(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixMember) {
+		slog.Info(op + ": invalid id")
+		return Statement{}, false, c.NoContent(http.StatusBadRequest)
+	}
+
+	statement, err := GetStatement(c.Request().Context(), groupID, id, parseYear(c.QueryParam("year")))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Statement{}, false, c.NoContent(http.StatusNotFound)
+	}
+	if err != nil {
+		slog.Error(op+": failed to get data", "member_id", id, "err", err)
+		return Statement{}, false, c.NoContent(http.StatusInternalServerError)
+	}
+	return statement, true, nil
+}
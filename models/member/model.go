@@ -6,6 +6,7 @@ import (
 	ctxi18n "github.com/invopop/ctxi18n/i18n"
 
 	"bandcash/internal/utils"
+	expensestore "bandcash/models/expense/data"
 	groupstore "bandcash/models/group/data"
 	memberstore "bandcash/models/member/data"
 )
@@ -28,16 +29,20 @@ func MemberEventsTableQuerySpec() utils.TableQuerySpec {
 
 func convertToMemberEvent(r memberstore.MemberEventRow) MemberEvent {
 	return MemberEvent{
-		ID:                 r.ID,
-		GroupID:            r.GroupID,
-		Title:              r.Title,
-		Time:               r.Time,
-		Description:        r.Description,
-		Amount:             r.Amount,
-		ParticipantAmount:  r.ParticipantAmount,
-		ParticipantExpense: r.ParticipantExpense,
-		ParticipantPaid:    r.ParticipantPaid,
-		ParticipantPaidAt:  r.ParticipantPaidAt,
+		ID:                  r.ID,
+		GroupID:             r.GroupID,
+		Title:               r.Title,
+		Time:                r.Time,
+		Description:         r.Description,
+		Amount:              r.Amount,
+		ParticipantAmount:   r.ParticipantAmount,
+		ParticipantWithheld: r.ParticipantWithheld,
+		ParticipantExpense:  r.ParticipantExpense,
+		ParticipantPaid:     r.ParticipantPaid,
+		ParticipantPaidAt:   r.ParticipantPaidAt,
+		ParticipantPaidOut:  r.ParticipantPaidOut,
+		Currency:            r.Currency,
+		ExchangeRate:        r.ExchangeRate,
 	}
 }
 
@@ -99,26 +104,41 @@ func GetShowData(ctx context.Context, groupID, memberID string, query utils.Tabl
 		events = append(events, convertToMemberEvent(r))
 	}
 
+	balance, err := memberstore.GetMemberBalance(ctx, groupID, memberID)
+	if err != nil {
+		return MemberData{}, err
+	}
+
+	reimbursements, err := expensestore.ListMemberExpenses(ctx, groupID, memberID)
+	if err != nil {
+		return MemberData{}, err
+	}
+
 	return MemberData{
-		Title:        "bandcash - " + member.Name,
-		Member:       &member,
-		Events:       events,
-		GroupID:      groupID,
-		Query:        query,
-		Pager:        utils.BuildTablePagination(int64(totalItems), query),
-		RecentYears:  utils.RecentYears(3),
-		TotalCut:     totals.TotalCut,
-		TotalExpense: totals.TotalExpense,
-		TotalPayout:  totals.TotalPayout,
-		TotalPaid:    totals.TotalPaid,
-		TotalUnpaid:  totals.TotalUnpaid,
+		Title:          "bandcash - " + member.Name,
+		Member:         &member,
+		Events:         events,
+		GroupID:        groupID,
+		BaseCurrency:   group.BaseCurrency,
+		Query:          query,
+		Pager:          utils.BuildTablePagination(int64(totalItems), query),
+		RecentYears:    utils.RecentYears(3),
+		TotalCut:       totals.TotalCut,
+		TotalWithheld:  totals.TotalWithheld,
+		TotalExpense:   totals.TotalExpense,
+		TotalPayout:    totals.TotalPayout,
+		TotalPaid:      totals.TotalPaid,
+		TotalUnpaid:    totals.TotalUnpaid,
+		Balance:        balance,
+		Reimbursements: reimbursements,
 		Breadcrumbs: []utils.Crumb{
 			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
 			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
 			{Label: ctxi18n.T(ctx, "members.title"), Href: "/groups/" + groupID + "/members"},
 			{Label: member.Name},
 		},
-		EventsTable: MemberEventsTableLayout(),
+		EventsTable:         MemberEventsTableLayout(),
+		ReimbursementsTable: MemberReimbursementsTableLayout(),
 	}, nil
 }
 
@@ -151,12 +171,13 @@ func GetIndexData(ctx context.Context, groupID string, query utils.TableQuery) (
 		members = append(members, convertToMemberListRow(row))
 	}
 	return MembersData{
-		Title:     ctxi18n.T(ctx, "members.page_title"),
-		GroupName: group.Name,
-		Members:   members,
-		Query:     query,
-		Pager:     utils.BuildTablePagination(totalItems, query),
-		GroupID:   groupID,
+		Title:        ctxi18n.T(ctx, "members.page_title"),
+		GroupName:    group.Name,
+		Members:      members,
+		Query:        query,
+		Pager:        utils.BuildTablePagination(totalItems, query),
+		GroupID:      groupID,
+		BaseCurrency: group.BaseCurrency,
 		Breadcrumbs: []utils.Crumb{
 			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
 			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
@@ -165,3 +186,85 @@ func GetIndexData(ctx context.Context, groupID string, query utils.TableQuery) (
 		MembersTable: MembersIndexTableLayout(),
 	}, nil
 }
+
+func GetSettleData(ctx context.Context, groupID string) (SettleData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return SettleData{}, err
+	}
+
+	balances, err := memberstore.ListMemberBalances(ctx, groupID)
+	if err != nil {
+		return SettleData{}, err
+	}
+
+	return SettleData{
+		Title:        ctxi18n.T(ctx, "settle.page_title"),
+		GroupID:      groupID,
+		BaseCurrency: group.BaseCurrency,
+		Balances:     balances,
+		Transfers:    PlanSettlement(balances),
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "members.title"), Href: "/groups/" + groupID + "/members"},
+			{Label: ctxi18n.T(ctx, "settle.title")},
+		},
+		BalancesTable: MemberBalancesTableLayout(),
+	}, nil
+}
+
+func GetWithholdingData(ctx context.Context, groupID string, year int) (WithholdingData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return WithholdingData{}, err
+	}
+
+	withholdings, err := memberstore.ListMemberWithholdings(ctx, groupID, year)
+	if err != nil {
+		return WithholdingData{}, err
+	}
+	total := int64(0)
+	for _, withholding := range withholdings {
+		total += withholding.Withheld
+	}
+
+	return WithholdingData{
+		Title:         ctxi18n.T(ctx, "withholding.page_title"),
+		GroupID:       groupID,
+		BaseCurrency:  group.BaseCurrency,
+		Year:          year,
+		Years:         reportYears(year),
+		Withholdings:  withholdings,
+		TotalWithheld: total,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "members.title"), Href: "/groups/" + groupID + "/members"},
+			{Label: ctxi18n.T(ctx, "withholding.title")},
+		},
+		WithholdingTable: MemberWithholdingTableLayout(),
+	}, nil
+}
+
+func GetStatementData(ctx context.Context, groupID, memberID string, year int) (StatementData, error) {
+	statement, err := GetStatement(ctx, groupID, memberID, year)
+	if err != nil {
+		return StatementData{}, err
+	}
+
+	return StatementData{
+		Title:     ctxi18n.T(ctx, "statements.page_title"),
+		GroupID:   groupID,
+		Statement: statement,
+		Years:     reportYears(year),
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: statement.GroupName, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "members.title"), Href: "/groups/" + groupID + "/members"},
+			{Label: statement.Member.Name, Href: "/groups/" + groupID + "/members/" + memberID},
+			{Label: ctxi18n.T(ctx, "statements.title")},
+		},
+		StatementTable: MemberStatementTableLayout(),
+	}, nil
+}
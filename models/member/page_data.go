@@ -5,19 +5,24 @@ import (
 
 	"bandcash/internal/db"
 	"bandcash/internal/utils"
+	memberstore "bandcash/models/member/data"
 )
 
 type MemberEvent struct {
-	ID                 string
-	GroupID            string
-	Title              string
-	Time               string
-	Description        string
-	Amount             int64
-	ParticipantAmount  int64
-	ParticipantExpense int64
-	ParticipantPaid    int64
-	ParticipantPaidAt  sql.NullString
+	ID                  string
+	GroupID             string
+	Title               string
+	Time                string
+	Description         string
+	Amount              int64
+	ParticipantAmount   int64
+	ParticipantWithheld int64
+	ParticipantExpense  int64
+	ParticipantPaid     int64
+	ParticipantPaidAt   sql.NullString
+	ParticipantPaidOut  int64
+	Currency            string
+	ExchangeRate        float64
 }
 
 type MemberData struct {
@@ -27,6 +32,7 @@ type MemberData struct {
 	Breadcrumbs     []utils.Crumb
 	Signals         map[string]any
 	GroupID         string
+	BaseCurrency    string
 	IsAdmin         bool
 	IsAuthenticated bool
 	IsSuperAdmin    bool
@@ -34,12 +40,17 @@ type MemberData struct {
 	Pager           utils.TablePagination
 	RecentYears     []int
 	TotalCut        int64
+	TotalWithheld   int64
 	TotalExpense    int64
 	TotalPayout     int64
 	TotalPaid       int64
 	TotalUnpaid     int64
-	EventsTable     utils.TableLayout
-	PaidAtDialog    ParticipantPaidAtDialogState
+	Balance         memberstore.MemberBalance
+	// Reimbursements are expenses the member paid for the band.
+	Reimbursements      []db.Expense
+	EventsTable         utils.TableLayout
+	ReimbursementsTable utils.TableLayout
+	PaidAtDialog        ParticipantPaidAtDialogState
 }
 
 type ParticipantPaidAtDialogState struct {
@@ -64,6 +75,7 @@ type MembersData struct {
 	Breadcrumbs     []utils.Crumb
 	Signals         map[string]any
 	GroupID         string
+	BaseCurrency    string
 	IsAdmin         bool
 	IsAuthenticated bool
 	IsSuperAdmin    bool
@@ -77,6 +89,61 @@ type MemberListRow struct {
 	Unpaid      int64
 }
 
+// SettleData is the group's balance sheet with the transfers that would
+// settle it.
+type SettleData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	BaseCurrency    string
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+	Balances        []memberstore.MemberBalance
+	Transfers       []SettlementTransfer
+	BalancesTable   utils.TableLayout
+}
+
+// MemberName names a member of the balance sheet.
+func (d SettleData) MemberName(memberID string) string {
+	for _, balance := range d.Balances {
+		if balance.MemberID == memberID {
+			return balance.Name
+		}
+	}
+	return ""
+}
+
+type WithholdingData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	BaseCurrency    string
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+	Year            int
+	Years           []int
+	Withholdings    []memberstore.MemberWithholding
+	// TotalWithheld is what the band has to remit to the tax office.
+	TotalWithheld    int64
+	WithholdingTable utils.TableLayout
+}
+
+type StatementData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+	Statement       Statement
+	Years           []int
+	StatementTable  utils.TableLayout
+}
+
 type NewMemberPageData struct {
 	Title           string
 	Breadcrumbs     []utils.Crumb
@@ -0,0 +1,176 @@
+package member
+
+import (
+	"sort"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	memberstore "bandcash/models/member/data"
+)
+
+// SettlementTransfer moves Amount (base currency) from one party to another.
+// An empty member id is the band itself. A transfer from a member to
+// themselves keeps collected money against what they are owed.
+type SettlementTransfer struct {
+	FromMemberID string
+	ToMemberID   string
+	Amount       int64
+}
+
+// Keeps reports whether the member settles with money they already hold.
+func (t SettlementTransfer) Keeps() bool {
+	return t.FromMemberID != "" && t.FromMemberID == t.ToMemberID
+}
+
+type settlementParty struct {
+	memberID string
+	amount   int64
+}
+
+// PlanSettlement proposes the transfers that bring every member's balance to
+// zero. Members first keep collected money against what they are owed; the
+// rest is matched largest debt to largest claim, with the band covering the
+// difference, which takes at most one transfer fewer than the parties
+// involved.
+func PlanSettlement(balances []memberstore.MemberBalance) []SettlementTransfer {
+	transfers := make([]SettlementTransfer, 0)
+	creditors := make([]settlementParty, 0)
+	debtors := make([]settlementParty, 0)
+	total := int64(0)
+	for _, balance := range balances {
+		if kept := min(balance.Held(), balance.Owed()); kept > 0 {
+			transfers = append(transfers, SettlementTransfer{FromMemberID: balance.MemberID, ToMemberID: balance.MemberID, Amount: kept})
+		}
+		net := balance.Balance()
+		total += net
+		switch {
+		case net > 0:
+			creditors = append(creditors, settlementParty{memberID: balance.MemberID, amount: net})
+		case net < 0:
+			debtors = append(debtors, settlementParty{memberID: balance.MemberID, amount: -net})
+		}
+	}
+	switch {
+	case total > 0:
+		debtors = append(debtors, settlementParty{amount: total})
+	case total < 0:
+		creditors = append(creditors, settlementParty{amount: -total})
+	}
+	sortParties(creditors)
+	sortParties(debtors)
+
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := min(debtors[i].amount, creditors[j].amount)
+		if debtors[i].memberID != "" || creditors[j].memberID != "" {
+			transfers = append(transfers, SettlementTransfer{FromMemberID: debtors[i].memberID, ToMemberID: creditors[j].memberID, Amount: amount})
+		}
+		debtors[i].amount -= amount
+		creditors[j].amount -= amount
+		if debtors[i].amount == 0 {
+			i++
+		}
+		if creditors[j].amount == 0 {
+			j++
+		}
+	}
+	return transfers
+}
+
+// sortParties orders parties by amount, largest first, keeping the input
+// order for ties so a plan is stable between requests.
+func sortParties(parties []settlementParty) {
+	sort.SliceStable(parties, func(i, j int) bool {
+		return parties[i].amount > parties[j].amount
+	})
+}
+
+// buildSettlement turns planned transfers into the records confirming them.
+// A transfer to a member first pays back the expenses they paid, oldest first
+// and only in full, then pays out their outstanding participations, oldest
+// first. A member paying back an expense on the band's behalf hands the
+// amount over to the band. Transfers to the band become handovers.
+func buildSettlement(groupID, paidAt, method string, transfers []SettlementTransfer, outstanding []memberstore.OutstandingParticipantRow, reimbursements []memberstore.OutstandingReimbursementRow) memberstore.RecordSettlementParams {
+	params := memberstore.RecordSettlementParams{
+		Payouts:        make([]eventstore.CreateParticipantPayoutParams, 0),
+		Reimbursements: make([]memberstore.ReimburseExpenseParams, 0),
+		Handovers:      make([]memberstore.CreateMemberHandoverParams, 0),
+	}
+	remaining := make([]int64, len(outstanding))
+	for i, row := range outstanding {
+		remaining[i] = row.Outstanding()
+	}
+	reimbursed := make([]bool, len(reimbursements))
+
+	for _, transfer := range transfers {
+		if transfer.ToMemberID == "" {
+			params.Handovers = append(params.Handovers, memberstore.CreateMemberHandoverParams{
+				ID:       utils.GenerateID(utils.PrefixHandover),
+				GroupID:  groupID,
+				MemberID: transfer.FromMemberID,
+				Amount:   transfer.Amount,
+				HandedAt: paidAt,
+			})
+			continue
+		}
+
+		left := transfer.Amount
+		handedOver := int64(0)
+		for i, row := range reimbursements {
+			base := row.BaseAmount()
+			if row.MemberID != transfer.ToMemberID || reimbursed[i] || base > left {
+				continue
+			}
+			reimbursed[i] = true
+			left -= base
+			params.Reimbursements = append(params.Reimbursements, memberstore.ReimburseExpenseParams{
+				ID:      row.ExpenseID,
+				GroupID: groupID,
+				PaidAt:  paidAt,
+			})
+			if transfer.FromMemberID != "" {
+				handedOver += base
+			}
+		}
+		if handedOver > 0 {
+			params.Handovers = append(params.Handovers, memberstore.CreateMemberHandoverParams{
+				ID:       utils.GenerateID(utils.PrefixHandover),
+				GroupID:  groupID,
+				MemberID: transfer.FromMemberID,
+				Amount:   handedOver,
+				HandedAt: paidAt,
+			})
+		}
+
+		for i, row := range outstanding {
+			if left <= 0 {
+				break
+			}
+			if row.MemberID != transfer.ToMemberID || remaining[i] <= 0 {
+				continue
+			}
+			amount := remaining[i]
+			if base := currency.ToBase(amount, row.ExchangeRate); left < base {
+				amount = min(currency.FromBase(left, row.ExchangeRate), remaining[i])
+				left = 0
+			} else {
+				left -= base
+			}
+			if amount <= 0 {
+				continue
+			}
+			remaining[i] -= amount
+			params.Payouts = append(params.Payouts, eventstore.CreateParticipantPayoutParams{
+				ID:       utils.GenerateID(utils.PrefixPayout),
+				GroupID:  groupID,
+				EventID:  row.EventID,
+				MemberID: row.MemberID,
+				Amount:   amount,
+				PaidAt:   paidAt,
+				Method:   method,
+				PaidBy:   transfer.FromMemberID,
+			})
+		}
+	}
+	return params
+}
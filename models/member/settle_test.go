@@ -0,0 +1,136 @@
+package member
+
+import (
+	"testing"
+
+	memberstore "bandcash/models/member/data"
+)
+
+func TestPlanSettlement(t *testing.T) {
+	t.Parallel()
+
+	balances := []memberstore.MemberBalance{
+		// Owed 400, holds 300 from the door: keeps 300, band pays 100.
+		{MemberID: "mem_a", Earned: 400, Collected: 300},
+		// Owed 200, holds nothing.
+		{MemberID: "mem_b", Earned: 150, Expenses: 50},
+		// Owed nothing, holds 500: pays the others and hands the rest back.
+		{MemberID: "mem_c", Collected: 500},
+	}
+	got := PlanSettlement(balances)
+	want := []SettlementTransfer{
+		{FromMemberID: "mem_a", ToMemberID: "mem_a", Amount: 300},
+		{FromMemberID: "mem_c", ToMemberID: "mem_b", Amount: 200},
+		{FromMemberID: "mem_c", ToMemberID: "", Amount: 200},
+		{FromMemberID: "mem_c", ToMemberID: "mem_a", Amount: 100},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("PlanSettlement() = %+v; want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("transfer %d = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPlanSettlementSettled(t *testing.T) {
+	t.Parallel()
+
+	balances := []memberstore.MemberBalance{
+		{MemberID: "mem_a", Earned: 400, PaidOut: 400},
+		{MemberID: "mem_b", Collected: 100, PassedOn: 100},
+	}
+	if got := PlanSettlement(balances); len(got) != 0 {
+		t.Fatalf("PlanSettlement() = %+v; want no transfers", got)
+	}
+}
+
+func TestPlanSettlementBandPays(t *testing.T) {
+	t.Parallel()
+
+	balances := []memberstore.MemberBalance{
+		{MemberID: "mem_a", Earned: 100},
+		{MemberID: "mem_b", Earned: 300},
+	}
+	got := PlanSettlement(balances)
+	want := []SettlementTransfer{
+		{FromMemberID: "", ToMemberID: "mem_b", Amount: 300},
+		{FromMemberID: "", ToMemberID: "mem_a", Amount: 100},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("PlanSettlement() = %+v; want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("transfer %d = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBuildSettlement(t *testing.T) {
+	t.Parallel()
+
+	transfers := []SettlementTransfer{
+		{FromMemberID: "mem_a", ToMemberID: "mem_a", Amount: 300},
+		{FromMemberID: "", ToMemberID: "mem_a", Amount: 100},
+		{FromMemberID: "mem_c", ToMemberID: "", Amount: 50},
+	}
+	outstanding := []memberstore.OutstandingParticipantRow{
+		{EventID: "evt_1", MemberID: "mem_a", Owed: 250, ExchangeRate: 1},
+		// Kept in a currency worth 2 base units.
+		{EventID: "evt_2", MemberID: "mem_a", Owed: 100, PaidOut: 25, ExchangeRate: 2},
+	}
+	got := buildSettlement("grp_1", "2026-05-11", "cash", transfers, outstanding, nil)
+
+	type payout struct {
+		eventID string
+		amount  int64
+		paidBy  string
+	}
+	want := []payout{
+		{"evt_1", 250, "mem_a"},
+		{"evt_2", 25, "mem_a"},
+		{"evt_2", 50, ""},
+	}
+	if len(got.Payouts) != len(want) {
+		t.Fatalf("payouts = %+v; want %+v", got.Payouts, want)
+	}
+	for i, w := range want {
+		p := got.Payouts[i]
+		if p.EventID != w.eventID || p.Amount != w.amount || p.PaidBy != w.paidBy || p.MemberID != "mem_a" || p.PaidAt != "2026-05-11" || p.Method != "cash" {
+			t.Errorf("payout %d = %+v; want %+v", i, p, w)
+		}
+	}
+	if len(got.Handovers) != 1 || got.Handovers[0].MemberID != "mem_c" || got.Handovers[0].Amount != 50 {
+		t.Errorf("handovers = %+v; want one of 50 from mem_c", got.Handovers)
+	}
+}
+
+func TestBuildSettlementReimbursements(t *testing.T) {
+	t.Parallel()
+
+	transfers := []SettlementTransfer{
+		{FromMemberID: "mem_b", ToMemberID: "mem_a", Amount: 150},
+	}
+	outstanding := []memberstore.OutstandingParticipantRow{
+		{EventID: "evt_1", MemberID: "mem_a", Owed: 100, ExchangeRate: 1},
+	}
+	reimbursements := []memberstore.OutstandingReimbursementRow{
+		// Too large for what is left once the first one is paid back.
+		{ExpenseID: "exp_1", MemberID: "mem_a", Amount: 40, ExchangeRate: 1},
+		{ExpenseID: "exp_2", MemberID: "mem_a", Amount: 200, ExchangeRate: 1},
+		{ExpenseID: "exp_3", MemberID: "mem_b", Amount: 10, ExchangeRate: 1},
+	}
+	got := buildSettlement("grp_1", "2026-05-12", "cash", transfers, outstanding, reimbursements)
+
+	if len(got.Reimbursements) != 1 || got.Reimbursements[0].ID != "exp_1" || got.Reimbursements[0].PaidAt != "2026-05-12" {
+		t.Fatalf("reimbursements = %+v; want exp_1 only", got.Reimbursements)
+	}
+	if len(got.Handovers) != 1 || got.Handovers[0].MemberID != "mem_b" || got.Handovers[0].Amount != 40 {
+		t.Errorf("handovers = %+v; want one of 40 from mem_b", got.Handovers)
+	}
+	if len(got.Payouts) != 1 || got.Payouts[0].EventID != "evt_1" || got.Payouts[0].Amount != 100 || got.Payouts[0].PaidBy != "mem_b" {
+		t.Errorf("payouts = %+v; want 100 on evt_1 paid by mem_b", got.Payouts)
+	}
+}
@@ -1,10 +1,23 @@
 package member
 
-import "bandcash/internal/utils"
+import (
+	"time"
+
+	"bandcash/internal/db"
+	"bandcash/internal/tax"
+	"bandcash/internal/utils"
+)
 
 type memberParams struct {
-	Name        string `json:"name" validate:"required,min=1,max=255"`
-	Description string `json:"description" validate:"max=1000"`
+	Name            string  `json:"name" validate:"required,min=1,max=255"`
+	Description     string  `json:"description" validate:"max=1000"`
+	TaxMode         string  `json:"taxMode" validate:"omitempty,oneof=gross withholding"`
+	WithholdingRate float64 `json:"withholdingRate" validate:"gte=0,max=100"`
+	// Version is the member's when the edit form opened.
+	Version int64 `json:"version"`
+	// Opened holds the member's values when the edit form opened, so a
+	// conflict lists what others changed since.
+	Opened *memberParams `json:"opened,omitempty" validate:"-"`
 }
 
 type memberTableParams struct {
@@ -28,6 +41,44 @@ type participantPaidAtParams struct {
 	} `json:"participantPaidAtDialog"`
 }
 
+type settleParams struct {
+	TabID      string         `json:"tab_id"`
+	SettleForm settleFormData `json:"settleForm"`
+}
+
+type settleFormData struct {
+	PaidAt string `json:"paidAt" validate:"required"`
+	Method string `json:"method" validate:"omitempty,oneof=bank_transfer cash card other"`
+}
+
+var settleErrorFields = []string{"paidAt", "method"}
+
+func newMemberFormData() map[string]any {
+	return map[string]any{"name": "", "description": "", "taxMode": tax.ModeGross, "withholdingRate": 0}
+}
+
+// memberFormValues returns the editable fields of member as the edit form
+// binds them.
+func memberFormValues(member db.Member) map[string]any {
+	return map[string]any{
+		"name":            member.Name,
+		"description":     member.Description,
+		"taxMode":         tax.NormalizeMode(member.TaxMode),
+		"withholdingRate": member.WithholdingRate,
+	}
+}
+
+func memberFormData(member db.Member) map[string]any {
+	form := memberFormValues(member)
+	form["version"] = member.Version
+	form["opened"] = memberFormValues(member)
+	return form
+}
+
+func newMemberErrors() map[string]any {
+	return map[string]any{"name": "", "description": "", "taxMode": "", "withholdingRate": ""}
+}
+
 func memberIndexSignals(query map[string]any) map[string]any {
 	return map[string]any{
 		"tableQuery":     query,
@@ -35,8 +86,8 @@ func memberIndexSignals(query map[string]any) map[string]any {
 		"formState":      "",
 		"eventFormState": "",
 		"editingId":      0,
-		"formData":       map[string]any{"name": "", "description": ""},
-		"errors":         map[string]any{"name": "", "description": ""},
+		"formData":       newMemberFormData(),
+		"errors":         newMemberErrors(),
 	}
 }
 
@@ -47,14 +98,8 @@ func memberShowSignals(data MemberData) map[string]any {
 		"formState":      "",
 		"eventFormState": "",
 		"summaryMode":    data.Query.Summary,
-		"formData": map[string]any{
-			"name":        data.Member.Name,
-			"description": data.Member.Description,
-		},
-		"errors": map[string]any{
-			"name":        "",
-			"description": "",
-		},
+		"formData":       memberFormData(*data.Member),
+		"errors":         newMemberErrors(),
 		"participantPaidAtDialog": map[string]any{
 			"open":        data.PaidAtDialog.Open,
 			"fetching":    data.PaidAtDialog.Fetching,
@@ -69,3 +114,16 @@ func memberShowSignals(data MemberData) map[string]any {
 		},
 	}
 }
+
+func settleSignals() map[string]any {
+	return map[string]any{
+		"settleForm": map[string]any{
+			"paidAt": time.Now().Format("2006-01-02"),
+			"method": "",
+		},
+		"settleErrors": map[string]any{
+			"paidAt": "",
+			"method": "",
+		},
+	}
+}
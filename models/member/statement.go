@@ -0,0 +1,146 @@
+package member
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"bandcash/internal/currency"
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
+)
+
+// Statement is what a member earned from the band over a year, one line per
+// event, oldest first. Totals are in the group's base currency.
+type Statement struct {
+	Member        db.Member
+	GroupName     string
+	BaseCurrency  string
+	Year          int
+	Lines         []StatementLine
+	TotalCut      int64
+	TotalWithheld int64
+	TotalExpense  int64
+	TotalPayout   int64
+	TotalPaid     int64
+}
+
+// StatementLine is one event of a statement, in the event's currency. A
+// cancelled event earns its compensation, with nothing withheld and no
+// expense.
+type StatementLine struct {
+	EventID      string
+	Title        string
+	Date         string
+	Cancelled    bool
+	Currency     string
+	ExchangeRate float64
+	Cut          int64
+	Withheld     int64
+	Expense      int64
+	Payout       int64
+	// PaidAt is the day the member was paid in full; empty while unpaid.
+	PaidAt string
+}
+
+// buildStatement lays out a member's events as a statement.
+func buildStatement(member db.Member, group db.Group, year int, rows []memberstore.MemberEventRow) Statement {
+	statement := Statement{
+		Member:       member,
+		GroupName:    group.Name,
+		BaseCurrency: group.BaseCurrency,
+		Year:         year,
+		Lines:        make([]StatementLine, 0, len(rows)),
+	}
+	for _, row := range rows {
+		line := StatementLine{
+			EventID:      row.ID,
+			Title:        row.Title,
+			Date:         row.Date,
+			Cancelled:    row.Status == eventstore.EventStatusCancelled,
+			Currency:     currency.Of(row.Currency, group.BaseCurrency),
+			ExchangeRate: row.ExchangeRate,
+			Cut:          row.ParticipantAmount,
+			Withheld:     row.ParticipantWithheld,
+			Expense:      row.ParticipantExpense,
+		}
+		if line.Cancelled {
+			line.Cut = row.ParticipantCompensation
+			line.Withheld = 0
+			line.Expense = 0
+		}
+		line.Payout = eventstore.PayoutAmount(row.Status, row.ParticipantAmount, row.ParticipantWithheld, row.ParticipantExpense, row.ParticipantCompensation)
+		if row.ParticipantPaid == 1 && row.ParticipantPaidAt.Valid {
+			line.PaidAt = utils.FormatDateInput(row.ParticipantPaidAt.String)
+		}
+		paid := eventstore.PaidOutAmount(line.Payout, row.ParticipantPaidOut, row.ParticipantPaid)
+
+		statement.TotalCut += currency.ToBase(line.Cut, line.ExchangeRate)
+		statement.TotalWithheld += currency.ToBase(line.Withheld, line.ExchangeRate)
+		statement.TotalExpense += currency.ToBase(line.Expense, line.ExchangeRate)
+		statement.TotalPayout += currency.ToBase(line.Payout, line.ExchangeRate)
+		statement.TotalPaid += currency.ToBase(paid, line.ExchangeRate)
+		statement.Lines = append(statement.Lines, line)
+	}
+	return statement
+}
+
+// getStatement loads the statement of a member for year.
+func getStatement(ctx context.Context, group db.Group, member db.Member, year int) (Statement, error) {
+	rows, err := memberstore.ListMemberEventsTable(ctx, memberstore.MemberEventListParams{
+		MemberEventFilter: memberstore.MemberEventFilter{
+			MemberID: member.ID,
+			GroupID:  group.ID,
+			Year:     strconv.Itoa(year),
+		},
+		Sort: "date",
+		Dir:  "asc",
+	})
+	if err != nil {
+		return Statement{}, err
+	}
+	return buildStatement(member, group, year, rows), nil
+}
+
+// GetStatement loads the statement of one member of a group for year.
+func GetStatement(ctx context.Context, groupID, memberID string, year int) (Statement, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return Statement{}, err
+	}
+	member, err := memberstore.GetMember(ctx, memberstore.GetMemberParams{ID: memberID, GroupID: groupID})
+	if err != nil {
+		return Statement{}, err
+	}
+	return getStatement(ctx, group, member, year)
+}
+
+// ListStatements loads the statements for year of every member of a group
+// with any event that year, by name.
+func ListStatements(ctx context.Context, groupID string, year int) ([]Statement, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := memberstore.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]Statement, 0, len(members))
+	for _, member := range members {
+		statement, err := getStatement(ctx, group, member, year)
+		if err != nil {
+			return nil, err
+		}
+		if len(statement.Lines) > 0 {
+			statements = append(statements, statement)
+		}
+	}
+	sort.SliceStable(statements, func(i, j int) bool {
+		return statements[i].Member.Name < statements[j].Member.Name
+	})
+	return statements, nil
+}
@@ -0,0 +1,213 @@
+package member
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"golang.org/x/text/unicode/norm"
+
+	appi18n "bandcash/internal/i18n"
+	"bandcash/internal/pdf"
+	"bandcash/internal/spreadsheet"
+	"bandcash/internal/utils"
+)
+
+const (
+	statementMarginX    = 50.0
+	statementRight      = pdf.PageWidth - statementMarginX
+	statementPageBottom = pdf.PageHeight - 60
+	statementBodySize   = 9.0
+	statementLineHeight = 13.0
+)
+
+// Column right edges of the statement table; the event fills the rest.
+var (
+	statementColCut      = statementRight - 280
+	statementColWithheld = statementRight - 210
+	statementColExpense  = statementRight - 140
+	statementColPayout   = statementRight - 70
+	statementColPaidAt   = statementRight
+)
+
+var statementColumns = []string{"date", "title", "cut", "withheld", "expense", "payout", "currency", "paid_at"}
+
+// statementRecords lists the lines of a statement, header first and totals
+// in the base currency last.
+func statementRecords(ctx context.Context, statement Statement) ([][]string, spreadsheet.Format) {
+	format := spreadsheet.FormatForLocale(appi18n.LocaleCode(ctx))
+
+	header := make([]string, len(statementColumns))
+	for i, column := range statementColumns {
+		header[i] = ctxi18n.T(ctx, "csv.columns."+column)
+	}
+	records := [][]string{header}
+
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			line.Date,
+			statementLineTitle(ctx, line),
+			strconv.FormatInt(line.Cut, 10),
+			strconv.FormatInt(line.Withheld, 10),
+			strconv.FormatInt(line.Expense, 10),
+			strconv.FormatInt(line.Payout, 10),
+			line.Currency,
+			line.PaidAt,
+		})
+	}
+	records = append(records, []string{
+		"",
+		ctxi18n.T(ctx, "statements.total"),
+		strconv.FormatInt(statement.TotalCut, 10),
+		strconv.FormatInt(statement.TotalWithheld, 10),
+		strconv.FormatInt(statement.TotalExpense, 10),
+		strconv.FormatInt(statement.TotalPayout, 10),
+		statement.BaseCurrency,
+		"",
+	})
+	return records, format
+}
+
+// statementCSV writes a statement as CSV in the locale of ctx.
+func statementCSV(ctx context.Context, statement Statement) ([]byte, error) {
+	records, format := statementRecords(ctx, statement)
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, format, records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// statementPDF lays out a statement in the locale of ctx.
+func statementPDF(ctx context.Context, statement Statement) ([]byte, error) {
+	title := ctxi18n.T(ctx, "statements.title_year", statement.Year)
+	out := pdf.New(title + " - " + statement.Member.Name)
+	out.AddPage()
+
+	out.Text(statementMarginX, 70, 20, pdf.Bold, title)
+	out.Text(statementMarginX, 92, 11, pdf.Bold, pdf.Fit(statement.Member.Name, statementRight-statementMarginX, 11, pdf.Bold))
+	out.Text(statementMarginX, 92+statementLineHeight+2, statementBodySize+1, pdf.Regular, pdf.Fit(statement.GroupName, statementRight-statementMarginX, statementBodySize+1, pdf.Regular))
+	out.Line(statementMarginX, 125, statementRight, 125, 0.5)
+
+	y := statementTableHeader(ctx, out, 150)
+	eventWidth := statementColCut - statementMarginX - 75
+	for _, line := range statement.Lines {
+		if y > statementPageBottom {
+			out.AddPage()
+			y = statementTableHeader(ctx, out, 70)
+		}
+		out.Text(statementMarginX, y, statementBodySize, pdf.Regular, pdf.Fit(statementLineTitle(ctx, line), eventWidth, statementBodySize, pdf.Regular))
+		out.Text(statementMarginX, y+statementLineHeight-2, statementBodySize-1, pdf.Regular, utils.FormatDateLocalized(ctx, line.Date))
+		out.TextRight(statementColCut, y, statementBodySize, pdf.Regular, utils.FormatMoneyLocalized(ctx, line.Cut, line.Currency))
+		out.TextRight(statementColWithheld, y, statementBodySize, pdf.Regular, utils.FormatMoneyLocalized(ctx, line.Withheld, line.Currency))
+		out.TextRight(statementColExpense, y, statementBodySize, pdf.Regular, utils.FormatMoneyLocalized(ctx, line.Expense, line.Currency))
+		out.TextRight(statementColPayout, y, statementBodySize, pdf.Regular, utils.FormatMoneyLocalized(ctx, line.Payout, line.Currency))
+		paidAt := "-"
+		if line.PaidAt != "" {
+			paidAt = utils.FormatDateLocalized(ctx, line.PaidAt)
+		}
+		out.TextRight(statementColPaidAt, y, statementBodySize, pdf.Regular, paidAt)
+		y += 2*statementLineHeight + 2
+	}
+
+	if y > statementPageBottom {
+		out.AddPage()
+		y = 70
+	}
+	out.Line(statementMarginX, y-8, statementRight, y-8, 0.5)
+	y += 6
+	out.Text(statementMarginX, y, statementBodySize, pdf.Bold, ctxi18n.T(ctx, "statements.total"))
+	out.TextRight(statementColCut, y, statementBodySize, pdf.Bold, utils.FormatMoneyLocalized(ctx, statement.TotalCut, statement.BaseCurrency))
+	out.TextRight(statementColWithheld, y, statementBodySize, pdf.Bold, utils.FormatMoneyLocalized(ctx, statement.TotalWithheld, statement.BaseCurrency))
+	out.TextRight(statementColExpense, y, statementBodySize, pdf.Bold, utils.FormatMoneyLocalized(ctx, statement.TotalExpense, statement.BaseCurrency))
+	out.TextRight(statementColPayout, y, statementBodySize, pdf.Bold, utils.FormatMoneyLocalized(ctx, statement.TotalPayout, statement.BaseCurrency))
+	y += statementLineHeight + 4
+	out.Text(statementMarginX, y, statementBodySize, pdf.Regular, ctxi18n.T(ctx, "statements.paid_line", utils.FormatMoneyLocalized(ctx, statement.TotalPaid, statement.BaseCurrency)))
+
+	return out.Bytes()
+}
+
+func statementTableHeader(ctx context.Context, out *pdf.Document, y float64) float64 {
+	size := statementBodySize - 1
+	out.Text(statementMarginX, y, size, pdf.Bold, ctxi18n.T(ctx, "statements.event"))
+	out.TextRight(statementColCut, y, size, pdf.Bold, ctxi18n.T(ctx, "participants.cut"))
+	out.TextRight(statementColWithheld, y, size, pdf.Bold, ctxi18n.T(ctx, "participants.withheld"))
+	out.TextRight(statementColExpense, y, size, pdf.Bold, ctxi18n.T(ctx, "participants.expense"))
+	out.TextRight(statementColPayout, y, size, pdf.Bold, ctxi18n.T(ctx, "participants.payout_total"))
+	out.TextRight(statementColPaidAt, y, size, pdf.Bold, ctxi18n.T(ctx, "fields.paid_at"))
+	out.Line(statementMarginX, y+6, statementRight, y+6, 0.5)
+	return y + statementLineHeight + 8
+}
+
+// statementLineTitle names the event of a line, marking cancelled ones.
+func statementLineTitle(ctx context.Context, line StatementLine) string {
+	if line.Cancelled {
+		return line.Title + " (" + ctxi18n.T(ctx, "events.status.cancelled") + ")"
+	}
+	return line.Title
+}
+
+// statementFileName names the files of a member's statement, e.g.
+// "statement-2026-jane-doe.pdf".
+func statementFileName(statement Statement, ext string) string {
+	return fmt.Sprintf("statement-%d-%s.%s", statement.Year, fileSlug(statement.Member.Name, statement.Member.ID), ext)
+}
+
+// fileSlug lowercases name to ASCII letters, digits and dashes for a file
+// name, dropping accents, and falls back to fallback when nothing is left.
+func fileSlug(name, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
+
+// statementsZip packs the PDF statement of every member into one archive.
+// Members sharing a name get their id appended so no file is overwritten.
+func statementsZip(ctx context.Context, statements []Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	used := make(map[string]bool, len(statements))
+	for _, statement := range statements {
+		name := statementFileName(statement, "pdf")
+		if used[name] {
+			name = fmt.Sprintf("statement-%d-%s-%s.pdf", statement.Year, fileSlug(statement.Member.Name, statement.Member.ID), statement.Member.ID)
+		}
+		used[name] = true
+
+		content, err := statementPDF(ctx, statement)
+		if err != nil {
+			return nil, err
+		}
+		file, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package member
+
+import (
+	"database/sql"
+	"testing"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	memberstore "bandcash/models/member/data"
+)
+
+func TestBuildStatement(t *testing.T) {
+	t.Parallel()
+
+	member := db.Member{ID: "mem_a", Name: "Anna"}
+	group := db.Group{ID: "grp_a", Name: "Band", BaseCurrency: "HUF"}
+	rows := []memberstore.MemberEventRow{
+		// Paid gig: 1000 cut, 150 withheld, 50 expense.
+		{
+			ID: "evt_a", Title: "Club", Date: "2026-03-14", Status: eventstore.EventStatusActive, Currency: "HUF", ExchangeRate: 1,
+			ParticipantAmount: 1000, ParticipantWithheld: 150, ParticipantExpense: 50,
+			ParticipantPaid: 1, ParticipantPaidAt: sql.NullString{String: "2026-03-20", Valid: true},
+		},
+		// Cancelled gig in EUR: only the compensation counts.
+		{
+			ID: "evt_b", Title: "Festival", Date: "2026-06-01", Status: eventstore.EventStatusCancelled, Currency: "EUR", ExchangeRate: 400,
+			ParticipantAmount: 100, ParticipantWithheld: 15, ParticipantExpense: 10, ParticipantCompensation: 20,
+			ParticipantPaidOut: 5,
+		},
+	}
+
+	got := buildStatement(member, group, 2026, rows)
+	if len(got.Lines) != 2 {
+		t.Fatalf("buildStatement() lines = %d; want 2", len(got.Lines))
+	}
+	if line := got.Lines[0]; line.Payout != 900 || line.PaidAt != "2026-03-20" || line.Cancelled {
+		t.Errorf("line 0 = %+v; want payout 900 paid at 2026-03-20", line)
+	}
+	if line := got.Lines[1]; line.Cut != 20 || line.Withheld != 0 || line.Expense != 0 || line.Payout != 20 || line.PaidAt != "" || !line.Cancelled {
+		t.Errorf("line 1 = %+v; want cancelled with cut and payout 20", line)
+	}
+
+	totals := []struct {
+		name      string
+		got, want int64
+	}{
+		{"TotalCut", got.TotalCut, 1000 + 20*400},
+		{"TotalWithheld", got.TotalWithheld, 150},
+		{"TotalExpense", got.TotalExpense, 50},
+		{"TotalPayout", got.TotalPayout, 900 + 20*400},
+		{"TotalPaid", got.TotalPaid, 900 + 5*400},
+	}
+	for _, total := range totals {
+		if total.got != total.want {
+			t.Errorf("%s = %d; want %d", total.name, total.got, total.want)
+		}
+	}
+}
+
+func TestFileSlug(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Jane Doe", "jane-doe"},
+		{"Kovács Árpád", "kovacs-arpad"},
+		{"  --Dj  Ölő!-- ", "dj-olo"},
+		{"???", "mem_a"},
+	}
+	for _, tt := range tests {
+		if got := fileSlug(tt.name, "mem_a"); got != tt.want {
+			t.Errorf("fileSlug(%q) = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -21,3 +21,46 @@ func MemberEventsTableLayout() utils.TableLayout {
 		{Key: "paid_at", MaxWRem: 12, WRem: 12},
 	}, 0)
 }
+
+func MemberReimbursementsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "title"},
+		{Key: "date"},
+		{Key: "amount"},
+		{Key: "paid", MaxWRem: 9, WRem: 9},
+		{Key: "paid_at", MaxWRem: 12, WRem: 12},
+	}, 0)
+}
+
+func MemberBalancesTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "name"},
+		{Key: "earned", MaxWRem: 10},
+		{Key: "expenses", MaxWRem: 10},
+		{Key: "paid_out", MaxWRem: 10},
+		{Key: "collected", MaxWRem: 10},
+		{Key: "passed_on", MaxWRem: 10},
+		{Key: "balance", MaxWRem: 10},
+	}, 0)
+}
+
+func MemberWithholdingTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "name"},
+		{Key: "gross", MaxWRem: 10},
+		{Key: "withheld", MaxWRem: 10},
+		{Key: "net", MaxWRem: 10},
+	}, 0)
+}
+
+func MemberStatementTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "date", MaxWRem: 10},
+		{Key: "title"},
+		{Key: "cut", MaxWRem: 10},
+		{Key: "withheld", MaxWRem: 10},
+		{Key: "expense", MaxWRem: 10},
+		{Key: "payout", MaxWRem: 10},
+		{Key: "paid_at", MaxWRem: 10},
+	}, 0)
+}
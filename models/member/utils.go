@@ -1,9 +1,20 @@
 package member
 
 import (
+	"context"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/db"
+	"bandcash/internal/tax"
 	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	memberstore "bandcash/models/member/data"
 )
 
 type staticTableQueryable struct {
@@ -34,3 +45,93 @@ func applyMemberShowTableByRole(data *MemberData, isAdmin bool) {
 		data.EventsTable.ActionsWidthRem = 0
 	}
 }
+
+// settlePartyName names a party of a settlement transfer; the band has no
+// member id.
+func settlePartyName(ctx context.Context, data SettleData, memberID string) string {
+	if memberID == "" {
+		return ctxi18n.T(ctx, "settle.band")
+	}
+	return data.MemberName(memberID)
+}
+
+// validateMember validates a member form. Members with tax withheld need a
+// rate above zero.
+func validateMember(ctx context.Context, form memberParams) map[string]string {
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		return errs
+	}
+	if form.TaxMode == tax.ModeWithholding && form.WithholdingRate <= 0 {
+		return map[string]string{"withholdingRate": ctxi18n.T(ctx, "validation.gt", "0")}
+	}
+	return nil
+}
+
+// parseYear reads the year of a yearly report, the current year when value
+// is not one.
+func parseYear(value string) int {
+	year, err := strconv.Atoi(value)
+	if err != nil || year < 1900 || year > 9999 {
+		return time.Now().Year()
+	}
+	return year
+}
+
+// reportYears lists the recent years and the selected one, newest first, for
+// the yearly reports.
+func reportYears(selected int) []int {
+	years := utils.RecentYears(3)
+	if !slices.Contains(years, selected) {
+		years = append(years, selected)
+		slices.SortFunc(years, func(a, b int) int { return b - a })
+	}
+	return years
+}
+
+// publishMember tells other tabs that a member changed: the group's pages,
+// the member's and those of eventIDs.
+func publishMember(c echo.Context, groupID, memberID string, eventIDs ...string) {
+	topics := []string{utils.GroupTopic(groupID), utils.MemberTopic(memberID)}
+	for _, eventID := range eventIDs {
+		topics = append(topics, utils.EventTopic(eventID))
+	}
+	utils.SSEHub.Publish(c, topics...)
+}
+
+// memberConflictFields lists, as audit field names, where the saved member
+// differs from the one the form was opened with.
+func memberConflictFields(current db.Member, opened memberParams) []string {
+	fields := make([]string, 0)
+	if current.Name != opened.Name {
+		fields = append(fields, "name")
+	}
+	if current.Description != opened.Description {
+		fields = append(fields, "description")
+	}
+	if tax.NormalizeMode(current.TaxMode) != tax.NormalizeMode(opened.TaxMode) {
+		fields = append(fields, "tax_mode")
+	}
+	if tax.NormalizeMode(current.TaxMode) == tax.ModeWithholding && current.WithholdingRate != opened.WithholdingRate {
+		fields = append(fields, "withholding_rate")
+	}
+	return fields
+}
+
+// patchMemberConflict opens the conflict dialog after a save of form was
+// rejected as stale, with the member's current version to overwrite with.
+func patchMemberConflict(c echo.Context, groupID, memberID string, form memberParams) error {
+	ctx := c.Request().Context()
+	current, err := memberstore.GetMember(ctx, memberstore.GetMemberParams{ID: memberID, GroupID: groupID})
+	if err != nil {
+		return err
+	}
+	opened := form
+	if form.Opened != nil {
+		opened = *form.Opened
+	}
+	labels := make([]string, 0)
+	for _, field := range memberConflictFields(current, opened) {
+		labels = append(labels, audit.FieldLabel(ctx, field))
+	}
+	return utils.PatchConflict(c, labels, map[string]int64{"version": current.Version})
+}
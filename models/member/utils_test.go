@@ -0,0 +1,26 @@
+package member
+
+import (
+	"slices"
+	"testing"
+
+	"bandcash/internal/db"
+	"bandcash/internal/tax"
+)
+
+func TestMemberConflictFields(t *testing.T) {
+	t.Parallel()
+
+	current := db.Member{Name: "Anna", TaxMode: tax.ModeGross, WithholdingRate: 15}
+	opened := memberParams{Name: "Anna"}
+	if got := memberConflictFields(current, opened); len(got) != 0 {
+		t.Errorf("memberConflictFields() = %v; want none, the rate only counts when withholding", got)
+	}
+
+	current.Description = "Drums"
+	current.TaxMode = tax.ModeWithholding
+	want := []string{"description", "tax_mode", "withholding_rate"}
+	if got := memberConflictFields(current, opened); !slices.Equal(got, want) {
+		t.Errorf("memberConflictFields() = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,358 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
+	memberstore "bandcash/models/member/data"
+)
+
+// Create records a payment received for an event. The event counts as paid
+// once its payments cover the income.
+func Create(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	eventID := c.Param("id")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		slog.Info("payment.create: invalid event id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals createParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("payment.create: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := signals.PaymentForm
+	form.PaidAt = utils.FormatDateInput(strings.TrimSpace(form.PaidAt))
+	form.Reference = strings.TrimSpace(form.Reference)
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"paymentErrors": utils.WithErrors(paymentErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("payment.create: event not found", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if form.CollectedBy != "" {
+		if _, err := memberstore.GetMember(ctx, memberstore.GetMemberParams{ID: form.CollectedBy, GroupID: groupID}); err != nil {
+			utils.SSEHub.PatchSignals(c, map[string]any{"paymentErrors": utils.WithErrors(paymentErrorFields, map[string]string{
+				"collectedBy": ctxi18n.T(ctx, "validation.required"),
+			})})
+			return c.NoContent(http.StatusUnprocessableEntity)
+		}
+	}
+
+	summary, err := eventstore.SumEventPayments(ctx, eventstore.ListEventPaymentsParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Error("payment.create: failed to sum payments", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if form.Amount > eventstore.OutstandingAmount(event, summary.Received) {
+		utils.SSEHub.PatchSignals(c, map[string]any{"paymentErrors": utils.WithErrors(paymentErrorFields, map[string]string{
+			"amount": ctxi18n.T(ctx, "payments.errors.exceeds_outstanding"),
+		})})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	var created db.EventPayment
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		created, err = eventstore.CreateEventPayment(ctx, eventstore.CreateEventPaymentParams{
+			ID:          utils.GenerateID(utils.PrefixPayment),
+			GroupID:     groupID,
+			EventID:     eventID,
+			Amount:      form.Amount,
+			PaidAt:      form.PaidAt,
+			Method:      form.Method,
+			Reference:   form.Reference,
+			CollectedBy: form.CollectedBy,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{
+			Action:   audit.ActionPayment,
+			Entity:   utils.EntityFilterEvent,
+			EntityID: eventID,
+			Label:    event.Title,
+			After:    paymentValues(created),
+		})
+	})
+	if err != nil {
+		slog.Error("payment.create: failed to create payment", "event_id", eventID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "payments.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	publish(c, groupID, eventID, created.CollectedBy.String)
+	utils.InvalidateGroupCaches(groupID)
+	utils.Notify(c, ctxi18n.T(ctx, "payments.notifications.created"))
+
+	if err := utils.SSEHub.Redirect(c, eventURL(groupID, eventID)); err != nil {
+		slog.Warn("payment.create: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// Destroy removes a payment; the event becomes unpaid again if the rest no
+// longer covers its income.
+func Destroy(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	eventID := c.Param("id")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		slog.Info("payment.destroy: invalid event id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+	paymentID := c.Param("paymentId")
+	if !utils.IsValidID(paymentID, utils.PrefixPayment) {
+		slog.Info("payment.destroy: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("payment.destroy: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("payment.destroy: event not found", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+	row, err := eventstore.GetEventPayment(ctx, eventstore.GetEventPaymentParams{ID: paymentID, EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("payment.destroy: payment not found", "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := eventstore.DeleteEventPayment(ctx, eventstore.DeleteEventPaymentParams{ID: paymentID, EventID: eventID, GroupID: groupID})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{
+			Action:   audit.ActionPaymentDelete,
+			Entity:   utils.EntityFilterEvent,
+			EntityID: eventID,
+			Label:    event.Title,
+			Before:   paymentValues(row),
+		})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.NoContent(http.StatusNotFound)
+	}
+	if err != nil {
+		slog.Error("payment.destroy: failed to delete payment", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "payments.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	publish(c, groupID, eventID, row.CollectedBy.String)
+	utils.InvalidateGroupCaches(groupID)
+	utils.Notify(c, ctxi18n.T(ctx, "payments.notifications.deleted"))
+
+	if err := utils.SSEHub.Redirect(c, eventURL(groupID, eventID)); err != nil {
+		slog.Warn("payment.destroy: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// CreatePayout records a payout to a participant of an event. The
+// participant counts as paid once the payouts cover what they are owed.
+func CreatePayout(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	eventID := c.Param("id")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		slog.Info("payout.create: invalid event id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals createPayoutParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("payout.create: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := signals.PayoutForm
+	form.PaidAt = utils.FormatDateInput(strings.TrimSpace(form.PaidAt))
+	form.Reference = strings.TrimSpace(form.Reference)
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"payoutErrors": utils.WithErrors(payoutErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("payout.create: event not found", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+	participants, err := eventstore.ListParticipantsByEvent(ctx, eventstore.ListParticipantsByEventParams{EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Error("payout.create: failed to list participants", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	var participant *eventstore.ListParticipantsByEventRow
+	for i := range participants {
+		if participants[i].ID == form.MemberID {
+			participant = &participants[i]
+			break
+		}
+	}
+	if participant == nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"payoutErrors": utils.WithErrors(payoutErrorFields, map[string]string{
+			"memberId": ctxi18n.T(ctx, "validation.required"),
+		})})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	owed := eventstore.PayoutAmount(event.Status, participant.ParticipantAmount, participant.ParticipantWithheld, participant.ParticipantExpense, participant.ParticipantCompensation)
+	if form.Amount > eventstore.OutstandingPayout(owed, participant.ParticipantPaidOut) {
+		utils.SSEHub.PatchSignals(c, map[string]any{"payoutErrors": utils.WithErrors(payoutErrorFields, map[string]string{
+			"amount": ctxi18n.T(ctx, "payments.errors.exceeds_outstanding"),
+		})})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	var created db.ParticipantPayout
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		created, err = eventstore.CreateParticipantPayout(ctx, eventstore.CreateParticipantPayoutParams{
+			ID:        utils.GenerateID(utils.PrefixPayout),
+			GroupID:   groupID,
+			EventID:   eventID,
+			MemberID:  participant.ID,
+			Amount:    form.Amount,
+			PaidAt:    form.PaidAt,
+			Method:    form.Method,
+			Reference: form.Reference,
+		})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{
+			Action:   audit.ActionPayout,
+			Entity:   utils.EntityFilterParticipant,
+			EntityID: audit.ParticipantID(eventID, participant.ID),
+			Label:    event.Title + " – " + participant.Name,
+			After:    payoutValues(created),
+		})
+	})
+	if err != nil {
+		slog.Error("payout.create: failed to create payout", "event_id", eventID, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "payouts.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	publish(c, groupID, eventID, created.MemberID, created.PaidBy.String)
+	utils.InvalidateGroupCaches(groupID)
+	utils.Notify(c, ctxi18n.T(ctx, "payouts.notifications.created"))
+
+	if err := utils.SSEHub.Redirect(c, eventURL(groupID, eventID)); err != nil {
+		slog.Warn("payout.create: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// DestroyPayout removes a payout; the participant becomes unpaid again if
+// the rest no longer covers what they are owed.
+func DestroyPayout(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	eventID := c.Param("id")
+	if !utils.IsValidID(eventID, utils.PrefixEvent) {
+		slog.Info("payout.destroy: invalid event id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+	payoutID := c.Param("payoutId")
+	if !utils.IsValidID(payoutID, utils.PrefixPayout) {
+		slog.Info("payout.destroy: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("payout.destroy: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("payout.destroy: event not found", "event_id", eventID, "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+	row, err := eventstore.GetParticipantPayout(ctx, eventstore.GetParticipantPayoutParams{ID: payoutID, EventID: eventID, GroupID: groupID})
+	if err != nil {
+		slog.Info("payout.destroy: payout not found", "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	label := event.Title
+	if member, err := memberstore.GetMember(ctx, memberstore.GetMemberParams{ID: row.MemberID, GroupID: groupID}); err == nil {
+		label += " – " + member.Name
+	}
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := eventstore.DeleteParticipantPayout(ctx, eventstore.DeleteParticipantPayoutParams{ID: payoutID, EventID: eventID, MemberID: row.MemberID, GroupID: groupID})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{
+			Action:   audit.ActionPayoutDelete,
+			Entity:   utils.EntityFilterParticipant,
+			EntityID: audit.ParticipantID(eventID, row.MemberID),
+			Label:    label,
+			Before:   payoutValues(row),
+		})
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.NoContent(http.StatusNotFound)
+	}
+	if err != nil {
+		slog.Error("payout.destroy: failed to delete payout", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "payouts.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	publish(c, groupID, eventID, row.MemberID, row.PaidBy.String)
+	utils.InvalidateGroupCaches(groupID)
+	utils.Notify(c, ctxi18n.T(ctx, "payouts.notifications.deleted"))
+
+	if err := utils.SSEHub.Redirect(c, eventURL(groupID, eventID)); err != nil {
+		slog.Warn("payout.destroy: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,96 @@
+package payment
+
+import (
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+)
+
+type PaymentSectionProps struct {
+	GroupID string
+	EventID string
+	IsAdmin bool
+	// Currency is the event's currency; payments are kept in it.
+	Currency string
+	Income   int64
+	Received int64
+	Payments []db.EventPayment
+	// Members can be picked as having collected a payment for the band.
+	Members []db.Member
+}
+
+// CollectorName names the member who collected a payment, or "" when it
+// went to the band directly.
+func (p PaymentSectionProps) CollectorName(payment db.EventPayment) string {
+	if !payment.CollectedBy.Valid {
+		return ""
+	}
+	for _, member := range p.Members {
+		if member.ID == payment.CollectedBy.String {
+			return member.Name
+		}
+	}
+	return ""
+}
+
+// Outstanding is what is left to receive, never negative.
+func (p PaymentSectionProps) Outstanding() int64 {
+	return max(p.Income-p.Received, 0)
+}
+
+type PayoutSectionProps struct {
+	GroupID     string
+	EventID     string
+	EventStatus string
+	IsAdmin     bool
+	// Currency is the event's currency; payouts are kept in it.
+	Currency     string
+	Participants []eventstore.ListParticipantsByEventRow
+	Payouts      []db.ParticipantPayout
+	// Members names who paid a payout out of money they collected.
+	Members []db.Member
+}
+
+// Owed is what a participant is owed for the event.
+func (p PayoutSectionProps) Owed(row eventstore.ListParticipantsByEventRow) int64 {
+	return eventstore.PayoutAmount(p.EventStatus, row.ParticipantAmount, row.ParticipantWithheld, row.ParticipantExpense, row.ParticipantCompensation)
+}
+
+// Outstanding is what is left to pay a participant.
+func (p PayoutSectionProps) Outstanding(row eventstore.ListParticipantsByEventRow) int64 {
+	return eventstore.OutstandingPayout(p.Owed(row), row.ParticipantPaidOut)
+}
+
+// Unpaid lists the participants still owed something, for the form.
+func (p PayoutSectionProps) Unpaid() []eventstore.ListParticipantsByEventRow {
+	rows := make([]eventstore.ListParticipantsByEventRow, 0, len(p.Participants))
+	for _, row := range p.Participants {
+		if row.ParticipantPaid == 0 && p.Outstanding(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// MemberName names the participant a payout went to.
+func (p PayoutSectionProps) MemberName(memberID string) string {
+	for _, row := range p.Participants {
+		if row.ID == memberID {
+			return row.Name
+		}
+	}
+	return ""
+}
+
+// PayerName names the member who paid a payout out of money they collected,
+// or "" when the band paid it.
+func (p PayoutSectionProps) PayerName(payout db.ParticipantPayout) string {
+	if !payout.PaidBy.Valid {
+		return ""
+	}
+	for _, member := range p.Members {
+		if member.ID == payout.PaidBy.String {
+			return member.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package payment
+
+import "time"
+
+type paymentFormData struct {
+	Amount      int64  `json:"amount" validate:"required,gt=0"`
+	PaidAt      string `json:"paidAt" validate:"required"`
+	Method      string `json:"method" validate:"omitempty,oneof=bank_transfer cash card other"`
+	Reference   string `json:"reference" validate:"max=255"`
+	CollectedBy string `json:"collectedBy"`
+}
+
+// createParams uses its own signal names so the form can live on the event
+// page next to the event's formData.
+type createParams struct {
+	TabID       string          `json:"tab_id"`
+	PaymentForm paymentFormData `json:"paymentForm"`
+}
+
+type payoutFormData struct {
+	MemberID  string `json:"memberId" validate:"required"`
+	Amount    int64  `json:"amount" validate:"required,gt=0"`
+	PaidAt    string `json:"paidAt" validate:"required"`
+	Method    string `json:"method" validate:"omitempty,oneof=bank_transfer cash card other"`
+	Reference string `json:"reference" validate:"max=255"`
+}
+
+type createPayoutParams struct {
+	TabID      string         `json:"tab_id"`
+	PayoutForm payoutFormData `json:"payoutForm"`
+}
+
+type tabParams struct {
+	TabID string `json:"tab_id"`
+}
+
+var paymentErrorFields = []string{"amount", "paidAt", "method", "reference", "collectedBy"}
+
+var payoutErrorFields = []string{"memberId", "amount", "paidAt", "method", "reference"}
+
+// paymentSectionSignals prefills the form with the outstanding amount and
+// today's date, the usual case for the final payment.
+func paymentSectionSignals(outstanding int64) map[string]any {
+	return map[string]any{
+		"paymentForm": map[string]any{
+			"amount":      outstanding,
+			"paidAt":      time.Now().Format("2006-01-02"),
+			"method":      "",
+			"reference":   "",
+			"collectedBy": "",
+		},
+		"paymentErrors": map[string]any{
+			"amount":      "",
+			"paidAt":      "",
+			"method":      "",
+			"reference":   "",
+			"collectedBy": "",
+		},
+	}
+}
+
+// payoutSectionSignals starts the form empty: the amount depends on the
+// member picked.
+func payoutSectionSignals() map[string]any {
+	return map[string]any{
+		"payoutForm": map[string]any{
+			"memberId":  "",
+			"amount":    0,
+			"paidAt":    time.Now().Format("2006-01-02"),
+			"method":    "",
+			"reference": "",
+		},
+		"payoutErrors": map[string]any{
+			"memberId":  "",
+			"amount":    "",
+			"paidAt":    "",
+			"method":    "",
+			"reference": "",
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package payment
+
+import "bandcash/internal/utils"
+
+func PaymentsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "paid_at", MaxWRem: 10},
+		{Key: "amount", MaxWRem: 10},
+		{Key: "method", MaxWRem: 10},
+		{Key: "collected_by", MaxWRem: 12},
+		{Key: "reference"},
+	}, 4)
+}
+
+func PayoutsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "member", MaxWRem: 14},
+		{Key: "paid_at", MaxWRem: 10},
+		{Key: "amount", MaxWRem: 10},
+		{Key: "method", MaxWRem: 10},
+		{Key: "reference"},
+	}, 4)
+}
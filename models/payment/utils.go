@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"context"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
+
+func eventURL(groupID, eventID string) string {
+	return "/groups/" + groupID + "/events/" + eventID
+}
+
+// publish tells other tabs that money of an event moved: the group's pages,
+// the event's and those of the members involved.
+func publish(c echo.Context, groupID, eventID string, memberIDs ...string) {
+	topics := []string{utils.GroupTopic(groupID), utils.EventTopic(eventID)}
+	for _, memberID := range memberIDs {
+		if memberID != "" {
+			topics = append(topics, utils.MemberTopic(memberID))
+		}
+	}
+	utils.SSEHub.Publish(c, topics...)
+}
+
+func methodLabel(ctx context.Context, method string) string {
+	if method == "" {
+		return "-"
+	}
+	return ctxi18n.T(ctx, "payments.methods."+method)
+}
+
+// paymentValues is the audited part of a payment.
+func paymentValues(row db.EventPayment) map[string]any {
+	return map[string]any{
+		"amount":       row.Amount,
+		"paid_at":      row.PaidAt,
+		"method":       row.Method,
+		"reference":    row.Reference,
+		"collected_by": row.CollectedBy.String,
+	}
+}
+
+// payoutValues is the audited part of a payout.
+func payoutValues(row db.ParticipantPayout) map[string]any {
+	return map[string]any{
+		"amount":    row.Amount,
+		"paid_at":   row.PaidAt,
+		"method":    row.Method,
+		"reference": row.Reference,
+		"paid_by":   row.PaidBy.String,
+	}
+}
@@ -0,0 +1,201 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	"github.com/uptrace/bun"
+)
+
+func GetQuote(ctx context.Context, arg GetQuoteParams) (db.Quote, error) {
+	var row db.Quote
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
+
+// GetQuoteByEventID returns the quote an event was created from, if any.
+func GetQuoteByEventID(ctx context.Context, groupID, eventID string) (db.Quote, error) {
+	var row db.Quote
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("event_id = ?", eventID).Where("group_id = ?", groupID).Scan(ctx)
+	return row, err
+}
+
+func ListQuoteItems(ctx context.Context, quoteID string) ([]db.QuoteItem, error) {
+	rows := make([]db.QuoteItem, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("quote_id = ?", quoteID).
+		OrderExpr("position ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+func CreateQuote(ctx context.Context, arg CreateQuoteParams) (db.Quote, error) {
+	row := db.Quote{
+		ID:          arg.ID,
+		GroupID:     arg.GroupID,
+		ClientName:  arg.ClientName,
+		ClientEmail: arg.ClientEmail,
+		Title:       arg.Title,
+		Place:       arg.Place,
+		EventDate:   arg.EventDate,
+		EventTime:   arg.EventTime,
+		Notes:       arg.Notes,
+		ValidUntil:  arg.ValidUntil,
+		Status:      StatusDraft,
+		Total:       QuoteTotal(arg.Items),
+	}
+
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(&row).ExcludeColumn("created_at", "updated_at").Exec(ctx); err != nil {
+			return err
+		}
+		return replaceItemsTx(ctx, tx, arg.ID, arg.Items)
+	})
+	if err != nil {
+		return db.Quote{}, err
+	}
+	return GetQuote(ctx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+func UpdateQuote(ctx context.Context, arg UpdateQuoteParams) (db.Quote, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		current, err := getQuoteTx(ctx, tx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+		if err != nil {
+			return err
+		}
+		if !IsEditable(current.Status) {
+			return ErrQuoteLocked
+		}
+
+		_, err = tx.NewUpdate().Model((*db.Quote)(nil)).
+			Set("client_name = ?", arg.ClientName).
+			Set("client_email = ?", arg.ClientEmail).
+			Set("title = ?", arg.Title).
+			Set("place = ?", arg.Place).
+			Set("event_date = ?", arg.EventDate).
+			Set("event_time = ?", arg.EventTime).
+			Set("notes = ?", arg.Notes).
+			Set("valid_until = ?", arg.ValidUntil).
+			Set("total = ?", QuoteTotal(arg.Items)).
+			Where("id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return replaceItemsTx(ctx, tx, arg.ID, arg.Items)
+	})
+	if err != nil {
+		return db.Quote{}, err
+	}
+	return GetQuote(ctx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+// SetQuoteStatus moves a quote along the pipeline. Use AcceptQuote for the
+// accepted status.
+func SetQuoteStatus(ctx context.Context, arg SetQuoteStatusParams) (db.Quote, error) {
+	if arg.Status == StatusAccepted {
+		return db.Quote{}, ErrInvalidTransition
+	}
+
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		current, err := getQuoteTx(ctx, tx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+		if err != nil {
+			return err
+		}
+		if !CanTransition(current.Status, arg.Status) {
+			return ErrInvalidTransition
+		}
+
+		_, err = tx.NewUpdate().Model((*db.Quote)(nil)).
+			Set("status = ?", arg.Status).
+			Where("id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return db.Quote{}, err
+	}
+	return GetQuote(ctx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+// AcceptQuote marks a sent quote as accepted and creates the matching event
+// in the same transaction. The quote keeps a reference to the event.
+func AcceptQuote(ctx context.Context, arg AcceptQuoteParams) (db.Quote, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		current, err := getQuoteTx(ctx, tx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+		if err != nil {
+			return err
+		}
+		if !CanTransition(current.Status, StatusAccepted) {
+			return ErrInvalidTransition
+		}
+
+		_, err = eventstore.CreateEventTx(ctx, tx, eventstore.CreateEventParams{
+			ID:          arg.EventID,
+			GroupID:     arg.GroupID,
+			Title:       current.Title,
+			Date:        current.EventDate,
+			EventTime:   current.EventTime,
+			Place:       current.Place,
+			Description: current.Notes,
+			Amount:      current.Total,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().Model((*db.Quote)(nil)).
+			Set("status = ?", StatusAccepted).
+			Set("event_id = ?", arg.EventID).
+			Where("id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return db.Quote{}, err
+	}
+	return GetQuote(ctx, GetQuoteParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+// DeleteQuote removes the quote and its line items. An event created from it
+// is kept.
+func DeleteQuote(ctx context.Context, arg DeleteQuoteParams) error {
+	_, err := db.Conn(ctx).NewDelete().Model((*db.Quote)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
+	return err
+}
+
+func getQuoteTx(ctx context.Context, tx bun.Tx, arg GetQuoteParams) (db.Quote, error) {
+	var row db.Quote
+	err := tx.NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
+
+func replaceItemsTx(ctx context.Context, tx bun.Tx, quoteID string, items []QuoteItemParams) error {
+	_, err := tx.NewDelete().
+		TableExpr("quote_items").
+		Where("quote_id = ?", quoteID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		row := db.QuoteItem{
+			QuoteID:     quoteID,
+			Position:    int64(i + 1),
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		}
+		if _, err := tx.NewInsert().Model(&row).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package data
+
+import "errors"
+
+const (
+	StatusDraft    = "draft"
+	StatusSent     = "sent"
+	StatusAccepted = "accepted"
+	StatusRejected = "rejected"
+	StatusInvoiced = "invoiced"
+)
+
+// Statuses lists the pipeline in order.
+var Statuses = []string{StatusDraft, StatusSent, StatusAccepted, StatusRejected, StatusInvoiced}
+
+var (
+	ErrInvalidTransition = errors.New("quote: invalid status transition")
+	ErrQuoteLocked       = errors.New("quote: quote can no longer be edited")
+)
+
+// transitions holds the allowed next statuses. Accepted is reached through
+// AcceptQuote so the event is created in the same transaction.
+var transitions = map[string][]string{
+	StatusDraft:    {StatusSent},
+	StatusSent:     {StatusAccepted, StatusRejected},
+	StatusAccepted: {StatusInvoiced},
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether line items and details can still change. Once a
+// quote is accepted the event is the source of truth.
+func IsEditable(status string) bool {
+	return status == StatusDraft || status == StatusSent
+}
+
+// QuoteTotal sums quantity times unit price over the line items.
+func QuoteTotal(items []QuoteItemParams) int64 {
+	var total int64
+	for _, item := range items {
+		total += item.Quantity * item.UnitPrice
+	}
+	return total
+}
@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"strings"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+type QuoteTableFilter struct {
+	GroupID string
+	Search  string
+	Year    string
+	From    string
+	To      string
+	Status  string
+}
+
+type QuoteTableListParams struct {
+	QuoteTableFilter
+	Sort   string
+	Dir    string
+	Limit  int
+	Offset int
+}
+
+func CountQuotesTable(ctx context.Context, filter QuoteTableFilter) (int64, error) {
+	q := db.Conn(ctx).NewSelect().TableExpr("quotes")
+	q = applyQuoteTableFilters(q, filter)
+	n, err := q.Count(ctx)
+	return int64(n), err
+}
+
+func ListQuotesTable(ctx context.Context, params QuoteTableListParams) ([]db.Quote, error) {
+	rows := make([]db.Quote, 0)
+	q := db.Conn(ctx).NewSelect().Model(&rows)
+	q = applyQuoteTableFilters(q, params.QuoteTableFilter)
+	q = orderQuotes(q, params.Sort, params.Dir)
+	if params.Limit > 0 {
+		q = q.Limit(params.Limit)
+	}
+	if params.Offset > 0 {
+		q = q.Offset(params.Offset)
+	}
+	err := q.Scan(ctx)
+	return rows, err
+}
+
+func applyQuoteTableFilters(q *bun.SelectQuery, filter QuoteTableFilter) *bun.SelectQuery {
+	q = q.Where("group_id = ?", filter.GroupID)
+	q = applyStatus(q, filter.Status)
+	q = applySearch(q, filter.Search, func(sq *bun.SelectQuery, search string) *bun.SelectQuery {
+		like := "%" + search + "%"
+		return sq.WhereGroup(" AND ", func(qq *bun.SelectQuery) *bun.SelectQuery {
+			return qq.Where("title LIKE ?", like).WhereOr("client_name LIKE ?", like).WhereOr("place LIKE ?", like)
+		})
+	})
+	return applyDateRangeOrYear(q, filter.From, filter.To, filter.Year, "event_date")
+}
+
+func orderQuotes(q *bun.SelectQuery, sort, dir string) *bun.SelectQuery {
+	d := normalizeDir(dir)
+	switch sort {
+	case "event_date":
+		q = q.OrderExpr("event_date " + d)
+	case "title":
+		q = q.OrderExpr("title " + d)
+	case "client_name":
+		q = q.OrderExpr("client_name " + d)
+	case "valid_until":
+		q = q.OrderExpr("valid_until " + d)
+	case "total":
+		q = q.OrderExpr("total " + d)
+	case "status":
+		q = q.OrderExpr("status " + d)
+	default:
+		q = q.OrderExpr("event_date DESC")
+	}
+	return q.OrderExpr("created_at DESC")
+}
+
+func applyStatus(q *bun.SelectQuery, status string) *bun.SelectQuery {
+	status = strings.TrimSpace(status)
+	for _, known := range Statuses {
+		if status == known {
+			return q.Where("status = ?", status)
+		}
+	}
+	return q
+}
+
+func applySearch(q *bun.SelectQuery, search string, fn func(*bun.SelectQuery, string) *bun.SelectQuery) *bun.SelectQuery {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return q
+	}
+	return fn(q, search)
+}
+
+func applyDateRangeOrYear(q *bun.SelectQuery, from, to, year, columnExpr string) *bun.SelectQuery {
+	from = strings.TrimSpace(from)
+	to = strings.TrimSpace(to)
+	year = strings.TrimSpace(year)
+	if from != "" && to != "" {
+		return q.Where(columnExpr+" >= ?", from).Where(columnExpr+" <= ?", to)
+	}
+	if year != "" {
+		return q.Where("substr("+columnExpr+", 1, 4) = ?", year)
+	}
+	return q
+}
+
+func normalizeDir(dir string) string {
+	if strings.EqualFold(strings.TrimSpace(dir), "asc") {
+		return "ASC"
+	}
+	return "DESC"
+}
@@ -0,0 +1,60 @@
+package data
+
+type GetQuoteParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
+
+type QuoteItemParams struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unit_price"`
+}
+
+type CreateQuoteParams struct {
+	ID          string            `json:"id"`
+	GroupID     string            `json:"group_id"`
+	ClientName  string            `json:"client_name"`
+	ClientEmail string            `json:"client_email"`
+	Title       string            `json:"title"`
+	Place       string            `json:"place"`
+	EventDate   string            `json:"event_date"`
+	EventTime   string            `json:"event_time"`
+	Notes       string            `json:"notes"`
+	ValidUntil  string            `json:"valid_until"`
+	Items       []QuoteItemParams `json:"items"`
+}
+
+// UpdateQuoteParams replaces the quote fields and its line items. Only draft
+// and sent quotes can be updated.
+type UpdateQuoteParams struct {
+	ID          string            `json:"id"`
+	GroupID     string            `json:"group_id"`
+	ClientName  string            `json:"client_name"`
+	ClientEmail string            `json:"client_email"`
+	Title       string            `json:"title"`
+	Place       string            `json:"place"`
+	EventDate   string            `json:"event_date"`
+	EventTime   string            `json:"event_time"`
+	Notes       string            `json:"notes"`
+	ValidUntil  string            `json:"valid_until"`
+	Items       []QuoteItemParams `json:"items"`
+}
+
+type SetQuoteStatusParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+	Status  string `json:"status"`
+}
+
+// AcceptQuoteParams carries the ID of the event created from the quote.
+type AcceptQuoteParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+	EventID string `json:"event_id"`
+}
+
+type DeleteQuoteParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
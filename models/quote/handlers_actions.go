@@ -0,0 +1,270 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
+	quotestore "bandcash/models/quote/data"
+)
+
+func Create(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	var signals quoteFormParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("quote.create: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := normalizeForm(signals.FormData)
+	items, errs := validateForm(ctx, form)
+	if errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(quoteErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	quote, err := quotestore.CreateQuote(ctx, quotestore.CreateQuoteParams{
+		ID:          utils.GenerateID(utils.PrefixQuote),
+		GroupID:     groupID,
+		ClientName:  form.ClientName,
+		ClientEmail: form.ClientEmail,
+		Title:       form.Title,
+		Place:       form.Place,
+		EventDate:   form.EventDate,
+		EventTime:   form.EventTime,
+		Notes:       form.Notes,
+		ValidUntil:  form.ValidUntil,
+		Items:       items,
+	})
+	if err != nil {
+		slog.Error("quote.create: failed to create quote", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.created"))
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/quotes/"+quote.ID); err != nil {
+		slog.Warn("quote.create: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Update(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixQuote) {
+		slog.Info("quote.update: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals quoteFormParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("quote.update: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := normalizeForm(signals.FormData)
+	items, errs := validateForm(ctx, form)
+	if errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(quoteErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	_, err := quotestore.UpdateQuote(ctx, quotestore.UpdateQuoteParams{
+		ID:          id,
+		GroupID:     groupID,
+		ClientName:  form.ClientName,
+		ClientEmail: form.ClientEmail,
+		Title:       form.Title,
+		Place:       form.Place,
+		EventDate:   form.EventDate,
+		EventTime:   form.EventTime,
+		Notes:       form.Notes,
+		ValidUntil:  form.ValidUntil,
+		Items:       items,
+	})
+	if errors.Is(err, quotestore.ErrQuoteLocked) {
+		slog.Info("quote.update: quote is locked", "quote_id", id)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.locked"))
+		return c.NoContent(http.StatusConflict)
+	}
+	if err != nil {
+		slog.Error("quote.update: failed to update quote", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.updated"))
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/quotes/"+id); err != nil {
+		slog.Warn("quote.update: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Send(c echo.Context) error {
+	return setStatus(c, quotestore.StatusSent)
+}
+
+func Reject(c echo.Context) error {
+	return setStatus(c, quotestore.StatusRejected)
+}
+
+func MarkInvoiced(c echo.Context) error {
+	return setStatus(c, quotestore.StatusInvoiced)
+}
+
+func setStatus(c echo.Context, status string) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixQuote) {
+		slog.Info("quote.status: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("quote.status: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	_, err := quotestore.SetQuoteStatus(ctx, quotestore.SetQuoteStatusParams{ID: id, GroupID: groupID, Status: status})
+	if errors.Is(err, quotestore.ErrInvalidTransition) {
+		slog.Info("quote.status: invalid transition", "quote_id", id, "status", status)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.invalid_transition"))
+		return c.NoContent(http.StatusConflict)
+	}
+	if err != nil {
+		slog.Error("quote.status: failed to set status", "quote_id", id, "status", status, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.status_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.status_changed", statusLabel(ctx, status)))
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/quotes/"+id); err != nil {
+		slog.Warn("quote.status: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// Accept marks the quote as accepted and creates the matching event.
+func Accept(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixQuote) {
+		slog.Info("quote.accept: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("quote.accept: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var quote db.Quote
+	err := db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		var err error
+		quote, err = quotestore.AcceptQuote(ctx, quotestore.AcceptQuoteParams{
+			ID:      id,
+			GroupID: groupID,
+			EventID: utils.GenerateID(utils.PrefixEvent),
+		})
+		if err != nil {
+			return err
+		}
+		event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: quote.EventID.String, GroupID: groupID})
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, audit.Change{Action: audit.ActionCreate, Entity: utils.EntityFilterEvent, EntityID: event.ID, After: event})
+	})
+	if errors.Is(err, quotestore.ErrInvalidTransition) {
+		slog.Info("quote.accept: invalid transition", "quote_id", id)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.invalid_transition"))
+		return c.NoContent(http.StatusConflict)
+	}
+	if err != nil {
+		slog.Error("quote.accept: failed to accept quote", "quote_id", id, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.accept_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.accepted"))
+	utils.InvalidateGroupCaches(groupID)
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/events/"+quote.EventID.String); err != nil {
+		slog.Warn("quote.accept: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Destroy(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixQuote) {
+		slog.Info("quote.destroy: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("quote.destroy: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	if err := quotestore.DeleteQuote(ctx, quotestore.DeleteQuoteParams{ID: id, GroupID: groupID}); err != nil {
+		slog.Error("quote.destroy: failed to delete quote", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "quotes.notifications.deleted"))
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/quotes"); err != nil {
+		slog.Warn("quote.destroy: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
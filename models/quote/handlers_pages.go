@@ -0,0 +1,91 @@
+package quote
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
+	quotestore "bandcash/models/quote/data"
+)
+
+func IndexPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+	query := utils.ParseTableQuery(c, staticTableQueryable{spec: TableQuerySpec()})
+
+	data, err := GetIndexData(c.Request().Context(), groupID, query)
+	if err != nil {
+		slog.Error("quote.index: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = quoteIndexSignals(data.Query)
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, QuoteIndexPage(data))
+}
+
+func ShowPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixQuote) {
+		slog.Info("quote.show: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	data, err := GetShowData(c.Request().Context(), groupID, id)
+	if err != nil {
+		slog.Error("quote.show: failed to get data", "group_id", groupID, "quote_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = quoteShowSignals()
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, QuoteShowPage(data))
+}
+
+func NewQuotePage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	data, err := GetFormData(c.Request().Context(), groupID, "")
+	if err != nil {
+		slog.Error("quote.new_page: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, QuoteFormPage(data))
+}
+
+func EditQuotePage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixQuote) {
+		slog.Info("quote.edit_page: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	data, err := GetFormData(c.Request().Context(), groupID, id)
+	if err != nil {
+		slog.Error("quote.edit_page: failed to get data", "group_id", groupID, "quote_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if !quotestore.IsEditable(data.Quote.Status) {
+		return c.Redirect(http.StatusFound, "/groups/"+groupID+"/quotes/"+id)
+	}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, QuoteFormPage(data))
+}
@@ -0,0 +1,156 @@
+package quote
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	groupstore "bandcash/models/group/data"
+	quotestore "bandcash/models/quote/data"
+)
+
+func TableQuerySpec() utils.TableQuerySpec {
+	return utils.StandardTableQuerySpec(utils.StandardTableQuerySpecParams{
+		DefaultSort:  "event_date",
+		DefaultDir:   "desc",
+		AllowedSorts: []string{"event_date", "title", "client_name", "valid_until", "total", "status"},
+	})
+}
+
+func GetIndexData(ctx context.Context, groupID string, query utils.TableQuery) (QuotesData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return QuotesData{}, err
+	}
+
+	filters := quotestore.QuoteTableFilter{
+		GroupID: groupID,
+		Search:  query.Search,
+		Year:    query.Year,
+		From:    query.From,
+		To:      query.To,
+		Status:  query.Status,
+	}
+
+	totalItems, err := quotestore.CountQuotesTable(ctx, filters)
+	if err != nil {
+		return QuotesData{}, err
+	}
+	query = utils.ClampPage(query, totalItems)
+
+	quotes, err := quotestore.ListQuotesTable(ctx, quotestore.QuoteTableListParams{
+		QuoteTableFilter: filters,
+		Sort:             query.Sort,
+		Dir:              query.Dir,
+		Limit:            query.PageSize,
+		Offset:           int(query.Offset()),
+	})
+	if err != nil {
+		return QuotesData{}, err
+	}
+
+	return QuotesData{
+		Title:       ctxi18n.T(ctx, "quotes.page_title"),
+		Quotes:      quotes,
+		RecentYears: utils.RecentYears(3),
+		Query:       query,
+		Pager:       utils.BuildTablePagination(totalItems, query),
+		Table:       QuotesIndexTableLayout(),
+		GroupID:     groupID,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "quotes.title")},
+		},
+	}, nil
+}
+
+func GetShowData(ctx context.Context, groupID, quoteID string) (QuoteData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return QuoteData{}, err
+	}
+
+	quote, err := quotestore.GetQuote(ctx, quotestore.GetQuoteParams{ID: quoteID, GroupID: groupID})
+	if err != nil {
+		return QuoteData{}, err
+	}
+
+	items, err := quotestore.ListQuoteItems(ctx, quote.ID)
+	if err != nil {
+		return QuoteData{}, err
+	}
+
+	data := QuoteData{
+		Title:      "bandcash - " + quote.Title,
+		Quote:      &quote,
+		Items:      items,
+		ItemsTable: QuoteItemsTableLayout(),
+		Expired:    isExpired(quote, time.Now()),
+		GroupID:    groupID,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "quotes.title"), Href: "/groups/" + groupID + "/quotes"},
+			{Label: quote.Title},
+		},
+	}
+
+	if quote.EventID.Valid {
+		event, err := eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: quote.EventID.String, GroupID: groupID})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return QuoteData{}, err
+		}
+		if err == nil {
+			data.Event = &event
+		}
+	}
+	return data, nil
+}
+
+// GetFormData loads the new page when quoteID is empty and the edit page
+// otherwise.
+func GetFormData(ctx context.Context, groupID, quoteID string) (QuoteFormPageData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return QuoteFormPageData{}, err
+	}
+
+	data := QuoteFormPageData{
+		Title:   ctxi18n.T(ctx, "quotes.page_title"),
+		GroupID: groupID,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "quotes.title"), Href: "/groups/" + groupID + "/quotes"},
+		},
+	}
+
+	if quoteID == "" {
+		data.Breadcrumbs = append(data.Breadcrumbs, utils.Crumb{Label: ctxi18n.T(ctx, "quotes.add")})
+		data.Signals = quoteFormSignals(nil, nil)
+		return data, nil
+	}
+
+	quote, err := quotestore.GetQuote(ctx, quotestore.GetQuoteParams{ID: quoteID, GroupID: groupID})
+	if err != nil {
+		return QuoteFormPageData{}, err
+	}
+	items, err := quotestore.ListQuoteItems(ctx, quote.ID)
+	if err != nil {
+		return QuoteFormPageData{}, err
+	}
+
+	data.Quote = &quote
+	data.Breadcrumbs = append(data.Breadcrumbs,
+		utils.Crumb{Label: quote.Title, Href: "/groups/" + groupID + "/quotes/" + quote.ID},
+		utils.Crumb{Label: ctxi18n.T(ctx, "quotes.edit")},
+	)
+	data.Signals = quoteFormSignals(&quote, items)
+	return data, nil
+}
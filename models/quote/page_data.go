@@ -0,0 +1,48 @@
+package quote
+
+import (
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
+
+type QuotesData struct {
+	Title           string
+	Quotes          []db.Quote
+	RecentYears     []int
+	Query           utils.TableQuery
+	Pager           utils.TablePagination
+	Table           utils.TableLayout
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
+
+type QuoteData struct {
+	Title           string
+	Quote           *db.Quote
+	Items           []db.QuoteItem
+	ItemsTable      utils.TableLayout
+	Event           *db.Event
+	Expired         bool
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
+
+// QuoteFormPageData backs both the new and the edit page. Quote is nil on the
+// new page.
+type QuoteFormPageData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	GroupID         string
+	Quote           *db.Quote
+	Signals         map[string]any
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
@@ -0,0 +1,104 @@
+package quote
+
+import (
+	"strconv"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+)
+
+// maxItems is the number of line item rows the form offers. Rows without a
+// description are dropped on save.
+const maxItems = 20
+
+// quoteItemData rows are checked by itemParams; the validator does not see
+// them.
+type quoteItemData struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unitPrice"`
+}
+
+type quoteFormData struct {
+	ClientName  string                   `json:"clientName" validate:"required,min=1,max=255"`
+	ClientEmail string                   `json:"clientEmail" validate:"omitempty,email,max=255"`
+	Title       string                   `json:"title" validate:"required,min=1,max=255"`
+	Place       string                   `json:"place" validate:"max=255"`
+	EventDate   string                   `json:"eventDate" validate:"required"`
+	EventTime   string                   `json:"eventTime" validate:"required"`
+	ValidUntil  string                   `json:"validUntil" validate:"required"`
+	Notes       string                   `json:"notes" validate:"max=1000"`
+	ItemCount   int                      `json:"itemCount"`
+	Items       map[string]quoteItemData `json:"items"`
+}
+
+type quoteFormParams struct {
+	TabID    string        `json:"tab_id"`
+	FormData quoteFormData `json:"formData"`
+}
+
+type tabParams struct {
+	TabID string `json:"tab_id"`
+}
+
+var quoteErrorFields = []string{"clientName", "clientEmail", "title", "place", "eventDate", "eventTime", "validUntil", "notes", "items"}
+
+func quoteIndexSignals(query utils.TableQuery) map[string]any {
+	return map[string]any{
+		"tableQuery":      utils.TableQuerySignals(query),
+		"dateRange":       map[string]any{"from": query.From, "to": query.To},
+		"showCustomRange": query.DateMode == "custom" || query.From != "" || query.To != "",
+		"_fetching":       false,
+	}
+}
+
+// quoteFormSignals fills maxItems rows so the form can show more of them
+// without a round trip; itemCount says how many are visible.
+func quoteFormSignals(quote *db.Quote, items []db.QuoteItem) map[string]any {
+	itemSignals := make(map[string]any, maxItems)
+	for i := range maxItems {
+		row := map[string]any{"description": "", "quantity": 1, "unitPrice": 0}
+		if i < len(items) {
+			row["description"] = items[i].Description
+			row["quantity"] = items[i].Quantity
+			row["unitPrice"] = items[i].UnitPrice
+		}
+		itemSignals[strconv.Itoa(i)] = row
+	}
+
+	formData := map[string]any{
+		"clientName":  "",
+		"clientEmail": "",
+		"title":       "",
+		"place":       "",
+		"eventDate":   "",
+		"eventTime":   "",
+		"validUntil":  "",
+		"notes":       "",
+		"itemCount":   max(len(items), 1),
+		"items":       itemSignals,
+	}
+	if quote != nil {
+		formData["clientName"] = quote.ClientName
+		formData["clientEmail"] = quote.ClientEmail
+		formData["title"] = quote.Title
+		formData["place"] = quote.Place
+		formData["eventDate"] = quote.EventDate
+		formData["eventTime"] = quote.EventTime
+		formData["validUntil"] = quote.ValidUntil
+		formData["notes"] = quote.Notes
+	}
+
+	return map[string]any{
+		"formData":  formData,
+		"errors":    utils.GetEmptyErrors(quoteErrorFields),
+		"_fetching": false,
+	}
+}
+
+func quoteShowSignals() map[string]any {
+	return map[string]any{
+		"mode":      "single",
+		"_fetching": false,
+	}
+}
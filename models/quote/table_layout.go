@@ -0,0 +1,23 @@
+package quote
+
+import "bandcash/internal/utils"
+
+func QuotesIndexTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "title"},
+		{Key: "client_name"},
+		{Key: "event_date", MaxWRem: 10},
+		{Key: "valid_until", MaxWRem: 10},
+		{Key: "total", MaxWRem: 10},
+		{Key: "status", MaxWRem: 9, WRem: 9},
+	}, 0)
+}
+
+func QuoteItemsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "description"},
+		{Key: "quantity", MaxWRem: 7},
+		{Key: "unit_price", MaxWRem: 10},
+		{Key: "amount", MaxWRem: 10},
+	}, 0)
+}
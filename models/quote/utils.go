@@ -0,0 +1,105 @@
+package quote
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	quotestore "bandcash/models/quote/data"
+)
+
+const dateLayout = "2006-01-02"
+
+type staticTableQueryable struct {
+	spec utils.TableQuerySpec
+}
+
+func (s staticTableQueryable) TableQuerySpec() utils.TableQuerySpec {
+	return s.spec
+}
+
+func statusLabel(ctx context.Context, status string) string {
+	return ctxi18n.T(ctx, "quotes.status."+status)
+}
+
+func statusFilterLabel(ctx context.Context, status string) string {
+	if status == utils.StatusFilterAll {
+		return ctxi18n.T(ctx, "table.all")
+	}
+	return statusLabel(ctx, status)
+}
+
+// isExpired reports whether a sent quote is past its validity date.
+func isExpired(quote db.Quote, now time.Time) bool {
+	return quote.Status == quotestore.StatusSent && quote.ValidUntil != "" && quote.ValidUntil < now.Format(dateLayout)
+}
+
+func normalizeForm(form quoteFormData) quoteFormData {
+	form.ClientName = strings.TrimSpace(form.ClientName)
+	form.ClientEmail = strings.TrimSpace(form.ClientEmail)
+	form.Title = strings.TrimSpace(form.Title)
+	form.Place = strings.TrimSpace(form.Place)
+	form.EventDate = strings.TrimSpace(form.EventDate)
+	form.EventTime = strings.TrimSpace(form.EventTime)
+	form.ValidUntil = strings.TrimSpace(form.ValidUntil)
+	form.Notes = strings.TrimSpace(form.Notes)
+	return form
+}
+
+// itemParams keeps the visible rows that have a description, in row order.
+// It returns a field error for the items list when a row is invalid or no row
+// is left.
+func itemParams(ctx context.Context, form quoteFormData) ([]quotestore.QuoteItemParams, map[string]string) {
+	keys := make([]int, 0, len(form.Items))
+	for key := range form.Items {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= min(form.ItemCount, maxItems) {
+			continue
+		}
+		keys = append(keys, index)
+	}
+	sort.Ints(keys)
+
+	items := make([]quotestore.QuoteItemParams, 0, len(keys))
+	for _, index := range keys {
+		item := form.Items[strconv.Itoa(index)]
+		description := strings.TrimSpace(item.Description)
+		if description == "" {
+			continue
+		}
+		if len(description) > 255 {
+			return nil, map[string]string{"items": ctxi18n.T(ctx, "validation.max", "255")}
+		}
+		if item.Quantity <= 0 || item.UnitPrice < 0 {
+			return nil, map[string]string{"items": ctxi18n.T(ctx, "quotes.validation.invalid_item")}
+		}
+		items = append(items, quotestore.QuoteItemParams{
+			Description: description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+	if len(items) == 0 {
+		return nil, map[string]string{"items": ctxi18n.T(ctx, "quotes.validation.no_items")}
+	}
+	return items, nil
+}
+
+func validateForm(ctx context.Context, form quoteFormData) ([]quotestore.QuoteItemParams, map[string]string) {
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		return nil, errs
+	}
+	if _, err := time.Parse(dateLayout, form.EventDate); err != nil {
+		return nil, map[string]string{"eventDate": ctxi18n.T(ctx, "validation.required")}
+	}
+	if _, err := time.Parse(dateLayout, form.ValidUntil); err != nil {
+		return nil, map[string]string{"validUntil": ctxi18n.T(ctx, "validation.required")}
+	}
+	return itemParams(ctx, form)
+}
@@ -0,0 +1,66 @@
+package quote
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	quotestore "bandcash/models/quote/data"
+)
+
+func TestItemParams(t *testing.T) {
+	t.Parallel()
+
+	form := quoteFormData{
+		ItemCount: 3,
+		Items: map[string]quoteItemData{
+			"2": {Description: "Travel", Quantity: 1, UnitPrice: 100},
+			"0": {Description: " Set ", Quantity: 2, UnitPrice: 500},
+			"1": {Description: "  ", Quantity: 1, UnitPrice: 0},
+			"3": {Description: "Hidden row", Quantity: 1, UnitPrice: 999},
+		},
+	}
+
+	items, errs := itemParams(context.Background(), form)
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want := []quotestore.QuoteItemParams{
+		{Description: "Set", Quantity: 2, UnitPrice: 500},
+		{Description: "Travel", Quantity: 1, UnitPrice: 100},
+	}
+	if !slices.Equal(items, want) {
+		t.Fatalf("items = %v, want %v", items, want)
+	}
+	if got := quotestore.QuoteTotal(items); got != 1100 {
+		t.Fatalf("total = %d, want 1100", got)
+	}
+
+	for name, form := range map[string]quoteFormData{
+		"no rows":       {ItemCount: 1, Items: map[string]quoteItemData{"0": {Quantity: 1}}},
+		"zero quantity": {ItemCount: 1, Items: map[string]quoteItemData{"0": {Description: "Set", Quantity: 0, UnitPrice: 5}}},
+	} {
+		if _, errs := itemParams(context.Background(), form); errs["items"] == "" {
+			t.Errorf("%s: expected an items error", name)
+		}
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+
+	allowed := [][2]string{
+		{quotestore.StatusDraft, quotestore.StatusSent},
+		{quotestore.StatusSent, quotestore.StatusAccepted},
+		{quotestore.StatusSent, quotestore.StatusRejected},
+		{quotestore.StatusAccepted, quotestore.StatusInvoiced},
+	}
+	for _, from := range quotestore.Statuses {
+		for _, to := range quotestore.Statuses {
+			want := slices.Contains(allowed, [2]string{from, to})
+			if got := quotestore.CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
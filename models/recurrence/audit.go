@@ -0,0 +1,121 @@
+package recurrence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	eventstore "bandcash/models/event/data"
+	expensestore "bandcash/models/expense/data"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+// seriesRow is a generated event or expense, kept as its db row.
+type seriesRow struct {
+	id  string
+	row any
+}
+
+// SeriesChanges runs fn and returns the changes it made to the rows generated
+// from a template: rows it created, edited or deleted. Run it in the same
+// transaction as fn. Rows that only lost their link to a deleted template are
+// left out.
+func SeriesChanges(ctx context.Context, kind, recurrenceID, groupID string, fn func() error) ([]audit.Change, error) {
+	before, err := listSeriesRows(ctx, kind, recurrenceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(); err != nil {
+		return nil, err
+	}
+	after, err := listSeriesRows(ctx, kind, recurrenceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := utils.EntityFilterEvent
+	if kind == recurrencestore.KindExpense {
+		entity = utils.EntityFilterExpense
+	}
+
+	gone := make(map[string]any, len(before))
+	for _, row := range before {
+		gone[row.id] = row.row
+	}
+	changes := make([]audit.Change, 0, len(after))
+	for _, row := range after {
+		prev, ok := gone[row.id]
+		if !ok {
+			changes = append(changes, audit.Change{Action: audit.ActionCreate, Entity: entity, EntityID: row.id, After: row.row})
+			continue
+		}
+		delete(gone, row.id)
+		changes = append(changes, audit.Change{Action: audit.ActionUpdate, Entity: entity, EntityID: row.id, Before: prev, After: row.row})
+	}
+	for _, row := range before {
+		if _, ok := gone[row.id]; !ok {
+			continue
+		}
+		exists, err := seriesRowExists(ctx, kind, row.id, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			changes = append(changes, audit.Change{Action: audit.ActionDelete, Entity: entity, EntityID: row.id, Before: row.row})
+		}
+	}
+	return changes, nil
+}
+
+// generateSeries creates the due rows of rec and returns how many it created
+// together with their changes.
+func generateSeries(ctx context.Context, rec db.Recurrence) (int, []audit.Change, error) {
+	created := 0
+	changes, err := SeriesChanges(ctx, rec.Kind, rec.ID, rec.GroupID, func() error {
+		var err error
+		created, err = GenerateRecurrence(ctx, rec, generationHorizon(time.Now()))
+		return err
+	})
+	return created, changes, err
+}
+
+func listSeriesRows(ctx context.Context, kind, recurrenceID, groupID string) ([]seriesRow, error) {
+	if kind == recurrencestore.KindExpense {
+		expenses, err := recurrencestore.ListSeriesExpenses(ctx, recurrenceID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]seriesRow, 0, len(expenses))
+		for _, expense := range expenses {
+			rows = append(rows, seriesRow{id: expense.ID, row: expense})
+		}
+		return rows, nil
+	}
+
+	events, err := recurrencestore.ListSeriesEvents(ctx, recurrenceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]seriesRow, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, seriesRow{id: event.ID, row: event})
+	}
+	return rows, nil
+}
+
+func seriesRowExists(ctx context.Context, kind, id, groupID string) (bool, error) {
+	var err error
+	if kind == recurrencestore.KindExpense {
+		_, err = expensestore.GetExpense(ctx, expensestore.GetExpenseParams{ID: id, GroupID: groupID})
+	} else {
+		_, err = eventstore.GetEvent(ctx, eventstore.GetEventParams{ID: id, GroupID: groupID})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,75 @@
+package recurrence
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bandcash/internal/db"
+	"bandcash/models/audit"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+func TestSeriesChanges(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "recurrence_test.sqlite")
+	if err := db.Init(dbPath); err != nil {
+		t.Fatalf("db.Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
+	}
+	ctx := context.Background()
+
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.BunDB.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	exec(`INSERT INTO users (id, email) VALUES ('usr_1', 'a@example.com')`)
+	exec(`INSERT INTO groups (id, name, admin_user_id) VALUES ('grp_1', 'Band', 'usr_1')`)
+	exec(`INSERT INTO recurrences (id, group_id, kind, title, amount, freq, start_date, count) VALUES ('rec_1', 'grp_1', 'event', 'Gig', 100, 'weekly', '2026-05-01', 3)`)
+	rec, err := recurrencestore.GetRecurrence(ctx, recurrencestore.GetRecurrenceParams{ID: "rec_1", GroupID: "grp_1"})
+	if err != nil {
+		t.Fatalf("GetRecurrence failed: %v", err)
+	}
+
+	created := 0
+	changes, err := SeriesChanges(ctx, rec.Kind, rec.ID, rec.GroupID, func() error {
+		var err error
+		created, err = GenerateRecurrence(ctx, rec, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("generating failed: %v", err)
+	}
+	if created != 3 || len(changes) != 3 {
+		t.Fatalf("generated %d rows with %d changes; want 3 and 3", created, len(changes))
+	}
+	for _, change := range changes {
+		if change.Action != audit.ActionCreate || change.Before != nil || change.After == nil {
+			t.Fatalf("generation change = %+v; want a creation", change)
+		}
+	}
+	first := changes[0].EntityID
+	exec(`UPDATE events SET paid = 1 WHERE id = ?`, first)
+
+	changes, err = SeriesChanges(ctx, rec.Kind, rec.ID, rec.GroupID, func() error {
+		return recurrencestore.DeleteRecurrence(ctx, recurrencestore.DeleteRecurrenceParams{ID: rec.ID, GroupID: rec.GroupID, FromDate: "2026-05-01"})
+	})
+	if err != nil {
+		t.Fatalf("deleting failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("delete logged %d changes; want the 2 unpaid rows", len(changes))
+	}
+	for _, change := range changes {
+		if change.Action != audit.ActionDelete || change.EntityID == first {
+			t.Fatalf("delete change = %s %s; want deletions of unpaid rows", change.Action, change.EntityID)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"bandcash/internal/db"
+	eventstore "bandcash/models/event/data"
+	"github.com/uptrace/bun"
+)
+
+const (
+	KindEvent   = "event"
+	KindExpense = "expense"
+)
+
+func GetRecurrence(ctx context.Context, arg GetRecurrenceParams) (db.Recurrence, error) {
+	var row db.Recurrence
+	err := db.Conn(ctx).NewSelect().Model(&row).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+	return row, err
+}
+
+func ListRecurrencesByGroup(ctx context.Context, groupID string) ([]db.Recurrence, error) {
+	rows := make([]db.Recurrence, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("group_id = ?", groupID).
+		OrderExpr("start_date ASC").
+		OrderExpr("created_at ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+// ListRecurrences returns every template across all groups, used by the
+// background generator.
+func ListRecurrences(ctx context.Context) ([]db.Recurrence, error) {
+	rows := make([]db.Recurrence, 0)
+	err := db.Conn(ctx).NewSelect().Model(&rows).OrderExpr("group_id ASC").Scan(ctx)
+	return rows, err
+}
+
+func ListRecurrenceParticipants(ctx context.Context, recurrenceID string) ([]ListRecurrenceParticipantsRow, error) {
+	rows := make([]ListRecurrenceParticipantsRow, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr("recurrence_participants AS rp").
+		ColumnExpr("rp.member_id").
+		ColumnExpr("m.name AS member_name").
+		ColumnExpr("rp.amount").
+		ColumnExpr("rp.expense").
+		Join("JOIN members AS m ON m.id = rp.member_id").
+		Where("rp.recurrence_id = ?", recurrenceID).
+		OrderExpr("m.name ASC").
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+func ListGeneratedRows(ctx context.Context, rec db.Recurrence) ([]GeneratedRow, error) {
+	rows := make([]GeneratedRow, 0)
+	err := db.Conn(ctx).NewSelect().
+		TableExpr(seriesTable(rec.Kind)).
+		Column("id", "title", "date", "amount", "paid").
+		Where("recurrence_id = ?", rec.ID).
+		Where("group_id = ?", rec.GroupID).
+		OrderExpr("recurrence_date ASC").
+		Scan(ctx, &rows)
+	return rows, err
+}
+
+// ListSeriesEvents returns every event generated from a template.
+func ListSeriesEvents(ctx context.Context, recurrenceID, groupID string) ([]db.Event, error) {
+	rows := make([]db.Event, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("recurrence_id = ?", recurrenceID).
+		Where("group_id = ?", groupID).
+		OrderExpr("recurrence_date ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+// ListSeriesExpenses returns every expense generated from a template.
+func ListSeriesExpenses(ctx context.Context, recurrenceID, groupID string) ([]db.Expense, error) {
+	rows := make([]db.Expense, 0)
+	err := db.Conn(ctx).NewSelect().
+		Model(&rows).
+		Where("recurrence_id = ?", recurrenceID).
+		Where("group_id = ?", groupID).
+		OrderExpr("recurrence_date ASC").
+		Scan(ctx)
+	return rows, err
+}
+
+func CreateRecurrence(ctx context.Context, arg CreateRecurrenceParams) (db.Recurrence, error) {
+	row := db.Recurrence{
+		ID:          arg.ID,
+		GroupID:     arg.GroupID,
+		Kind:        arg.Kind,
+		Title:       arg.Title,
+		Description: arg.Description,
+		Place:       arg.Place,
+		EventTime:   arg.EventTime,
+		Amount:      arg.Amount,
+		Freq:        arg.Freq,
+		Interval:    arg.Interval,
+		StartDate:   arg.StartDate,
+		EndDate:     arg.EndDate,
+		Count:       arg.Count,
+	}
+
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(&row).Exec(ctx); err != nil {
+			return err
+		}
+		return replaceParticipantsTx(ctx, tx, arg.ID, arg.Participants)
+	})
+	if err != nil {
+		return db.Recurrence{}, err
+	}
+	return GetRecurrence(ctx, GetRecurrenceParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+func UpdateRecurrence(ctx context.Context, arg UpdateRecurrenceParams) (db.Recurrence, error) {
+	err := db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var current db.Recurrence
+		err := tx.NewSelect().Model(&current).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		generatedUntil := current.GeneratedUntil
+		if arg.LastDate != "" && generatedUntil > arg.LastDate {
+			generatedUntil = arg.LastDate
+		}
+
+		_, err = tx.NewUpdate().Model((*db.Recurrence)(nil)).
+			Set("title = ?", arg.Title).
+			Set("description = ?", arg.Description).
+			Set("place = ?", arg.Place).
+			Set("event_time = ?", arg.EventTime).
+			Set("amount = ?", arg.Amount).
+			Set("end_date = ?", arg.EndDate).
+			Set("count = ?", arg.Count).
+			Set("generated_until = ?", generatedUntil).
+			Where("id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := replaceParticipantsTx(ctx, tx, arg.ID, arg.Participants); err != nil {
+			return err
+		}
+
+		if arg.LastDate != "" {
+			_, err = tx.NewDelete().
+				TableExpr(seriesTable(current.Kind)).
+				Where("recurrence_id = ?", arg.ID).
+				Where("group_id = ?", arg.GroupID).
+				Where("recurrence_date > ?", arg.LastDate).
+				Where("date >= ?", arg.FromDate).
+				Where("paid = 0").
+				Apply(keepInvoiced(current.Kind)).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return applySeriesTx(ctx, tx, current, ApplySeriesParams{
+			RecurrenceID: arg.ID,
+			GroupID:      arg.GroupID,
+			FromDate:     arg.FromDate,
+			Title:        arg.Title,
+			Description:  arg.Description,
+			Place:        arg.Place,
+			EventTime:    arg.EventTime,
+			Amount:       arg.Amount,
+		}, true)
+	})
+	if err != nil {
+		return db.Recurrence{}, err
+	}
+	return GetRecurrence(ctx, GetRecurrenceParams{ID: arg.ID, GroupID: arg.GroupID})
+}
+
+// DeleteRecurrence removes the template together with its unpaid rows dated
+// on or after FromDate. Older rows are kept and lose their link.
+func DeleteRecurrence(ctx context.Context, arg DeleteRecurrenceParams) error {
+	return db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var current db.Recurrence
+		err := tx.NewSelect().Model(&current).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			TableExpr(seriesTable(current.Kind)).
+			Where("recurrence_id = ?", arg.ID).
+			Where("group_id = ?", arg.GroupID).
+			Where("date >= ?", arg.FromDate).
+			Where("paid = 0").
+			Apply(keepInvoiced(current.Kind)).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().Model((*db.Recurrence)(nil)).Where("id = ?", arg.ID).Where("group_id = ?", arg.GroupID).Exec(ctx)
+		return err
+	})
+}
+
+// ApplyEventSeries applies a "this and future" edit made on a generated event.
+func ApplyEventSeries(ctx context.Context, arg ApplySeriesParams) error {
+	return applySeries(ctx, KindEvent, arg)
+}
+
+// ApplyExpenseSeries applies a "this and future" edit made on a generated expense.
+func ApplyExpenseSeries(ctx context.Context, arg ApplySeriesParams) error {
+	return applySeries(ctx, KindExpense, arg)
+}
+
+func applySeries(ctx context.Context, kind string, arg ApplySeriesParams) error {
+	return db.Conn(ctx).RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var current db.Recurrence
+		err := tx.NewSelect().Model(&current).
+			Where("id = ?", arg.RecurrenceID).
+			Where("group_id = ?", arg.GroupID).
+			Where("kind = ?", kind).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if err := updateTemplateTx(ctx, tx, kind, arg); err != nil {
+			return err
+		}
+		return applySeriesTx(ctx, tx, current, arg, false)
+	})
+}
+
+func updateTemplateTx(ctx context.Context, tx bun.Tx, kind string, arg ApplySeriesParams) error {
+	q := tx.NewUpdate().Model((*db.Recurrence)(nil)).
+		Set("title = ?", arg.Title).
+		Set("description = ?", arg.Description).
+		Set("amount = ?", arg.Amount)
+	if kind == KindEvent {
+		q = q.Set("place = ?", arg.Place).Set("event_time = ?", arg.EventTime)
+	}
+	_, err := q.
+		Where("id = ?", arg.RecurrenceID).
+		Where("group_id = ?", arg.GroupID).
+		Where("kind = ?", kind).
+		Exec(ctx)
+	return err
+}
+
+// applySeriesTx copies the template values onto generated rows. Rows dated on
+// FromDate are included only when inclusive is set; a "this and future" edit
+// has already saved the row it was made on. Paid rows are left alone, and so
+// are rows that no longer carry the values of prev, the template before the
+// change: those were edited on their own.
+func applySeriesTx(ctx context.Context, tx bun.Tx, prev db.Recurrence, arg ApplySeriesParams, inclusive bool) error {
+	q := tx.NewUpdate().
+		TableExpr(seriesTable(prev.Kind)).
+		Set("title = ?", arg.Title).
+		Set("description = ?", arg.Description).
+		Set("amount = ?", arg.Amount)
+	if prev.Kind == KindEvent {
+		q = q.
+			Set("place = ?", arg.Place).
+			Set("event_time = ?", arg.EventTime).
+			Set("time = CASE WHEN ? = '' OR date = '' THEN '' ELSE date || 'T' || ? END", arg.EventTime, arg.EventTime).
+			Where("place = ?", prev.Place).
+			Where("event_time = ?", prev.EventTime)
+	}
+	q = q.
+		Where("recurrence_id = ?", arg.RecurrenceID).
+		Where("group_id = ?", arg.GroupID).
+		Where("paid = 0").
+		Where("title = ?", prev.Title).
+		Where("description = ?", prev.Description).
+		Where("amount = ?", prev.Amount)
+	if inclusive {
+		q = q.Where("recurrence_date >= ?", arg.FromDate)
+	} else {
+		q = q.Where("recurrence_date > ?", arg.FromDate)
+	}
+	_, err := q.Exec(ctx)
+	return err
+}
+
+func replaceParticipantsTx(ctx context.Context, tx bun.Tx, recurrenceID string, participants []RecurrenceParticipantParams) error {
+	_, err := tx.NewDelete().
+		TableExpr("recurrence_participants").
+		Where("recurrence_id = ?", recurrenceID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, participant := range participants {
+		row := db.RecurrenceParticipant{
+			RecurrenceID: recurrenceID,
+			MemberID:     participant.MemberID,
+			Amount:       participant.Amount,
+			Expense:      participant.Expense,
+		}
+		if _, err := tx.NewInsert().Model(&row).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepInvoiced leaves events with an issued invoice out of a delete.
+func keepInvoiced(kind string) func(*bun.DeleteQuery) *bun.DeleteQuery {
+	return func(q *bun.DeleteQuery) *bun.DeleteQuery {
+		if kind == KindEvent {
+			q = q.Where(eventstore.NotInvoicedExpr)
+		}
+		return q
+	}
+}
+
+func seriesTable(kind string) string {
+	if kind == KindExpense {
+		return "expenses"
+	}
+	return "events"
+}
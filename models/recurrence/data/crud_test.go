@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"bandcash/internal/db"
+)
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "recurrence_test.sqlite")
+	if err := db.Init(dbPath); err != nil {
+		t.Fatalf("db.Init failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("db.Migrate failed: %v", err)
+	}
+}
+
+func TestApplyEventSeriesSkipsPaidAndEditedRows(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.BunDB.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	exec(`INSERT INTO users (id, email) VALUES ('usr_1', 'a@example.com')`)
+	exec(`INSERT INTO groups (id, name, admin_user_id) VALUES ('grp_1', 'Band', 'usr_1')`)
+	exec(`INSERT INTO recurrences (id, group_id, kind, title, place, event_time, amount, freq, start_date) VALUES ('rec_1', 'grp_1', 'event', 'Gig', 'Club', '20:00', 100, 'weekly', '2026-05-01')`)
+	for _, row := range []struct {
+		id, date, title string
+		paid            int
+	}{
+		{"evt_1", "2026-05-01", "Gig", 0},
+		{"evt_2", "2026-05-08", "Gig", 0},
+		{"evt_3", "2026-05-15", "Gig", 1},
+		{"evt_4", "2026-05-22", "Gig with guests", 0},
+	} {
+		exec(`INSERT INTO events (id, group_id, title, time, date, event_time, place, description, amount, paid, recurrence_id, recurrence_date)
+			VALUES (?, 'grp_1', ?, ?, ?, '20:00', 'Club', '', 100, ?, 'rec_1', ?)`,
+			row.id, row.title, row.date+"T20:00", row.date, row.paid, row.date)
+	}
+
+	err := ApplyEventSeries(ctx, ApplySeriesParams{
+		RecurrenceID: "rec_1",
+		GroupID:      "grp_1",
+		FromDate:     "2026-05-01",
+		Title:        "Residency",
+		Place:        "Hall",
+		EventTime:    "21:00",
+		Amount:       150,
+	})
+	if err != nil {
+		t.Fatalf("ApplyEventSeries failed: %v", err)
+	}
+
+	rec, err := GetRecurrence(ctx, GetRecurrenceParams{ID: "rec_1", GroupID: "grp_1"})
+	if err != nil {
+		t.Fatalf("GetRecurrence failed: %v", err)
+	}
+	if rec.Title != "Residency" || rec.Amount != 150 {
+		t.Fatalf("template = %q %d; want Residency 150", rec.Title, rec.Amount)
+	}
+
+	want := map[string]struct {
+		title string
+		time  string
+	}{
+		"evt_1": {"Gig", "2026-05-01T20:00"},
+		"evt_2": {"Residency", "2026-05-08T21:00"},
+		"evt_3": {"Gig", "2026-05-15T20:00"},
+		"evt_4": {"Gig with guests", "2026-05-22T20:00"},
+	}
+	for id, w := range want {
+		var title, eventTime string
+		if err := db.BunDB.QueryRowContext(ctx, `SELECT title, time FROM events WHERE id = ?`, id).Scan(&title, &eventTime); err != nil {
+			t.Fatalf("reading %s failed: %v", id, err)
+		}
+		if title != w.title || eventTime != w.time {
+			t.Errorf("%s = %q %q; want %q %q", id, title, eventTime, w.title, w.time)
+		}
+	}
+}
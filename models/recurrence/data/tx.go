@@ -0,0 +1,16 @@
+package data
+
+import (
+	"context"
+
+	"bandcash/internal/db"
+	"github.com/uptrace/bun"
+)
+
+func SetGeneratedUntilTx(ctx context.Context, tx bun.Tx, id, date string) error {
+	_, err := tx.NewUpdate().Model((*db.Recurrence)(nil)).
+		Set("generated_until = ?", date).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
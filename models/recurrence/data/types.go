@@ -0,0 +1,81 @@
+package data
+
+type GetRecurrenceParams struct {
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+}
+
+type RecurrenceParticipantParams struct {
+	MemberID string `json:"member_id"`
+	Amount   int64  `json:"amount"`
+	Expense  int64  `json:"expense"`
+}
+
+type CreateRecurrenceParams struct {
+	ID           string                        `json:"id"`
+	GroupID      string                        `json:"group_id"`
+	Kind         string                        `json:"kind"`
+	Title        string                        `json:"title"`
+	Description  string                        `json:"description"`
+	Place        string                        `json:"place"`
+	EventTime    string                        `json:"event_time"`
+	Amount       int64                         `json:"amount"`
+	Freq         string                        `json:"freq"`
+	Interval     int64                         `json:"interval"`
+	StartDate    string                        `json:"start_date"`
+	EndDate      string                        `json:"end_date"`
+	Count        int64                         `json:"count"`
+	Participants []RecurrenceParticipantParams `json:"participants"`
+}
+
+// UpdateRecurrenceParams changes the template and its future rows. Rows of the
+// series dated on or after FromDate that are past LastDate (when set) and still
+// unpaid are removed, so shortening a series does not leave stray rows behind.
+type UpdateRecurrenceParams struct {
+	ID           string                        `json:"id"`
+	GroupID      string                        `json:"group_id"`
+	Title        string                        `json:"title"`
+	Description  string                        `json:"description"`
+	Place        string                        `json:"place"`
+	EventTime    string                        `json:"event_time"`
+	Amount       int64                         `json:"amount"`
+	EndDate      string                        `json:"end_date"`
+	Count        int64                         `json:"count"`
+	FromDate     string                        `json:"from_date"`
+	LastDate     string                        `json:"last_date"`
+	Participants []RecurrenceParticipantParams `json:"participants"`
+}
+
+type DeleteRecurrenceParams struct {
+	ID       string `json:"id"`
+	GroupID  string `json:"group_id"`
+	FromDate string `json:"from_date"`
+}
+
+// ApplySeriesParams carries the "this and future" edit of a generated row:
+// the template and every row of the series after FromDate get the new values.
+type ApplySeriesParams struct {
+	RecurrenceID string `json:"recurrence_id"`
+	GroupID      string `json:"group_id"`
+	FromDate     string `json:"from_date"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Place        string `json:"place"`
+	EventTime    string `json:"event_time"`
+	Amount       int64  `json:"amount"`
+}
+
+type ListRecurrenceParticipantsRow struct {
+	MemberID   string `json:"member_id"`
+	MemberName string `json:"member_name"`
+	Amount     int64  `json:"amount"`
+	Expense    int64  `json:"expense"`
+}
+
+type GeneratedRow struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Date   string `json:"date"`
+	Amount int64  `json:"amount"`
+	Paid   int64  `json:"paid"`
+}
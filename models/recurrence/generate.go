@@ -0,0 +1,171 @@
+package recurrence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	eventstore "bandcash/models/event/data"
+	expensestore "bandcash/models/expense/data"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+// generateAheadDays is how far past today rows are created, so upcoming
+// occurrences show up in the event and expense lists before they happen.
+const generateAheadDays = 90
+
+type GenerateReport struct {
+	Recurrences int
+	Created     int
+	Failed      int
+}
+
+func generationHorizon(now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, generateAheadDays)
+}
+
+// GenerateRecurrence creates the rows of rec that are due up to until and have
+// not been generated yet. Rows deleted by hand are not recreated because
+// generation resumes after generated_until.
+func GenerateRecurrence(ctx context.Context, rec db.Recurrence, until time.Time) (int, error) {
+	r, err := ruleFromRecurrence(rec)
+	if err != nil {
+		return 0, err
+	}
+
+	after := r.Start.AddDate(0, 0, -1)
+	if rec.GeneratedUntil != "" {
+		after, err = time.Parse(dateLayout, rec.GeneratedUntil)
+		if err != nil {
+			return 0, errInvalidRule
+		}
+	}
+
+	dates := r.occurrences(after, until)
+	if len(dates) == 0 {
+		return 0, nil
+	}
+
+	participants := []recurrencestore.ListRecurrenceParticipantsRow{}
+	if rec.Kind == recurrencestore.KindEvent {
+		participants, err = recurrencestore.ListRecurrenceParticipants(ctx, rec.ID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	link := sql.NullString{String: rec.ID, Valid: true}
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		for _, date := range dates {
+			day := date.Format(dateLayout)
+			switch rec.Kind {
+			case recurrencestore.KindEvent:
+				event, err := eventstore.CreateEventTx(ctx, tx, eventstore.CreateEventParams{
+					ID:             utils.GenerateID(utils.PrefixEvent),
+					GroupID:        rec.GroupID,
+					Title:          rec.Title,
+					Date:           day,
+					EventTime:      rec.EventTime,
+					Place:          rec.Place,
+					Description:    rec.Description,
+					Amount:         rec.Amount,
+					RecurrenceID:   link,
+					RecurrenceDate: day,
+				})
+				if err != nil {
+					return err
+				}
+				for _, participant := range participants {
+					_, err := eventstore.AddParticipantTx(ctx, tx, eventstore.AddParticipantParams{
+						GroupID:  rec.GroupID,
+						EventID:  event.ID,
+						MemberID: participant.MemberID,
+						Amount:   participant.Amount,
+						Expense:  participant.Expense,
+					})
+					if err != nil {
+						return err
+					}
+				}
+			case recurrencestore.KindExpense:
+				_, err := expensestore.CreateExpenseTx(ctx, tx, expensestore.CreateExpenseParams{
+					ID:             utils.GenerateID(utils.PrefixExpense),
+					GroupID:        rec.GroupID,
+					Title:          rec.Title,
+					Description:    rec.Description,
+					Amount:         rec.Amount,
+					Date:           day,
+					RecurrenceID:   link,
+					RecurrenceDate: day,
+				})
+				if err != nil {
+					return err
+				}
+			default:
+				return errInvalidRule
+			}
+		}
+		return recurrencestore.SetGeneratedUntilTx(ctx, tx, rec.ID, dates[len(dates)-1].Format(dateLayout))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(dates), nil
+}
+
+// GenerateDue runs GenerateRecurrence for every template in every group.
+func GenerateDue(ctx context.Context, now time.Time) (GenerateReport, error) {
+	var report GenerateReport
+
+	recurrences, err := recurrencestore.ListRecurrences(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	until := generationHorizon(now)
+	var errs []error
+	for _, rec := range recurrences {
+		report.Recurrences++
+		created, err := GenerateRecurrence(ctx, rec, until)
+		if err != nil {
+			report.Failed++
+			errs = append(errs, err)
+			slog.Warn("recurrence.generate: failed", "recurrence_id", rec.ID, "group_id", rec.GroupID, "err", err)
+			continue
+		}
+		if created > 0 {
+			report.Created += created
+			utils.InvalidateGroupCaches(rec.GroupID)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// RunScheduler generates due rows right away and then on every tick until ctx
+// is cancelled.
+func RunScheduler(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		report, err := GenerateDue(ctx, time.Now())
+		if err != nil {
+			slog.Warn("recurrence scheduler finished with errors", "recurrences", report.Recurrences, "created", report.Created, "failed", report.Failed, "err", err)
+		} else if report.Created > 0 {
+			slog.Info("recurrence scheduler generated rows", "recurrences", report.Recurrences, "created", report.Created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package recurrence
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+	"github.com/uptrace/bun"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	"bandcash/models/audit"
+	memberstore "bandcash/models/member/data"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+func Create(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	var signals recurrenceFormParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("recurrence.create: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	form := normalizeForm(signals.FormData)
+	if errs := validateForm(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(recurrenceErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	participants, err := participantParams(ctx, groupID, form)
+	if err != nil {
+		slog.Error("recurrence.create: failed to list members", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	var rec db.Recurrence
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		rec, err = recurrencestore.CreateRecurrence(ctx, recurrencestore.CreateRecurrenceParams{
+			ID:           utils.GenerateID(utils.PrefixRecurrence),
+			GroupID:      groupID,
+			Kind:         form.Kind,
+			Title:        form.Title,
+			Description:  form.Description,
+			Place:        form.Place,
+			EventTime:    form.Time,
+			Amount:       form.Amount,
+			Freq:         form.Freq,
+			Interval:     form.Interval,
+			StartDate:    form.StartDate,
+			EndDate:      form.EndDate,
+			Count:        form.Count,
+			Participants: participants,
+		})
+		if err != nil {
+			return err
+		}
+		changes := []audit.Change{{Action: audit.ActionCreate, Entity: utils.EntityFilterRecurrence, EntityID: rec.ID, After: rec}}
+
+		// Generation runs in a savepoint; the template is kept when it fails.
+		_, generated, err := generateSeries(ctx, rec)
+		if err != nil {
+			slog.Warn("recurrence.create: failed to generate rows", "recurrence_id", rec.ID, "err", err)
+		}
+		return audit.Record(ctx, c, append(changes, generated...)...)
+	})
+	if err != nil {
+		slog.Error("recurrence.create: failed to create recurrence", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.create_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.created"))
+	utils.InvalidateGroupCaches(groupID)
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/recurrences/"+rec.ID); err != nil {
+		slog.Warn("recurrence.create: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Update(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixRecurrence) {
+		slog.Info("recurrence.update: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals recurrenceFormParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("recurrence.update: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	current, err := recurrencestore.GetRecurrence(ctx, recurrencestore.GetRecurrenceParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("recurrence.update: failed to get recurrence", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// The schedule itself is fixed once rows exist; only the end of the series
+	// can move.
+	form := normalizeForm(signals.FormData)
+	form.Kind = current.Kind
+	form.Freq = current.Freq
+	form.Interval = current.Interval
+	form.StartDate = current.StartDate
+	if errs := validateForm(ctx, form); errs != nil {
+		utils.SSEHub.PatchSignals(c, map[string]any{"errors": utils.WithErrors(recurrenceErrorFields, errs)})
+		return c.NoContent(http.StatusUnprocessableEntity)
+	}
+
+	participants, err := participantParams(ctx, groupID, form)
+	if err != nil {
+		slog.Error("recurrence.update: failed to list members", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	updated := current
+	updated.EndDate = form.EndDate
+	updated.Count = form.Count
+	r, err := ruleFromRecurrence(updated)
+	if err != nil {
+		slog.Error("recurrence.update: invalid rule", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	lastDate := ""
+	if last, ok := r.last(); ok {
+		lastDate = last.Format(dateLayout)
+	} else if !r.End.IsZero() || r.Count > 0 {
+		lastDate = r.Start.AddDate(0, 0, -1).Format(dateLayout)
+	}
+
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		var rec db.Recurrence
+		changes, err := SeriesChanges(ctx, current.Kind, id, groupID, func() error {
+			var err error
+			rec, err = recurrencestore.UpdateRecurrence(ctx, recurrencestore.UpdateRecurrenceParams{
+				ID:           id,
+				GroupID:      groupID,
+				Title:        form.Title,
+				Description:  form.Description,
+				Place:        form.Place,
+				EventTime:    form.Time,
+				Amount:       form.Amount,
+				EndDate:      form.EndDate,
+				Count:        form.Count,
+				FromDate:     time.Now().Format(dateLayout),
+				LastDate:     lastDate,
+				Participants: participants,
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		changes = append([]audit.Change{{Action: audit.ActionUpdate, Entity: utils.EntityFilterRecurrence, EntityID: id, Before: current, After: rec}}, changes...)
+
+		// Generation runs in a savepoint; the update is kept when it fails.
+		_, generated, err := generateSeries(ctx, rec)
+		if err != nil {
+			slog.Warn("recurrence.update: failed to generate rows", "recurrence_id", id, "err", err)
+		}
+		return audit.Record(ctx, c, append(changes, generated...)...)
+	})
+	if err != nil {
+		slog.Error("recurrence.update: failed to update recurrence", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.update_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.updated"))
+	utils.InvalidateGroupCaches(groupID)
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/recurrences/"+id); err != nil {
+		slog.Warn("recurrence.update: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Generate(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixRecurrence) {
+		slog.Info("recurrence.generate: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("recurrence.generate: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	rec, err := recurrencestore.GetRecurrence(ctx, recurrencestore.GetRecurrenceParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("recurrence.generate: failed to get recurrence", "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	created := 0
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		var changes []audit.Change
+		created, changes, err = generateSeries(ctx, rec)
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, c, changes...)
+	})
+	if err != nil {
+		slog.Error("recurrence.generate: failed to generate rows", "recurrence_id", id, "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.generate_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.generated", strconv.Itoa(created)))
+	utils.InvalidateGroupCaches(groupID)
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/recurrences/"+id); err != nil {
+		slog.Warn("recurrence.generate: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func Destroy(c echo.Context) error {
+	groupID := utils.GetGroupID(c)
+	ctx := c.Request().Context()
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixRecurrence) {
+		slog.Info("recurrence.destroy: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	var signals tabParams
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		slog.Info("recurrence.destroy: failed to read signals", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+	if !utils.SetTabID(c, signals.TabID) {
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	current, err := recurrencestore.GetRecurrence(ctx, recurrencestore.GetRecurrenceParams{ID: id, GroupID: groupID})
+	if err != nil {
+		slog.Error("recurrence.destroy: failed to get recurrence", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	err = db.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		changes, err := SeriesChanges(ctx, current.Kind, id, groupID, func() error {
+			return recurrencestore.DeleteRecurrence(ctx, recurrencestore.DeleteRecurrenceParams{
+				ID:       id,
+				GroupID:  groupID,
+				FromDate: time.Now().Format(dateLayout),
+			})
+		})
+		if err != nil {
+			return err
+		}
+		changes = append(changes, audit.Change{Action: audit.ActionDelete, Entity: utils.EntityFilterRecurrence, EntityID: id, Before: current})
+		return audit.Record(ctx, c, changes...)
+	})
+	if err != nil {
+		slog.Error("recurrence.destroy: failed to delete recurrence", "err", err)
+		utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.delete_failed"))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	utils.Notify(c, ctxi18n.T(ctx, "recurrences.notifications.deleted"))
+	utils.InvalidateGroupCaches(groupID)
+
+	utils.SSEHub.Publish(c, utils.GroupTopic(groupID))
+	if err := utils.SSEHub.Redirect(c, "/groups/"+groupID+"/recurrences"); err != nil {
+		slog.Warn("recurrence.destroy: failed to redirect", "err", err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func normalizeForm(form recurrenceFormData) recurrenceFormData {
+	form.Kind = strings.TrimSpace(form.Kind)
+	form.Title = strings.TrimSpace(form.Title)
+	form.Description = strings.TrimSpace(form.Description)
+	form.Place = strings.TrimSpace(form.Place)
+	form.Time = strings.TrimSpace(form.Time)
+	form.Freq = strings.TrimSpace(form.Freq)
+	form.StartDate = strings.TrimSpace(form.StartDate)
+	form.EndDate = strings.TrimSpace(form.EndDate)
+	if form.Kind == recurrencestore.KindExpense {
+		form.Place = ""
+		form.Time = ""
+		form.Participants = nil
+	}
+	return form
+}
+
+func validateForm(ctx context.Context, form recurrenceFormData) map[string]string {
+	if errs := utils.ValidateWithLocale(ctx, form); errs != nil {
+		return errs
+	}
+
+	start, err := time.Parse(dateLayout, form.StartDate)
+	if err != nil {
+		return map[string]string{"startDate": ctxi18n.T(ctx, "validation.required")}
+	}
+	if form.EndDate != "" {
+		end, err := time.Parse(dateLayout, form.EndDate)
+		if err != nil {
+			return map[string]string{"endDate": ctxi18n.T(ctx, "validation.required")}
+		}
+		if end.Before(start) {
+			return map[string]string{"endDate": ctxi18n.T(ctx, "recurrences.validation.end_before_start")}
+		}
+	}
+	return nil
+}
+
+// participantParams keeps the included rows that point at a member of the
+// group. Unknown member IDs are dropped silently.
+func participantParams(ctx context.Context, groupID string, form recurrenceFormData) ([]recurrencestore.RecurrenceParticipantParams, error) {
+	if form.Kind != recurrencestore.KindEvent || len(form.Participants) == 0 {
+		return nil, nil
+	}
+
+	members, err := memberstore.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := make([]recurrencestore.RecurrenceParticipantParams, 0, len(form.Participants))
+	for _, member := range members {
+		participant, ok := form.Participants[member.ID]
+		if !ok || !participant.Included {
+			continue
+		}
+		participants = append(participants, recurrencestore.RecurrenceParticipantParams{
+			MemberID: member.ID,
+			Amount:   max(participant.Amount, 0),
+			Expense:  max(participant.Expense, 0),
+		})
+	}
+	return participants, nil
+}
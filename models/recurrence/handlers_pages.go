@@ -0,0 +1,86 @@
+package recurrence
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"bandcash/internal/utils"
+)
+
+func IndexPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	data, err := GetIndexData(c.Request().Context(), groupID)
+	if err != nil {
+		slog.Error("recurrence.index: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = map[string]any{"_fetching": false}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, RecurrenceIndexPage(data))
+}
+
+func ShowPage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixRecurrence) {
+		slog.Info("recurrence.show: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	data, err := GetShowData(c.Request().Context(), groupID, id)
+	if err != nil {
+		slog.Error("recurrence.show: failed to get data", "group_id", groupID, "recurrence_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAdmin = utils.IsAdmin(c)
+	data.Signals = recurrenceShowSignals()
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, RecurrenceShowPage(data))
+}
+
+func NewRecurrencePage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	data, err := GetFormData(c.Request().Context(), groupID, "")
+	if err != nil {
+		slog.Error("recurrence.new_page: failed to get data", "group_id", groupID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, RecurrenceFormPage(data))
+}
+
+func EditRecurrencePage(c echo.Context) error {
+	utils.EnsureTabID(c)
+	groupID := utils.GetGroupID(c)
+
+	id := c.Param("id")
+	if !utils.IsValidID(id, utils.PrefixRecurrence) {
+		slog.Info("recurrence.edit_page: invalid id")
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	data, err := GetFormData(c.Request().Context(), groupID, id)
+	if err != nil {
+		slog.Error("recurrence.edit_page: failed to get data", "group_id", groupID, "recurrence_id", id, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	data.IsAuthenticated = true
+	data.IsSuperAdmin = utils.IsSuperadmin(c)
+
+	return utils.RenderPage(c, RecurrenceFormPage(data))
+}
@@ -0,0 +1,121 @@
+package recurrence
+
+import (
+	"context"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/utils"
+	groupstore "bandcash/models/group/data"
+	memberstore "bandcash/models/member/data"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+func GetIndexData(ctx context.Context, groupID string) (RecurrencesData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return RecurrencesData{}, err
+	}
+
+	recurrences, err := recurrencestore.ListRecurrencesByGroup(ctx, groupID)
+	if err != nil {
+		return RecurrencesData{}, err
+	}
+
+	return RecurrencesData{
+		Title:       ctxi18n.T(ctx, "recurrences.page_title"),
+		Recurrences: recurrences,
+		Table:       RecurrencesIndexTableLayout(),
+		GroupID:     groupID,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "recurrences.title")},
+		},
+	}, nil
+}
+
+func GetShowData(ctx context.Context, groupID, recurrenceID string) (RecurrenceData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return RecurrenceData{}, err
+	}
+
+	rec, err := recurrencestore.GetRecurrence(ctx, recurrencestore.GetRecurrenceParams{ID: recurrenceID, GroupID: groupID})
+	if err != nil {
+		return RecurrenceData{}, err
+	}
+
+	participants, err := recurrencestore.ListRecurrenceParticipants(ctx, rec.ID)
+	if err != nil {
+		return RecurrenceData{}, err
+	}
+
+	rows, err := recurrencestore.ListGeneratedRows(ctx, rec)
+	if err != nil {
+		return RecurrenceData{}, err
+	}
+
+	return RecurrenceData{
+		Title:        "bandcash - " + rec.Title,
+		Recurrence:   &rec,
+		Participants: participants,
+		Rows:         rows,
+		RowsTable:    GeneratedRowsTableLayout(),
+		GroupID:      groupID,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "recurrences.title"), Href: "/groups/" + groupID + "/recurrences"},
+			{Label: rec.Title},
+		},
+	}, nil
+}
+
+// GetFormData loads the new page when recurrenceID is empty and the edit page
+// otherwise.
+func GetFormData(ctx context.Context, groupID, recurrenceID string) (RecurrenceFormPageData, error) {
+	group, err := groupstore.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return RecurrenceFormPageData{}, err
+	}
+
+	members, err := memberstore.ListMembers(ctx, groupID)
+	if err != nil {
+		return RecurrenceFormPageData{}, err
+	}
+
+	data := RecurrenceFormPageData{
+		Title:   ctxi18n.T(ctx, "recurrences.page_title"),
+		GroupID: groupID,
+		Members: members,
+		Breadcrumbs: []utils.Crumb{
+			{Label: ctxi18n.T(ctx, "groups.title"), Href: "/groups"},
+			{Label: group.Name, Href: "/groups/" + groupID + "/events"},
+			{Label: ctxi18n.T(ctx, "recurrences.title"), Href: "/groups/" + groupID + "/recurrences"},
+		},
+	}
+
+	if recurrenceID == "" {
+		data.Breadcrumbs = append(data.Breadcrumbs, utils.Crumb{Label: ctxi18n.T(ctx, "recurrences.add")})
+		data.Signals = recurrenceFormSignals(nil, members, nil)
+		return data, nil
+	}
+
+	rec, err := recurrencestore.GetRecurrence(ctx, recurrencestore.GetRecurrenceParams{ID: recurrenceID, GroupID: groupID})
+	if err != nil {
+		return RecurrenceFormPageData{}, err
+	}
+	participants, err := recurrencestore.ListRecurrenceParticipants(ctx, rec.ID)
+	if err != nil {
+		return RecurrenceFormPageData{}, err
+	}
+
+	data.Recurrence = &rec
+	data.Breadcrumbs = append(data.Breadcrumbs,
+		utils.Crumb{Label: rec.Title, Href: "/groups/" + groupID + "/recurrences/" + rec.ID},
+		utils.Crumb{Label: ctxi18n.T(ctx, "recurrences.edit")},
+	)
+	data.Signals = recurrenceFormSignals(&rec, members, participants)
+	return data, nil
+}
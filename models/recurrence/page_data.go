@@ -0,0 +1,46 @@
+package recurrence
+
+import (
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+type RecurrencesData struct {
+	Title           string
+	Recurrences     []db.Recurrence
+	Table           utils.TableLayout
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
+
+type RecurrenceData struct {
+	Title           string
+	Recurrence      *db.Recurrence
+	Participants    []recurrencestore.ListRecurrenceParticipantsRow
+	Rows            []recurrencestore.GeneratedRow
+	RowsTable       utils.TableLayout
+	Breadcrumbs     []utils.Crumb
+	Signals         map[string]any
+	GroupID         string
+	IsAdmin         bool
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
+
+// RecurrenceFormPageData backs both the new and the edit page. Recurrence is
+// nil on the new page.
+type RecurrenceFormPageData struct {
+	Title           string
+	Breadcrumbs     []utils.Crumb
+	GroupID         string
+	Recurrence      *db.Recurrence
+	Members         []db.Member
+	Signals         map[string]any
+	IsAuthenticated bool
+	IsSuperAdmin    bool
+}
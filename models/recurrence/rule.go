@@ -0,0 +1,130 @@
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"bandcash/internal/db"
+)
+
+const (
+	freqDaily   = "daily"
+	freqWeekly  = "weekly"
+	freqMonthly = "monthly"
+	freqYearly  = "yearly"
+
+	dateLayout = "2006-01-02"
+)
+
+var errInvalidRule = errors.New("recurrence: invalid rule")
+
+// rule is the subset of an iCalendar RRULE that templates support: FREQ with
+// INTERVAL, a start date and an optional UNTIL (end date) and/or COUNT.
+type rule struct {
+	Freq     string
+	Interval int
+	Start    time.Time
+	End      time.Time
+	Count    int
+}
+
+func ruleFromRecurrence(rec db.Recurrence) (rule, error) {
+	start, err := time.Parse(dateLayout, strings.TrimSpace(rec.StartDate))
+	if err != nil {
+		return rule{}, errInvalidRule
+	}
+
+	r := rule{
+		Freq:     rec.Freq,
+		Interval: int(rec.Interval),
+		Start:    start,
+		Count:    int(rec.Count),
+	}
+	if end := strings.TrimSpace(rec.EndDate); end != "" {
+		r.End, err = time.Parse(dateLayout, end)
+		if err != nil {
+			return rule{}, errInvalidRule
+		}
+	}
+
+	switch r.Freq {
+	case freqDaily, freqWeekly, freqMonthly, freqYearly:
+	default:
+		return rule{}, errInvalidRule
+	}
+	if r.Interval < 1 || r.Count < 0 {
+		return rule{}, errInvalidRule
+	}
+	return r, nil
+}
+
+// at returns the n-th occurrence, counting from zero. Monthly and yearly rules
+// keep the start day and fall back to the last day of shorter months.
+func (r rule) at(n int) time.Time {
+	step := n * r.Interval
+	switch r.Freq {
+	case freqWeekly:
+		return r.Start.AddDate(0, 0, 7*step)
+	case freqMonthly:
+		return addMonthsClamped(r.Start, step)
+	case freqYearly:
+		return addMonthsClamped(r.Start, 12*step)
+	default:
+		return r.Start.AddDate(0, 0, step)
+	}
+}
+
+// occurrences lists the dates of the rule that fall in (after, until].
+func (r rule) occurrences(after, until time.Time) []time.Time {
+	dates := make([]time.Time, 0)
+	for n := 0; ; n++ {
+		if r.Count > 0 && n >= r.Count {
+			break
+		}
+		date := r.at(n)
+		if !r.End.IsZero() && date.After(r.End) {
+			break
+		}
+		if date.After(until) {
+			break
+		}
+		if date.After(after) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// last returns the final occurrence of a bounded rule. Open-ended rules report
+// false.
+func (r rule) last() (time.Time, bool) {
+	if r.End.IsZero() && r.Count == 0 {
+		return time.Time{}, false
+	}
+
+	var last time.Time
+	found := false
+	for n := 0; ; n++ {
+		if r.Count > 0 && n >= r.Count {
+			break
+		}
+		date := r.at(n)
+		if !r.End.IsZero() && date.After(r.End) {
+			break
+		}
+		last = date
+		found = true
+	}
+	return last, found
+}
+
+func addMonthsClamped(start time.Time, months int) time.Time {
+	year, month, day := start.Date()
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, start.Location())
+}
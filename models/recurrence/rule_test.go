@@ -0,0 +1,109 @@
+package recurrence
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"bandcash/internal/db"
+)
+
+func TestRuleOccurrences(t *testing.T) {
+	t.Parallel()
+
+	day := func(value string) time.Time {
+		parsed, err := time.Parse(dateLayout, value)
+		if err != nil {
+			t.Fatalf("bad test date %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name  string
+		rec   db.Recurrence
+		after string
+		until string
+		want  []string
+	}{
+		{
+			name:  "weekly with interval",
+			rec:   db.Recurrence{Freq: freqWeekly, Interval: 2, StartDate: "2026-05-04"},
+			after: "2026-05-03",
+			until: "2026-06-10",
+			want:  []string{"2026-05-04", "2026-05-18", "2026-06-01"},
+		},
+		{
+			name:  "monthly clamps to last day",
+			rec:   db.Recurrence{Freq: freqMonthly, Interval: 1, StartDate: "2026-01-31"},
+			after: "2026-01-30",
+			until: "2026-04-30",
+			want:  []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"},
+		},
+		{
+			name:  "count limits occurrences",
+			rec:   db.Recurrence{Freq: freqDaily, Interval: 1, StartDate: "2026-05-01", Count: 3},
+			after: "2026-04-30",
+			until: "2026-12-31",
+			want:  []string{"2026-05-01", "2026-05-02", "2026-05-03"},
+		},
+		{
+			name:  "end date is inclusive",
+			rec:   db.Recurrence{Freq: freqYearly, Interval: 1, StartDate: "2024-02-29", EndDate: "2027-02-28"},
+			after: "2024-02-28",
+			until: "2030-01-01",
+			want:  []string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28"},
+		},
+		{
+			name:  "resumes after generated date",
+			rec:   db.Recurrence{Freq: freqWeekly, Interval: 1, StartDate: "2026-05-01"},
+			after: "2026-05-08",
+			until: "2026-05-22",
+			want:  []string{"2026-05-15", "2026-05-22"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := ruleFromRecurrence(tt.rec)
+			if err != nil {
+				t.Fatalf("ruleFromRecurrence() error = %v", err)
+			}
+			got := make([]string, 0)
+			for _, date := range r.occurrences(day(tt.after), day(tt.until)) {
+				got = append(got, date.Format(dateLayout))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleLast(t *testing.T) {
+	t.Parallel()
+
+	r, err := ruleFromRecurrence(db.Recurrence{Freq: freqMonthly, Interval: 1, StartDate: "2026-01-15", Count: 4})
+	if err != nil {
+		t.Fatalf("ruleFromRecurrence() error = %v", err)
+	}
+	last, ok := r.last()
+	if !ok || last.Format(dateLayout) != "2026-04-15" {
+		t.Fatalf("last() = %v, %v, want 2026-04-15, true", last, ok)
+	}
+
+	r, err = ruleFromRecurrence(db.Recurrence{Freq: freqDaily, Interval: 1, StartDate: "2026-01-15"})
+	if err != nil {
+		t.Fatalf("ruleFromRecurrence() error = %v", err)
+	}
+	if _, ok := r.last(); ok {
+		t.Fatalf("last() on open-ended rule reported a date")
+	}
+
+	if _, err := ruleFromRecurrence(db.Recurrence{Freq: "hourly", Interval: 1, StartDate: "2026-01-15"}); err == nil {
+		t.Fatalf("ruleFromRecurrence() accepted unknown frequency")
+	}
+}
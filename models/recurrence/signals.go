@@ -0,0 +1,101 @@
+package recurrence
+
+import (
+	"bandcash/internal/db"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+type recurrenceParticipantData struct {
+	Included bool  `json:"included"`
+	Amount   int64 `json:"amount"`
+	Expense  int64 `json:"expense"`
+}
+
+type recurrenceFormData struct {
+	Kind         string                               `json:"kind" validate:"required,oneof=event expense"`
+	Title        string                               `json:"title" validate:"required,min=1,max=255"`
+	Description  string                               `json:"description" validate:"max=1000"`
+	Place        string                               `json:"place" validate:"max=255"`
+	Time         string                               `json:"time" validate:"required_if=Kind event"`
+	Amount       int64                                `json:"amount" validate:"required,gt=0"`
+	Freq         string                               `json:"freq" validate:"required,oneof=daily weekly monthly yearly"`
+	Interval     int64                                `json:"interval" validate:"required,gte=1,max=365"`
+	StartDate    string                               `json:"startDate" validate:"required"`
+	EndDate      string                               `json:"endDate"`
+	Count        int64                                `json:"count" validate:"gte=0,max=1000"`
+	Participants map[string]recurrenceParticipantData `json:"participants"`
+}
+
+type recurrenceFormParams struct {
+	TabID    string             `json:"tab_id"`
+	FormData recurrenceFormData `json:"formData"`
+}
+
+type tabParams struct {
+	TabID string `json:"tab_id"`
+}
+
+var recurrenceErrorFields = []string{"kind", "title", "description", "place", "time", "amount", "freq", "interval", "startDate", "endDate", "count"}
+
+func recurrenceFormSignals(rec *db.Recurrence, members []db.Member, participants []recurrencestore.ListRecurrenceParticipantsRow) map[string]any {
+	defaults := make(map[string]recurrencestore.ListRecurrenceParticipantsRow, len(participants))
+	for _, participant := range participants {
+		defaults[participant.MemberID] = participant
+	}
+
+	memberSignals := make(map[string]any, len(members))
+	for _, member := range members {
+		participant, ok := defaults[member.ID]
+		memberSignals[member.ID] = map[string]any{
+			"included": ok,
+			"amount":   participant.Amount,
+			"expense":  participant.Expense,
+		}
+	}
+
+	formData := map[string]any{
+		"kind":         recurrencestore.KindEvent,
+		"title":        "",
+		"description":  "",
+		"place":        "",
+		"time":         "",
+		"amount":       0,
+		"freq":         freqMonthly,
+		"interval":     1,
+		"startDate":    "",
+		"endDate":      "",
+		"count":        0,
+		"participants": memberSignals,
+	}
+	if rec != nil {
+		formData["kind"] = rec.Kind
+		formData["title"] = rec.Title
+		formData["description"] = rec.Description
+		formData["place"] = rec.Place
+		formData["time"] = rec.EventTime
+		formData["amount"] = rec.Amount
+		formData["freq"] = rec.Freq
+		formData["interval"] = rec.Interval
+		formData["startDate"] = rec.StartDate
+		formData["endDate"] = rec.EndDate
+		formData["count"] = rec.Count
+	}
+
+	errors := make(map[string]any, len(recurrenceErrorFields))
+	for _, field := range recurrenceErrorFields {
+		errors[field] = ""
+	}
+
+	return map[string]any{
+		"formData":  formData,
+		"errors":    errors,
+		"_fetching": false,
+	}
+}
+
+func recurrenceShowSignals() map[string]any {
+	return map[string]any{
+		"mode":      "single",
+		"_fetching": false,
+	}
+}
@@ -0,0 +1,23 @@
+package recurrence
+
+import "bandcash/internal/utils"
+
+func RecurrencesIndexTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "title"},
+		{Key: "kind", MaxWRem: 8},
+		{Key: "schedule", MaxWRem: 14},
+		{Key: "start_date", MaxWRem: 12},
+		{Key: "ends", MaxWRem: 16},
+		{Key: "amount", MaxWRem: 10},
+	}, 0)
+}
+
+func GeneratedRowsTableLayout() utils.TableLayout {
+	return utils.NewTableLayout([]utils.TableColumn{
+		{Key: "title"},
+		{Key: "date", MaxWRem: 12},
+		{Key: "amount", MaxWRem: 10},
+		{Key: "paid", MaxWRem: 7, WRem: 7},
+	}, 0)
+}
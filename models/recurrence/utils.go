@@ -0,0 +1,45 @@
+package recurrence
+
+import (
+	"context"
+	"strconv"
+
+	ctxi18n "github.com/invopop/ctxi18n/i18n"
+
+	"bandcash/internal/db"
+	"bandcash/internal/utils"
+	recurrencestore "bandcash/models/recurrence/data"
+)
+
+func scheduleLabel(ctx context.Context, rec db.Recurrence) string {
+	return ctxi18n.T(ctx, "recurrences.every."+rec.Freq, strconv.FormatInt(rec.Interval, 10))
+}
+
+func kindLabel(ctx context.Context, kind string) string {
+	if kind == recurrencestore.KindExpense {
+		return ctxi18n.T(ctx, "recurrences.kinds.expense")
+	}
+	return ctxi18n.T(ctx, "recurrences.kinds.event")
+}
+
+// endLabel describes where the series stops: an end date, a number of
+// occurrences, both, or neither.
+func endLabel(ctx context.Context, rec db.Recurrence) string {
+	switch {
+	case rec.EndDate != "" && rec.Count > 0:
+		return ctxi18n.T(ctx, "recurrences.ends_on_or_after", utils.FormatDateLocalized(ctx, rec.EndDate), strconv.FormatInt(rec.Count, 10))
+	case rec.EndDate != "":
+		return ctxi18n.T(ctx, "recurrences.ends_on", utils.FormatDateLocalized(ctx, rec.EndDate))
+	case rec.Count > 0:
+		return ctxi18n.T(ctx, "recurrences.ends_after", strconv.FormatInt(rec.Count, 10))
+	default:
+		return ctxi18n.T(ctx, "recurrences.never_ends")
+	}
+}
+
+func generatedRowHref(rec db.Recurrence, rowID string) string {
+	if rec.Kind == recurrencestore.KindExpense {
+		return "/groups/" + rec.GroupID + "/expenses/" + rowID
+	}
+	return "/groups/" + rec.GroupID + "/events/" + rowID
+}
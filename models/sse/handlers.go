@@ -33,12 +33,12 @@ func SSEHandler() echo.HandlerFunc {
 		tabIDValue := utils.TabIDFromContext(c.Request().Context())
 
 		sseConn := datastar.NewSSE(w, r)
-		utils.SSEHub.AddClient(tabIDValue, sseConn)
+		client := utils.SSEHub.AddClient(tabIDValue, sseConn, r.Header.Get("Last-Event-ID"))
 
 		log.Debug("sse: client connected", "tab_id", tabIDValue)
 
 		defer func() {
-			utils.SSEHub.RemoveClient(tabIDValue)
+			utils.SSEHub.RemoveClient(client)
 			log.Debug("sse: client disconnected", "tab_id", tabIDValue)
 		}()
 